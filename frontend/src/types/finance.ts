@@ -10,6 +10,8 @@ export interface FinanceAccount {
   name: string;
   type: string;
   balance: number;
+  openingBalance: number;
+  openingDate: string;
   currency: string;
 }
 
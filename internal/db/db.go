@@ -11,15 +11,23 @@ import (
 	_ "github.com/mattn/go-sqlite3"
 )
 
+// DataDir returns the application data directory, ~/.tally.
+func DataDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".tally"), nil
+}
+
 // Init sets up the SQLite database, creating directories and running migrations.
 func Init() *sql.DB {
 	// Ensure data directory exists
-	homeDir, err := os.UserHomeDir()
+	appDataDir, err := DataDir()
 	if err != nil {
 		log.Fatal("Failed to get user home directory:", err)
 	}
 
-	appDataDir := filepath.Join(homeDir, ".tally")
 	if err := os.MkdirAll(appDataDir, 0700); err != nil {
 		log.Fatal("Failed to create app data directory:", err)
 	}
@@ -38,17 +46,20 @@ func Init() *sql.DB {
 		runLegacyMigrations(db)
 	}
 
+	if err := EnsureSearchIndex(db); err != nil {
+		log.Printf("Full-text search index unavailable, search falls back to plain matching: %v", err)
+	}
+
 	return db
 }
 
 // Open opens the application database without running migrations.
 func Open() (*sql.DB, string, error) {
-	homeDir, err := os.UserHomeDir()
+	appDataDir, err := DataDir()
 	if err != nil {
 		return nil, "", err
 	}
 
-	appDataDir := filepath.Join(homeDir, ".tally")
 	if err := os.MkdirAll(appDataDir, 0700); err != nil {
 		return nil, "", err
 	}
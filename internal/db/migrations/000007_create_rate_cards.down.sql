@@ -0,0 +1,2 @@
+-- 000007_create_rate_cards.down.sql
+DROP TABLE IF EXISTS rate_cards;
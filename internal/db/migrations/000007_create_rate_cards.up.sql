@@ -0,0 +1,23 @@
+-- 000007_create_rate_cards.up.sql
+-- Dated hourly rates per client or project. The rate in force on a time entry's
+-- date wins over projects.hourly_rate, which remains the fallback.
+
+CREATE TABLE IF NOT EXISTS rate_cards (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    client_id INTEGER, -- set for client-wide rates
+    project_id INTEGER, -- set for project rates (overrides client rates)
+    rate REAL NOT NULL,
+    currency TEXT,
+    effective_from TEXT NOT NULL, -- YYYY-MM-DD, inclusive
+    notes TEXT,
+    created_at TEXT DEFAULT (datetime('now')),
+    updated_at TEXT DEFAULT (datetime('now')),
+    FOREIGN KEY(user_id) REFERENCES users(id),
+    FOREIGN KEY(client_id) REFERENCES clients(id),
+    FOREIGN KEY(project_id) REFERENCES projects(id),
+    CHECK (client_id IS NOT NULL OR project_id IS NOT NULL)
+);
+
+CREATE INDEX idx_rate_cards_project ON rate_cards(user_id, project_id, effective_from);
+CREATE INDEX idx_rate_cards_client ON rate_cards(user_id, client_id, effective_from);
@@ -0,0 +1,33 @@
+-- 000008_add_expense_allocation.down.sql
+-- SQLite cannot drop columns that carry foreign keys, so rebuild the table.
+
+PRAGMA foreign_keys=OFF;
+
+CREATE TABLE finance_transactions_old (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    account_id INTEGER NOT NULL,
+    category_id INTEGER,
+    date TEXT NOT NULL,
+    description TEXT NOT NULL,
+    amount REAL NOT NULL,
+    status TEXT DEFAULT 'pending',
+    reference_id TEXT,
+    created_at TEXT DEFAULT (datetime('now')),
+    updated_at TEXT DEFAULT (datetime('now')),
+    FOREIGN KEY(user_id) REFERENCES users(id),
+    FOREIGN KEY(account_id) REFERENCES finance_accounts(id),
+    FOREIGN KEY(category_id) REFERENCES finance_categories(id)
+);
+
+INSERT INTO finance_transactions_old (
+    id, user_id, account_id, category_id, date, description, amount, status, reference_id, created_at, updated_at
+)
+SELECT
+    id, user_id, account_id, category_id, date, description, amount, status, reference_id, created_at, updated_at
+FROM finance_transactions;
+
+DROP TABLE finance_transactions;
+ALTER TABLE finance_transactions_old RENAME TO finance_transactions;
+
+PRAGMA foreign_keys=ON;
@@ -0,0 +1,12 @@
+-- 000008_add_expense_allocation.up.sql
+-- Allow finance transactions to be allocated to a client/project as expenses,
+-- and track which invoice a reimbursable expense was billed on.
+
+ALTER TABLE finance_transactions ADD COLUMN client_id INTEGER REFERENCES clients(id);
+ALTER TABLE finance_transactions ADD COLUMN project_id INTEGER REFERENCES projects(id);
+ALTER TABLE finance_transactions ADD COLUMN expense_type TEXT; -- reimbursable | non_reimbursable
+ALTER TABLE finance_transactions ADD COLUMN invoice_id INTEGER REFERENCES invoices(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_finance_transactions_project ON finance_transactions(user_id, project_id);
+CREATE INDEX idx_finance_transactions_client ON finance_transactions(user_id, client_id);
+CREATE INDEX idx_finance_transactions_invoice ON finance_transactions(invoice_id);
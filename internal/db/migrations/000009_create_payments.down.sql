@@ -0,0 +1,2 @@
+-- 000009_create_payments.down.sql
+DROP TABLE IF EXISTS payments;
@@ -0,0 +1,25 @@
+-- 000009_create_payments.up.sql
+-- Payments received from clients and credits issued to them. Rows may be tied
+-- to an invoice or left unapplied on the client's account.
+
+CREATE TABLE IF NOT EXISTS payments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    client_id INTEGER NOT NULL,
+    invoice_id INTEGER, -- NULL for unapplied payments and credits
+    kind TEXT NOT NULL DEFAULT 'payment', -- payment | credit
+    date TEXT NOT NULL, -- YYYY-MM-DD
+    amount REAL NOT NULL, -- always positive; reduces the client's balance
+    method TEXT,
+    reference TEXT,
+    notes TEXT,
+    created_at TEXT DEFAULT (datetime('now')),
+    updated_at TEXT DEFAULT (datetime('now')),
+    FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY(client_id) REFERENCES clients(id) ON DELETE CASCADE,
+    FOREIGN KEY(invoice_id) REFERENCES invoices(id) ON DELETE SET NULL,
+    CHECK (kind IN ('payment', 'credit'))
+);
+
+CREATE INDEX idx_payments_client ON payments(user_id, client_id, date);
+CREATE INDEX idx_payments_invoice ON payments(invoice_id);
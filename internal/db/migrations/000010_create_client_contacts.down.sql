@@ -0,0 +1,2 @@
+-- 000010_create_client_contacts.down.sql
+DROP TABLE IF EXISTS client_contacts;
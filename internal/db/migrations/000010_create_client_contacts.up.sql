@@ -0,0 +1,22 @@
+-- 000010_create_client_contacts.up.sql
+-- People at a client and the role they play. Invoice emails go to billing
+-- contacts, with cc/bcc contacts copied. Clients without billing contacts
+-- keep using clients.email.
+
+CREATE TABLE IF NOT EXISTS client_contacts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    client_id INTEGER NOT NULL,
+    name TEXT NOT NULL DEFAULT '',
+    email TEXT NOT NULL,
+    phone TEXT,
+    title TEXT,
+    role TEXT NOT NULL DEFAULT 'billing', -- billing | technical | cc | bcc
+    created_at TEXT DEFAULT (datetime('now')),
+    updated_at TEXT DEFAULT (datetime('now')),
+    FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY(client_id) REFERENCES clients(id) ON DELETE CASCADE,
+    CHECK (role IN ('billing', 'technical', 'cc', 'bcc'))
+);
+
+CREATE INDEX idx_client_contacts_client ON client_contacts(user_id, client_id);
@@ -0,0 +1,8 @@
+-- 000011_add_payment_terms.down.sql
+DROP TABLE IF EXISTS invoice_late_fees;
+
+ALTER TABLE clients DROP COLUMN payment_terms;
+
+ALTER TABLE user_invoice_settings DROP COLUMN late_fee_value;
+ALTER TABLE user_invoice_settings DROP COLUMN late_fee_type;
+ALTER TABLE user_invoice_settings DROP COLUMN payment_terms;
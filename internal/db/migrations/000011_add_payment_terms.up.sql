@@ -0,0 +1,23 @@
+-- 000011_add_payment_terms.up.sql
+-- Structured payment terms (due_on_receipt, net_N, eom_N) for the user and per
+-- client, a late-fee rule on the user's invoice settings, and the late fees
+-- charged on overdue invoices.
+
+ALTER TABLE user_invoice_settings ADD COLUMN payment_terms TEXT; -- NULL/empty: no structured terms, DefaultTerms text applies
+ALTER TABLE user_invoice_settings ADD COLUMN late_fee_type TEXT DEFAULT ''; -- '' (none) | flat | percent
+ALTER TABLE user_invoice_settings ADD COLUMN late_fee_value REAL DEFAULT 0; -- amount, or percent per month
+
+ALTER TABLE clients ADD COLUMN payment_terms TEXT; -- NULL/empty inherits the user's terms
+
+CREATE TABLE IF NOT EXISTS invoice_late_fees (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    invoice_id INTEGER NOT NULL,
+    period INTEGER NOT NULL, -- 1 for the first month overdue, 2 for the second, ...
+    amount REAL NOT NULL,
+    description TEXT NOT NULL,
+    created_at TEXT DEFAULT (datetime('now')),
+    FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY(invoice_id) REFERENCES invoices(id) ON DELETE CASCADE,
+    UNIQUE(invoice_id, period)
+);
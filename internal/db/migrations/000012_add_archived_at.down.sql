@@ -0,0 +1,2 @@
+ALTER TABLE projects DROP COLUMN archived_at;
+ALTER TABLE clients DROP COLUMN archived_at;
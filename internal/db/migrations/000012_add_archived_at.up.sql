@@ -0,0 +1,5 @@
+-- 000012_add_archived_at.up.sql
+-- Soft delete for clients and projects: archived rows are hidden from lists but keep their history.
+
+ALTER TABLE clients ADD COLUMN archived_at DATETIME;
+ALTER TABLE projects ADD COLUMN archived_at DATETIME;
@@ -0,0 +1,13 @@
+UPDATE projects SET tags = (
+    SELECT group_concat(t.name, ',')
+    FROM entity_tags et
+    JOIN tags t ON t.id = et.tag_id
+    WHERE et.entity_type = 'project' AND et.entity_id = projects.id
+);
+
+DROP TRIGGER IF EXISTS entity_tags_transaction_ad;
+DROP TRIGGER IF EXISTS entity_tags_invoice_ad;
+DROP TRIGGER IF EXISTS entity_tags_time_entry_ad;
+DROP TRIGGER IF EXISTS entity_tags_project_ad;
+DROP TABLE IF EXISTS entity_tags;
+DROP TABLE IF EXISTS tags;
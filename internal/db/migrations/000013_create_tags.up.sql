@@ -0,0 +1,60 @@
+-- 000013_create_tags.up.sql
+-- Normalized tags shared by projects, time entries, invoices and finance
+-- transactions. Replaces the comma-separated projects.tags column, whose
+-- values are copied over.
+
+CREATE TABLE IF NOT EXISTS tags (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER,
+    name TEXT NOT NULL COLLATE NOCASE,
+    color TEXT,
+    created_at TEXT DEFAULT (datetime('now')),
+    FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE(user_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS entity_tags (
+    tag_id INTEGER NOT NULL,
+    entity_type TEXT NOT NULL CHECK (entity_type IN ('project', 'time_entry', 'invoice', 'transaction')),
+    entity_id INTEGER NOT NULL,
+    FOREIGN KEY(tag_id) REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY(tag_id, entity_type, entity_id)
+);
+
+CREATE INDEX idx_entity_tags_entity ON entity_tags(entity_type, entity_id);
+
+-- Tagged rows disappear with their entity.
+CREATE TRIGGER IF NOT EXISTS entity_tags_project_ad AFTER DELETE ON projects BEGIN
+    DELETE FROM entity_tags WHERE entity_type = 'project' AND entity_id = old.id;
+END;
+CREATE TRIGGER IF NOT EXISTS entity_tags_time_entry_ad AFTER DELETE ON time_entries BEGIN
+    DELETE FROM entity_tags WHERE entity_type = 'time_entry' AND entity_id = old.id;
+END;
+CREATE TRIGGER IF NOT EXISTS entity_tags_invoice_ad AFTER DELETE ON invoices BEGIN
+    DELETE FROM entity_tags WHERE entity_type = 'invoice' AND entity_id = old.id;
+END;
+CREATE TRIGGER IF NOT EXISTS entity_tags_transaction_ad AFTER DELETE ON finance_transactions BEGIN
+    DELETE FROM entity_tags WHERE entity_type = 'transaction' AND entity_id = old.id;
+END;
+
+-- Copy projects.tags ("a,b,c") into the new tables.
+CREATE TEMP TABLE project_tag_split AS
+WITH RECURSIVE split(user_id, project_id, tag, rest) AS (
+    SELECT user_id, id, '', tags || ',' FROM projects WHERE tags IS NOT NULL AND TRIM(tags) != ''
+    UNION ALL
+    SELECT user_id, project_id, TRIM(substr(rest, 1, instr(rest, ',') - 1)), substr(rest, instr(rest, ',') + 1)
+    FROM split WHERE rest != ''
+)
+SELECT user_id, project_id, tag FROM split WHERE tag != '';
+
+INSERT OR IGNORE INTO tags(user_id, name)
+SELECT user_id, tag FROM project_tag_split;
+
+INSERT OR IGNORE INTO entity_tags(tag_id, entity_type, entity_id)
+SELECT t.id, 'project', s.project_id
+FROM project_tag_split s
+JOIN tags t ON t.user_id IS s.user_id AND t.name = s.tag;
+
+DROP TABLE project_tag_split;
+
+UPDATE projects SET tags = NULL;
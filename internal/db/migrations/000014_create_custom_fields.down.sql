@@ -0,0 +1,6 @@
+-- 000014_create_custom_fields.down.sql
+DROP TRIGGER IF EXISTS custom_field_values_invoice_ad;
+DROP TRIGGER IF EXISTS custom_field_values_project_ad;
+DROP TRIGGER IF EXISTS custom_field_values_client_ad;
+DROP TABLE IF EXISTS custom_field_values;
+DROP TABLE IF EXISTS custom_field_definitions;
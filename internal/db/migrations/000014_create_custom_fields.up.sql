@@ -0,0 +1,42 @@
+-- 000014_create_custom_fields.up.sql
+-- User-defined fields (PO number, cost centre, vendor ID, ...) on clients,
+-- projects and invoices. Values are stored as text and validated by type.
+
+CREATE TABLE IF NOT EXISTS custom_field_definitions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER,
+    entity_type TEXT NOT NULL CHECK (entity_type IN ('client', 'project', 'invoice')),
+    field_key TEXT NOT NULL,
+    label TEXT NOT NULL,
+    field_type TEXT NOT NULL CHECK (field_type IN ('text', 'number', 'date', 'select')),
+    options_json TEXT DEFAULT '[]',
+    show_on_invoice BOOLEAN DEFAULT 1,
+    sort_order INTEGER DEFAULT 0,
+    created_at TEXT DEFAULT (datetime('now')),
+    FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE(user_id, entity_type, field_key)
+);
+
+CREATE TABLE IF NOT EXISTS custom_field_values (
+    field_id INTEGER NOT NULL,
+    entity_id INTEGER NOT NULL,
+    value TEXT NOT NULL,
+    FOREIGN KEY(field_id) REFERENCES custom_field_definitions(id) ON DELETE CASCADE,
+    PRIMARY KEY(field_id, entity_id)
+);
+
+CREATE INDEX idx_custom_field_values_entity ON custom_field_values(entity_id);
+
+-- Values disappear with their record.
+CREATE TRIGGER IF NOT EXISTS custom_field_values_client_ad AFTER DELETE ON clients BEGIN
+    DELETE FROM custom_field_values WHERE entity_id = old.id
+      AND field_id IN (SELECT id FROM custom_field_definitions WHERE entity_type = 'client');
+END;
+CREATE TRIGGER IF NOT EXISTS custom_field_values_project_ad AFTER DELETE ON projects BEGIN
+    DELETE FROM custom_field_values WHERE entity_id = old.id
+      AND field_id IN (SELECT id FROM custom_field_definitions WHERE entity_type = 'project');
+END;
+CREATE TRIGGER IF NOT EXISTS custom_field_values_invoice_ad AFTER DELETE ON invoices BEGIN
+    DELETE FROM custom_field_values WHERE entity_id = old.id
+      AND field_id IN (SELECT id FROM custom_field_definitions WHERE entity_type = 'invoice');
+END;
@@ -0,0 +1,4 @@
+-- 000015_create_purchase_orders.down.sql
+ALTER TABLE clients DROP COLUMN requires_po;
+ALTER TABLE invoices DROP COLUMN purchase_order_id;
+DROP TABLE IF EXISTS purchase_orders;
@@ -0,0 +1,24 @@
+-- 000015_create_purchase_orders.up.sql
+-- Client purchase orders with an authorized amount and validity window.
+-- Invoices reference the PO they bill against.
+
+CREATE TABLE IF NOT EXISTS purchase_orders (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER,
+    client_id INTEGER NOT NULL,
+    number TEXT NOT NULL,
+    authorized_amount REAL NOT NULL DEFAULT 0,
+    currency TEXT,
+    valid_from TEXT,
+    valid_to TEXT,
+    notes TEXT,
+    created_at TEXT DEFAULT (datetime('now')),
+    FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY(client_id) REFERENCES clients(id) ON DELETE CASCADE,
+    UNIQUE(user_id, client_id, number)
+);
+
+CREATE INDEX idx_purchase_orders_client ON purchase_orders(client_id);
+
+ALTER TABLE invoices ADD COLUMN purchase_order_id INTEGER REFERENCES purchase_orders(id);
+ALTER TABLE clients ADD COLUMN requires_po BOOLEAN DEFAULT 0;
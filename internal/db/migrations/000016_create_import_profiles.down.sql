@@ -0,0 +1,2 @@
+-- 000016_create_import_profiles.down.sql
+DROP TABLE IF EXISTS import_profiles;
@@ -0,0 +1,23 @@
+-- 000016_create_import_profiles.up.sql
+-- Saved CSV layouts for bank exports. Columns are 1-based numbers or header
+-- names; the built-in CIBC, RBC and TD layouts live in code.
+
+CREATE TABLE IF NOT EXISTS import_profiles (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER,
+    name TEXT NOT NULL,
+    delimiter TEXT NOT NULL DEFAULT ',',
+    skip_rows INTEGER NOT NULL DEFAULT 0,
+    date_column TEXT NOT NULL,
+    description_columns_json TEXT NOT NULL DEFAULT '[]',
+    amount_column TEXT,
+    debit_column TEXT,
+    credit_column TEXT,
+    reference_column TEXT,
+    date_format TEXT,
+    sign_convention TEXT NOT NULL DEFAULT 'signed' CHECK (sign_convention IN ('signed', 'inverted', 'debit_credit')),
+    decimal_separator TEXT NOT NULL DEFAULT '.' CHECK (decimal_separator IN ('.', ',')),
+    created_at TEXT DEFAULT (datetime('now')),
+    FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE(user_id, name)
+);
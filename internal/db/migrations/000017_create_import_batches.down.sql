@@ -0,0 +1,4 @@
+-- 000017_create_import_batches.down.sql
+DROP INDEX IF EXISTS idx_finance_transactions_import_batch;
+ALTER TABLE finance_transactions DROP COLUMN import_batch_id;
+DROP TABLE IF EXISTS import_batches;
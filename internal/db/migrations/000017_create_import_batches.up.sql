@@ -0,0 +1,25 @@
+-- 000017_create_import_batches.up.sql
+-- Every transaction import is recorded as a batch so that a wrong import can
+-- be rolled back as a whole.
+
+CREATE TABLE IF NOT EXISTS import_batches (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER,
+    account_id INTEGER NOT NULL,
+    file_name TEXT,
+    format TEXT NOT NULL,
+    profile_id INTEGER,
+    bank_type TEXT,
+    row_count INTEGER NOT NULL DEFAULT 0,
+    imported_count INTEGER NOT NULL DEFAULT 0,
+    created_at TEXT DEFAULT (datetime('now')),
+    rolled_back_at TEXT,
+    FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY(account_id) REFERENCES finance_accounts(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_import_batches_account ON import_batches(account_id);
+
+ALTER TABLE finance_transactions ADD COLUMN import_batch_id INTEGER REFERENCES import_batches(id);
+
+CREATE INDEX idx_finance_transactions_import_batch ON finance_transactions(import_batch_id);
@@ -0,0 +1,4 @@
+-- 000018_add_transaction_fingerprints.down.sql
+DROP INDEX IF EXISTS idx_finance_transactions_fingerprint;
+ALTER TABLE finance_transactions DROP COLUMN possible_duplicate_of_id;
+ALTER TABLE finance_transactions DROP COLUMN fingerprint;
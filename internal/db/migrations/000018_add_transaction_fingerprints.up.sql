@@ -0,0 +1,9 @@
+-- 000018_add_transaction_fingerprints.up.sql
+-- Normalized date/amount/description fingerprints for import deduplication,
+-- and a flag on imported near-duplicates awaiting review.
+-- Existing rows are fingerprinted by the application on their next import.
+
+ALTER TABLE finance_transactions ADD COLUMN fingerprint TEXT;
+ALTER TABLE finance_transactions ADD COLUMN possible_duplicate_of_id INTEGER;
+
+CREATE INDEX idx_finance_transactions_fingerprint ON finance_transactions(account_id, fingerprint);
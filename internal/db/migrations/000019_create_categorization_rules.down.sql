@@ -0,0 +1,6 @@
+-- 000019_create_categorization_rules.down.sql
+DROP TRIGGER IF EXISTS categorization_rules_account_ad;
+DROP TRIGGER IF EXISTS categorization_rules_category_ad;
+ALTER TABLE finance_transactions DROP COLUMN category_rule_id;
+DROP INDEX IF EXISTS idx_categorization_rules_user;
+DROP TABLE IF EXISTS categorization_rules;
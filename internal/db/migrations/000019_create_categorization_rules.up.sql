@@ -0,0 +1,35 @@
+-- 000019_create_categorization_rules.up.sql
+-- Rules that categorize and tag transactions by description, amount and
+-- account. Transactions remember the rule that categorized them, so manual
+-- categorizations can be told apart.
+
+CREATE TABLE IF NOT EXISTS categorization_rules (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER,
+    name TEXT NOT NULL,
+    priority INTEGER NOT NULL DEFAULT 0,
+    match_type TEXT NOT NULL DEFAULT 'substring' CHECK (match_type IN ('substring', 'regex')),
+    pattern TEXT,
+    min_amount REAL,
+    max_amount REAL,
+    account_id INTEGER,
+    category_id INTEGER NOT NULL,
+    tags TEXT,
+    disabled BOOLEAN DEFAULT 0,
+    created_at TEXT DEFAULT (datetime('now')),
+    FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY(account_id) REFERENCES finance_accounts(id) ON DELETE CASCADE,
+    FOREIGN KEY(category_id) REFERENCES finance_categories(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_categorization_rules_user ON categorization_rules(user_id, priority);
+
+ALTER TABLE finance_transactions ADD COLUMN category_rule_id INTEGER REFERENCES categorization_rules(id);
+
+-- Rules disappear with the category they assign or the account they watch.
+CREATE TRIGGER IF NOT EXISTS categorization_rules_category_ad AFTER DELETE ON finance_categories BEGIN
+    DELETE FROM categorization_rules WHERE category_id = old.id;
+END;
+CREATE TRIGGER IF NOT EXISTS categorization_rules_account_ad AFTER DELETE ON finance_accounts BEGIN
+    DELETE FROM categorization_rules WHERE account_id = old.id;
+END;
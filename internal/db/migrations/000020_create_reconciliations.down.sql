@@ -0,0 +1,7 @@
+-- 000020_create_reconciliations.down.sql
+DROP TRIGGER IF EXISTS reconciliations_account_ad;
+ALTER TABLE finance_transactions DROP COLUMN reconciliation_id;
+DROP INDEX IF EXISTS idx_reconciliations_account;
+DROP TABLE IF EXISTS reconciliations;
+ALTER TABLE finance_accounts DROP COLUMN opening_date;
+ALTER TABLE finance_accounts DROP COLUMN opening_balance;
@@ -0,0 +1,35 @@
+-- 000020_create_reconciliations.up.sql
+-- Account balances are derived from an opening balance plus transactions, and
+-- statements are reconciled against cleared transactions. The opening balance
+-- of existing accounts is set so their derived balance equals the balance that
+-- was typed in. Transactions use pending, cleared and reconciled statuses.
+
+ALTER TABLE finance_accounts ADD COLUMN opening_balance REAL DEFAULT 0;
+ALTER TABLE finance_accounts ADD COLUMN opening_date TEXT;
+
+UPDATE finance_accounts SET opening_balance = COALESCE(balance, 0) - COALESCE(
+    (SELECT SUM(t.amount) FROM finance_transactions t WHERE t.account_id = finance_accounts.id), 0);
+
+CREATE TABLE IF NOT EXISTS reconciliations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER,
+    account_id INTEGER NOT NULL,
+    statement_date TEXT NOT NULL,
+    statement_balance REAL NOT NULL,
+    status TEXT NOT NULL DEFAULT 'in_progress' CHECK (status IN ('in_progress', 'completed')),
+    created_at TEXT DEFAULT (datetime('now')),
+    completed_at TEXT,
+    FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY(account_id) REFERENCES finance_accounts(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_reconciliations_account ON reconciliations(account_id);
+
+ALTER TABLE finance_transactions ADD COLUMN reconciliation_id INTEGER REFERENCES reconciliations(id);
+
+UPDATE finance_transactions SET status = 'pending'
+WHERE status IS NULL OR status NOT IN ('pending', 'cleared', 'reconciled');
+
+CREATE TRIGGER IF NOT EXISTS reconciliations_account_ad AFTER DELETE ON finance_accounts BEGIN
+    DELETE FROM reconciliations WHERE account_id = old.id;
+END;
@@ -0,0 +1,5 @@
+-- 000021_create_transaction_splits.down.sql
+DROP TRIGGER IF EXISTS transaction_splits_category_ad;
+DROP TRIGGER IF EXISTS transaction_splits_transaction_ad;
+DROP INDEX IF EXISTS idx_transaction_splits_transaction;
+DROP TABLE IF EXISTS transaction_splits;
@@ -0,0 +1,25 @@
+-- 000021_create_transaction_splits.up.sql
+-- Splits divide one transaction across categories. Their amounts add up to
+-- the transaction's; a split transaction has no category of its own.
+
+CREATE TABLE IF NOT EXISTS transaction_splits (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER,
+    transaction_id INTEGER NOT NULL,
+    category_id INTEGER,
+    amount REAL NOT NULL,
+    memo TEXT,
+    sort_order INTEGER DEFAULT 0,
+    FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY(transaction_id) REFERENCES finance_transactions(id) ON DELETE CASCADE,
+    FOREIGN KEY(category_id) REFERENCES finance_categories(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_transaction_splits_transaction ON transaction_splits(transaction_id);
+
+CREATE TRIGGER IF NOT EXISTS transaction_splits_transaction_ad AFTER DELETE ON finance_transactions BEGIN
+    DELETE FROM transaction_splits WHERE transaction_id = old.id;
+END;
+CREATE TRIGGER IF NOT EXISTS transaction_splits_category_ad AFTER DELETE ON finance_categories BEGIN
+    UPDATE transaction_splits SET category_id = NULL WHERE category_id = old.id;
+END;
@@ -0,0 +1,5 @@
+-- 000022_add_transfers.down.sql
+DROP TRIGGER IF EXISTS finance_transactions_transfer_ad;
+DROP INDEX IF EXISTS idx_finance_transactions_transfer;
+ALTER TABLE finance_transactions DROP COLUMN not_transfer;
+ALTER TABLE finance_transactions DROP COLUMN transfer_id;
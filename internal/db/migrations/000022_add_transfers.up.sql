@@ -0,0 +1,14 @@
+-- 000022_add_transfers.up.sql
+-- Transfers between the user's own accounts link the outgoing and incoming
+-- transactions to each other; linked transactions are neither income nor
+-- expense. not_transfer remembers pairs the user unlinked so detection leaves
+-- them alone.
+
+ALTER TABLE finance_transactions ADD COLUMN transfer_id INTEGER;
+ALTER TABLE finance_transactions ADD COLUMN not_transfer BOOLEAN DEFAULT 0;
+
+CREATE INDEX idx_finance_transactions_transfer ON finance_transactions(transfer_id);
+
+CREATE TRIGGER IF NOT EXISTS finance_transactions_transfer_ad AFTER DELETE ON finance_transactions BEGIN
+    UPDATE finance_transactions SET transfer_id = NULL WHERE transfer_id = old.id;
+END;
@@ -0,0 +1,4 @@
+-- 000023_add_payment_transactions.down.sql
+DROP TRIGGER IF EXISTS payments_transaction_ad;
+DROP INDEX IF EXISTS idx_payments_transaction;
+ALTER TABLE payments DROP COLUMN transaction_id;
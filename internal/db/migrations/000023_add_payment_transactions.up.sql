@@ -0,0 +1,11 @@
+-- 000023_add_payment_transactions.up.sql
+-- Links a payment to the bank deposit it arrived in, so a deposit matched to
+-- an invoice is not offered again.
+
+ALTER TABLE payments ADD COLUMN transaction_id INTEGER;
+
+CREATE INDEX idx_payments_transaction ON payments(transaction_id);
+
+CREATE TRIGGER IF NOT EXISTS payments_transaction_ad AFTER DELETE ON finance_transactions BEGIN
+    UPDATE payments SET transaction_id = NULL WHERE transaction_id = old.id;
+END;
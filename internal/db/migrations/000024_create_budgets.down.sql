@@ -0,0 +1,5 @@
+-- 000024_create_budgets.down.sql
+DROP TRIGGER IF EXISTS budget_alerts_budget_ad;
+DROP TRIGGER IF EXISTS budgets_category_ad;
+DROP TABLE IF EXISTS budget_alerts;
+DROP TABLE IF EXISTS budgets;
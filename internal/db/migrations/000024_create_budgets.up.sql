@@ -0,0 +1,37 @@
+-- 000024_create_budgets.up.sql
+-- Monthly or annual spending budgets per expense category. Rollover carries
+-- what is left of one period (or also what was overspent) into the next.
+-- budget_alerts records the alerts already raised so each is sent once.
+
+CREATE TABLE IF NOT EXISTS budgets (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER,
+    category_id INTEGER NOT NULL,
+    period TEXT NOT NULL DEFAULT 'monthly' CHECK (period IN ('monthly', 'annual')),
+    amount REAL NOT NULL,
+    rollover TEXT NOT NULL DEFAULT 'none' CHECK (rollover IN ('none', 'unspent', 'all')),
+    start_date TEXT NOT NULL,
+    alert_threshold REAL NOT NULL DEFAULT 80,
+    created_at TEXT DEFAULT (datetime('now')),
+    updated_at TEXT DEFAULT (datetime('now')),
+    UNIQUE(user_id, category_id),
+    FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY(category_id) REFERENCES finance_categories(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS budget_alerts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    budget_id INTEGER NOT NULL,
+    period_start TEXT NOT NULL,
+    level TEXT NOT NULL CHECK (level IN ('warning', 'over')),
+    created_at TEXT DEFAULT (datetime('now')),
+    UNIQUE(budget_id, period_start, level),
+    FOREIGN KEY(budget_id) REFERENCES budgets(id) ON DELETE CASCADE
+);
+
+CREATE TRIGGER IF NOT EXISTS budgets_category_ad AFTER DELETE ON finance_categories BEGIN
+    DELETE FROM budgets WHERE category_id = old.id;
+END;
+CREATE TRIGGER IF NOT EXISTS budget_alerts_budget_ad AFTER DELETE ON budgets BEGIN
+    DELETE FROM budget_alerts WHERE budget_id = old.id;
+END;
@@ -0,0 +1,7 @@
+-- 000025_create_attachments.down.sql
+DROP TRIGGER IF EXISTS attachments_client_ad;
+DROP TRIGGER IF EXISTS attachments_invoice_ad;
+DROP TRIGGER IF EXISTS attachments_transaction_ad;
+DROP INDEX IF EXISTS idx_attachments_sha256;
+DROP INDEX IF EXISTS idx_attachments_entity;
+DROP TABLE IF EXISTS attachments;
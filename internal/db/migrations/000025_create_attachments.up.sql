@@ -0,0 +1,33 @@
+-- 000025_create_attachments.up.sql
+-- Receipts and other documents attached to finance transactions, invoices and
+-- clients. File contents live under ~/.tally/attachments, named by their
+-- SHA-256, so identical files are stored once; files no row refers to are
+-- removed by orphan cleanup.
+
+CREATE TABLE IF NOT EXISTS attachments (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER,
+    entity_type TEXT NOT NULL CHECK (entity_type IN ('transaction', 'invoice', 'client')),
+    entity_id INTEGER NOT NULL,
+    sha256 TEXT NOT NULL,
+    file_name TEXT NOT NULL,
+    mime_type TEXT NOT NULL,
+    size INTEGER NOT NULL,
+    created_at TEXT DEFAULT (datetime('now')),
+    FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE(entity_type, entity_id, sha256)
+);
+
+CREATE INDEX idx_attachments_entity ON attachments(entity_type, entity_id);
+CREATE INDEX idx_attachments_sha256 ON attachments(sha256);
+
+-- Attachment rows disappear with their record; the files go at the next cleanup.
+CREATE TRIGGER IF NOT EXISTS attachments_transaction_ad AFTER DELETE ON finance_transactions BEGIN
+    DELETE FROM attachments WHERE entity_type = 'transaction' AND entity_id = old.id;
+END;
+CREATE TRIGGER IF NOT EXISTS attachments_invoice_ad AFTER DELETE ON invoices BEGIN
+    DELETE FROM attachments WHERE entity_type = 'invoice' AND entity_id = old.id;
+END;
+CREATE TRIGGER IF NOT EXISTS attachments_client_ad AFTER DELETE ON clients BEGIN
+    DELETE FROM attachments WHERE entity_type = 'client' AND entity_id = old.id;
+END;
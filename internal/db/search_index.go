@@ -0,0 +1,142 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"strings"
+)
+
+// ErrSearchUnavailable is returned when SQLite was built without FTS5
+// (the go-sqlite3 "sqlite_fts5" build tag).
+var ErrSearchUnavailable = errors.New("full-text search requires SQLite FTS5")
+
+// searchIndexes describes the FTS5 tables behind global search. Each index
+// row shares its rowid with the source row; ownership is checked on the source.
+var searchIndexes = []struct {
+	table   string // FTS5 table name
+	source  string // indexed table
+	columns string // FTS5 column list
+	watch   string // source columns whose updates refresh the index
+	values  string // expressions over new.* producing the indexed values
+}{
+	{
+		table:   "search_clients",
+		source:  "clients",
+		columns: "name, notes",
+		watch:   "name, notes",
+		values:  "new.name, COALESCE(new.notes, '')",
+	},
+	{
+		table:   "search_projects",
+		source:  "projects",
+		columns: "name, description",
+		watch:   "name, description",
+		values:  "new.name, COALESCE(new.description, '')",
+	},
+	{
+		table:   "search_time_entries",
+		source:  "time_entries",
+		columns: "description",
+		watch:   "description",
+		values:  "COALESCE(new.description, '')",
+	},
+	{
+		table:   "search_invoices",
+		source:  "invoices",
+		columns: "number, items",
+		watch:   "number, items_json",
+		values: `COALESCE(new.number, ''), COALESCE((
+			SELECT group_concat(json_extract(value, '$.description'), ' ')
+			FROM json_each(CASE WHEN json_valid(new.items_json) THEN new.items_json ELSE '[]' END)), '')`,
+	},
+	{
+		table:   "search_transactions",
+		source:  "finance_transactions",
+		columns: "description",
+		watch:   "description",
+		values:  "COALESCE(new.description, '')",
+	},
+}
+
+// EnsureSearchIndex creates the full-text search tables and the triggers that
+// keep them in sync. A table is (re)filled from its source when it is new or
+// when its triggers were missing, so it is safe to call on every start.
+//
+// When FTS5 is not compiled in, the sync triggers are dropped so writes keep
+// working on a database indexed by another build, and ErrSearchUnavailable is
+// returned; search then falls back to plain matching.
+func EnsureSearchIndex(conn *sql.DB) error {
+	var fts5 bool
+	if err := conn.QueryRow("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&fts5); err != nil {
+		return err
+	}
+	if !fts5 {
+		for _, idx := range searchIndexes {
+			for _, suffix := range []string{"_ai", "_ad", "_au"} {
+				if _, err := conn.Exec("DROP TRIGGER IF EXISTS " + idx.table + suffix); err != nil {
+					return err
+				}
+			}
+		}
+		return ErrSearchUnavailable
+	}
+
+	for _, idx := range searchIndexes {
+		var tables, triggers int
+		if err := conn.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", idx.table).Scan(&tables); err != nil {
+			return err
+		}
+		if err := conn.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name IN (?, ?, ?)",
+			idx.table+"_ai", idx.table+"_ad", idx.table+"_au").Scan(&triggers); err != nil {
+			return err
+		}
+		rebuild := tables == 0 || triggers < 3
+
+		tx, err := conn.Begin()
+		if err != nil {
+			return err
+		}
+		if err := createSearchIndex(tx, idx.table, idx.source, idx.columns, idx.watch, idx.values, rebuild); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+		if rebuild {
+			log.Printf("Search: built %s index", idx.table)
+		}
+	}
+	return nil
+}
+
+// createSearchIndex creates one FTS5 table with its insert, update and delete
+// triggers, and optionally refills it from the source table.
+func createSearchIndex(tx *sql.Tx, table, source, columns, watch, values string, rebuild bool) error {
+	queries := []string{
+		`CREATE VIRTUAL TABLE IF NOT EXISTS ` + table + ` USING fts5(` + columns + `, tokenize = 'unicode61 remove_diacritics 2')`,
+		`CREATE TRIGGER IF NOT EXISTS ` + table + `_ai AFTER INSERT ON ` + source + ` BEGIN
+			INSERT INTO ` + table + `(rowid, ` + columns + `) VALUES (new.id, ` + values + `);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS ` + table + `_ad AFTER DELETE ON ` + source + ` BEGIN
+			DELETE FROM ` + table + ` WHERE rowid = old.id;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS ` + table + `_au AFTER UPDATE OF ` + watch + ` ON ` + source + ` BEGIN
+			DELETE FROM ` + table + ` WHERE rowid = old.id;
+			INSERT INTO ` + table + `(rowid, ` + columns + `) VALUES (new.id, ` + values + `);
+		END`,
+	}
+	if rebuild {
+		fillValues := strings.ReplaceAll(values, "new.", "src.")
+		queries = append(queries,
+			`DELETE FROM `+table,
+			`INSERT INTO `+table+`(rowid, `+columns+`) SELECT src.id, `+fillValues+` FROM `+source+` src`)
+	}
+	for _, query := range queries {
+		if _, err := tx.Exec(query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
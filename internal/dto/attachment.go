@@ -0,0 +1,34 @@
+package dto
+
+// AddAttachmentInput attaches a file to a finance transaction, invoice or client.
+type AddAttachmentInput struct {
+	EntityType string `json:"entityType"` // transaction | invoice | client
+	EntityID   int    `json:"entityId"`
+	FileName   string `json:"fileName"`
+	Content    string `json:"content"` // base64
+}
+
+// AttachmentOutput represents an attached file.
+type AttachmentOutput struct {
+	ID         int    `json:"id"`
+	EntityType string `json:"entityType"`
+	EntityID   int    `json:"entityId"`
+	SHA256     string `json:"sha256"`
+	FileName   string `json:"fileName"`
+	MimeType   string `json:"mimeType"` // sniffed from the content
+	Size       int64  `json:"size"`
+	CreatedAt  string `json:"createdAt"`
+}
+
+// AttachmentContentOutput is an attachment with its content.
+type AttachmentContentOutput struct {
+	Attachment AttachmentOutput `json:"attachment"`
+	Content    string           `json:"content"` // base64
+}
+
+// AttachmentCleanupOutput reports what orphan cleanup removed.
+type AttachmentCleanupOutput struct {
+	RemovedRows  int   `json:"removedRows"`  // attachments whose record no longer exists
+	RemovedFiles int   `json:"removedFiles"` // stored files no attachment refers to
+	FreedBytes   int64 `json:"freedBytes"`
+}
@@ -0,0 +1,69 @@
+package dto
+
+// CreateBudgetInput sets a budget for an expense category.
+type CreateBudgetInput struct {
+	CategoryID int     `json:"categoryId"`
+	Period     string  `json:"period"` // monthly (default) | annual
+	Amount     float64 `json:"amount"`
+	// Rollover is none (default), unspent to carry what is left into the next
+	// period, or all to also carry overspending.
+	Rollover string `json:"rollover"`
+	// StartDate is moved to the first of its month; empty starts this month.
+	// Annual budgets run for twelve months from it.
+	StartDate string `json:"startDate"`
+	// AlertThreshold is the percent spent that raises a warning; 0 means 80.
+	AlertThreshold float64 `json:"alertThreshold"`
+}
+
+// UpdateBudgetInput changes a budget. Its category cannot change.
+type UpdateBudgetInput struct {
+	ID             int     `json:"id"`
+	Period         string  `json:"period"`
+	Amount         float64 `json:"amount"`
+	Rollover       string  `json:"rollover"`
+	StartDate      string  `json:"startDate"`
+	AlertThreshold float64 `json:"alertThreshold"`
+}
+
+// BudgetOutput represents a budget with the period it is in.
+type BudgetOutput struct {
+	ID             int                `json:"id"`
+	CategoryID     int                `json:"categoryId"`
+	CategoryName   string             `json:"categoryName"`
+	CategoryColor  string             `json:"categoryColor"`
+	Period         string             `json:"period"`
+	Amount         float64            `json:"amount"`
+	Rollover       string             `json:"rollover"`
+	StartDate      string             `json:"startDate"`
+	AlertThreshold float64            `json:"alertThreshold"`
+	CreatedAt      string             `json:"createdAt"`
+	UpdatedAt      string             `json:"updatedAt"`
+	Current        BudgetPeriodOutput `json:"current"`
+}
+
+// BudgetPeriodOutput compares actual spending with the budget for one period.
+type BudgetPeriodOutput struct {
+	PeriodStart string  `json:"periodStart"` // YYYY-MM-DD
+	PeriodEnd   string  `json:"periodEnd"`   // inclusive
+	Budgeted    float64 `json:"budgeted"`
+	Carryover   float64 `json:"carryover"` // rolled over from earlier periods, negative when overspent
+	Available   float64 `json:"available"` // budgeted plus carryover
+	Actual      float64 `json:"actual"`    // spending net of refunds
+	Remaining   float64 `json:"remaining"`
+	PercentUsed float64 `json:"percentUsed"`
+	Status      string  `json:"status"` // ok | warning | over
+}
+
+// BudgetAlertOutput is raised once per period when spending reaches a
+// budget's threshold and again when it goes over. It is also emitted to the
+// frontend as a budget:alert event.
+type BudgetAlertOutput struct {
+	BudgetID     int     `json:"budgetId"`
+	CategoryID   int     `json:"categoryId"`
+	CategoryName string  `json:"categoryName"`
+	PeriodStart  string  `json:"periodStart"`
+	Level        string  `json:"level"` // warning | over
+	Available    float64 `json:"available"`
+	Actual       float64 `json:"actual"`
+	PercentUsed  float64 `json:"percentUsed"`
+}
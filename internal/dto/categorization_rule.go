@@ -0,0 +1,63 @@
+package dto
+
+// CreateCategorizationRuleInput represents the input for a new categorization rule.
+type CreateCategorizationRuleInput struct {
+	Name       string   `json:"name"`
+	Priority   int      `json:"priority"`
+	MatchType  string   `json:"matchType"` // substring (default) or regex
+	Pattern    string   `json:"pattern"`
+	MinAmount  *float64 `json:"minAmount"`
+	MaxAmount  *float64 `json:"maxAmount"`
+	AccountID  int      `json:"accountId"` // 0 for any account
+	CategoryID int      `json:"categoryId"`
+	Tags       []string `json:"tags"`
+	Disabled   bool     `json:"disabled"`
+}
+
+// UpdateCategorizationRuleInput represents the input for changing a categorization rule.
+type UpdateCategorizationRuleInput struct {
+	ID         int      `json:"id"`
+	Name       string   `json:"name"`
+	Priority   int      `json:"priority"`
+	MatchType  string   `json:"matchType"`
+	Pattern    string   `json:"pattern"`
+	MinAmount  *float64 `json:"minAmount"`
+	MaxAmount  *float64 `json:"maxAmount"`
+	AccountID  int      `json:"accountId"`
+	CategoryID int      `json:"categoryId"`
+	Tags       []string `json:"tags"`
+	Disabled   bool     `json:"disabled"`
+}
+
+// CategorizationRuleOutput represents a categorization rule.
+type CategorizationRuleOutput struct {
+	ID           int      `json:"id"`
+	Name         string   `json:"name"`
+	Priority     int      `json:"priority"`
+	MatchType    string   `json:"matchType"`
+	Pattern      string   `json:"pattern"`
+	MinAmount    *float64 `json:"minAmount"`
+	MaxAmount    *float64 `json:"maxAmount"`
+	AccountID    *int     `json:"accountId"`
+	CategoryID   int      `json:"categoryId"`
+	CategoryName string   `json:"categoryName"`
+	Tags         []string `json:"tags"`
+	Disabled     bool     `json:"disabled"`
+	MatchCount   int      `json:"matchCount"`
+}
+
+// ApplyRulesOutput reports a run of the rules over uncategorized transactions.
+type ApplyRulesOutput struct {
+	Checked     int `json:"checked"`
+	Categorized int `json:"categorized"`
+}
+
+// RuleSuggestionOutput proposes a rule learned from manual categorizations.
+// It can be passed to Create as is.
+type RuleSuggestionOutput struct {
+	Rule          CreateCategorizationRuleInput `json:"rule"`
+	CategoryName  string                        `json:"categoryName"`
+	MatchCount    int                           `json:"matchCount"`    // manually categorized transactions it covers
+	Uncategorized int                           `json:"uncategorized"` // uncategorized transactions it would categorize
+	Examples      []string                      `json:"examples"`
+}
@@ -0,0 +1,26 @@
+package dto
+
+import "time"
+
+// CategoryCandidate is one category the classifier proposes for a transaction.
+type CategoryCandidate struct {
+	CategoryID   int     `json:"categoryId"`
+	CategoryName string  `json:"categoryName"`
+	Confidence   float64 `json:"confidence"` // 0..1, candidates of one transaction sum to at most 1
+}
+
+// CategorySuggestionOutput ranks likely categories for an uncategorized transaction.
+type CategorySuggestionOutput struct {
+	TransactionID int                 `json:"transactionId"`
+	AccountID     int                 `json:"accountId"`
+	Date          time.Time           `json:"date"`
+	Description   string              `json:"description"`
+	Amount        float64             `json:"amount"`
+	Candidates    []CategoryCandidate `json:"candidates"` // best first; empty without history
+}
+
+// SetTransactionCategoryInput assigns a category to one transaction.
+type SetTransactionCategoryInput struct {
+	TransactionID int `json:"transactionId"`
+	CategoryID    int `json:"categoryId"`
+}
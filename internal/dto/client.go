@@ -19,6 +19,8 @@ type CreateClientInput struct {
 	BillingCity       string `json:"billingCity"`
 	BillingProvince   string `json:"billingProvince"`
 	BillingPostalCode string `json:"billingPostalCode"`
+	PaymentTerms      string `json:"paymentTerms"` // empty inherits the user's terms
+	RequiresPO        bool   `json:"requiresPo"`   // invoices must reference a valid purchase order
 }
 
 // UpdateClientInput represents the input for updating an existing client.
@@ -40,6 +42,8 @@ type UpdateClientInput struct {
 	BillingCity       string `json:"billingCity"`
 	BillingProvince   string `json:"billingProvince"`
 	BillingPostalCode string `json:"billingPostalCode"`
+	PaymentTerms      string `json:"paymentTerms"` // empty inherits the user's terms
+	RequiresPO        bool   `json:"requiresPo"`   // invoices must reference a valid purchase order
 }
 
 // ClientOutput represents the client data returned from API.
@@ -60,4 +64,7 @@ type ClientOutput struct {
 	BillingCity       string `json:"billingCity"`
 	BillingProvince   string `json:"billingProvince"`
 	BillingPostalCode string `json:"billingPostalCode"`
+	PaymentTerms      string `json:"paymentTerms"` // empty inherits the user's terms
+	RequiresPO        bool   `json:"requiresPo"`   // invoices must reference a valid purchase order
+	ArchivedAt        string `json:"archivedAt"`   // empty while the client is active
 }
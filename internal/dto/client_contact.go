@@ -0,0 +1,39 @@
+package dto
+
+// CreateClientContactInput represents the input for adding a contact to a client.
+type CreateClientContactInput struct {
+	ClientID int    `json:"clientId"`
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Phone    string `json:"phone"`
+	Title    string `json:"title"`
+	Role     string `json:"role"` // billing (default) | technical | cc | bcc
+}
+
+// UpdateClientContactInput represents the input for updating a client contact.
+type UpdateClientContactInput struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Phone string `json:"phone"`
+	Title string `json:"title"`
+	Role  string `json:"role"`
+}
+
+// ClientContactOutput represents a client contact returned from API.
+type ClientContactOutput struct {
+	ID       int    `json:"id"`
+	ClientID int    `json:"clientId"`
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Phone    string `json:"phone"`
+	Title    string `json:"title"`
+	Role     string `json:"role"`
+}
+
+// EmailRecipients lists the addresses an invoice email is sent to.
+type EmailRecipients struct {
+	To  []string `json:"to"`
+	CC  []string `json:"cc"`
+	BCC []string `json:"bcc"`
+}
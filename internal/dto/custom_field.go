@@ -0,0 +1,54 @@
+package dto
+
+// CreateCustomFieldInput represents the input for defining a custom field.
+// Key defaults to a snake_case form of Label and is used in templates as {{key}}.
+type CreateCustomFieldInput struct {
+	EntityType    string   `json:"entityType"` // client | project | invoice
+	Key           string   `json:"key"`
+	Label         string   `json:"label"`
+	FieldType     string   `json:"fieldType"` // text | number | date | select
+	Options       []string `json:"options"`
+	ShowOnInvoice bool     `json:"showOnInvoice"`
+	SortOrder     int      `json:"sortOrder"`
+}
+
+// UpdateCustomFieldInput represents the input for changing a custom field.
+// The entity type, key and field type are fixed once created.
+type UpdateCustomFieldInput struct {
+	ID            int      `json:"id"`
+	Label         string   `json:"label"`
+	Options       []string `json:"options"`
+	ShowOnInvoice bool     `json:"showOnInvoice"`
+	SortOrder     int      `json:"sortOrder"`
+}
+
+// CustomFieldOutput represents a custom field definition returned from API.
+type CustomFieldOutput struct {
+	ID            int      `json:"id"`
+	EntityType    string   `json:"entityType"`
+	Key           string   `json:"key"`
+	Label         string   `json:"label"`
+	FieldType     string   `json:"fieldType"`
+	Options       []string `json:"options"`
+	ShowOnInvoice bool     `json:"showOnInvoice"`
+	SortOrder     int      `json:"sortOrder"`
+}
+
+// CustomFieldValueOutput is the value of one custom field on a record.
+type CustomFieldValueOutput struct {
+	FieldID       int    `json:"fieldId"`
+	EntityType    string `json:"entityType"`
+	Key           string `json:"key"`
+	Label         string `json:"label"`
+	FieldType     string `json:"fieldType"`
+	Value         string `json:"value"`
+	ShowOnInvoice bool   `json:"showOnInvoice"`
+}
+
+// SetCustomFieldValuesInput sets custom field values on one record, keyed by
+// field key. An empty value clears the field; keys not listed are left alone.
+type SetCustomFieldValuesInput struct {
+	EntityType string            `json:"entityType"`
+	EntityID   int               `json:"entityId"`
+	Values     map[string]string `json:"values"`
+}
@@ -0,0 +1,22 @@
+package dto
+
+// Dependents counts the records that reference a client or project.
+// Project-level counts leave Projects, Invoices, Payments and Contacts at zero.
+type Dependents struct {
+	Projects            int `json:"projects"`
+	TimeEntries         int `json:"timeEntries"`
+	InvoicedTimeEntries int `json:"invoicedTimeEntries"`
+	Invoices            int `json:"invoices"`
+	Payments            int `json:"payments"`
+	Contacts            int `json:"contacts"`
+	RateCards           int `json:"rateCards"`
+	Transactions        int `json:"transactions"` // finance transactions linked to the record
+	PurchaseOrders      int `json:"purchaseOrders"`
+}
+
+// CascadeDeleteInput confirms a hard delete that also removes dependent records.
+// ConfirmName must repeat the client or project name exactly.
+type CascadeDeleteInput struct {
+	ID          int    `json:"id"`
+	ConfirmName string `json:"confirmName"`
+}
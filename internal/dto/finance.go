@@ -4,32 +4,39 @@ import "time"
 
 // CreateAccountInput represents the input to create a new account.
 type CreateAccountInput struct {
-	Name     string  `json:"name"`
-	Type     string  `json:"type"`
-	Currency string  `json:"currency"`
-	Balance  float64 `json:"balance"`
-	BankName string  `json:"bankName"`
+	Name           string  `json:"name"`
+	Type           string  `json:"type"`
+	Currency       string  `json:"currency"`
+	OpeningBalance float64 `json:"openingBalance"`
+	OpeningDate    string  `json:"openingDate"` // YYYY-MM-DD; earlier transactions don't count. Empty counts all.
+	BankName       string  `json:"bankName"`
 }
 
 // UpdateAccountInput represents the input to update an account.
 type UpdateAccountInput struct {
-	ID       int     `json:"id"`
-	Name     string  `json:"name"`
-	Type     string  `json:"type"`
-	Currency string  `json:"currency"`
-	Balance  float64 `json:"balance"`
-	BankName string  `json:"bankName"`
+	ID             int     `json:"id"`
+	Name           string  `json:"name"`
+	Type           string  `json:"type"`
+	Currency       string  `json:"currency"`
+	OpeningBalance float64 `json:"openingBalance"`
+	OpeningDate    string  `json:"openingDate"`
+	BankName       string  `json:"bankName"`
 }
 
-// AccountOutput represents the output for an account.
+// AccountOutput represents the output for an account. Balances are derived
+// from the opening balance and the account's transactions.
 type AccountOutput struct {
-	ID        int       `json:"id"`
-	Name      string    `json:"name"`
-	Type      string    `json:"type"`
-	Currency  string    `json:"currency"`
-	Balance   float64   `json:"balance"`
-	BankName  string    `json:"bankName"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	ID                 int       `json:"id"`
+	Name               string    `json:"name"`
+	Type               string    `json:"type"`
+	Currency           string    `json:"currency"`
+	OpeningBalance     float64   `json:"openingBalance"`
+	OpeningDate        string    `json:"openingDate"`
+	Balance            float64   `json:"balance"`
+	ClearedBalance     float64   `json:"clearedBalance"`     // counting cleared and reconciled transactions only
+	LastReconciledDate string    `json:"lastReconciledDate"` // statement date of the last completed reconciliation
+	BankName           string    `json:"bankName"`
+	UpdatedAt          time.Time `json:"updatedAt"`
 }
 
 // CreateCategoryInput represents the input to create a new category.
@@ -70,6 +77,65 @@ type TransactionOutput struct {
 	Amount       float64         `json:"amount"`
 	Status       string          `json:"status"`
 	ReferenceID  string          `json:"referenceId"`
+	ClientID     *int            `json:"clientId"`
+	ProjectID    *int            `json:"projectId"`
+	ExpenseType  string          `json:"expenseType,omitempty"`
+	InvoiceID    *int            `json:"invoiceId"`
+	Tags         []string        `json:"tags"`
+	// PossibleDuplicateOfID is set on imported rows awaiting a keep/delete decision.
+	PossibleDuplicateOfID *int `json:"possibleDuplicateOfId,omitempty"`
+	// Splits divide the transaction across categories; CategoryID is then nil.
+	Splits []TransactionSplitOutput `json:"splits,omitempty"`
+	// TransferID links a transfer to its counterpart in another account.
+	TransferID *int `json:"transferId,omitempty"`
+	// AttachmentCount is the number of receipts and documents attached.
+	AttachmentCount int `json:"attachmentCount"`
+}
+
+// TransactionSplitInput is one part of a split transaction.
+type TransactionSplitInput struct {
+	CategoryID int     `json:"categoryId"`
+	Amount     float64 `json:"amount"` // signed like the transaction
+	Memo       string  `json:"memo"`
+}
+
+// SetTransactionSplitsInput replaces the splits of a transaction. The amounts
+// must add up to the transaction's; no splits removes them.
+type SetTransactionSplitsInput struct {
+	TransactionID int                     `json:"transactionId"`
+	Splits        []TransactionSplitInput `json:"splits"`
+}
+
+// TransactionSplitOutput represents one part of a split transaction.
+type TransactionSplitOutput struct {
+	ID            int     `json:"id"`
+	CategoryID    *int    `json:"categoryId"` // nil once the category is deleted
+	CategoryName  string  `json:"categoryName,omitempty"`
+	CategoryColor string  `json:"categoryColor,omitempty"`
+	Amount        float64 `json:"amount"`
+	Memo          string  `json:"memo"`
+}
+
+// LinkTransferInput pairs two transactions as a transfer between accounts.
+type LinkTransferInput struct {
+	TransactionID int `json:"transactionId"`
+	CounterpartID int `json:"counterpartId"`
+}
+
+// TransferOutput represents a transfer: money leaving one account and
+// arriving in another.
+type TransferOutput struct {
+	OutgoingID      int     `json:"outgoingId"`
+	IncomingID      int     `json:"incomingId"`
+	FromAccountID   int     `json:"fromAccountId"`
+	FromAccountName string  `json:"fromAccountName"`
+	ToAccountID     int     `json:"toAccountId"`
+	ToAccountName   string  `json:"toAccountName"`
+	Amount          float64 `json:"amount"` // positive
+	FromDate        string  `json:"fromDate"`
+	ToDate          string  `json:"toDate"`
+	FromDescription string  `json:"fromDescription"`
+	ToDescription   string  `json:"toDescription"`
 }
 
 // ImportTransactionsInput represents the input to import transactions.
@@ -77,6 +143,12 @@ type ImportTransactionsInput struct {
 	AccountID   int    `json:"accountId"`
 	BankType    string `json:"bankType"` // CIBC, RBC, TD
 	FileContent string `json:"fileContent"` // Base64 or raw string
+	// ProfileID selects a saved import profile; BankType is used when zero.
+	ProfileID int `json:"profileId"`
+	// Format is csv, ofx (also QFX) or camt053; empty detects it from the content.
+	Format string `json:"format"`
+	// FileName is recorded on the import batch.
+	FileName string `json:"fileName"`
 }
 
 // TransactionFilter represents filtering options for transactions.
@@ -84,6 +156,23 @@ type TransactionFilter struct {
 	StartDate string `json:"startDate,omitempty"`
 	EndDate   string `json:"endDate,omitempty"`
 	AccountID int    `json:"accountId,omitempty"`
+	ClientID  int    `json:"clientId,omitempty"`
+	ProjectID int    `json:"projectId,omitempty"`
+	// Tags keeps transactions carrying any of the tags.
+	Tags []string `json:"tags,omitempty"`
+	// NeedsReview keeps imported possible duplicates awaiting a decision.
+	NeedsReview bool `json:"needsReview,omitempty"`
+	// ExcludeTransfers leaves out transactions linked as transfers.
+	ExcludeTransfers bool `json:"excludeTransfers,omitempty"`
+}
+
+// AllocateExpenseInput tags a transaction as a client or project expense.
+// An empty ExpenseType clears the allocation.
+type AllocateExpenseInput struct {
+	TransactionID int    `json:"transactionId"`
+	ClientID      int    `json:"clientId"`
+	ProjectID     int    `json:"projectId"`
+	ExpenseType   string `json:"expenseType"` // reimbursable, non_reimbursable
 }
 
 // FinanceSummary represents the dashboard summary.
@@ -93,3 +182,75 @@ type FinanceSummary struct {
 	TotalExpense float64 `json:"totalExpense"`
 	CashFlow     float64 `json:"cashFlow"`
 }
+
+// SummaryPeriodInput selects the period of a finance summary.
+type SummaryPeriodInput struct {
+	Period string `json:"period"` // month (default) | quarter | year | custom
+	// Date picks the month, quarter or year containing it; empty is today.
+	Date string `json:"date"`
+	// StartDate and EndDate bound a custom period, inclusive.
+	StartDate string `json:"startDate"`
+	EndDate   string `json:"endDate"`
+}
+
+// PeriodTotals sums income and expenses over a period. Transfers between
+// accounts are left out.
+type PeriodTotals struct {
+	StartDate      string  `json:"startDate"`
+	EndDate        string  `json:"endDate"`
+	Income         float64 `json:"income"`
+	Expense        float64 `json:"expense"` // positive
+	CashFlow       float64 `json:"cashFlow"`
+	ClosingBalance float64 `json:"closingBalance"` // all accounts at the end of the period
+}
+
+// PeriodChange compares a period with the one before it. Percentages are
+// nil when the previous value is zero.
+type PeriodChange struct {
+	Income          float64  `json:"income"`
+	Expense         float64  `json:"expense"`
+	CashFlow        float64  `json:"cashFlow"`
+	IncomePercent   *float64 `json:"incomePercent"`
+	ExpensePercent  *float64 `json:"expensePercent"`
+	CashFlowPercent *float64 `json:"cashFlowPercent"`
+}
+
+// PeriodSummaryOutput is a finance summary for a period compared with the
+// previous period of the same length.
+type PeriodSummaryOutput struct {
+	Period   string       `json:"period"`
+	Current  PeriodTotals `json:"current"`
+	Previous PeriodTotals `json:"previous"`
+	Change   PeriodChange `json:"change"`
+}
+
+// CashFlowSeriesInput selects the months of a cash-flow series. Empty dates
+// cover the last twelve months; AccountID and CategoryID narrow it.
+type CashFlowSeriesInput struct {
+	StartDate  string `json:"startDate"`
+	EndDate    string `json:"endDate"`
+	AccountID  int    `json:"accountId"`
+	CategoryID int    `json:"categoryId"`
+}
+
+// CashFlowSeries is monthly income, expense and net for one account or
+// category, aligned with CashFlowSeriesOutput.Months.
+type CashFlowSeries struct {
+	ID      int       `json:"id"` // 0 for uncategorized
+	Name    string    `json:"name"`
+	Color   string    `json:"color,omitempty"`
+	Income  []float64 `json:"income"`
+	Expense []float64 `json:"expense"` // positive
+	Net     []float64 `json:"net"`
+}
+
+// CashFlowSeriesOutput provides monthly cash-flow series for charts, in
+// total and per account and category. Transfers are left out.
+type CashFlowSeriesOutput struct {
+	Months     []string         `json:"months"` // YYYY-MM
+	Income     []float64        `json:"income"`
+	Expense    []float64        `json:"expense"`
+	Net        []float64        `json:"net"`
+	ByAccount  []CashFlowSeries `json:"byAccount"`
+	ByCategory []CashFlowSeries `json:"byCategory"`
+}
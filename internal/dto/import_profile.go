@@ -0,0 +1,83 @@
+package dto
+
+// CreateImportProfileInput represents the input for saving a bank CSV layout.
+// Columns are 1-based numbers ("3") or header names ("Posted Date").
+type CreateImportProfileInput struct {
+	Name               string   `json:"name"`
+	Delimiter          string   `json:"delimiter"` // defaults to ","
+	SkipRows           int      `json:"skipRows"`
+	DateColumn         string   `json:"dateColumn"`
+	DescriptionColumns []string `json:"descriptionColumns"`
+	AmountColumn       string   `json:"amountColumn"`
+	DebitColumn        string   `json:"debitColumn"`
+	CreditColumn       string   `json:"creditColumn"`
+	ReferenceColumn    string   `json:"referenceColumn"`
+	DateFormat         string   `json:"dateFormat"`       // e.g. YYYY-MM-DD, DD/MM/YYYY, DD MMM YYYY
+	SignConvention     string   `json:"signConvention"`   // signed | inverted | debit_credit
+	DecimalSeparator   string   `json:"decimalSeparator"` // defaults to "."
+}
+
+// UpdateImportProfileInput represents the input for changing a saved layout.
+type UpdateImportProfileInput struct {
+	ID                 int      `json:"id"`
+	Name               string   `json:"name"`
+	Delimiter          string   `json:"delimiter"`
+	SkipRows           int      `json:"skipRows"`
+	DateColumn         string   `json:"dateColumn"`
+	DescriptionColumns []string `json:"descriptionColumns"`
+	AmountColumn       string   `json:"amountColumn"`
+	DebitColumn        string   `json:"debitColumn"`
+	CreditColumn       string   `json:"creditColumn"`
+	ReferenceColumn    string   `json:"referenceColumn"`
+	DateFormat         string   `json:"dateFormat"`
+	SignConvention     string   `json:"signConvention"`
+	DecimalSeparator   string   `json:"decimalSeparator"`
+}
+
+// ImportProfileOutput represents a bank CSV layout returned from API.
+// Built-in layouts have ID 0 and are selected by name as the bank type.
+type ImportProfileOutput struct {
+	ID                 int      `json:"id"`
+	Name               string   `json:"name"`
+	Builtin            bool     `json:"builtin"`
+	Delimiter          string   `json:"delimiter"`
+	SkipRows           int      `json:"skipRows"`
+	DateColumn         string   `json:"dateColumn"`
+	DescriptionColumns []string `json:"descriptionColumns"`
+	AmountColumn       string   `json:"amountColumn"`
+	DebitColumn        string   `json:"debitColumn"`
+	CreditColumn       string   `json:"creditColumn"`
+	ReferenceColumn    string   `json:"referenceColumn"`
+	DateFormat         string   `json:"dateFormat"`
+	SignConvention     string   `json:"signConvention"`
+	DecimalSeparator   string   `json:"decimalSeparator"`
+}
+
+// PreviewImportInput parses a file without saving anything. Profile, when
+// set, wins over ProfileID, which wins over the built-in BankType.
+type PreviewImportInput struct {
+	FileContent string                    `json:"fileContent"`
+	ProfileID   int                       `json:"profileId"`
+	BankType    string                    `json:"bankType"`
+	Profile     *CreateImportProfileInput `json:"profile"`
+	Limit       int                       `json:"limit"` // rows returned, defaults to 20
+}
+
+// ImportPreviewRow is one parsed data row; Error is set when it would be skipped.
+type ImportPreviewRow struct {
+	Line        int     `json:"line"` // 1-based line in the file
+	Date        string  `json:"date"`
+	Description string  `json:"description"`
+	Amount      float64 `json:"amount"`
+	ReferenceID string  `json:"referenceId"`
+	Error       string  `json:"error,omitempty"`
+}
+
+// ImportPreviewOutput shows how a file reads with a profile.
+type ImportPreviewOutput struct {
+	Profile    ImportProfileOutput `json:"profile"`
+	Headers    []string            `json:"headers"`
+	Rows       []ImportPreviewRow  `json:"rows"`
+	TotalRows  int                 `json:"totalRows"`
+	ErrorCount int                 `json:"errorCount"`
+}
@@ -29,6 +29,8 @@ type CreateInvoiceInput struct {
 	Total     float64            `json:"total"`
 	Status    string             `json:"status"`
 	Items     []InvoiceItemInput `json:"items"`
+	// PurchaseOrderID optionally bills the invoice against a client PO.
+	PurchaseOrderID int `json:"purchaseOrderId"`
 }
 
 // UpdateInvoiceInput represents the input for updating an existing invoice.
@@ -48,18 +50,26 @@ type UpdateInvoiceInput struct {
 
 // InvoiceOutput represents the invoice data returned from API.
 type InvoiceOutput struct {
-	ID        int                 `json:"id"`
-	ClientID  int                 `json:"clientId"`
-	ProjectID int                 `json:"projectId"` // 0 if mixed or not set, but usually linked to one project
-	Number    string              `json:"number"`
-	IssueDate string              `json:"issueDate"`
-	DueDate   string              `json:"dueDate"`
-	Subtotal  float64             `json:"subtotal"`
-	TaxRate   float64             `json:"taxRate"`
-	TaxAmount float64             `json:"taxAmount"`
-	Total     float64             `json:"total"`
-	Status    string              `json:"status"`
-	Items     []InvoiceItemOutput `json:"items"`
+	ID                  int                 `json:"id"`
+	ClientID            int                 `json:"clientId"`
+	ProjectID           int                 `json:"projectId"` // 0 if mixed or not set, but usually linked to one project
+	Number              string              `json:"number"`
+	IssueDate           string              `json:"issueDate"`
+	DueDate             string              `json:"dueDate"`
+	Subtotal            float64             `json:"subtotal"`
+	TaxRate             float64             `json:"taxRate"`
+	TaxAmount           float64             `json:"taxAmount"`
+	Total               float64             `json:"total"`
+	Status              string              `json:"status"`
+	Items               []InvoiceItemOutput `json:"items"`
+	PurchaseOrderID     int                 `json:"purchaseOrderId"` // 0 when not billed against a PO
+	PurchaseOrderNumber string              `json:"purchaseOrderNumber"`
+	// Warnings lists purchase order problems, e.g. a missing required PO or an
+	// exceeded authorized amount. Only filled by Get.
+	Warnings []string `json:"warnings"`
+	// CustomFields holds the values from the client, billed projects and the
+	// invoice itself, with the most specific level winning per key.
+	CustomFields []CustomFieldValueOutput `json:"customFields"`
 }
 
 // SetInvoiceTimeEntriesInput links time entries to an invoice.
@@ -67,3 +77,9 @@ type SetInvoiceTimeEntriesInput struct {
 	InvoiceID    int   `json:"invoiceId"`
 	TimeEntryIDs []int `json:"timeEntryIds"`
 }
+
+// SetInvoiceExpensesInput links reimbursable expense transactions to an invoice.
+type SetInvoiceExpensesInput struct {
+	InvoiceID      int   `json:"invoiceId"`
+	TransactionIDs []int `json:"transactionIds"`
+}
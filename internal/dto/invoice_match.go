@@ -0,0 +1,25 @@
+package dto
+
+// InvoiceMatchOutput proposes that a bank deposit is a client paying an
+// outstanding invoice.
+type InvoiceMatchOutput struct {
+	InvoiceID      int     `json:"invoiceId"`
+	InvoiceNumber  string  `json:"invoiceNumber"`
+	ClientID       int     `json:"clientId"`
+	ClientName     string  `json:"clientName"`
+	IssueDate      string  `json:"issueDate"`
+	Outstanding    float64 `json:"outstanding"`
+	TransactionID  int     `json:"transactionId"`
+	AccountID      int     `json:"accountId"`
+	Date           string  `json:"date"`
+	Description    string  `json:"description"`
+	Amount         float64 `json:"amount"`
+	NameMatch      bool    `json:"nameMatch"`      // the description mentions the client
+	DaysAfterIssue int     `json:"daysAfterIssue"` // deposit date minus issue date
+}
+
+// ConfirmInvoiceMatchInput records a deposit as the payment of an invoice.
+type ConfirmInvoiceMatchInput struct {
+	InvoiceID     int `json:"invoiceId"`
+	TransactionID int `json:"transactionId"`
+}
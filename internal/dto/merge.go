@@ -0,0 +1,46 @@
+package dto
+
+// MergeInput merges the source record into the target; the source is removed.
+type MergeInput struct {
+	SourceID int `json:"sourceId"`
+	TargetID int `json:"targetId"`
+}
+
+// MergeReport summarizes what a merge moved onto the surviving record.
+type MergeReport struct {
+	SourceID     int    `json:"sourceId"`
+	SourceName   string `json:"sourceName"`
+	TargetID     int    `json:"targetId"`
+	TargetName   string `json:"targetName"`
+	Projects     int    `json:"projects"`
+	TimeEntries  int    `json:"timeEntries"`
+	Invoices     int    `json:"invoices"`
+	Payments     int    `json:"payments"`
+	Contacts     int    `json:"contacts"`
+	RateCards    int    `json:"rateCards"`
+	Transactions int    `json:"transactions"`
+	// PurchaseOrders counts the source's purchase orders moved to the target;
+	// one the target already has under the same number is merged into it.
+	PurchaseOrders int `json:"purchaseOrders"`
+	// Attachments counts the source's files moved to the target, leaving out
+	// those the target already has.
+	Attachments int `json:"attachments"`
+	// FilledFields lists target fields that were empty and copied from the source.
+	FilledFields []string `json:"filledFields"`
+}
+
+// DuplicateRecord is one member of a group of probable duplicates.
+type DuplicateRecord struct {
+	ID         int    `json:"id"`
+	Name       string `json:"name"`
+	Email      string `json:"email"`
+	ClientID   int    `json:"clientId"` // set for projects
+	ArchivedAt string `json:"archivedAt"`
+}
+
+// DuplicateGroup is a set of records that probably describe the same thing.
+type DuplicateGroup struct {
+	Reason  string            `json:"reason"` // name | email_domain
+	Key     string            `json:"key"`    // the normalized name or the shared domain
+	Records []DuplicateRecord `json:"records"`
+}
@@ -0,0 +1,37 @@
+package dto
+
+// CreatePaymentInput records a payment or credit for a client.
+// When InvoiceID is set the client is taken from the invoice.
+type CreatePaymentInput struct {
+	ClientID  int     `json:"clientId"`
+	InvoiceID int     `json:"invoiceId"`
+	Kind      string  `json:"kind"` // payment (default) | credit
+	Date      string  `json:"date"` // YYYY-MM-DD
+	Amount    float64 `json:"amount"`
+	Method    string  `json:"method"`
+	Reference string  `json:"reference"`
+	Notes     string  `json:"notes"`
+	// TransactionID links the payment to the bank deposit it arrived in.
+	TransactionID int `json:"transactionId"`
+}
+
+// PaymentOutput represents a payment or credit returned from API.
+type PaymentOutput struct {
+	ID            int     `json:"id"`
+	ClientID      int     `json:"clientId"`
+	InvoiceID     int     `json:"invoiceId"`
+	InvoiceNumber string  `json:"invoiceNumber,omitempty"`
+	Kind          string  `json:"kind"`
+	Date          string  `json:"date"`
+	Amount        float64 `json:"amount"`
+	Method        string  `json:"method"`
+	Reference     string  `json:"reference"`
+	Notes         string  `json:"notes"`
+	TransactionID int     `json:"transactionId,omitempty"`
+}
+
+// PaymentFilter narrows the payment listing.
+type PaymentFilter struct {
+	ClientID  int `json:"clientId,omitempty"`
+	InvoiceID int `json:"invoiceId,omitempty"`
+}
@@ -39,4 +39,5 @@ type ProjectOutput struct {
 	Deadline    string   `json:"deadline"`
 	Tags        []string `json:"tags"`
 	ServiceType string   `json:"serviceType"`
+	ArchivedAt  string   `json:"archivedAt"` // empty while the project is active
 }
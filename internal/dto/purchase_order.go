@@ -0,0 +1,45 @@
+package dto
+
+// CreatePurchaseOrderInput represents the input for recording a client purchase order.
+type CreatePurchaseOrderInput struct {
+	ClientID         int     `json:"clientId"`
+	Number           string  `json:"number"`
+	AuthorizedAmount float64 `json:"authorizedAmount"`
+	Currency         string  `json:"currency"`
+	ValidFrom        string  `json:"validFrom"`
+	ValidTo          string  `json:"validTo"`
+	Notes            string  `json:"notes"`
+}
+
+// UpdatePurchaseOrderInput represents the input for changing a purchase order.
+type UpdatePurchaseOrderInput struct {
+	ID               int     `json:"id"`
+	Number           string  `json:"number"`
+	AuthorizedAmount float64 `json:"authorizedAmount"`
+	Currency         string  `json:"currency"`
+	ValidFrom        string  `json:"validFrom"`
+	ValidTo          string  `json:"validTo"`
+	Notes            string  `json:"notes"`
+}
+
+// PurchaseOrderOutput represents a purchase order with its billing balance.
+type PurchaseOrderOutput struct {
+	ID               int     `json:"id"`
+	ClientID         int     `json:"clientId"`
+	Number           string  `json:"number"`
+	AuthorizedAmount float64 `json:"authorizedAmount"`
+	Currency         string  `json:"currency"`
+	ValidFrom        string  `json:"validFrom"`
+	ValidTo          string  `json:"validTo"`
+	Notes            string  `json:"notes"`
+	InvoicedAmount   float64 `json:"invoicedAmount"`
+	RemainingAmount  float64 `json:"remainingAmount"` // negative when overbilled
+	InvoiceCount     int     `json:"invoiceCount"`
+	Status           string  `json:"status"` // active, not_started, expired, exhausted
+}
+
+// SetInvoicePurchaseOrderInput links an invoice to a purchase order; 0 unlinks it.
+type SetInvoicePurchaseOrderInput struct {
+	InvoiceID       int `json:"invoiceId"`
+	PurchaseOrderID int `json:"purchaseOrderId"`
+}
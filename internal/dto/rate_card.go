@@ -0,0 +1,41 @@
+package dto
+
+// CreateRateCardInput represents the input for creating a dated rate.
+// Set ProjectID for a project rate, or only ClientID for a client-wide rate.
+type CreateRateCardInput struct {
+	ClientID      int     `json:"clientId"`
+	ProjectID     int     `json:"projectId"`
+	Rate          float64 `json:"rate"`
+	Currency      string  `json:"currency"`
+	EffectiveFrom string  `json:"effectiveFrom"` // YYYY-MM-DD
+	Notes         string  `json:"notes"`
+}
+
+// UpdateRateCardInput represents the input for updating a dated rate.
+type UpdateRateCardInput struct {
+	ID            int     `json:"id"`
+	ClientID      int     `json:"clientId"`
+	ProjectID     int     `json:"projectId"`
+	Rate          float64 `json:"rate"`
+	Currency      string  `json:"currency"`
+	EffectiveFrom string  `json:"effectiveFrom"`
+	Notes         string  `json:"notes"`
+}
+
+// RateCardOutput represents a dated rate returned from API.
+type RateCardOutput struct {
+	ID            int     `json:"id"`
+	ClientID      int     `json:"clientId"`
+	ProjectID     int     `json:"projectId"`
+	Scope         string  `json:"scope"` // client | project
+	Rate          float64 `json:"rate"`
+	Currency      string  `json:"currency"`
+	EffectiveFrom string  `json:"effectiveFrom"`
+	Notes         string  `json:"notes"`
+}
+
+// RateCardFilter narrows the rate history listing.
+type RateCardFilter struct {
+	ClientID  int `json:"clientId,omitempty"`
+	ProjectID int `json:"projectId,omitempty"`
+}
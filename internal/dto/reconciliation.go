@@ -0,0 +1,33 @@
+package dto
+
+// StartReconciliationInput opens a reconciliation against a bank statement.
+type StartReconciliationInput struct {
+	AccountID        int     `json:"accountId"`
+	StatementDate    string  `json:"statementDate"` // YYYY-MM-DD
+	StatementBalance float64 `json:"statementBalance"`
+}
+
+// SetClearedInput ticks or unticks transactions in an open reconciliation.
+type SetClearedInput struct {
+	ReconciliationID int   `json:"reconciliationId"`
+	TransactionIDs   []int `json:"transactionIds"`
+	Cleared          bool  `json:"cleared"`
+}
+
+// ReconciliationOutput represents a reconciliation. While in progress,
+// ClearedBalance and Difference follow the ticked transactions; Difference
+// must be zero to complete it.
+type ReconciliationOutput struct {
+	ID               int                 `json:"id"`
+	AccountID        int                 `json:"accountId"`
+	AccountName      string              `json:"accountName"`
+	StatementDate    string              `json:"statementDate"`
+	StatementBalance float64             `json:"statementBalance"`
+	Status           string              `json:"status"` // in_progress | completed
+	ClearedBalance   float64             `json:"clearedBalance"`
+	Difference       float64             `json:"difference"` // statement balance minus cleared balance
+	ReconciledCount  int                 `json:"reconciledCount"`
+	CreatedAt        string              `json:"createdAt"`
+	CompletedAt      string              `json:"completedAt"`
+	Transactions     []TransactionOutput `json:"transactions,omitempty"` // unreconciled ones to tick, from Get
+}
@@ -0,0 +1,30 @@
+package dto
+
+// RecurringExpenseOutput is a charge that repeats at a regular interval,
+// such as a subscription, found in the user's transactions. Amounts are
+// positive.
+type RecurringExpenseOutput struct {
+	Description    string              `json:"description"` // of the latest charge
+	AccountID      int                 `json:"accountId"`
+	AccountName    string              `json:"accountName"`
+	CategoryName   string              `json:"categoryName"`
+	Frequency      string              `json:"frequency"` // weekly, biweekly, monthly, quarterly or annual
+	Amount         float64             `json:"amount"`    // latest charge
+	Variable       bool                `json:"variable"`  // the amount differs from charge to charge, like a utility bill
+	AnnualCost     float64             `json:"annualCost"`
+	Occurrences    int                 `json:"occurrences"`
+	FirstDate      string              `json:"firstDate"`
+	LastDate       string              `json:"lastDate"`
+	NextDate       string              `json:"nextDate"` // when the next charge is expected
+	Active         bool                `json:"active"`   // false once a charge is overdue, e.g. after cancelling
+	PriceChanges   []PriceChangeOutput `json:"priceChanges"`
+	TransactionIDs []int               `json:"transactionIds"`
+}
+
+// PriceChangeOutput is a change in the amount of a fixed recurring charge.
+type PriceChangeOutput struct {
+	Date      string  `json:"date"` // of the first charge at the new price
+	OldAmount float64 `json:"oldAmount"`
+	NewAmount float64 `json:"newAmount"`
+	Percent   float64 `json:"percent"`
+}
@@ -6,6 +6,8 @@ type ReportFilter struct {
 	EndDate   string `json:"endDate"`   // inclusive, YYYY-MM-DD
 	ClientID  int    `json:"clientId"`
 	ProjectID int    `json:"projectId"`
+	// Tags keeps time whose entry or project carries any of the tags.
+	Tags []string `json:"tags"`
 }
 
 // ReportRow is a grouped row for the report table.
@@ -34,3 +36,47 @@ type ReportOutput struct {
 	Chart       ReportChartSeries `json:"chart"`
 }
 
+// ProjectProfitabilityRow summarizes revenue, cost and hours for one project.
+type ProjectProfitabilityRow struct {
+	ClientID             int     `json:"clientId"`
+	ClientName           string  `json:"clientName"`
+	ProjectID            int     `json:"projectId"`
+	ProjectName          string  `json:"projectName"`
+	Hours                float64 `json:"hours"`
+	Revenue              float64 `json:"revenue"`              // share of invoice subtotals, before tax
+	DirectExpenses       float64 `json:"directExpenses"`       // non-reimbursable costs
+	ReimbursableExpenses float64 `json:"reimbursableExpenses"` // passed through to the client
+	Profit               float64 `json:"profit"`               // revenue - direct expenses
+	EffectiveHourlyRate  float64 `json:"effectiveHourlyRate"`  // profit / hours
+}
+
+// ProjectProfitabilityOutput is the per-project profitability report.
+type ProjectProfitabilityOutput struct {
+	Rows                []ProjectProfitabilityRow `json:"rows"`
+	TotalHours          float64                   `json:"totalHours"`
+	TotalRevenue        float64                   `json:"totalRevenue"`
+	TotalDirectExpenses float64                   `json:"totalDirectExpenses"`
+	TotalProfit         float64                   `json:"totalProfit"`
+	EffectiveHourlyRate float64                   `json:"effectiveHourlyRate"`
+}
+
+// CategoryReportRow totals one finance category. Split transactions count
+// toward the category of each split.
+type CategoryReportRow struct {
+	CategoryID    int     `json:"categoryId"` // 0 for uncategorized
+	CategoryName  string  `json:"categoryName"`
+	CategoryType  string  `json:"categoryType"`
+	CategoryColor string  `json:"categoryColor"`
+	Income        float64 `json:"income"`
+	Expense       float64 `json:"expense"` // positive
+	Net           float64 `json:"net"`
+	Count         int     `json:"count"` // transactions and splits
+}
+
+// CategoryReportOutput is the income and expense by category report.
+type CategoryReportOutput struct {
+	Rows         []CategoryReportRow `json:"rows"`
+	TotalIncome  float64             `json:"totalIncome"`
+	TotalExpense float64             `json:"totalExpense"`
+	Net          float64             `json:"net"`
+}
@@ -0,0 +1,34 @@
+package dto
+
+// SearchInput is a global search request.
+type SearchInput struct {
+	Query string   `json:"query"`
+	Types []string `json:"types"` // client | project | time_entry | invoice | transaction; empty searches all
+	Limit int      `json:"limit"` // per type, defaults to 10
+}
+
+// SearchResult is one matching record.
+type SearchResult struct {
+	Type     string  `json:"type"`
+	ID       int     `json:"id"`
+	Title    string  `json:"title"`
+	Subtitle string  `json:"subtitle"`
+	Snippet  string  `json:"snippet"` // matched text with hits wrapped in [ ]
+	Date     string  `json:"date"`
+	Score    float64 `json:"score"` // higher is more relevant
+}
+
+// SearchGroup holds the results of one entity type, best first.
+type SearchGroup struct {
+	Type    string         `json:"type"`
+	Results []SearchResult `json:"results"`
+}
+
+// SearchOutput is the grouped result of a global search. Groups are ordered by
+// their best result.
+type SearchOutput struct {
+	Query    string        `json:"query"`
+	FullText bool          `json:"fullText"` // false when FTS5 is unavailable and plain matching was used
+	Total    int           `json:"total"`
+	Groups   []SearchGroup `json:"groups"`
+}
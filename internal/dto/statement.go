@@ -0,0 +1,34 @@
+package dto
+
+// StatementFilter selects the client and period for a statement of account.
+type StatementFilter struct {
+	ClientID  int    `json:"clientId"`
+	StartDate string `json:"startDate"` // inclusive, YYYY-MM-DD; empty = from the beginning
+	EndDate   string `json:"endDate"`   // inclusive, YYYY-MM-DD; empty = today
+}
+
+// StatementLine is one invoice, payment or credit on a statement.
+type StatementLine struct {
+	Date      string  `json:"date"`
+	Type      string  `json:"type"` // invoice | payment | credit
+	Reference string  `json:"reference"`
+	Details   string  `json:"details"`
+	Charges   float64 `json:"charges"`
+	Credits   float64 `json:"credits"`
+	Balance   float64 `json:"balance"` // running balance after this line
+}
+
+// StatementOutput is a client's statement of account over a date range.
+type StatementOutput struct {
+	ClientID       int             `json:"clientId"`
+	ClientName     string          `json:"clientName"`
+	StartDate      string          `json:"startDate"`
+	EndDate        string          `json:"endDate"`
+	Currency       string          `json:"currency"`
+	OpeningBalance float64         `json:"openingBalance"`
+	TotalInvoiced  float64         `json:"totalInvoiced"`
+	TotalPaid      float64         `json:"totalPaid"`
+	TotalCredits   float64         `json:"totalCredits"`
+	ClosingBalance float64         `json:"closingBalance"`
+	Lines          []StatementLine `json:"lines"`
+}
@@ -0,0 +1,29 @@
+package dto
+
+// CreateTagInput represents the input for creating a tag.
+type CreateTagInput struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// UpdateTagInput represents the input for renaming or recoloring a tag.
+type UpdateTagInput struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// TagOutput represents a tag returned from API.
+type TagOutput struct {
+	ID         int    `json:"id"`
+	Name       string `json:"name"`
+	Color      string `json:"color"`
+	UsageCount int    `json:"usageCount"`
+}
+
+// SetEntityTagsInput replaces the tags on one record. Unknown tag names are created.
+type SetEntityTagsInput struct {
+	EntityType string   `json:"entityType"` // project | time_entry | invoice | transaction
+	EntityID   int      `json:"entityId"`
+	Tags       []string `json:"tags"`
+}
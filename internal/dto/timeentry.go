@@ -29,14 +29,22 @@ type UpdateTimeEntryInput struct {
 
 // TimeEntryOutput represents the time entry data returned from API.
 type TimeEntryOutput struct {
-	ID              int    `json:"id"`
-	ProjectID       int    `json:"projectId"`
-	InvoiceID       int    `json:"invoiceId"`
-	Date            string `json:"date"`
-	StartTime       string `json:"startTime"`
-	EndTime         string `json:"endTime"`
-	DurationSeconds int    `json:"durationSeconds"`
-	Description     string `json:"description"`
-	Billable        bool   `json:"billable"`
-	Invoiced        bool   `json:"invoiced"`
+	ID              int      `json:"id"`
+	ProjectID       int      `json:"projectId"`
+	InvoiceID       int      `json:"invoiceId"`
+	Date            string   `json:"date"`
+	StartTime       string   `json:"startTime"`
+	EndTime         string   `json:"endTime"`
+	DurationSeconds int      `json:"durationSeconds"`
+	Description     string   `json:"description"`
+	Billable        bool     `json:"billable"`
+	Invoiced        bool     `json:"invoiced"`
+	Tags            []string `json:"tags"`
+}
+
+// TimeEntryFilter narrows the time entry list. An entry matches a tag when it
+// or its project carries it; several tags match any of them.
+type TimeEntryFilter struct {
+	ProjectID int      `json:"projectId"`
+	Tags      []string `json:"tags"`
 }
@@ -0,0 +1,48 @@
+package dto
+
+// ImportRowOutput is one row of a parsed import file and what importing it
+// would do.
+type ImportRowOutput struct {
+	Line          int     `json:"line"` // CSV line or statement entry number; identifies the row on commit
+	Date          string  `json:"date"`
+	Description   string  `json:"description"`
+	Amount        float64 `json:"amount"`
+	ReferenceID   string  `json:"referenceId"`
+	Status        string  `json:"status"` // new | duplicate | review | error
+	Reason        string  `json:"reason,omitempty"`
+	DuplicateOfID int     `json:"duplicateOfId,omitempty"` // existing transaction a duplicate or near-duplicate matches
+}
+
+// ImportParseOutput is the first phase of an import: every row with its status.
+type ImportParseOutput struct {
+	Format         string            `json:"format"`
+	Rows           []ImportRowOutput `json:"rows"`
+	NewCount       int               `json:"newCount"`
+	DuplicateCount int               `json:"duplicateCount"`
+	ReviewCount    int               `json:"reviewCount"`
+	ErrorCount     int               `json:"errorCount"`
+	// Warnings lists statement balances that the entries do not explain.
+	Warnings []string `json:"warnings"`
+}
+
+// CommitImportInput imports the selected rows of a parsed file. Selected
+// duplicates are imported anyway; rows with errors are ignored.
+type CommitImportInput struct {
+	Import ImportTransactionsInput `json:"import"`
+	Lines  []int                   `json:"lines"`
+}
+
+// ImportBatchOutput represents one recorded import.
+type ImportBatchOutput struct {
+	ID               int    `json:"id"`
+	AccountID        int    `json:"accountId"`
+	AccountName      string `json:"accountName"`
+	FileName         string `json:"fileName"`
+	Format           string `json:"format"`
+	RowCount         int    `json:"rowCount"`
+	ImportedCount    int    `json:"importedCount"`
+	TransactionCount int    `json:"transactionCount"`
+	CreatedAt        string `json:"createdAt"`
+	RolledBack       bool   `json:"rolledBack"`
+	RolledBackAt     string `json:"rolledBackAt"`
+}
@@ -2,13 +2,16 @@ package dto
 
 // UserInvoiceSettings represents invoice template settings exposed to frontend.
 type UserInvoiceSettings struct {
-	UserID                 int    `json:"userId"`
-	SenderName             string `json:"senderName"`
-	SenderCompany          string `json:"senderCompany"`
-	SenderAddress          string `json:"senderAddress"`
-	SenderPhone            string `json:"senderPhone"`
-	SenderEmail            string `json:"senderEmail"`
-	SenderPostalCode       string `json:"senderPostalCode"`
-	DefaultTerms           string `json:"defaultTerms"`
-	DefaultMessageTemplate string `json:"defaultMessageTemplate"`
+	UserID                 int     `json:"userId"`
+	SenderName             string  `json:"senderName"`
+	SenderCompany          string  `json:"senderCompany"`
+	SenderAddress          string  `json:"senderAddress"`
+	SenderPhone            string  `json:"senderPhone"`
+	SenderEmail            string  `json:"senderEmail"`
+	SenderPostalCode       string  `json:"senderPostalCode"`
+	DefaultTerms           string  `json:"defaultTerms"`
+	DefaultMessageTemplate string  `json:"defaultMessageTemplate"`
+	PaymentTerms           string  `json:"paymentTerms"` // "" (none) | due_on_receipt | net_N | eom_N
+	LateFeeType            string  `json:"lateFeeType"`  // "" (none) | flat | percent
+	LateFeeValue           float64 `json:"lateFeeValue"` // flat amount, or percent of the balance per month
 }
@@ -0,0 +1,32 @@
+package mapper
+
+import (
+	"tally/internal/dto"
+	"tally/internal/models"
+)
+
+// ToAttachmentOutput converts an Attachment entity to AttachmentOutput DTO.
+func ToAttachmentOutput(e models.Attachment) dto.AttachmentOutput {
+	return dto.AttachmentOutput{
+		ID:         e.ID,
+		EntityType: e.EntityType,
+		EntityID:   e.EntityID,
+		SHA256:     e.SHA256,
+		FileName:   e.FileName,
+		MimeType:   e.MimeType,
+		Size:       e.Size,
+		CreatedAt:  e.CreatedAt,
+	}
+}
+
+// ToAttachmentOutputList converts a slice of Attachment entities to AttachmentOutput DTOs.
+func ToAttachmentOutputList(entities []models.Attachment) []dto.AttachmentOutput {
+	if entities == nil {
+		return []dto.AttachmentOutput{}
+	}
+	result := make([]dto.AttachmentOutput, len(entities))
+	for i, e := range entities {
+		result[i] = ToAttachmentOutput(e)
+	}
+	return result
+}
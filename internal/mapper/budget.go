@@ -0,0 +1,35 @@
+package mapper
+
+import (
+	"tally/internal/dto"
+	"tally/internal/models"
+)
+
+// ToBudgetOutput converts a Budget entity to BudgetOutput DTO.
+func ToBudgetOutput(e models.Budget) dto.BudgetOutput {
+	return dto.BudgetOutput{
+		ID:             e.ID,
+		CategoryID:     e.CategoryID,
+		CategoryName:   e.CategoryName,
+		CategoryColor:  e.CategoryColor,
+		Period:         e.Period,
+		Amount:         e.Amount,
+		Rollover:       e.Rollover,
+		StartDate:      e.StartDate,
+		AlertThreshold: e.AlertThreshold,
+		CreatedAt:      e.CreatedAt,
+		UpdatedAt:      e.UpdatedAt,
+	}
+}
+
+// ToBudgetOutputList converts a slice of Budget entities to BudgetOutput DTOs.
+func ToBudgetOutputList(entities []models.Budget) []dto.BudgetOutput {
+	if entities == nil {
+		return []dto.BudgetOutput{}
+	}
+	result := make([]dto.BudgetOutput, len(entities))
+	for i, e := range entities {
+		result[i] = ToBudgetOutput(e)
+	}
+	return result
+}
@@ -0,0 +1,41 @@
+package mapper
+
+import (
+	"tally/internal/dto"
+	"tally/internal/models"
+)
+
+// ToCategorizationRuleOutput converts a CategorizationRule entity to CategorizationRuleOutput DTO.
+func ToCategorizationRuleOutput(e models.CategorizationRule) dto.CategorizationRuleOutput {
+	tags := e.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return dto.CategorizationRuleOutput{
+		ID:           e.ID,
+		Name:         e.Name,
+		Priority:     e.Priority,
+		MatchType:    e.MatchType,
+		Pattern:      e.Pattern,
+		MinAmount:    e.MinAmount,
+		MaxAmount:    e.MaxAmount,
+		AccountID:    e.AccountID,
+		CategoryID:   e.CategoryID,
+		CategoryName: e.CategoryName,
+		Tags:         tags,
+		Disabled:     e.Disabled,
+		MatchCount:   e.MatchCount,
+	}
+}
+
+// ToCategorizationRuleOutputList converts a slice of CategorizationRule entities to CategorizationRuleOutput DTOs.
+func ToCategorizationRuleOutputList(entities []models.CategorizationRule) []dto.CategorizationRuleOutput {
+	if entities == nil {
+		return []dto.CategorizationRuleOutput{}
+	}
+	result := make([]dto.CategorizationRuleOutput, len(entities))
+	for i, e := range entities {
+		result[i] = ToCategorizationRuleOutput(e)
+	}
+	return result
+}
@@ -24,6 +24,9 @@ func ToClientOutput(e models.Client) dto.ClientOutput {
 		BillingCity:       e.BillingCity,
 		BillingProvince:   e.BillingProvince,
 		BillingPostalCode: e.BillingPostalCode,
+		PaymentTerms:      e.PaymentTerms,
+		RequiresPO:        e.RequiresPO,
+		ArchivedAt:        e.ArchivedAt,
 	}
 }
 
@@ -56,6 +59,8 @@ func ToClientEntity(input dto.CreateClientInput) models.Client {
 		BillingCity:       input.BillingCity,
 		BillingProvince:   input.BillingProvince,
 		BillingPostalCode: input.BillingPostalCode,
+		PaymentTerms:      input.PaymentTerms,
+		RequiresPO:        input.RequiresPO,
 	}
 }
 
@@ -75,4 +80,6 @@ func ApplyClientUpdate(e *models.Client, input dto.UpdateClientInput) {
 	e.BillingCity = input.BillingCity
 	e.BillingProvince = input.BillingProvince
 	e.BillingPostalCode = input.BillingPostalCode
+	e.PaymentTerms = input.PaymentTerms
+	e.RequiresPO = input.RequiresPO
 }
@@ -0,0 +1,47 @@
+package mapper
+
+import (
+	"tally/internal/dto"
+	"tally/internal/models"
+)
+
+// ToClientContactOutput converts a ClientContact entity to ClientContactOutput DTO.
+func ToClientContactOutput(e models.ClientContact) dto.ClientContactOutput {
+	return dto.ClientContactOutput{
+		ID:       e.ID,
+		ClientID: e.ClientID,
+		Name:     e.Name,
+		Email:    e.Email,
+		Phone:    e.Phone,
+		Title:    e.Title,
+		Role:     e.Role,
+	}
+}
+
+// ToClientContactOutputList converts a slice of ClientContact entities to DTOs.
+func ToClientContactOutputList(entities []models.ClientContact) []dto.ClientContactOutput {
+	if entities == nil {
+		return []dto.ClientContactOutput{}
+	}
+	result := make([]dto.ClientContactOutput, len(entities))
+	for i, e := range entities {
+		result[i] = ToClientContactOutput(e)
+	}
+	return result
+}
+
+// ToClientContactEntity converts CreateClientContactInput DTO to ClientContact entity.
+func ToClientContactEntity(input dto.CreateClientContactInput) models.ClientContact {
+	role := input.Role
+	if role == "" {
+		role = "billing"
+	}
+	return models.ClientContact{
+		ClientID: input.ClientID,
+		Name:     input.Name,
+		Email:    input.Email,
+		Phone:    input.Phone,
+		Title:    input.Title,
+		Role:     role,
+	}
+}
@@ -0,0 +1,36 @@
+package mapper
+
+import (
+	"tally/internal/dto"
+	"tally/internal/models"
+)
+
+// ToCustomFieldOutput converts a CustomField entity to CustomFieldOutput DTO.
+func ToCustomFieldOutput(e models.CustomField) dto.CustomFieldOutput {
+	options := e.Options
+	if options == nil {
+		options = []string{}
+	}
+	return dto.CustomFieldOutput{
+		ID:            e.ID,
+		EntityType:    e.EntityType,
+		Key:           e.Key,
+		Label:         e.Label,
+		FieldType:     e.FieldType,
+		Options:       options,
+		ShowOnInvoice: e.ShowOnInvoice,
+		SortOrder:     e.SortOrder,
+	}
+}
+
+// ToCustomFieldOutputList converts a slice of CustomField entities to CustomFieldOutput DTOs.
+func ToCustomFieldOutputList(entities []models.CustomField) []dto.CustomFieldOutput {
+	if entities == nil {
+		return []dto.CustomFieldOutput{}
+	}
+	result := make([]dto.CustomFieldOutput, len(entities))
+	for i, e := range entities {
+		result[i] = ToCustomFieldOutput(e)
+	}
+	return result
+}
@@ -0,0 +1,35 @@
+package mapper
+
+import (
+	"tally/internal/dto"
+	"tally/internal/models"
+)
+
+// ToImportBatchOutput converts an ImportBatch entity to ImportBatchOutput DTO.
+func ToImportBatchOutput(e models.ImportBatch) dto.ImportBatchOutput {
+	return dto.ImportBatchOutput{
+		ID:               e.ID,
+		AccountID:        e.AccountID,
+		AccountName:      e.AccountName,
+		FileName:         e.FileName,
+		Format:           e.Format,
+		RowCount:         e.RowCount,
+		ImportedCount:    e.ImportedCount,
+		TransactionCount: e.TransactionCount,
+		CreatedAt:        e.CreatedAt,
+		RolledBack:       e.RolledBackAt != "",
+		RolledBackAt:     e.RolledBackAt,
+	}
+}
+
+// ToImportBatchOutputList converts a slice of ImportBatch entities to ImportBatchOutput DTOs.
+func ToImportBatchOutputList(entities []models.ImportBatch) []dto.ImportBatchOutput {
+	if entities == nil {
+		return []dto.ImportBatchOutput{}
+	}
+	result := make([]dto.ImportBatchOutput, len(entities))
+	for i, e := range entities {
+		result[i] = ToImportBatchOutput(e)
+	}
+	return result
+}
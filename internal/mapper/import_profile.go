@@ -0,0 +1,42 @@
+package mapper
+
+import (
+	"tally/internal/dto"
+	"tally/internal/models"
+)
+
+// ToImportProfileOutput converts an ImportProfile entity to ImportProfileOutput DTO.
+func ToImportProfileOutput(e models.ImportProfile) dto.ImportProfileOutput {
+	description := e.DescriptionColumns
+	if description == nil {
+		description = []string{}
+	}
+	return dto.ImportProfileOutput{
+		ID:                 e.ID,
+		Name:               e.Name,
+		Builtin:            e.Builtin,
+		Delimiter:          e.Delimiter,
+		SkipRows:           e.SkipRows,
+		DateColumn:         e.DateColumn,
+		DescriptionColumns: description,
+		AmountColumn:       e.AmountColumn,
+		DebitColumn:        e.DebitColumn,
+		CreditColumn:       e.CreditColumn,
+		ReferenceColumn:    e.ReferenceColumn,
+		DateFormat:         e.DateFormat,
+		SignConvention:     e.SignConvention,
+		DecimalSeparator:   e.DecimalSeparator,
+	}
+}
+
+// ToImportProfileOutputList converts a slice of ImportProfile entities to ImportProfileOutput DTOs.
+func ToImportProfileOutputList(entities []models.ImportProfile) []dto.ImportProfileOutput {
+	if entities == nil {
+		return []dto.ImportProfileOutput{}
+	}
+	result := make([]dto.ImportProfileOutput, len(entities))
+	for i, e := range entities {
+		result[i] = ToImportProfileOutput(e)
+	}
+	return result
+}
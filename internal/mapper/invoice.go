@@ -43,6 +43,10 @@ func ToInvoiceOutput(e models.Invoice) dto.InvoiceOutput {
 		Total:     e.Total,
 		Status:    e.Status,
 		Items:     ToInvoiceItemOutputList(e.Items),
+
+		PurchaseOrderID: e.PurchaseOrderID,
+		Warnings:        []string{},
+		CustomFields:    []dto.CustomFieldValueOutput{},
 	}
 }
 
@@ -93,6 +97,8 @@ func ToInvoiceEntity(input dto.CreateInvoiceInput) models.Invoice {
 		Total:     input.Total,
 		Status:    input.Status,
 		Items:     ToInvoiceItemEntityList(input.Items),
+
+		PurchaseOrderID: input.PurchaseOrderID,
 	}
 }
 
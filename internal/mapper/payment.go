@@ -0,0 +1,41 @@
+package mapper
+
+import (
+	"tally/internal/dto"
+	"tally/internal/models"
+)
+
+// ToPaymentOutput converts a Payment entity to PaymentOutput DTO.
+func ToPaymentOutput(e models.Payment) dto.PaymentOutput {
+	return dto.PaymentOutput{
+		ID:            e.ID,
+		ClientID:      e.ClientID,
+		InvoiceID:     e.InvoiceID,
+		Kind:          e.Kind,
+		Date:          e.Date,
+		Amount:        e.Amount,
+		Method:        e.Method,
+		Reference:     e.Reference,
+		Notes:         e.Notes,
+		TransactionID: e.TransactionID,
+	}
+}
+
+// ToPaymentEntity converts CreatePaymentInput DTO to Payment entity.
+func ToPaymentEntity(input dto.CreatePaymentInput) models.Payment {
+	kind := input.Kind
+	if kind == "" {
+		kind = "payment"
+	}
+	return models.Payment{
+		ClientID:      input.ClientID,
+		InvoiceID:     input.InvoiceID,
+		Kind:          kind,
+		Date:          input.Date,
+		Amount:        input.Amount,
+		Method:        input.Method,
+		Reference:     input.Reference,
+		Notes:         input.Notes,
+		TransactionID: input.TransactionID,
+	}
+}
@@ -22,6 +22,7 @@ func ToProjectOutput(e models.Project) dto.ProjectOutput {
 		Deadline:    e.Deadline,
 		Tags:        tags,
 		ServiceType: e.ServiceType,
+		ArchivedAt:  e.ArchivedAt,
 	}
 }
 
@@ -0,0 +1,36 @@
+package mapper
+
+import (
+	"tally/internal/dto"
+	"tally/internal/models"
+)
+
+// ToPurchaseOrderOutput converts a PurchaseOrder entity to PurchaseOrderOutput DTO.
+// Status is left for the service, which knows the current date.
+func ToPurchaseOrderOutput(e models.PurchaseOrder) dto.PurchaseOrderOutput {
+	return dto.PurchaseOrderOutput{
+		ID:               e.ID,
+		ClientID:         e.ClientID,
+		Number:           e.Number,
+		AuthorizedAmount: e.AuthorizedAmount,
+		Currency:         e.Currency,
+		ValidFrom:        e.ValidFrom,
+		ValidTo:          e.ValidTo,
+		Notes:            e.Notes,
+		InvoicedAmount:   e.InvoicedAmount,
+		RemainingAmount:  e.AuthorizedAmount - e.InvoicedAmount,
+		InvoiceCount:     e.InvoiceCount,
+	}
+}
+
+// ToPurchaseOrderOutputList converts a slice of PurchaseOrder entities to PurchaseOrderOutput DTOs.
+func ToPurchaseOrderOutputList(entities []models.PurchaseOrder) []dto.PurchaseOrderOutput {
+	if entities == nil {
+		return []dto.PurchaseOrderOutput{}
+	}
+	result := make([]dto.PurchaseOrderOutput, len(entities))
+	for i, e := range entities {
+		result[i] = ToPurchaseOrderOutput(e)
+	}
+	return result
+}
@@ -0,0 +1,48 @@
+package mapper
+
+import (
+	"tally/internal/dto"
+	"tally/internal/models"
+)
+
+// ToRateCardOutput converts a RateCard entity to RateCardOutput DTO.
+func ToRateCardOutput(e models.RateCard) dto.RateCardOutput {
+	scope := "client"
+	if e.ProjectID > 0 {
+		scope = "project"
+	}
+	return dto.RateCardOutput{
+		ID:            e.ID,
+		ClientID:      e.ClientID,
+		ProjectID:     e.ProjectID,
+		Scope:         scope,
+		Rate:          e.Rate,
+		Currency:      e.Currency,
+		EffectiveFrom: e.EffectiveFrom,
+		Notes:         e.Notes,
+	}
+}
+
+// ToRateCardOutputList converts a slice of RateCard entities to RateCardOutput DTOs.
+func ToRateCardOutputList(entities []models.RateCard) []dto.RateCardOutput {
+	if entities == nil {
+		return []dto.RateCardOutput{}
+	}
+	result := make([]dto.RateCardOutput, len(entities))
+	for i, e := range entities {
+		result[i] = ToRateCardOutput(e)
+	}
+	return result
+}
+
+// ToRateCardEntity converts CreateRateCardInput DTO to RateCard entity.
+func ToRateCardEntity(input dto.CreateRateCardInput) models.RateCard {
+	return models.RateCard{
+		ClientID:      input.ClientID,
+		ProjectID:     input.ProjectID,
+		Rate:          input.Rate,
+		Currency:      input.Currency,
+		EffectiveFrom: input.EffectiveFrom,
+		Notes:         input.Notes,
+	}
+}
@@ -0,0 +1,33 @@
+package mapper
+
+import (
+	"tally/internal/dto"
+	"tally/internal/models"
+)
+
+// ToReconciliationOutput converts a Reconciliation entity to ReconciliationOutput DTO.
+func ToReconciliationOutput(e models.Reconciliation) dto.ReconciliationOutput {
+	return dto.ReconciliationOutput{
+		ID:               e.ID,
+		AccountID:        e.AccountID,
+		AccountName:      e.AccountName,
+		StatementDate:    e.StatementDate,
+		StatementBalance: e.StatementBalance,
+		Status:           e.Status,
+		ReconciledCount:  e.ReconciledCount,
+		CreatedAt:        e.CreatedAt,
+		CompletedAt:      e.CompletedAt,
+	}
+}
+
+// ToReconciliationOutputList converts a slice of Reconciliation entities to ReconciliationOutput DTOs.
+func ToReconciliationOutputList(entities []models.Reconciliation) []dto.ReconciliationOutput {
+	if entities == nil {
+		return []dto.ReconciliationOutput{}
+	}
+	result := make([]dto.ReconciliationOutput, len(entities))
+	for i, e := range entities {
+		result[i] = ToReconciliationOutput(e)
+	}
+	return result
+}
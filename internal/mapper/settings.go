@@ -123,6 +123,9 @@ func ToUserInvoiceSettingsDTO(model models.UserInvoiceSettings) dto.UserInvoiceS
 		SenderPostalCode:       model.SenderPostalCode,
 		DefaultTerms:           model.DefaultTerms,
 		DefaultMessageTemplate: model.DefaultMessageTemplate,
+		PaymentTerms:           model.PaymentTerms,
+		LateFeeType:            model.LateFeeType,
+		LateFeeValue:           model.LateFeeValue,
 	}
 }
 
@@ -138,5 +141,8 @@ func ToUserInvoiceSettingsModel(d dto.UserInvoiceSettings) models.UserInvoiceSet
 		SenderPostalCode:       d.SenderPostalCode,
 		DefaultTerms:           d.DefaultTerms,
 		DefaultMessageTemplate: d.DefaultMessageTemplate,
+		PaymentTerms:           d.PaymentTerms,
+		LateFeeType:            d.LateFeeType,
+		LateFeeValue:           d.LateFeeValue,
 	}
 }
@@ -0,0 +1,28 @@
+package mapper
+
+import (
+	"tally/internal/dto"
+	"tally/internal/models"
+)
+
+// ToTagOutput converts a Tag entity to TagOutput DTO.
+func ToTagOutput(e models.Tag) dto.TagOutput {
+	return dto.TagOutput{
+		ID:         e.ID,
+		Name:       e.Name,
+		Color:      e.Color,
+		UsageCount: e.UsageCount,
+	}
+}
+
+// ToTagOutputList converts a slice of Tag entities to TagOutput DTOs.
+func ToTagOutputList(entities []models.Tag) []dto.TagOutput {
+	if entities == nil {
+		return []dto.TagOutput{}
+	}
+	result := make([]dto.TagOutput, len(entities))
+	for i, e := range entities {
+		result[i] = ToTagOutput(e)
+	}
+	return result
+}
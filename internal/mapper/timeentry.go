@@ -7,6 +7,10 @@ import (
 
 // ToTimeEntryOutput converts a TimeEntry entity to TimeEntryOutput DTO.
 func ToTimeEntryOutput(e models.TimeEntry) dto.TimeEntryOutput {
+	tags := e.Tags
+	if tags == nil {
+		tags = []string{}
+	}
 	return dto.TimeEntryOutput{
 		ID:              e.ID,
 		ProjectID:       e.ProjectID,
@@ -18,6 +22,7 @@ func ToTimeEntryOutput(e models.TimeEntry) dto.TimeEntryOutput {
 		Description:     e.Description,
 		Billable:        e.Billable,
 		Invoiced:        e.Invoiced,
+		Tags:            tags,
 	}
 }
 
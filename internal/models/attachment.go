@@ -0,0 +1,14 @@
+package models
+
+// Attachment is a document, such as a receipt, attached to a finance
+// transaction, invoice or client. Its content is stored once per SHA-256.
+type Attachment struct {
+	ID         int    `json:"id"`
+	EntityType string `json:"entityType"` // transaction | invoice | client
+	EntityID   int    `json:"entityId"`
+	SHA256     string `json:"sha256"`
+	FileName   string `json:"fileName"`
+	MimeType   string `json:"mimeType"`
+	Size       int64  `json:"size"`
+	CreatedAt  string `json:"createdAt"`
+}
@@ -0,0 +1,16 @@
+package models
+
+// Budget caps spending in an expense category per month or year.
+type Budget struct {
+	ID             int     `json:"id"`
+	CategoryID     int     `json:"categoryId"`
+	CategoryName   string  `json:"categoryName"`
+	CategoryColor  string  `json:"categoryColor"`
+	Period         string  `json:"period"` // monthly | annual
+	Amount         float64 `json:"amount"`
+	Rollover       string  `json:"rollover"`       // none | unspent | all
+	StartDate      string  `json:"startDate"`      // YYYY-MM-DD, first day of the first period
+	AlertThreshold float64 `json:"alertThreshold"` // percent of the budget that raises a warning
+	CreatedAt      string  `json:"createdAt"`
+	UpdatedAt      string  `json:"updatedAt"`
+}
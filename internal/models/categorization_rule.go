@@ -0,0 +1,19 @@
+package models
+
+// CategorizationRule assigns a category, and optionally tags, to transactions
+// whose description, amount and account match. Lower priorities run first.
+type CategorizationRule struct {
+	ID           int      `json:"id"`
+	Name         string   `json:"name"`
+	Priority     int      `json:"priority"`
+	MatchType    string   `json:"matchType"` // substring | regex
+	Pattern      string   `json:"pattern"`   // empty matches any description
+	MinAmount    *float64 `json:"minAmount"` // bounds on the amount's magnitude
+	MaxAmount    *float64 `json:"maxAmount"`
+	AccountID    *int     `json:"accountId"`
+	CategoryID   int      `json:"categoryId"`
+	CategoryName string   `json:"categoryName"`
+	Tags         []string `json:"tags"`
+	Disabled     bool     `json:"disabled"`
+	MatchCount   int      `json:"matchCount"` // transactions the rule has categorized
+}
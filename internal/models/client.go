@@ -19,4 +19,10 @@ type Client struct {
 	BillingCity       string `json:"billingCity"`
 	BillingProvince   string `json:"billingProvince"`
 	BillingPostalCode string `json:"billingPostalCode"`
+	// PaymentTerms overrides the user's terms when set (e.g. net_30, eom_10).
+	PaymentTerms string `json:"paymentTerms"`
+	// RequiresPO marks clients that reject invoices without a valid purchase order.
+	RequiresPO bool `json:"requiresPo"`
+	// ArchivedAt is set when the client has been archived (soft deleted).
+	ArchivedAt string `json:"archivedAt"`
 }
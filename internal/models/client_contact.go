@@ -0,0 +1,13 @@
+package models
+
+// ClientContact is a person at a client. Role decides how they are addressed
+// on invoice emails: billing contacts are recipients, cc/bcc contacts are copied.
+type ClientContact struct {
+	ID       int    `json:"id"`
+	ClientID int    `json:"clientId"`
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Phone    string `json:"phone"`
+	Title    string `json:"title"`
+	Role     string `json:"role"` // billing, technical, cc, bcc
+}
@@ -0,0 +1,14 @@
+package models
+
+// CustomField defines a user-specific field on clients, projects or invoices,
+// such as a PO number or cost centre. Values are stored as text.
+type CustomField struct {
+	ID            int      `json:"id"`
+	EntityType    string   `json:"entityType"` // client | project | invoice
+	Key           string   `json:"key"`        // placeholder name, e.g. po_number
+	Label         string   `json:"label"`
+	FieldType     string   `json:"fieldType"` // text | number | date | select
+	Options       []string `json:"options"`   // choices for select fields
+	ShowOnInvoice bool     `json:"showOnInvoice"`
+	SortOrder     int      `json:"sortOrder"`
+}
@@ -14,6 +14,10 @@ type FinanceTransaction struct {
 	Amount      float64          `json:"amount"`
 	Status      string           `json:"status"` // pending, cleared, reconciled
 	ReferenceID string           `json:"referenceId"`
+	ClientID    *int             `json:"clientId"`    // Set when allocated to a client
+	ProjectID   *int             `json:"projectId"`   // Set when allocated to a project
+	ExpenseType string           `json:"expenseType"` // reimbursable, non_reimbursable, or empty
+	InvoiceID   *int             `json:"invoiceId"`   // Invoice a reimbursable expense was billed on
 	CreatedAt   time.Time        `json:"createdAt"`
 	UpdatedAt   time.Time        `json:"updatedAt"`
 }
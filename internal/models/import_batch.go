@@ -0,0 +1,15 @@
+package models
+
+// ImportBatch records one transaction import into an account.
+type ImportBatch struct {
+	ID               int    `json:"id"`
+	AccountID        int    `json:"accountId"`
+	AccountName      string `json:"accountName"`
+	FileName         string `json:"fileName"`
+	Format           string `json:"format"` // csv | ofx | camt053
+	RowCount         int    `json:"rowCount"`
+	ImportedCount    int    `json:"importedCount"`
+	TransactionCount int    `json:"transactionCount"` // transactions still in the batch
+	CreatedAt        string `json:"createdAt"`
+	RolledBackAt     string `json:"rolledBackAt"` // empty unless rolled back
+}
@@ -0,0 +1,20 @@
+package models
+
+// ImportProfile describes the CSV layout of one bank's transaction export.
+// Columns are 1-based numbers ("3") or header names ("Posted Date").
+type ImportProfile struct {
+	ID                 int      `json:"id"`
+	Name               string   `json:"name"`
+	Builtin            bool     `json:"builtin"`   // shipped with the app, not stored
+	Delimiter          string   `json:"delimiter"` // ",", ";", "\t" or "|"
+	SkipRows           int      `json:"skipRows"`  // leading rows before the data; the last one holds the headers
+	DateColumn         string   `json:"dateColumn"`
+	DescriptionColumns []string `json:"descriptionColumns"` // joined with a space
+	AmountColumn       string   `json:"amountColumn"`       // signed and inverted conventions
+	DebitColumn        string   `json:"debitColumn"`        // debit_credit convention
+	CreditColumn       string   `json:"creditColumn"`
+	ReferenceColumn    string   `json:"referenceColumn"`
+	DateFormat         string   `json:"dateFormat"`       // e.g. YYYY-MM-DD or DD/MM/YYYY; empty tries common formats
+	SignConvention     string   `json:"signConvention"`   // signed | inverted | debit_credit
+	DecimalSeparator   string   `json:"decimalSeparator"` // "." or ","
+}
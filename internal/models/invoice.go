@@ -23,4 +23,6 @@ type Invoice struct {
 	Total     float64       `json:"total"`
 	Status    string        `json:"status"` // draft, sent, paid, overdue
 	Items     []InvoiceItem `json:"items"`
+	// PurchaseOrderID references the client PO billed, 0 for none.
+	PurchaseOrderID int `json:"purchaseOrderId"`
 }
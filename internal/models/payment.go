@@ -0,0 +1,17 @@
+package models
+
+// Payment is money received from a client, or a credit issued to them.
+// Both reduce the client's outstanding balance.
+type Payment struct {
+	ID        int     `json:"id"`
+	ClientID  int     `json:"clientId"`
+	InvoiceID int     `json:"invoiceId"` // 0 when not applied to an invoice
+	Kind      string  `json:"kind"`      // payment | credit
+	Date      string  `json:"date"`      // YYYY-MM-DD
+	Amount    float64 `json:"amount"`
+	Method    string  `json:"method"`
+	Reference string  `json:"reference"`
+	Notes     string  `json:"notes"`
+	// TransactionID is the bank deposit the payment arrived in, 0 when unknown.
+	TransactionID int `json:"transactionId"`
+}
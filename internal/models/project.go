@@ -11,6 +11,7 @@ type Project struct {
 	Currency    string   `json:"currency"`
 	Status      string   `json:"status"` // active, archived, completed
 	Deadline    string   `json:"deadline"`
-	Tags        []string `json:"tags"`        // Handled as pipe-delimited string in DB for simplicity
+	Tags        []string `json:"tags"`        // Stored in entity_tags
 	ServiceType string   `json:"serviceType"` // software_development, system_maintenance, consulting, design, other
+	ArchivedAt  string   `json:"archivedAt"`  // set when the project has been archived (soft deleted)
 }
@@ -0,0 +1,16 @@
+package models
+
+// PurchaseOrder is a client's authorization to bill up to an amount within a
+// validity window. Invoices reference it by ID.
+type PurchaseOrder struct {
+	ID               int     `json:"id"`
+	ClientID         int     `json:"clientId"`
+	Number           string  `json:"number"`
+	AuthorizedAmount float64 `json:"authorizedAmount"`
+	Currency         string  `json:"currency"`
+	ValidFrom        string  `json:"validFrom"` // YYYY-MM-DD, empty for no start
+	ValidTo          string  `json:"validTo"`   // YYYY-MM-DD, empty for no end
+	Notes            string  `json:"notes"`
+	InvoicedAmount   float64 `json:"invoicedAmount"` // total of invoices referencing the PO
+	InvoiceCount     int     `json:"invoiceCount"`
+}
@@ -0,0 +1,13 @@
+package models
+
+// RateCard is an hourly rate that applies from EffectiveFrom onwards.
+// A card is scoped to a project when ProjectID is set, otherwise to a client.
+type RateCard struct {
+	ID            int     `json:"id"`
+	ClientID      int     `json:"clientId"`  // 0 when scoped to a project only
+	ProjectID     int     `json:"projectId"` // 0 for client-wide rates
+	Rate          float64 `json:"rate"`
+	Currency      string  `json:"currency"`
+	EffectiveFrom string  `json:"effectiveFrom"` // YYYY-MM-DD, inclusive
+	Notes         string  `json:"notes"`
+}
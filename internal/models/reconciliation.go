@@ -0,0 +1,15 @@
+package models
+
+// Reconciliation matches an account's cleared transactions against a bank
+// statement. Completing it marks those transactions reconciled and locks them.
+type Reconciliation struct {
+	ID               int     `json:"id"`
+	AccountID        int     `json:"accountId"`
+	AccountName      string  `json:"accountName"`
+	StatementDate    string  `json:"statementDate"` // YYYY-MM-DD
+	StatementBalance float64 `json:"statementBalance"`
+	Status           string  `json:"status"` // in_progress | completed
+	ReconciledCount  int     `json:"reconciledCount"`
+	CreatedAt        string  `json:"createdAt"`
+	CompletedAt      string  `json:"completedAt"`
+}
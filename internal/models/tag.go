@@ -0,0 +1,10 @@
+package models
+
+// Tag is a user-defined label that can be attached to projects, time entries,
+// invoices and finance transactions. Names are unique per user, ignoring case.
+type Tag struct {
+	ID         int    `json:"id"`
+	Name       string `json:"name"`
+	Color      string `json:"color"`
+	UsageCount int    `json:"usageCount"` // number of tagged records
+}
@@ -13,5 +13,8 @@ type UserInvoiceSettings struct {
 	SenderPostalCode       string    `json:"senderPostalCode"`
 	DefaultTerms           string    `json:"defaultTerms"`
 	DefaultMessageTemplate string    `json:"defaultMessageTemplate"`
+	PaymentTerms           string    `json:"paymentTerms"`
+	LateFeeType            string    `json:"lateFeeType"`
+	LateFeeValue           float64   `json:"lateFeeValue"`
 	UpdatedAt              time.Time `json:"updatedAt"`
 }
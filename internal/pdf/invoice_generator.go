@@ -76,6 +76,7 @@ func (g *Generator) renderPDFWithTemplate(invoice dto.InvoiceOutput, client mode
 		IssueDate:     utils.FormatDate(invoice.IssueDate, settings.DateFormat, settings.Timezone),
 		DueDate:       utils.FormatDate(invoice.DueDate, settings.DateFormat, settings.Timezone),
 		Terms:         settings.InvoiceTerms,
+		PONumber:      invoice.PurchaseOrderNumber,
 
 		Subtotal:       invoice.Subtotal,
 		TaxRate:        invoice.TaxRate,
@@ -86,6 +87,15 @@ func (g *Generator) renderPDFWithTemplate(invoice dto.InvoiceOutput, client mode
 
 		MinRows: 5,
 		Message: message,
+
+		Fields: map[string]string{},
+	}
+
+	for _, f := range invoice.CustomFields {
+		data.Fields[f.Key] = f.Value
+		if f.ShowOnInvoice {
+			data.CustomFields = append(data.CustomFields, CustomFieldData{Key: f.Key, Label: f.Label, Value: f.Value})
+		}
 	}
 
 	// Convert invoice items
@@ -225,6 +235,17 @@ func (g *Generator) renderPDF(invoice dto.InvoiceOutput, client models.Client, s
 
 		pdfPtr.SetXY(105, pdfPtr.GetY()-6)
 		pdfPtr.CellFormat(90, 6, fmt.Sprintf("TERMS %s", settings.InvoiceTerms), "", 1, "R", false, 0, "")
+		if invoice.PurchaseOrderNumber != "" {
+			pdfPtr.SetX(105)
+			pdfPtr.CellFormat(90, 6, fmt.Sprintf("P.O.# %s", invoice.PurchaseOrderNumber), "", 1, "R", false, 0, "")
+		}
+		for _, f := range invoice.CustomFields {
+			if !f.ShowOnInvoice {
+				continue
+			}
+			pdfPtr.SetX(105)
+			pdfPtr.CellFormat(90, 6, fmt.Sprintf("%s %s", strings.ToUpper(f.Label), f.Value), "", 1, "R", false, 0, "")
+		}
 		pdfPtr.Ln(6)
 	}
 
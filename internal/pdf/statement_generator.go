@@ -0,0 +1,79 @@
+package pdf
+
+import (
+	"encoding/base64"
+	"tally/internal/dto"
+	"tally/internal/models"
+	"tally/internal/utils"
+	"time"
+)
+
+// defaultStatementTemplate is the statements template used when none is configured.
+const defaultStatementTemplate = "standard"
+
+// GenerateStatementHTML renders a client's statement of account as a self-contained HTML page.
+func (g *Generator) GenerateStatementHTML(statement dto.StatementOutput, client models.Client, settings models.UserSettings) (string, error) {
+	renderer := NewTemplateRenderer(g.TemplatesDir)
+	return renderer.RenderStatementHTML(defaultStatementTemplate, buildStatementData(statement, client, settings))
+}
+
+// GenerateStatementPDF renders a client's statement of account and returns the PDF as base64.
+func (g *Generator) GenerateStatementPDF(statement dto.StatementOutput, client models.Client, settings models.UserSettings) (string, error) {
+	renderer := NewTemplateRenderer(g.TemplatesDir)
+	pdfBytes, err := renderer.GenerateStatementPDF(defaultStatementTemplate, buildStatementData(statement, client, settings))
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(pdfBytes), nil
+}
+
+// buildStatementData maps a statement and its client onto the template fields.
+func buildStatementData(statement dto.StatementOutput, client models.Client, settings models.UserSettings) StatementTemplateData {
+	billingCompany := client.BillingCompany
+	if billingCompany == "" {
+		billingCompany = client.Name
+	}
+	currency := statement.Currency
+	if currency == "" {
+		currency = settings.Currency
+	}
+	formatDate := func(raw string) string {
+		return utils.FormatDate(raw, settings.DateFormat, settings.Timezone)
+	}
+
+	data := StatementTemplateData{
+		SenderName:    settings.SenderName,
+		SenderCompany: settings.SenderCompany,
+		SenderAddress: settings.SenderAddress,
+		SenderPhone:   settings.SenderPhone,
+		SenderEmail:   settings.SenderEmail,
+
+		BillingCompany:  billingCompany,
+		BillingAddress:  client.BillingAddress,
+		BillingCityLine: buildBillingCityLine(client),
+
+		StatementDate: formatDate(time.Now().Format("2006-01-02")),
+		StartDate:     formatDate(statement.StartDate),
+		EndDate:       formatDate(statement.EndDate),
+
+		OpeningBalance: statement.OpeningBalance,
+		TotalInvoiced:  statement.TotalInvoiced,
+		TotalPaid:      statement.TotalPaid,
+		TotalCredits:   statement.TotalCredits,
+		ClosingBalance: statement.ClosingBalance,
+		Currency:       currency,
+		CurrencySymbol: utils.GetCurrencySymbol(currency),
+	}
+	for _, line := range statement.Lines {
+		data.Lines = append(data.Lines, StatementLineData{
+			Date:      formatDate(line.Date),
+			Type:      line.Type,
+			Reference: line.Reference,
+			Details:   line.Details,
+			Charges:   line.Charges,
+			Credits:   line.Credits,
+			Balance:   line.Balance,
+		})
+	}
+	return data
+}
@@ -15,6 +15,12 @@ import (
 	"github.com/chromedp/chromedp"
 )
 
+// Template families live in their own directory under the templates root.
+const (
+	invoiceFamily   = "invoices"
+	statementFamily = "statements"
+)
+
 // TemplateRenderer handles HTML template rendering and PDF generation.
 type TemplateRenderer struct {
 	templatesDir string
@@ -45,6 +51,12 @@ type InvoiceTemplateData struct {
 	IssueDate     string
 	DueDate       string
 	Terms         string
+	PONumber      string // client purchase order, empty when none
+
+	// Custom fields: CustomFields lists those marked to show on the invoice;
+	// Fields holds every value by key for templates, e.g. {{index .Fields "po_number"}}.
+	CustomFields []CustomFieldData
+	Fields       map[string]string
 
 	// Items
 	Items   []InvoiceItemData
@@ -65,6 +77,57 @@ type InvoiceTemplateData struct {
 	CSS template.CSS
 }
 
+// StatementTemplateData holds all data needed to render a statement of account.
+type StatementTemplateData struct {
+	// Sender info
+	SenderName    string
+	SenderCompany string
+	SenderAddress string
+	SenderPhone   string
+	SenderEmail   string
+
+	// Client billing info
+	BillingCompany  string
+	BillingAddress  string
+	BillingCityLine string
+
+	// Period
+	StatementDate string
+	StartDate     string
+	EndDate       string
+
+	// Activity
+	OpeningBalance float64
+	Lines          []StatementLineData
+	TotalInvoiced  float64
+	TotalPaid      float64
+	TotalCredits   float64
+	ClosingBalance float64
+	Currency       string
+	CurrencySymbol string
+
+	// CSS content (injected into template)
+	CSS template.CSS
+}
+
+// StatementLineData represents one invoice, payment or credit on a statement.
+type StatementLineData struct {
+	Date      string
+	Type      string
+	Reference string
+	Details   string
+	Charges   float64
+	Credits   float64
+	Balance   float64
+}
+
+// CustomFieldData is one labelled custom field value printed on an invoice.
+type CustomFieldData struct {
+	Key   string
+	Label string
+	Value string
+}
+
 // InvoiceItemData represents a single line item in the invoice.
 type InvoiceItemData struct {
 	Description string // Service type description
@@ -89,29 +152,51 @@ var templateFuncs = template.FuncMap{
 
 // RenderHTML renders the invoice template with the given data.
 func (r *TemplateRenderer) RenderHTML(templateName string, data InvoiceTemplateData) (string, error) {
-	templatePath := filepath.Join(r.templatesDir, "invoices", templateName, "template.tmpl")
-	cssPath := filepath.Join(r.templatesDir, "invoices", templateName, "style.css")
+	tmpl, css, err := r.load(invoiceFamily, templateName)
+	if err != nil {
+		return "", err
+	}
+	data.CSS = css
+	return execute(tmpl, data)
+}
+
+// RenderStatementHTML renders a statement of account template with the given data.
+func (r *TemplateRenderer) RenderStatementHTML(templateName string, data StatementTemplateData) (string, error) {
+	tmpl, css, err := r.load(statementFamily, templateName)
+	if err != nil {
+		return "", err
+	}
+	data.CSS = css
+	return execute(tmpl, data)
+}
+
+// load reads <templatesDir>/<family>/<templateName>/{template.tmpl,style.css}.
+func (r *TemplateRenderer) load(family, templateName string) (*template.Template, template.CSS, error) {
+	templatePath := filepath.Join(r.templatesDir, family, templateName, "template.tmpl")
+	cssPath := filepath.Join(r.templatesDir, family, templateName, "style.css")
 
 	// Read CSS file
 	cssContent, err := os.ReadFile(cssPath)
 	if err != nil {
-		return "", fmt.Errorf("failed to read CSS file: %w", err)
+		return nil, "", fmt.Errorf("failed to read CSS file: %w", err)
 	}
-	// #nosec G203 -- CSS is loaded from trusted local template files.
-	data.CSS = template.CSS(cssContent)
 
 	// Parse template
 	tmpl, err := template.New("template.tmpl").Funcs(templateFuncs).ParseFiles(templatePath)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse template: %w", err)
+		return nil, "", fmt.Errorf("failed to parse template: %w", err)
 	}
 
-	// Execute template
+	// #nosec G203 -- CSS is loaded from trusted local template files.
+	return tmpl, template.CSS(cssContent), nil
+}
+
+// execute renders a parsed template into a string.
+func execute(tmpl *template.Template, data any) (string, error) {
 	var buf bytes.Buffer
 	if err := tmpl.Execute(&buf, data); err != nil {
 		return "", fmt.Errorf("failed to execute template: %w", err)
 	}
-
 	return buf.String(), nil
 }
 
@@ -177,6 +262,15 @@ func (r *TemplateRenderer) GenerateInvoicePDF(templateName string, data InvoiceT
 	return r.RenderPDF(html)
 }
 
+// GenerateStatementPDF renders a statement of account and converts it to PDF.
+func (r *TemplateRenderer) GenerateStatementPDF(templateName string, data StatementTemplateData) ([]byte, error) {
+	html, err := r.RenderStatementHTML(templateName, data)
+	if err != nil {
+		return nil, err
+	}
+	return r.RenderPDF(html)
+}
+
 // EmbeddedTemplateRenderer uses embedded templates (for production builds).
 type EmbeddedTemplateRenderer struct {
 	fs embed.FS
@@ -189,36 +283,49 @@ func NewEmbeddedTemplateRenderer(fs embed.FS) *EmbeddedTemplateRenderer {
 
 // RenderHTML renders the invoice template from embedded filesystem.
 func (r *EmbeddedTemplateRenderer) RenderHTML(templateName string, data InvoiceTemplateData) (string, error) {
-	templatePath := filepath.Join("templates", "invoices", templateName, "template.tmpl")
-	cssPath := filepath.Join("templates", "invoices", templateName, "style.css")
+	tmpl, css, err := r.load(invoiceFamily, templateName)
+	if err != nil {
+		return "", err
+	}
+	data.CSS = css
+	return execute(tmpl, data)
+}
+
+// RenderStatementHTML renders a statement of account template from embedded filesystem.
+func (r *EmbeddedTemplateRenderer) RenderStatementHTML(templateName string, data StatementTemplateData) (string, error) {
+	tmpl, css, err := r.load(statementFamily, templateName)
+	if err != nil {
+		return "", err
+	}
+	data.CSS = css
+	return execute(tmpl, data)
+}
+
+// load reads templates/<family>/<templateName>/{template.tmpl,style.css} from the embedded filesystem.
+func (r *EmbeddedTemplateRenderer) load(family, templateName string) (*template.Template, template.CSS, error) {
+	templatePath := filepath.Join("templates", family, templateName, "template.tmpl")
+	cssPath := filepath.Join("templates", family, templateName, "style.css")
 
 	// Read CSS file
 	cssContent, err := r.fs.ReadFile(cssPath)
 	if err != nil {
-		return "", fmt.Errorf("failed to read embedded CSS file: %w", err)
+		return nil, "", fmt.Errorf("failed to read embedded CSS file: %w", err)
 	}
-	// #nosec G203 -- CSS is loaded from trusted embedded template files.
-	data.CSS = template.CSS(cssContent)
 
 	// Read template file
 	templateContent, err := r.fs.ReadFile(templatePath)
 	if err != nil {
-		return "", fmt.Errorf("failed to read embedded template file: %w", err)
+		return nil, "", fmt.Errorf("failed to read embedded template file: %w", err)
 	}
 
 	// Parse template
 	tmpl, err := template.New("template.tmpl").Funcs(templateFuncs).Parse(string(templateContent))
 	if err != nil {
-		return "", fmt.Errorf("failed to parse template: %w", err)
+		return nil, "", fmt.Errorf("failed to parse template: %w", err)
 	}
 
-	// Execute template
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
-		return "", fmt.Errorf("failed to execute template: %w", err)
-	}
-
-	return buf.String(), nil
+	// #nosec G203 -- CSS is loaded from trusted embedded template files.
+	return tmpl, template.CSS(cssContent), nil
 }
 
 // RenderPDF converts HTML content to PDF using chromedp.
@@ -237,6 +344,15 @@ func (r *EmbeddedTemplateRenderer) GenerateInvoicePDF(templateName string, data
 	return r.RenderPDF(html)
 }
 
+// GenerateStatementPDF renders a statement of account and converts it to PDF.
+func (r *EmbeddedTemplateRenderer) GenerateStatementPDF(templateName string, data StatementTemplateData) ([]byte, error) {
+	html, err := r.RenderStatementHTML(templateName, data)
+	if err != nil {
+		return nil, err
+	}
+	return r.RenderPDF(html)
+}
+
 // GetTemplatesDir returns the templates directory path based on executable location.
 func GetTemplatesDir() string {
 	// First try relative to executable
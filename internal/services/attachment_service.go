@@ -0,0 +1,332 @@
+package services
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	appdb "tally/internal/db"
+	"tally/internal/dto"
+	"tally/internal/mapper"
+	"tally/internal/models"
+	"time"
+)
+
+// AttachmentService stores receipts and other documents for finance
+// transactions, invoices and clients. Files are kept under
+// ~/.tally/attachments, named by the SHA-256 of their content, so a file
+// attached twice is stored once and can be checked for tampering.
+type AttachmentService struct {
+	db  *sql.DB
+	dir string
+}
+
+// NewAttachmentService creates a new AttachmentService instance.
+func NewAttachmentService(db *sql.DB) *AttachmentService {
+	dir := ""
+	if dataDir, err := appdb.DataDir(); err == nil {
+		dir = filepath.Join(dataDir, "attachments")
+	} else {
+		log.Println("Attachments unavailable, no home directory:", err)
+	}
+	return &AttachmentService{db: db, dir: dir}
+}
+
+// maxAttachmentSize is the largest file that can be attached.
+const maxAttachmentSize = 20 << 20
+
+// attachableTables maps an entity type to the table holding its records.
+var attachableTables = map[string]string{
+	"transaction": "finance_transactions",
+	"invoice":     "invoices",
+	"client":      "clients",
+}
+
+// attachmentTypes are the content types accepted, as sniffed from the file.
+var attachmentTypes = map[string]bool{
+	"application/pdf": true,
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"text/plain":      true,
+}
+
+const attachmentColumns = "id, entity_type, entity_id, sha256, file_name, mime_type, size, COALESCE(created_at, '')"
+
+// List returns the attachments of a record, oldest first.
+func (s *AttachmentService) List(userID int, entityType string, entityID int) []dto.AttachmentOutput {
+	rows, err := s.db.Query("SELECT "+attachmentColumns+" FROM attachments WHERE user_id = ? AND entity_type = ? AND entity_id = ? ORDER BY id",
+		userID, entityType, entityID)
+	if err != nil {
+		log.Println("Error querying attachments:", err)
+		return []dto.AttachmentOutput{}
+	}
+	defer closeWithLog(rows, "closing attachment rows")
+
+	var list []models.Attachment
+	for rows.Next() {
+		a, err := scanAttachment(rows)
+		if err != nil {
+			log.Println("Error scanning attachment:", err)
+			continue
+		}
+		list = append(list, a)
+	}
+	return mapper.ToAttachmentOutputList(list)
+}
+
+// Add stores a file and attaches it to a record. The type is sniffed from
+// the content rather than trusted from the name. Attaching the same file to
+// the same record again returns the existing attachment.
+func (s *AttachmentService) Add(userID int, input dto.AddAttachmentInput) (dto.AttachmentOutput, error) {
+	table, ok := attachableTables[input.EntityType]
+	if !ok {
+		return dto.AttachmentOutput{}, fmt.Errorf("unknown entity type: %s", input.EntityType)
+	}
+	var count int
+	// #nosec G202 -- table name comes from attachableTables.
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM "+table+" WHERE id = ? AND user_id = ?", input.EntityID, userID).Scan(&count); err != nil {
+		return dto.AttachmentOutput{}, fmt.Errorf("failed to check %s: %w", input.EntityType, err)
+	}
+	if count == 0 {
+		return dto.AttachmentOutput{}, fmt.Errorf("%s not found", input.EntityType)
+	}
+
+	name := filepath.Base(strings.TrimSpace(input.FileName))
+	if name == "." || name == string(filepath.Separator) {
+		return dto.AttachmentOutput{}, fmt.Errorf("file name is required")
+	}
+	if base64.StdEncoding.DecodedLen(len(input.Content)) > maxAttachmentSize+3 {
+		return dto.AttachmentOutput{}, fmt.Errorf("file is larger than %d MB", maxAttachmentSize>>20)
+	}
+	data, err := base64.StdEncoding.DecodeString(input.Content)
+	if err != nil {
+		return dto.AttachmentOutput{}, fmt.Errorf("invalid file content: %w", err)
+	}
+	if len(data) == 0 {
+		return dto.AttachmentOutput{}, fmt.Errorf("file is empty")
+	}
+	if len(data) > maxAttachmentSize {
+		return dto.AttachmentOutput{}, fmt.Errorf("file is larger than %d MB", maxAttachmentSize>>20)
+	}
+	mimeType, _, err := mime.ParseMediaType(http.DetectContentType(data))
+	if err != nil || !attachmentTypes[mimeType] {
+		return dto.AttachmentOutput{}, fmt.Errorf("unsupported file type %s; attach a PDF, image or text file", mimeType)
+	}
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	existing, err := scanAttachment(s.db.QueryRow("SELECT "+attachmentColumns+" FROM attachments WHERE user_id = ? AND entity_type = ? AND entity_id = ? AND sha256 = ?",
+		userID, input.EntityType, input.EntityID, hash))
+	if err == nil {
+		return mapper.ToAttachmentOutput(existing), nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return dto.AttachmentOutput{}, fmt.Errorf("failed to check attachments: %w", err)
+	}
+
+	// The row goes in first so a concurrent cleanup sees the file as referenced.
+	res, err := s.db.Exec("INSERT INTO attachments(user_id, entity_type, entity_id, sha256, file_name, mime_type, size) VALUES(?, ?, ?, ?, ?, ?, ?)",
+		userID, input.EntityType, input.EntityID, hash, name, mimeType, len(data))
+	if err != nil {
+		return dto.AttachmentOutput{}, fmt.Errorf("failed to insert attachment: %w", err)
+	}
+	id, _ := res.LastInsertId()
+	if err := s.store(hash, data); err != nil {
+		if _, delErr := s.db.Exec("DELETE FROM attachments WHERE id = ?", id); delErr != nil {
+			log.Println("Error removing attachment without file:", delErr)
+		}
+		return dto.AttachmentOutput{}, err
+	}
+	return s.get(userID, int(id))
+}
+
+// Read returns an attachment with its content, after checking that the
+// stored file still matches its hash.
+func (s *AttachmentService) Read(userID int, id int) (dto.AttachmentContentOutput, error) {
+	a, err := s.get(userID, id)
+	if err != nil {
+		return dto.AttachmentContentOutput{}, err
+	}
+	path, err := s.path(a.SHA256)
+	if err != nil {
+		return dto.AttachmentContentOutput{}, err
+	}
+	data, err := os.ReadFile(path) //nolint:gosec // path is built from a stored hash
+	if err != nil {
+		return dto.AttachmentContentOutput{}, fmt.Errorf("failed to read attachment: %w", err)
+	}
+	if sum := sha256.Sum256(data); hex.EncodeToString(sum[:]) != a.SHA256 {
+		return dto.AttachmentContentOutput{}, fmt.Errorf("attachment %s has been modified on disk", a.FileName)
+	}
+	return dto.AttachmentContentOutput{Attachment: a, Content: base64.StdEncoding.EncodeToString(data)}, nil
+}
+
+// Delete removes an attachment, and its file when nothing else refers to it.
+func (s *AttachmentService) Delete(userID int, id int) error {
+	a, err := s.get(userID, id)
+	if err != nil {
+		return err
+	}
+	if _, err := s.db.Exec("DELETE FROM attachments WHERE id = ? AND user_id = ?", id, userID); err != nil {
+		return fmt.Errorf("failed to delete attachment: %w", err)
+	}
+	var refs int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM attachments WHERE sha256 = ?", a.SHA256).Scan(&refs); err != nil {
+		return fmt.Errorf("failed to check attachment references: %w", err)
+	}
+	if refs == 0 {
+		path, err := s.path(a.SHA256)
+		if err != nil {
+			return err
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			log.Println("Error removing attachment file:", err)
+		}
+	}
+	return nil
+}
+
+// CleanupOrphans removes attachments whose record no longer exists and
+// stored files that no attachment refers to. Files written or reused in the
+// last hour are left alone, as an attachment may be being added.
+func (s *AttachmentService) CleanupOrphans() (dto.AttachmentCleanupOutput, error) {
+	var out dto.AttachmentCleanupOutput
+	for entityType, table := range attachableTables {
+		// #nosec G202 -- table name comes from attachableTables.
+		res, err := s.db.Exec("DELETE FROM attachments WHERE entity_type = ? AND NOT EXISTS (SELECT 1 FROM "+table+" r WHERE r.id = attachments.entity_id)", entityType)
+		if err != nil {
+			return out, fmt.Errorf("failed to remove orphaned attachments: %w", err)
+		}
+		n, _ := res.RowsAffected()
+		out.RemovedRows += int(n)
+	}
+
+	referenced := map[string]bool{}
+	rows, err := s.db.Query("SELECT DISTINCT sha256 FROM attachments")
+	if err != nil {
+		return out, fmt.Errorf("failed to query attachments: %w", err)
+	}
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			closeWithLog(rows, "closing attachment hash rows")
+			return out, fmt.Errorf("failed to scan attachment: %w", err)
+		}
+		referenced[hash] = true
+	}
+	closeWithLog(rows, "closing attachment hash rows")
+
+	if s.dir == "" {
+		return out, fmt.Errorf("attachment storage is unavailable")
+	}
+	cutoff := time.Now().Add(-time.Hour)
+	err = filepath.WalkDir(s.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		name := d.Name()
+		if referenced[name] {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if info.ModTime().After(cutoff) {
+			return nil
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		out.RemovedFiles++
+		out.FreedBytes += info.Size()
+		return nil
+	})
+	if err != nil {
+		return out, fmt.Errorf("failed to clean up attachment files: %w", err)
+	}
+	return out, nil
+}
+
+// get loads one of the user's attachments.
+func (s *AttachmentService) get(userID int, id int) (dto.AttachmentOutput, error) {
+	a, err := scanAttachment(s.db.QueryRow("SELECT "+attachmentColumns+" FROM attachments WHERE id = ? AND user_id = ?", id, userID))
+	if err != nil {
+		return dto.AttachmentOutput{}, fmt.Errorf("attachment not found: %w", err)
+	}
+	return mapper.ToAttachmentOutput(a), nil
+}
+
+// path is where the file with the given hash is stored, fanned out by the
+// first two hex digits to keep directories small.
+func (s *AttachmentService) path(hash string) (string, error) {
+	if s.dir == "" {
+		return "", fmt.Errorf("attachment storage is unavailable")
+	}
+	if len(hash) != sha256.Size*2 {
+		return "", fmt.Errorf("invalid attachment hash")
+	}
+	if _, err := hex.DecodeString(hash); err != nil {
+		return "", fmt.Errorf("invalid attachment hash")
+	}
+	return filepath.Join(s.dir, hash[:2], hash), nil
+}
+
+// store writes a file unless it is already stored, in which case its
+// modification time is refreshed so cleanup leaves it alone. It is written to
+// a temporary file first so a crash never leaves a partial file under its hash.
+func (s *AttachmentService) store(hash string, data []byte) error {
+	path, err := s.path(hash)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err == nil {
+		now := time.Now()
+		if err := os.Chtimes(path, now, now); err != nil {
+			return fmt.Errorf("failed to store attachment: %w", err)
+		}
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create attachment directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-")
+	if err != nil {
+		return fmt.Errorf("failed to store attachment: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to store attachment: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to store attachment: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to store attachment: %w", err)
+	}
+	return nil
+}
+
+func scanAttachment(row rowScanner) (models.Attachment, error) {
+	var a models.Attachment
+	err := row.Scan(&a.ID, &a.EntityType, &a.EntityID, &a.SHA256, &a.FileName, &a.MimeType, &a.Size, &a.CreatedAt)
+	return a, err
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"tally/internal/dto"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttachmentService_StoreReadAndCleanup(t *testing.T) {
+	db := setupFinanceTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	auth := NewAuthService(db)
+	user := createTestUser(t, auth, "attachment_user")
+	other := createTestUser(t, auth, "attachment_other")
+	finance := NewFinanceService(db, nil)
+	attachments := &AttachmentService{db: db, dir: t.TempDir()}
+	acc := finance.CreateAccount(user.ID, dto.CreateAccountInput{Name: "Card", Type: "credit"})
+	_, err := finance.ImportTransactions(user.ID, dto.ImportTransactionsInput{AccountID: acc.ID, FileContent: "2024-01-10,OFFICE SUPPLY,-80.00\n" +
+		"2024-01-12,PRINTER INK,-45.00\n"})
+	require.NoError(t, err)
+	txs := finance.GetTransactions(user.ID, dto.TransactionFilter{AccountID: acc.ID})
+	require.Len(t, txs, 2)
+
+	receipt := base64.StdEncoding.EncodeToString([]byte("%PDF-1.4\nreceipt for office supplies"))
+	a, err := attachments.Add(user.ID, dto.AddAttachmentInput{EntityType: "transaction", EntityID: txs[0].ID, FileName: "../receipt.pdf", Content: receipt})
+	require.NoError(t, err)
+	assert.Equal(t, "application/pdf", a.MimeType)
+	assert.Equal(t, "receipt.pdf", a.FileName)
+	assert.Len(t, a.SHA256, 64)
+	again, err := attachments.Add(user.ID, dto.AddAttachmentInput{EntityType: "transaction", EntityID: txs[0].ID, FileName: "copy.pdf", Content: receipt})
+	require.NoError(t, err)
+	assert.Equal(t, a.ID, again.ID, "the same file is attached once")
+
+	// The same receipt on another transaction shares the stored file.
+	shared, err := attachments.Add(user.ID, dto.AddAttachmentInput{EntityType: "transaction", EntityID: txs[1].ID, FileName: "receipt.pdf", Content: receipt})
+	require.NoError(t, err)
+	assert.NotEqual(t, a.ID, shared.ID)
+	png := base64.StdEncoding.EncodeToString(append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 32)...))
+	photo, err := attachments.Add(user.ID, dto.AddAttachmentInput{EntityType: "transaction", EntityID: txs[1].ID, FileName: "photo.pdf", Content: png})
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", photo.MimeType, "the type comes from the content, not the name")
+	assert.Equal(t, 2, storedFiles(t, attachments.dir))
+	for _, tx := range finance.GetTransactions(user.ID, dto.TransactionFilter{AccountID: acc.ID}) {
+		if tx.ID == txs[1].ID {
+			assert.Equal(t, 2, tx.AttachmentCount)
+		}
+	}
+
+	_, err = attachments.Add(user.ID, dto.AddAttachmentInput{EntityType: "transaction", EntityID: txs[0].ID, FileName: "archive.zip",
+		Content: base64.StdEncoding.EncodeToString([]byte("PK\x03\x04\x14\x00\x00\x00"))})
+	assert.ErrorContains(t, err, "unsupported file type")
+	_, err = attachments.Add(user.ID, dto.AddAttachmentInput{EntityType: "transaction", EntityID: txs[0].ID, FileName: "huge.txt",
+		Content: base64.StdEncoding.EncodeToString([]byte(strings.Repeat("a", maxAttachmentSize+1)))})
+	assert.ErrorContains(t, err, "larger than")
+	_, err = attachments.Add(other.ID, dto.AddAttachmentInput{EntityType: "transaction", EntityID: txs[0].ID, FileName: "receipt.pdf", Content: receipt})
+	assert.ErrorContains(t, err, "not found")
+	_, err = attachments.Add(user.ID, dto.AddAttachmentInput{EntityType: "project", EntityID: 1, FileName: "receipt.pdf", Content: receipt})
+	assert.Error(t, err)
+
+	content, err := attachments.Read(user.ID, a.ID)
+	require.NoError(t, err)
+	assert.Equal(t, receipt, content.Content)
+	_, err = attachments.Read(other.ID, a.ID)
+	assert.Error(t, err)
+	assert.Empty(t, attachments.List(other.ID, "transaction", txs[0].ID))
+
+	// A file changed on disk is refused.
+	path, err := attachments.path(photo.SHA256)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, []byte("tampered"), 0600))
+	_, err = attachments.Read(user.ID, photo.ID)
+	assert.ErrorContains(t, err, "modified")
+
+	// Deleting one reference keeps the shared file.
+	require.NoError(t, attachments.Delete(user.ID, a.ID))
+	assert.Equal(t, 2, storedFiles(t, attachments.dir))
+
+	// Deleting the transaction removes its attachments; cleanup removes the
+	// files once they are old enough not to belong to an attachment being added.
+	require.NoError(t, finance.DeleteTransaction(user.ID, txs[1].ID))
+	assert.Empty(t, attachments.List(user.ID, "transaction", txs[1].ID))
+	cleanup, err := attachments.CleanupOrphans()
+	require.NoError(t, err)
+	assert.Zero(t, cleanup.RemovedFiles)
+	assert.Equal(t, 2, storedFiles(t, attachments.dir))
+	ageFiles(t, attachments.dir, 2*time.Hour)
+	cleanup, err = attachments.CleanupOrphans()
+	require.NoError(t, err)
+	assert.Equal(t, 2, cleanup.RemovedFiles)
+	assert.Zero(t, storedFiles(t, attachments.dir))
+
+	// Rows left behind by a record deleted without the trigger are removed too.
+	_, err = db.Exec("INSERT INTO attachments(user_id, entity_type, entity_id, sha256, file_name, mime_type, size) VALUES(?, 'invoice', 999, ?, 'x.pdf', 'application/pdf', 1)",
+		user.ID, a.SHA256)
+	require.NoError(t, err)
+	cleanup, err = attachments.CleanupOrphans()
+	require.NoError(t, err)
+	assert.Equal(t, 1, cleanup.RemovedRows)
+}
+
+// ageFiles moves the modification time of the stored files into the past.
+func ageFiles(t *testing.T, dir string, age time.Duration) {
+	t.Helper()
+	old := time.Now().Add(-age)
+	require.NoError(t, filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			err = os.Chtimes(path, old, old)
+		}
+		return err
+	}))
+}
+
+// storedFiles counts the files in the attachment store.
+func storedFiles(t *testing.T, dir string) int {
+	t.Helper()
+	count := 0
+	require.NoError(t, filepath.WalkDir(dir, func(_ string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			count++
+		}
+		return err
+	}))
+	return count
+}
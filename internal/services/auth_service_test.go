@@ -77,6 +77,9 @@ func setupTestDB(t *testing.T) *sql.DB {
 			sender_postal_code TEXT,
 			default_terms TEXT DEFAULT 'Due upon receipt',
 			default_message_template TEXT DEFAULT 'Thank you for your business.',
+			payment_terms TEXT,
+			late_fee_type TEXT DEFAULT '',
+			late_fee_value REAL DEFAULT 0,
 			updated_at TEXT DEFAULT (datetime('now')),
 			FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
 		);`,
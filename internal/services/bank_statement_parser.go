@@ -0,0 +1,440 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"math"
+	"strings"
+	"tally/internal/dto"
+	"time"
+)
+
+// Bank statement formats accepted by ImportTransactions besides CSV.
+const (
+	importFormatCSV     = "csv"
+	importFormatOFX     = "ofx" // also QFX, SGML (1.x) or XML (2.x)
+	importFormatCAMT053 = "camt053"
+)
+
+// statementBalance is what a statement file says about its account's balance.
+// Opening is only reported by CAMT.053; OFX carries the ledger balance alone.
+type statementBalance struct {
+	Account  string
+	Currency string
+	Opening  *float64
+	Closing  *float64
+	AsOf     string
+	Net      float64 // sum of the statement's entries
+}
+
+// check verifies that the entries explain the move from opening to closing
+// balance, which catches truncated or partially parsed files.
+func (b statementBalance) check() error {
+	if b.Opening == nil || b.Closing == nil {
+		return nil
+	}
+	if diff := *b.Opening + b.Net - *b.Closing; math.Abs(diff) > 0.005 {
+		return fmt.Errorf("statement for %s does not balance: opening %.2f + entries %.2f != closing %.2f",
+			nonEmptyOr(b.Account, "account"), *b.Opening, b.Net, *b.Closing)
+	}
+	return nil
+}
+
+// detectImportFormat returns the explicit format, or guesses it from content.
+func detectImportFormat(format string, content string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "":
+	case "csv":
+		return importFormatCSV, nil
+	case "ofx", "qfx":
+		return importFormatOFX, nil
+	case "camt053", "camt.053", "camt":
+		return importFormatCAMT053, nil
+	default:
+		return "", fmt.Errorf("unsupported import format: %s", format)
+	}
+
+	head := content
+	if len(head) > 4096 {
+		head = head[:4096]
+	}
+	head = strings.ToUpper(head)
+	switch {
+	case strings.Contains(head, "OFXHEADER") || strings.Contains(head, "<OFX>"):
+		return importFormatOFX, nil
+	case strings.Contains(head, "CAMT.053") || strings.Contains(head, "<BKTOCSTMRSTMT"):
+		return importFormatCAMT053, nil
+	}
+	return importFormatCSV, nil
+}
+
+// parseImportFile reads the rows of an import file in whichever format it is.
+func parseImportFile(db *sql.DB, userID int, input dto.ImportTransactionsInput) ([]importRow, []statementBalance, error) {
+	format, err := detectImportFormat(input.Format, input.FileContent)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch format {
+	case importFormatOFX:
+		return parseOFX(input.FileContent)
+	case importFormatCAMT053:
+		return parseCAMT053(input.FileContent)
+	}
+
+	profile, err := resolveImportProfile(db, userID, input.ProfileID, input.BankType)
+	if err != nil {
+		return nil, nil, err
+	}
+	records, err := readImportCSV(input.FileContent, profile.Delimiter)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(records) < 1 {
+		return nil, nil, fmt.Errorf("empty CSV file")
+	}
+	_, rows, err := parseImportRecords(profile, records)
+	return rows, nil, err
+}
+
+// parseOFX reads the STMTTRN records of an OFX or QFX file. SGML files leave
+// leaf elements unclosed, so the file is walked as a stream of tags rather
+// than decoded as XML; aggregates are closed in both variants.
+func parseOFX(content string) ([]importRow, []statementBalance, error) {
+	var rows []importRow
+	var balances []statementBalance
+	var stack []string
+	var txn map[string]string
+	var current *statementBalance
+
+	inside := func(name string) bool {
+		for _, open := range stack {
+			if open == name {
+				return true
+			}
+		}
+		return false
+	}
+
+	rest := content
+	found := false
+	for {
+		start := strings.IndexByte(rest, '<')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(rest[start:], '>')
+		if end < 0 {
+			break
+		}
+		tag := strings.TrimSpace(rest[start+1 : start+end])
+		rest = rest[start+end+1:]
+		if tag == "" || tag[0] == '?' || tag[0] == '!' {
+			continue
+		}
+
+		if tag[0] == '/' {
+			name := strings.ToUpper(strings.TrimSpace(tag[1:]))
+			for i := len(stack) - 1; i >= 0; i-- {
+				if stack[i] == name {
+					stack = stack[:i]
+					break
+				}
+			}
+			switch name {
+			case "STMTTRN":
+				if txn != nil {
+					row := ofxRow(len(rows)+1, txn)
+					if current != nil && row.Err == nil {
+						current.Net += row.Transaction.Amount
+					}
+					rows = append(rows, row)
+					txn = nil
+				}
+			case "STMTRS", "CCSTMTRS":
+				if current != nil {
+					balances = append(balances, *current)
+					current = nil
+				}
+			}
+			continue
+		}
+
+		name := strings.ToUpper(strings.Fields(tag)[0])
+		value := rest
+		if next := strings.IndexByte(rest, '<'); next >= 0 {
+			value = rest[:next]
+		}
+		value = strings.TrimSpace(html.UnescapeString(value))
+		if value == "" {
+			// An aggregate such as <STMTTRN> or <LEDGERBAL>.
+			stack = append(stack, name)
+			switch name {
+			case "OFX":
+				found = true
+			case "STMTTRN":
+				txn = map[string]string{}
+			case "STMTRS", "CCSTMTRS":
+				current = &statementBalance{}
+			}
+			continue
+		}
+
+		switch {
+		case txn != nil && inside("STMTTRN"):
+			if name == "NAME" && inside("PAYEE") && txn["NAME"] != "" {
+				continue
+			}
+			txn[name] = value
+		case current != nil && name == "ACCTID":
+			current.Account = value
+		case current != nil && name == "CURDEF":
+			current.Currency = value
+		case current != nil && inside("LEDGERBAL") && name == "BALAMT":
+			if amount, err := parseStatementAmount(value); err == nil {
+				current.Closing = &amount
+			}
+		case current != nil && inside("LEDGERBAL") && name == "DTASOF":
+			if d, err := parseOFXDate(value); err == nil {
+				current.AsOf = d.Format("2006-01-02")
+			}
+		}
+	}
+	if !found {
+		return nil, nil, fmt.Errorf("not an OFX file: no <OFX> element")
+	}
+	return rows, balances, nil
+}
+
+func ofxRow(line int, fields map[string]string) importRow {
+	row := importRow{Line: line}
+	t := &row.Transaction
+
+	dateValue := fields["DTPOSTED"]
+	if dateValue == "" {
+		dateValue = fields["DTUSER"]
+	}
+	d, err := parseOFXDate(dateValue)
+	if err != nil {
+		row.Err = err
+		return row
+	}
+	t.Date = d
+	if t.Amount, err = parseStatementAmount(fields["TRNAMT"]); err != nil || fields["TRNAMT"] == "" {
+		row.Err = fmt.Errorf("invalid amount: %q", fields["TRNAMT"])
+		return row
+	}
+	t.ReferenceID = fields["FITID"]
+
+	name, memo := fields["NAME"], fields["MEMO"]
+	switch {
+	case name == "":
+		t.Description = memo
+	case memo == "" || strings.Contains(name, memo):
+		t.Description = name
+	default:
+		t.Description = name + " " + memo
+	}
+	if t.Description == "" {
+		t.Description = strings.TrimSpace(fields["TRNTYPE"] + " " + fields["CHECKNUM"])
+	}
+	return row
+}
+
+// parseOFXDate reads the date part of an OFX datetime such as
+// 20240115120000.000[-5:EST].
+func parseOFXDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if len(s) < 8 {
+		return time.Time{}, fmt.Errorf("invalid OFX date: %q", s)
+	}
+	d, err := time.Parse("20060102", s[:8])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid OFX date: %q", s)
+	}
+	return d, nil
+}
+
+// parseStatementAmount accepts "." decimals and, as some banks emit, ",".
+func parseStatementAmount(s string) (float64, error) {
+	if strings.Contains(s, ",") && !strings.Contains(s, ".") {
+		return parseAmountWith(s, ",")
+	}
+	return parseAmountWith(s, ".")
+}
+
+// camtDocument maps the parts of an ISO 20022 camt.053 bank-to-customer
+// statement that become transactions. Element names match any version.
+type camtDocument struct {
+	Statements []struct {
+		ID      string `xml:"Id"`
+		Account struct {
+			IBAN     string `xml:"Id>IBAN"`
+			Other    string `xml:"Id>Othr>Id"`
+			Currency string `xml:"Ccy"`
+		} `xml:"Acct"`
+		Balances []struct {
+			Code   string     `xml:"Tp>CdOrPrtry>Cd"`
+			Amount camtAmount `xml:"Amt"`
+			Sign   string     `xml:"CdtDbtInd"`
+			Date   camtDate   `xml:"Dt"`
+		} `xml:"Bal"`
+		Entries []camtEntry `xml:"Ntry"`
+	} `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camtAmount struct {
+	Value    string `xml:",chardata"`
+	Currency string `xml:"Ccy,attr"`
+}
+
+type camtDate struct {
+	Date     string `xml:"Dt"`
+	DateTime string `xml:"DtTm"`
+}
+
+func (d camtDate) day() string {
+	if d.Date != "" {
+		return d.Date
+	}
+	if len(d.DateTime) >= 10 {
+		return d.DateTime[:10]
+	}
+	return d.DateTime
+}
+
+// camtStatus is plain text (BOOK) in older versions and <Cd>BOOK</Cd> later.
+type camtStatus struct {
+	Text string `xml:",chardata"`
+	Code string `xml:"Cd"`
+}
+
+type camtEntry struct {
+	Reference       string     `xml:"NtryRef"`
+	Amount          camtAmount `xml:"Amt"`
+	Sign            string     `xml:"CdtDbtInd"`
+	Status          camtStatus `xml:"Sts"`
+	BookingDate     camtDate   `xml:"BookgDt"`
+	ValueDate       camtDate   `xml:"ValDt"`
+	ServicerRef     string     `xml:"AcctSvcrRef"`
+	AdditionalInfo  string     `xml:"AddtlNtryInf"`
+	TransactionInfo []struct {
+		ServicerRef string   `xml:"Refs>AcctSvcrRef"`
+		EndToEndID  string   `xml:"Refs>EndToEndId"`
+		Creditor    string   `xml:"RltdPties>Cdtr>Nm"`
+		CreditorPty string   `xml:"RltdPties>Cdtr>Pty>Nm"`
+		Debtor      string   `xml:"RltdPties>Dbtr>Nm"`
+		DebtorPty   string   `xml:"RltdPties>Dbtr>Pty>Nm"`
+		Remittance  []string `xml:"RmtInf>Ustrd"`
+	} `xml:"NtryDtls>TxDtls"`
+}
+
+// parseCAMT053 reads the booked entries of a camt.053 statement. Each entry
+// becomes one transaction, even when the bank batched several payments in it.
+func parseCAMT053(content string) ([]importRow, []statementBalance, error) {
+	var doc camtDocument
+	if err := xml.Unmarshal([]byte(content), &doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse CAMT.053: %w", err)
+	}
+	if len(doc.Statements) == 0 {
+		return nil, nil, fmt.Errorf("not a CAMT.053 file: no statements found")
+	}
+
+	var rows []importRow
+	var balances []statementBalance
+	for _, stmt := range doc.Statements {
+		balance := statementBalance{Account: nonEmptyOr(stmt.Account.IBAN, stmt.Account.Other), Currency: stmt.Account.Currency}
+		for _, b := range stmt.Balances {
+			amount, err := camtSignedAmount(b.Amount.Value, b.Sign)
+			if err != nil {
+				return nil, nil, err
+			}
+			switch b.Code {
+			case "OPBD", "PRCD":
+				if balance.Opening == nil {
+					balance.Opening = &amount
+				}
+			case "CLBD":
+				balance.Closing = &amount
+				balance.AsOf = b.Date.day()
+			}
+		}
+
+		for _, e := range stmt.Entries {
+			status := strings.ToUpper(nonEmptyOr(strings.TrimSpace(e.Status.Code), strings.TrimSpace(e.Status.Text)))
+			if status == "PDNG" || status == "INFO" {
+				continue
+			}
+			row := camtRow(len(rows)+1, e)
+			if row.Err == nil {
+				balance.Net += row.Transaction.Amount
+			}
+			rows = append(rows, row)
+		}
+		balances = append(balances, balance)
+	}
+	return rows, balances, nil
+}
+
+func camtRow(line int, e camtEntry) importRow {
+	row := importRow{Line: line}
+	t := &row.Transaction
+
+	dateValue := nonEmptyOr(e.BookingDate.day(), e.ValueDate.day())
+	d, err := time.Parse("2006-01-02", dateValue)
+	if err != nil {
+		row.Err = fmt.Errorf("invalid booking date: %q", dateValue)
+		return row
+	}
+	t.Date = d
+	if t.Amount, err = camtSignedAmount(e.Amount.Value, e.Sign); err != nil {
+		row.Err = err
+		return row
+	}
+
+	t.ReferenceID = nonEmptyOr(strings.TrimSpace(e.ServicerRef), strings.TrimSpace(e.Reference))
+	var parts []string
+	if len(e.TransactionInfo) > 0 {
+		info := e.TransactionInfo[0]
+		if t.ReferenceID == "" {
+			t.ReferenceID = strings.TrimSpace(info.ServicerRef)
+		}
+		if t.ReferenceID == "" && info.EndToEndID != "NOTPROVIDED" {
+			t.ReferenceID = strings.TrimSpace(info.EndToEndID)
+		}
+		// The counterparty is the creditor on payments out, the debtor on money in.
+		party := nonEmptyOr(info.Creditor, info.CreditorPty)
+		if t.Amount > 0 {
+			party = nonEmptyOr(info.Debtor, info.DebtorPty)
+		}
+		if party != "" {
+			parts = append(parts, strings.TrimSpace(party))
+		}
+		for _, u := range info.Remittance {
+			if u = strings.TrimSpace(u); u != "" {
+				parts = append(parts, u)
+			}
+		}
+		if len(e.TransactionInfo) > 1 {
+			parts = append(parts, fmt.Sprintf("(%d payments)", len(e.TransactionInfo)))
+		}
+	}
+	if len(parts) == 0 && strings.TrimSpace(e.AdditionalInfo) != "" {
+		parts = append(parts, strings.TrimSpace(e.AdditionalInfo))
+	}
+	t.Description = strings.Join(strings.Fields(strings.Join(parts, " ")), " ")
+	return row
+}
+
+// camtSignedAmount applies the credit/debit indicator to an unsigned amount.
+func camtSignedAmount(value string, sign string) (float64, error) {
+	amount, err := parseAmountWith(strings.TrimSpace(value), ".")
+	if err != nil || strings.TrimSpace(value) == "" {
+		return 0, fmt.Errorf("invalid amount: %q", value)
+	}
+	if strings.EqualFold(strings.TrimSpace(sign), "DBIT") {
+		amount = -math.Abs(amount)
+	}
+	return amount, nil
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"strings"
+	"tally/internal/dto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testOFXSGML = `OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+ENCODING:USASCII
+CHARSET:1252
+
+<OFX>
+<SIGNONMSGSRSV1><SONRS><STATUS><CODE>0<SEVERITY>INFO</STATUS><DTSERVER>20240131120000</SONRS></SIGNONMSGSRSV1>
+<BANKMSGSRSV1><STMTTRNRS><TRNUID>1<STMTRS>
+<CURDEF>CAD
+<BANKACCTFROM><BANKID>004<ACCTID>1234567<ACCTTYPE>CHECKING</BANKACCTFROM>
+<BANKTRANLIST><DTSTART>20240101<DTEND>20240131
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20240115120000.000[-5:EST]
+<TRNAMT>-42.50
+<FITID>TD-0001
+<NAME>GROCER &amp; CO
+<MEMO>Store 12
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20240115
+<TRNAMT>-42.50
+<FITID>TD-0002
+<NAME>GROCER &amp; CO
+<MEMO>Store 12
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20240120
+<TRNAMT>1500.00
+<FITID>TD-0003
+<PAYEE><NAME>ACME PAYROLL</PAYEE>
+</STMTTRN>
+</BANKTRANLIST>
+<LEDGERBAL><BALAMT>2415.00<DTASOF>20240131</LEDGERBAL>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>
+`
+
+const testOFXXML = `<?xml version="1.0" encoding="UTF-8"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+<OFX>
+  <CREDITCARDMSGSRSV1><CCSTMTTRNRS><CCSTMTRS>
+    <CURDEF>USD</CURDEF>
+    <CCACCTFROM><ACCTID>4111</ACCTID></CCACCTFROM>
+    <BANKTRANLIST>
+      <STMTTRN><TRNTYPE>DEBIT</TRNTYPE><DTPOSTED>20240203</DTPOSTED><TRNAMT>-9.99</TRNAMT><FITID>CC-77</FITID><NAME>STREAMING</NAME><MEMO></MEMO></STMTTRN>
+    </BANKTRANLIST>
+    <LEDGERBAL><BALAMT>-9.99</BALAMT><DTASOF>20240229</DTASOF></LEDGERBAL>
+  </CCSTMTRS></CCSTMTTRNRS></CREDITCARDMSGSRSV1>
+</OFX>`
+
+const testCAMT053 = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.02">
+  <BkToCstmrStmt>
+    <GrpHdr><MsgId>MSG1</MsgId></GrpHdr>
+    <Stmt>
+      <Id>STMT-2024-01</Id>
+      <Acct><Id><IBAN>DE89370400440532013000</IBAN></Id><Ccy>EUR</Ccy></Acct>
+      <Bal><Tp><CdOrPrtry><Cd>OPBD</Cd></CdOrPrtry></Tp><Amt Ccy="EUR">1000.00</Amt><CdtDbtInd>CRDT</CdtDbtInd><Dt><Dt>2024-01-01</Dt></Dt></Bal>
+      <Bal><Tp><CdOrPrtry><Cd>CLBD</Cd></CdOrPrtry></Tp><Amt Ccy="EUR">%CLOSING%</Amt><CdtDbtInd>CRDT</CdtDbtInd><Dt><Dt>2024-01-31</Dt></Dt></Bal>
+      <Ntry>
+        <Amt Ccy="EUR">250.00</Amt><CdtDbtInd>DBIT</CdtDbtInd><Sts>BOOK</Sts>
+        <BookgDt><Dt>2024-01-05</Dt></BookgDt><ValDt><Dt>2024-01-05</Dt></ValDt>
+        <AcctSvcrRef>BANKREF-1</AcctSvcrRef>
+        <NtryDtls><TxDtls>
+          <Refs><EndToEndId>NOTPROVIDED</EndToEndId></Refs>
+          <RltdPties><Cdtr><Nm>Hausverwaltung GmbH</Nm></Cdtr></RltdPties>
+          <RmtInf><Ustrd>Miete Januar</Ustrd></RmtInf>
+        </TxDtls></NtryDtls>
+      </Ntry>
+      <Ntry>
+        <Amt Ccy="EUR">400.00</Amt><CdtDbtInd>CRDT</CdtDbtInd><Sts><Cd>BOOK</Cd></Sts>
+        <BookgDt><DtTm>2024-01-20T10:00:00</DtTm></BookgDt>
+        <NtryDtls><TxDtls>
+          <Refs><EndToEndId>INV-2024-7</EndToEndId></Refs>
+          <RltdPties><Dbtr><Pty><Nm>Kunde AG</Nm></Pty></Dbtr></RltdPties>
+        </TxDtls></NtryDtls>
+      </Ntry>
+      <Ntry>
+        <Amt Ccy="EUR">99.00</Amt><CdtDbtInd>DBIT</CdtDbtInd><Sts>PDNG</Sts>
+        <BookgDt><Dt>2024-01-31</Dt></BookgDt>
+      </Ntry>
+    </Stmt>
+  </BkToCstmrStmt>
+</Document>`
+
+func TestDetectImportFormat(t *testing.T) {
+	for content, want := range map[string]string{
+		testOFXSGML:                      importFormatOFX,
+		testOFXXML:                       importFormatOFX,
+		testCAMT053:                      importFormatCAMT053,
+		"Date,Description,Amount\n1,2,3": importFormatCSV,
+	} {
+		got, err := detectImportFormat("", content)
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+	got, err := detectImportFormat("QFX", "")
+	require.NoError(t, err)
+	assert.Equal(t, importFormatOFX, got)
+	_, err = detectImportFormat("qif", "")
+	assert.Error(t, err)
+}
+
+func TestParseOFX(t *testing.T) {
+	rows, balances, err := parseOFX(testOFXSGML)
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, "2024-01-15", rows[0].Transaction.Date.Format("2006-01-02"))
+	assert.Equal(t, "GROCER & CO Store 12", rows[0].Transaction.Description)
+	assert.InDelta(t, -42.5, rows[0].Transaction.Amount, 0.001)
+	assert.Equal(t, "TD-0001", rows[0].Transaction.ReferenceID)
+	assert.Equal(t, "ACME PAYROLL", rows[2].Transaction.Description)
+	require.Len(t, balances, 1)
+	assert.Equal(t, "1234567", balances[0].Account)
+	assert.Equal(t, "CAD", balances[0].Currency)
+	require.NotNil(t, balances[0].Closing)
+	assert.InDelta(t, 2415.0, *balances[0].Closing, 0.001)
+	assert.InDelta(t, 1415.0, balances[0].Net, 0.001)
+
+	rows, balances, err = parseOFX(testOFXXML)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "STREAMING", rows[0].Transaction.Description)
+	assert.Equal(t, "CC-77", rows[0].Transaction.ReferenceID)
+	require.Len(t, balances, 1)
+	assert.Equal(t, "2024-02-29", balances[0].AsOf)
+
+	_, _, err = parseOFX("Date,Description\n")
+	assert.Error(t, err)
+}
+
+func TestParseCAMT053(t *testing.T) {
+	rows, balances, err := parseCAMT053(strings.Replace(testCAMT053, "%CLOSING%", "1150.00", 1))
+	require.NoError(t, err)
+	require.Len(t, rows, 2, "pending entries are left out")
+	assert.Equal(t, "Hausverwaltung GmbH Miete Januar", rows[0].Transaction.Description)
+	assert.InDelta(t, -250.0, rows[0].Transaction.Amount, 0.001)
+	assert.Equal(t, "BANKREF-1", rows[0].Transaction.ReferenceID)
+	assert.Equal(t, "Kunde AG", rows[1].Transaction.Description)
+	assert.Equal(t, "INV-2024-7", rows[1].Transaction.ReferenceID)
+	assert.Equal(t, "2024-01-20", rows[1].Transaction.Date.Format("2006-01-02"))
+
+	require.Len(t, balances, 1)
+	assert.Equal(t, "DE89370400440532013000", balances[0].Account)
+	assert.NoError(t, balances[0].check())
+
+	_, balances, err = parseCAMT053(strings.Replace(testCAMT053, "%CLOSING%", "1200.00", 1))
+	require.NoError(t, err)
+	assert.ErrorContains(t, balances[0].check(), "does not balance")
+}
+
+func TestFinanceService_ImportStatements(t *testing.T) {
+	db := setupFinanceTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	user := createTestUser(t, NewAuthService(db), "statement_import_user")
+	service := NewFinanceService(db, nil)
+	acc := service.CreateAccount(user.ID, dto.CreateAccountInput{Name: "Chequing", Type: "checking"})
+
+	// Identical purchases on the same day survive because their FITIDs differ.
+	count, err := service.ImportTransactions(user.ID, dto.ImportTransactionsInput{AccountID: acc.ID, FileContent: testOFXSGML})
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	// Re-importing the same statement adds nothing.
+	count, err = service.ImportTransactions(user.ID, dto.ImportTransactionsInput{AccountID: acc.ID, Format: "qfx", FileContent: testOFXSGML})
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	camt := strings.Replace(testCAMT053, "%CLOSING%", "1150.00", 1)
+	count, err = service.ImportTransactions(user.ID, dto.ImportTransactionsInput{AccountID: acc.ID, FileContent: camt})
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	// A statement whose entries don't add up is rejected as a whole.
+	other := service.CreateAccount(user.ID, dto.CreateAccountInput{Name: "Savings", Type: "savings"})
+	_, err = service.ImportTransactions(user.ID, dto.ImportTransactionsInput{AccountID: other.ID, FileContent: strings.Replace(testCAMT053, "%CLOSING%", "1200.00", 1)})
+	assert.Error(t, err)
+	assert.Empty(t, service.GetTransactions(user.ID, dto.TransactionFilter{AccountID: other.ID}))
+
+	txs := service.GetTransactions(user.ID, dto.TransactionFilter{AccountID: acc.ID})
+	require.Len(t, txs, 5)
+	refs := map[string]bool{}
+	for _, tx := range txs {
+		refs[tx.ReferenceID] = true
+	}
+	assert.True(t, refs["TD-0002"])
+	assert.True(t, refs["INV-2024-7"])
+}
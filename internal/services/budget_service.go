@@ -0,0 +1,394 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"tally/internal/dto"
+	"tally/internal/mapper"
+	"tally/internal/models"
+	"time"
+
+	wailsRuntime "github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// BudgetService tracks spending in expense categories against monthly or
+// annual budgets, and alerts the frontend when a budget nears or passes its
+// limit.
+type BudgetService struct {
+	db  *sql.DB
+	mu  sync.RWMutex
+	ctx context.Context
+}
+
+// BudgetAlerter checks the budgets once spending has changed. Services that
+// import or categorize transactions are given the app's BudgetService.
+type BudgetAlerter interface {
+	CheckAlerts(userID int) []dto.BudgetAlertOutput
+}
+
+// checkBudgets runs the budget alerts if the service was given an alerter.
+func checkBudgets(budgets BudgetAlerter, userID int) {
+	if budgets != nil {
+		budgets.CheckAlerts(userID)
+	}
+}
+
+// NewBudgetService creates a new BudgetService instance.
+func NewBudgetService(db *sql.DB) *BudgetService {
+	return &BudgetService{db: db}
+}
+
+// Budget periods, rollover modes and statuses.
+const (
+	budgetMonthly = "monthly"
+	budgetAnnual  = "annual"
+
+	rolloverNone    = "none"
+	rolloverUnspent = "unspent"
+	rolloverAll     = "all"
+
+	budgetOK      = "ok"
+	budgetWarning = "warning"
+	budgetOver    = "over"
+)
+
+// defaultBudgetAlertThreshold is the percent spent that raises a warning.
+const defaultBudgetAlertThreshold = 80
+
+const budgetColumns = `b.id, b.category_id, COALESCE(c.name, ''), COALESCE(c.color, ''), b.period, b.amount, b.rollover, b.start_date,
+  b.alert_threshold, COALESCE(b.created_at, ''), COALESCE(b.updated_at, '')`
+
+const budgetFrom = " FROM budgets b LEFT JOIN finance_categories c ON c.id = b.category_id"
+
+// startup gives the service the Wails context alerts are emitted on; until
+// then alerts are returned but not emitted.
+func (s *BudgetService) startup(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ctx = ctx
+}
+
+// List returns the user's budgets by category name, each with its current period.
+func (s *BudgetService) List(userID int) []dto.BudgetOutput {
+	rows, err := s.db.Query("SELECT "+budgetColumns+budgetFrom+" WHERE b.user_id = ? ORDER BY c.name COLLATE NOCASE, b.id", userID)
+	if err != nil {
+		log.Println("Error querying budgets:", err)
+		return []dto.BudgetOutput{}
+	}
+	defer closeWithLog(rows, "closing budget rows")
+
+	var list []models.Budget
+	for rows.Next() {
+		b, err := scanBudget(rows)
+		if err != nil {
+			log.Println("Error scanning budget:", err)
+			continue
+		}
+		list = append(list, b)
+	}
+	out := mapper.ToBudgetOutputList(list)
+	now := time.Now()
+	for i := range out {
+		periods, err := s.periods(userID, list[i], now)
+		if err != nil {
+			log.Println("Error computing budget:", err)
+			continue
+		}
+		out[i].Current = periods[len(periods)-1]
+	}
+	return out
+}
+
+// Get returns a budget with its current period.
+func (s *BudgetService) Get(userID int, id int) (dto.BudgetOutput, error) {
+	b, err := scanBudget(s.db.QueryRow("SELECT "+budgetColumns+budgetFrom+" WHERE b.id = ? AND b.user_id = ?", id, userID))
+	if err != nil {
+		return dto.BudgetOutput{}, fmt.Errorf("budget not found: %w", err)
+	}
+	periods, err := s.periods(userID, b, time.Now())
+	if err != nil {
+		return dto.BudgetOutput{}, err
+	}
+	out := mapper.ToBudgetOutput(b)
+	out.Current = periods[len(periods)-1]
+	return out, nil
+}
+
+// History returns every period of a budget from its start through the
+// current one, oldest first.
+func (s *BudgetService) History(userID int, id int) ([]dto.BudgetPeriodOutput, error) {
+	b, err := scanBudget(s.db.QueryRow("SELECT "+budgetColumns+budgetFrom+" WHERE b.id = ? AND b.user_id = ?", id, userID))
+	if err != nil {
+		return nil, fmt.Errorf("budget not found: %w", err)
+	}
+	return s.periods(userID, b, time.Now())
+}
+
+// Create sets a budget for an expense category; a category has one budget.
+func (s *BudgetService) Create(userID int, input dto.CreateBudgetInput) (dto.BudgetOutput, error) {
+	b, err := normalizeBudget(models.Budget{
+		CategoryID: input.CategoryID, Period: input.Period, Amount: input.Amount, Rollover: input.Rollover,
+		StartDate: input.StartDate, AlertThreshold: input.AlertThreshold,
+	})
+	if err != nil {
+		return dto.BudgetOutput{}, err
+	}
+	var categoryType string
+	if err := s.db.QueryRow("SELECT type FROM finance_categories WHERE id = ? AND user_id = ?", b.CategoryID, userID).Scan(&categoryType); err != nil {
+		return dto.BudgetOutput{}, fmt.Errorf("category not found: %w", err)
+	}
+	if categoryType != "expense" {
+		return dto.BudgetOutput{}, fmt.Errorf("budgets are for expense categories")
+	}
+	var existing int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM budgets WHERE category_id = ? AND user_id = ?", b.CategoryID, userID).Scan(&existing); err != nil {
+		return dto.BudgetOutput{}, fmt.Errorf("failed to check budgets: %w", err)
+	}
+	if existing > 0 {
+		return dto.BudgetOutput{}, fmt.Errorf("this category already has a budget")
+	}
+
+	res, err := s.db.Exec("INSERT INTO budgets(user_id, category_id, period, amount, rollover, start_date, alert_threshold) VALUES(?, ?, ?, ?, ?, ?, ?)",
+		userID, b.CategoryID, b.Period, b.Amount, b.Rollover, b.StartDate, b.AlertThreshold)
+	if err != nil {
+		return dto.BudgetOutput{}, fmt.Errorf("failed to insert budget: %w", err)
+	}
+	id, _ := res.LastInsertId()
+	return s.Get(userID, int(id))
+}
+
+// Update changes a budget's amount, period, rollover, start or threshold.
+// Alerts already raised for the current period are cleared so they can be
+// raised again against the new limit.
+func (s *BudgetService) Update(userID int, input dto.UpdateBudgetInput) (dto.BudgetOutput, error) {
+	b, err := normalizeBudget(models.Budget{
+		Period: input.Period, Amount: input.Amount, Rollover: input.Rollover, StartDate: input.StartDate, AlertThreshold: input.AlertThreshold,
+	})
+	if err != nil {
+		return dto.BudgetOutput{}, err
+	}
+	res, err := s.db.Exec("UPDATE budgets SET period = ?, amount = ?, rollover = ?, start_date = ?, alert_threshold = ?, updated_at = datetime('now') WHERE id = ? AND user_id = ?",
+		b.Period, b.Amount, b.Rollover, b.StartDate, b.AlertThreshold, input.ID, userID)
+	if err != nil {
+		return dto.BudgetOutput{}, fmt.Errorf("failed to update budget: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return dto.BudgetOutput{}, fmt.Errorf("budget not found")
+	}
+	if _, err := s.db.Exec("DELETE FROM budget_alerts WHERE budget_id = ?", input.ID); err != nil {
+		return dto.BudgetOutput{}, fmt.Errorf("failed to reset budget alerts: %w", err)
+	}
+	return s.Get(userID, input.ID)
+}
+
+// Delete removes a budget.
+func (s *BudgetService) Delete(userID int, id int) error {
+	res, err := s.db.Exec("DELETE FROM budgets WHERE id = ? AND user_id = ?", id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete budget: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("budget not found")
+	}
+	return nil
+}
+
+// CheckAlerts compares each budget's current period with its limits and
+// returns the alerts not raised before, emitting each as a budget:alert
+// event. Imports and categorization call it.
+func (s *BudgetService) CheckAlerts(userID int) []dto.BudgetAlertOutput {
+	alerts, err := s.checkAlerts(userID, time.Now())
+	if err != nil {
+		log.Println("Error checking budget alerts:", err)
+	}
+	s.mu.RLock()
+	ctx := s.ctx
+	s.mu.RUnlock()
+	for _, a := range alerts {
+		if ctx != nil {
+			wailsRuntime.EventsEmit(ctx, "budget:alert", a)
+		}
+	}
+	return alerts
+}
+
+// checkAlerts records and returns the new alerts as of the given day. A
+// budget that goes straight past its limit raises only the over alert.
+func (s *BudgetService) checkAlerts(userID int, asOf time.Time) ([]dto.BudgetAlertOutput, error) {
+	rows, err := s.db.Query("SELECT "+budgetColumns+budgetFrom+" WHERE b.user_id = ? ORDER BY b.id", userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query budgets: %w", err)
+	}
+	var budgets []models.Budget
+	for rows.Next() {
+		b, err := scanBudget(rows)
+		if err != nil {
+			closeWithLog(rows, "closing budget rows")
+			return nil, fmt.Errorf("failed to scan budget: %w", err)
+		}
+		budgets = append(budgets, b)
+	}
+	closeWithLog(rows, "closing budget rows")
+
+	alerts := []dto.BudgetAlertOutput{}
+	for _, b := range budgets {
+		periods, err := s.periods(userID, b, asOf)
+		if err != nil {
+			return alerts, err
+		}
+		current := periods[len(periods)-1]
+		if current.Status == budgetOK {
+			continue
+		}
+		raised := false
+		for _, level := range []string{budgetWarning, budgetOver} {
+			if level == budgetOver && current.Status != budgetOver {
+				break
+			}
+			res, err := s.db.Exec("INSERT OR IGNORE INTO budget_alerts(budget_id, period_start, level) VALUES(?, ?, ?)", b.ID, current.PeriodStart, level)
+			if err != nil {
+				return alerts, fmt.Errorf("failed to record budget alert: %w", err)
+			}
+			if n, _ := res.RowsAffected(); n > 0 {
+				raised = true
+			}
+		}
+		if raised {
+			alerts = append(alerts, dto.BudgetAlertOutput{
+				BudgetID: b.ID, CategoryID: b.CategoryID, CategoryName: b.CategoryName, PeriodStart: current.PeriodStart,
+				Level: current.Status, Available: current.Available, Actual: current.Actual, PercentUsed: current.PercentUsed,
+			})
+		}
+	}
+	return alerts, nil
+}
+
+// periods computes a budget's periods from its start through the one holding
+// asOf, carrying leftovers forward as its rollover mode says. A budget that
+// has not started yet has just its first period.
+func (s *BudgetService) periods(userID int, b models.Budget, asOf time.Time) ([]dto.BudgetPeriodOutput, error) {
+	start, err := time.Parse("2006-01-02", b.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid budget start date: %w", err)
+	}
+	months := 1
+	if b.Period == budgetAnnual {
+		months = 12
+	}
+	count := 1
+	if !asOf.Before(start) {
+		count = monthsBetween(start, asOf)/months + 1
+	}
+	end := start.AddDate(0, count*months, -1)
+
+	// Spending is net of refunds and counts split lines in this category.
+	rows, err := s.db.Query("SELECT substr(l.date, 1, 10), l.amount FROM ("+transactionLinesSQL+`) l
+WHERE l.user_id = ? AND l.category_id = ? AND l.transfer_id IS NULL AND substr(l.date, 1, 10) BETWEEN ? AND ?`,
+		userID, b.CategoryID, start.Format("2006-01-02"), end.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query budget spending: %w", err)
+	}
+	defer closeWithLog(rows, "closing budget spending rows")
+	spent := make([]float64, count)
+	for rows.Next() {
+		var date string
+		var amount float64
+		if err := rows.Scan(&date, &amount); err != nil {
+			return nil, fmt.Errorf("failed to scan budget spending: %w", err)
+		}
+		d, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			continue
+		}
+		spent[monthsBetween(start, d)/months] -= amount
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	out := make([]dto.BudgetPeriodOutput, count)
+	carry := 0.0
+	for i := range out {
+		periodStart := start.AddDate(0, i*months, 0)
+		p := dto.BudgetPeriodOutput{
+			PeriodStart: periodStart.Format("2006-01-02"),
+			PeriodEnd:   periodStart.AddDate(0, months, -1).Format("2006-01-02"),
+			Budgeted:    b.Amount,
+			Carryover:   math.Round(carry*100) / 100,
+			Actual:      math.Round(spent[i]*100) / 100,
+		}
+		p.Available = math.Round((p.Budgeted+p.Carryover)*100) / 100
+		p.Remaining = math.Round((p.Available-p.Actual)*100) / 100
+		p.Status = budgetOK
+		if p.Available > 0 {
+			p.PercentUsed = math.Round(p.Actual/p.Available*1000) / 10
+			if p.PercentUsed >= b.AlertThreshold {
+				p.Status = budgetWarning
+			}
+		}
+		if p.Remaining < 0 {
+			p.Status = budgetOver
+		}
+		out[i] = p
+
+		switch b.Rollover {
+		case rolloverUnspent:
+			carry = math.Max(p.Remaining, 0)
+		case rolloverAll:
+			carry = p.Remaining
+		}
+	}
+	return out, nil
+}
+
+// normalizeBudget validates a budget and fills in its defaults. The start
+// date moves to the first of its month.
+func normalizeBudget(b models.Budget) (models.Budget, error) {
+	if b.Period == "" {
+		b.Period = budgetMonthly
+	}
+	if b.Period != budgetMonthly && b.Period != budgetAnnual {
+		return b, fmt.Errorf("unknown budget period: %s", b.Period)
+	}
+	if b.Rollover == "" {
+		b.Rollover = rolloverNone
+	}
+	if b.Rollover != rolloverNone && b.Rollover != rolloverUnspent && b.Rollover != rolloverAll {
+		return b, fmt.Errorf("unknown rollover option: %s", b.Rollover)
+	}
+	if b.Amount <= 0 {
+		return b, fmt.Errorf("budget amount must be positive")
+	}
+	if b.AlertThreshold == 0 {
+		b.AlertThreshold = defaultBudgetAlertThreshold
+	}
+	if b.AlertThreshold < 0 {
+		return b, fmt.Errorf("alert threshold cannot be negative")
+	}
+	start := time.Now()
+	if b.StartDate != "" {
+		d, err := parseDate(b.StartDate)
+		if err != nil {
+			return b, fmt.Errorf("invalid start date: %w", err)
+		}
+		start = d
+	}
+	b.StartDate = time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC).Format("2006-01-02")
+	return b, nil
+}
+
+func scanBudget(row rowScanner) (models.Budget, error) {
+	var b models.Budget
+	err := row.Scan(&b.ID, &b.CategoryID, &b.CategoryName, &b.CategoryColor, &b.Period, &b.Amount, &b.Rollover, &b.StartDate,
+		&b.AlertThreshold, &b.CreatedAt, &b.UpdatedAt)
+	return b, err
+}
+
+// monthsBetween counts the calendar months from a's month to b's.
+func monthsBetween(a time.Time, b time.Time) int {
+	return (b.Year()-a.Year())*12 + int(b.Month()) - int(a.Month())
+}
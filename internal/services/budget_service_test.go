@@ -0,0 +1,188 @@
+package services
+
+import (
+	"tally/internal/dto"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBudgetService_CRUD(t *testing.T) {
+	db := setupFinanceTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	auth := NewAuthService(db)
+	user := createTestUser(t, auth, "budget_crud_user")
+	other := createTestUser(t, auth, "budget_crud_other")
+	finance := NewFinanceService(db, nil)
+	budgets := NewBudgetService(db)
+	groceries := finance.CreateCategory(user.ID, dto.CreateCategoryInput{Name: "Groceries", Type: "expense"})
+	salary := finance.CreateCategory(user.ID, dto.CreateCategoryInput{Name: "Salary", Type: "income"})
+
+	_, err := budgets.Create(user.ID, dto.CreateBudgetInput{CategoryID: salary.ID, Amount: 100})
+	assert.ErrorContains(t, err, "expense categories")
+	_, err = budgets.Create(user.ID, dto.CreateBudgetInput{CategoryID: groceries.ID})
+	assert.ErrorContains(t, err, "must be positive")
+	_, err = budgets.Create(user.ID, dto.CreateBudgetInput{CategoryID: groceries.ID, Amount: 100, Period: "weekly"})
+	assert.Error(t, err)
+	_, err = budgets.Create(user.ID, dto.CreateBudgetInput{CategoryID: groceries.ID, Amount: 100, Rollover: "sometimes"})
+	assert.Error(t, err)
+	_, err = budgets.Create(other.ID, dto.CreateBudgetInput{CategoryID: groceries.ID, Amount: 100})
+	assert.ErrorContains(t, err, "category not found")
+
+	b, err := budgets.Create(user.ID, dto.CreateBudgetInput{CategoryID: groceries.ID, Amount: 500, StartDate: "2024-01-15"})
+	require.NoError(t, err)
+	assert.Equal(t, "monthly", b.Period)
+	assert.Equal(t, "none", b.Rollover)
+	assert.Equal(t, "2024-01-01", b.StartDate)
+	assert.Equal(t, 80.0, b.AlertThreshold)
+	assert.Equal(t, "Groceries", b.CategoryName)
+	assert.Equal(t, time.Now().Format("2006-01")+"-01", b.Current.PeriodStart)
+	_, err = budgets.Create(user.ID, dto.CreateBudgetInput{CategoryID: groceries.ID, Amount: 100})
+	assert.ErrorContains(t, err, "already has a budget")
+
+	b, err = budgets.Update(user.ID, dto.UpdateBudgetInput{ID: b.ID, Period: "annual", Amount: 6000, Rollover: "unspent", StartDate: "2024-04-01", AlertThreshold: 90})
+	require.NoError(t, err)
+	assert.Equal(t, "annual", b.Period)
+	assert.Equal(t, 90.0, b.AlertThreshold)
+	_, err = budgets.Update(other.ID, dto.UpdateBudgetInput{ID: b.ID, Amount: 1})
+	assert.Error(t, err)
+	history, err := budgets.History(user.ID, b.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "2024-04-01", history[0].PeriodStart)
+	assert.Equal(t, "2025-03-31", history[0].PeriodEnd)
+
+	assert.Len(t, budgets.List(user.ID), 1)
+	assert.Empty(t, budgets.List(other.ID))
+	assert.Error(t, budgets.Delete(other.ID, b.ID))
+
+	// Deleting the category removes its budget.
+	finance.DeleteCategory(user.ID, groceries.ID)
+	assert.Empty(t, budgets.List(user.ID))
+}
+
+func TestBudgetService_TrackingRolloverAndAlerts(t *testing.T) {
+	db := setupFinanceTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	user := createTestUser(t, NewAuthService(db), "budget_tracking_user")
+	finance := NewFinanceService(db, nil)
+	budgets := NewBudgetService(db)
+	acc := finance.CreateAccount(user.ID, dto.CreateAccountInput{Name: "Card", Type: "credit"})
+	groceries := finance.CreateCategory(user.ID, dto.CreateCategoryInput{Name: "Groceries", Type: "expense"})
+	household := finance.CreateCategory(user.ID, dto.CreateCategoryInput{Name: "Household", Type: "expense"})
+	_, err := NewCategorizationRuleService(db, nil).Create(user.ID, dto.CreateCategorizationRuleInput{Name: "Grocer", Pattern: "grocer", CategoryID: groceries.ID})
+	require.NoError(t, err)
+
+	_, err = finance.ImportTransactions(user.ID, dto.ImportTransactionsInput{AccountID: acc.ID, FileContent: "2023-12-30,GROCER 1,-999.00\n" +
+		"2024-01-10,GROCER 2,-300.00\n" +
+		"2024-02-03,GROCER 3,-650.00\n" +
+		"2024-02-09,GROCER 3 REFUND,50.00\n" +
+		"2024-02-20,WAREHOUSE CLUB,-120.00\n" +
+		"2024-03-05,GROCER 4,-100.00\n"})
+	require.NoError(t, err)
+	// Part of the warehouse run was groceries.
+	for _, tx := range finance.GetTransactions(user.ID, dto.TransactionFilter{AccountID: acc.ID}) {
+		if tx.Description == "WAREHOUSE CLUB" {
+			_, err = finance.SetTransactionSplits(user.ID, dto.SetTransactionSplitsInput{TransactionID: tx.ID, Splits: []dto.TransactionSplitInput{
+				{CategoryID: groceries.ID, Amount: -20}, {CategoryID: household.ID, Amount: -100},
+			}})
+			require.NoError(t, err)
+		}
+	}
+
+	created, err := budgets.Create(user.ID, dto.CreateBudgetInput{CategoryID: groceries.ID, Amount: 500, Rollover: "unspent", StartDate: "2024-01-01"})
+	require.NoError(t, err)
+	b, err := scanBudget(db.QueryRow("SELECT "+budgetColumns+budgetFrom+" WHERE b.id = ?", created.ID))
+	require.NoError(t, err)
+
+	march := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+	periods, err := budgets.periods(user.ID, b, march)
+	require.NoError(t, err)
+	require.Len(t, periods, 3, "spending before the start is ignored")
+	assert.Equal(t, dto.BudgetPeriodOutput{PeriodStart: "2024-01-01", PeriodEnd: "2024-01-31", Budgeted: 500, Available: 500,
+		Actual: 300, Remaining: 200, PercentUsed: 60, Status: "ok"}, periods[0])
+	assert.Equal(t, 200.0, periods[1].Carryover)
+	assert.Equal(t, 700.0, periods[1].Available)
+	assert.Equal(t, 620.0, periods[1].Actual, "refunds reduce spending and split lines count")
+	assert.Equal(t, "warning", periods[1].Status)
+	assert.Equal(t, 80.0, periods[2].Carryover)
+	assert.Equal(t, "ok", periods[2].Status)
+
+	// Alerts are raised once per period and level.
+	february := time.Date(2024, 2, 25, 0, 0, 0, 0, time.UTC)
+	alerts, err := budgets.checkAlerts(user.ID, february)
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, "warning", alerts[0].Level)
+	assert.Equal(t, "Groceries", alerts[0].CategoryName)
+	assert.Equal(t, "2024-02-01", alerts[0].PeriodStart)
+	alerts, err = budgets.checkAlerts(user.ID, february)
+	require.NoError(t, err)
+	assert.Empty(t, alerts)
+
+	// Without rollover February is over; changing the budget re-arms alerts.
+	_, err = budgets.Update(user.ID, dto.UpdateBudgetInput{ID: b.ID, Amount: 500, StartDate: "2024-01-01"})
+	require.NoError(t, err)
+	alerts, err = budgets.checkAlerts(user.ID, february)
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, "over", alerts[0].Level)
+	assert.Equal(t, -120.0, alerts[0].Available-alerts[0].Actual)
+
+	// Rolling everything over carries the overspending into March.
+	_, err = budgets.Update(user.ID, dto.UpdateBudgetInput{ID: b.ID, Amount: 500, Rollover: "all", StartDate: "2024-01-01"})
+	require.NoError(t, err)
+	b.Rollover = "all"
+	periods, err = budgets.periods(user.ID, b, march)
+	require.NoError(t, err)
+	assert.Equal(t, 80.0, periods[2].Carryover)
+	b.Amount = 250
+	periods, err = budgets.periods(user.ID, b, march)
+	require.NoError(t, err)
+	assert.Equal(t, -50.0, periods[1].Carryover)
+	assert.Equal(t, -420.0, periods[2].Carryover)
+	assert.Equal(t, "over", periods[2].Status)
+}
+
+func TestBudgetService_AlertsOnImportAndCategorize(t *testing.T) {
+	db := setupFinanceTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	user := createTestUser(t, NewAuthService(db), "budget_alerts_user")
+	budgets := NewBudgetService(db)
+	finance := NewFinanceService(db, budgets)
+	acc := finance.CreateAccount(user.ID, dto.CreateAccountInput{Name: "Card", Type: "credit"})
+	dining := finance.CreateCategory(user.ID, dto.CreateCategoryInput{Name: "Dining", Type: "expense"})
+	travel := finance.CreateCategory(user.ID, dto.CreateCategoryInput{Name: "Travel", Type: "expense"})
+	_, err := NewCategorizationRuleService(db, budgets).Create(user.ID, dto.CreateCategorizationRuleInput{Name: "Bistro", Pattern: "bistro", CategoryID: dining.ID})
+	require.NoError(t, err)
+	start := time.Now().Format("2006-01") + "-01"
+	for _, category := range []int{dining.ID, travel.ID} {
+		_, err = budgets.Create(user.ID, dto.CreateBudgetInput{CategoryID: category, Amount: 100, StartDate: start})
+		require.NoError(t, err)
+	}
+	levels := func() int {
+		var n int
+		require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM budget_alerts").Scan(&n))
+		return n
+	}
+
+	// Rules categorize on import, which checks the budgets.
+	today := time.Now().Format("2006-01-02")
+	_, err = finance.ImportTransactions(user.ID, dto.ImportTransactionsInput{AccountID: acc.ID, FileContent: today + ",BISTRO,-90.00\n" +
+		today + ",AIRLINE,-150.00\n"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, levels(), "dining reached its warning level")
+
+	// So does categorizing by hand.
+	for _, tx := range finance.GetTransactions(user.ID, dto.TransactionFilter{AccountID: acc.ID}) {
+		if tx.Description == "AIRLINE" {
+			finance.UpdateTransaction(user.ID, tx.ID, &travel.ID)
+		}
+	}
+	assert.Equal(t, 3, levels(), "travel went straight over")
+	assert.Empty(t, budgets.CheckAlerts(user.ID), "alerts were already raised")
+}
@@ -0,0 +1,487 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"tally/internal/dto"
+	"tally/internal/mapper"
+	"tally/internal/models"
+	"unicode"
+)
+
+// CategorizationRuleService manages rules that categorize transactions and
+// suggests new ones from the user's manual categorizations.
+type CategorizationRuleService struct {
+	db      *sql.DB
+	budgets BudgetAlerter
+}
+
+// NewCategorizationRuleService creates a new CategorizationRuleService instance.
+// budgets is told when rules categorize transactions; nil skips budget alerts.
+func NewCategorizationRuleService(db *sql.DB, budgets BudgetAlerter) *CategorizationRuleService {
+	return &CategorizationRuleService{db: db, budgets: budgets}
+}
+
+// categorizationRuleColumns selects a rule (alias r) with its category name
+// and how many transactions it has categorized.
+const categorizationRuleColumns = `r.id, r.name, r.priority, r.match_type, COALESCE(r.pattern, ''), r.min_amount, r.max_amount,
+  r.account_id, r.category_id, COALESCE(c.name, ''), COALESCE(r.tags, ''), COALESCE(r.disabled, 0),
+  (SELECT COUNT(*) FROM finance_transactions t WHERE t.category_rule_id = r.id AND t.user_id = r.user_id)`
+
+const categorizationRuleFrom = " FROM categorization_rules r LEFT JOIN finance_categories c ON c.id = r.category_id"
+
+// List returns the user's rules in the order they are applied.
+func (s *CategorizationRuleService) List(userID int) []dto.CategorizationRuleOutput {
+	rules, err := queryCategorizationRules(s.db, userID, false)
+	if err != nil {
+		log.Println("Error querying categorization rules:", err)
+		return []dto.CategorizationRuleOutput{}
+	}
+	return mapper.ToCategorizationRuleOutputList(rules)
+}
+
+// Get returns one rule.
+func (s *CategorizationRuleService) Get(userID int, id int) (dto.CategorizationRuleOutput, error) {
+	row := s.db.QueryRow("SELECT "+categorizationRuleColumns+categorizationRuleFrom+" WHERE r.id = ? AND r.user_id = ?", id, userID)
+	rule, err := scanCategorizationRule(row)
+	if err != nil {
+		return dto.CategorizationRuleOutput{}, fmt.Errorf("categorization rule not found: %w", err)
+	}
+	return mapper.ToCategorizationRuleOutput(rule), nil
+}
+
+// Create adds a rule. It applies to imports from then on; use
+// ApplyToUncategorized to run it over existing transactions.
+func (s *CategorizationRuleService) Create(userID int, input dto.CreateCategorizationRuleInput) (dto.CategorizationRuleOutput, error) {
+	rule, err := s.validate(userID, input)
+	if err != nil {
+		return dto.CategorizationRuleOutput{}, err
+	}
+	res, err := s.db.Exec(`INSERT INTO categorization_rules(user_id, name, priority, match_type, pattern, min_amount, max_amount, account_id, category_id, tags, disabled)
+VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, userID, rule.Name, rule.Priority, rule.MatchType, rule.Pattern, rule.MinAmount, rule.MaxAmount,
+		nullableID(input.AccountID), rule.CategoryID, strings.Join(rule.Tags, ","), rule.Disabled)
+	if err != nil {
+		return dto.CategorizationRuleOutput{}, fmt.Errorf("failed to insert categorization rule: %w", err)
+	}
+	id, _ := res.LastInsertId()
+	return s.Get(userID, int(id))
+}
+
+// Update changes a rule. Transactions it already categorized keep their category.
+func (s *CategorizationRuleService) Update(userID int, input dto.UpdateCategorizationRuleInput) (dto.CategorizationRuleOutput, error) {
+	rule, err := s.validate(userID, dto.CreateCategorizationRuleInput{
+		Name: input.Name, Priority: input.Priority, MatchType: input.MatchType, Pattern: input.Pattern,
+		MinAmount: input.MinAmount, MaxAmount: input.MaxAmount, AccountID: input.AccountID,
+		CategoryID: input.CategoryID, Tags: input.Tags, Disabled: input.Disabled,
+	})
+	if err != nil {
+		return dto.CategorizationRuleOutput{}, err
+	}
+	res, err := s.db.Exec(`UPDATE categorization_rules SET name = ?, priority = ?, match_type = ?, pattern = ?, min_amount = ?, max_amount = ?,
+  account_id = ?, category_id = ?, tags = ?, disabled = ?
+WHERE id = ? AND user_id = ?`, rule.Name, rule.Priority, rule.MatchType, rule.Pattern, rule.MinAmount, rule.MaxAmount,
+		nullableID(input.AccountID), rule.CategoryID, strings.Join(rule.Tags, ","), rule.Disabled, input.ID, userID)
+	if err != nil {
+		return dto.CategorizationRuleOutput{}, fmt.Errorf("failed to update categorization rule: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return dto.CategorizationRuleOutput{}, fmt.Errorf("categorization rule not found or not owned by user")
+	}
+	return s.Get(userID, input.ID)
+}
+
+// Delete removes a rule. Transactions it categorized keep their category.
+func (s *CategorizationRuleService) Delete(userID int, id int) error {
+	res, err := s.db.Exec("DELETE FROM categorization_rules WHERE id = ? AND user_id = ?", id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete categorization rule: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("categorization rule not found or not owned by user")
+	}
+	_, err = s.db.Exec("UPDATE finance_transactions SET category_rule_id = NULL WHERE category_rule_id = ? AND user_id = ?", id, userID)
+	return err
+}
+
+// ApplyToUncategorized runs the enabled rules over the user's uncategorized
+// transactions, optionally in one account.
+func (s *CategorizationRuleService) ApplyToUncategorized(userID int, accountID int) (dto.ApplyRulesOutput, error) {
+	rules, err := loadCategorizationRules(s.db, userID)
+	if err != nil {
+		return dto.ApplyRulesOutput{}, err
+	}
+
+	query := "SELECT t.id, t.account_id, t.description, t.amount FROM finance_transactions t WHERE t.user_id = ? AND " + uncategorizedSQL
+	args := []interface{}{userID}
+	if accountID > 0 {
+		query += " AND t.account_id = ?"
+		args = append(args, accountID)
+	}
+	rows, err := s.db.Query(query+" ORDER BY t.id", args...)
+	if err != nil {
+		return dto.ApplyRulesOutput{}, fmt.Errorf("failed to load uncategorized transactions: %w", err)
+	}
+	var pending []models.FinanceTransaction
+	for rows.Next() {
+		var t models.FinanceTransaction
+		if err := rows.Scan(&t.ID, &t.AccountID, &t.Description, &t.Amount); err != nil {
+			closeWithLog(rows, "closing uncategorized rows")
+			return dto.ApplyRulesOutput{}, fmt.Errorf("failed to scan transaction: %w", err)
+		}
+		pending = append(pending, t)
+	}
+	closeWithLog(rows, "closing uncategorized rows")
+
+	out := dto.ApplyRulesOutput{Checked: len(pending)}
+	if len(rules) == 0 || len(pending) == 0 {
+		return out, nil
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return dto.ApplyRulesOutput{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	for _, t := range pending {
+		matched, err := categorizeTransaction(tx, userID, t, rules)
+		if err != nil {
+			return dto.ApplyRulesOutput{}, err
+		}
+		if matched {
+			out.Categorized++
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return dto.ApplyRulesOutput{}, err
+	}
+	if out.Categorized > 0 {
+		checkBudgets(s.budgets, userID)
+	}
+	return out, nil
+}
+
+// Suggest proposes substring rules for merchants the user keeps categorizing
+// by hand the same way, skipping those an existing rule already covers.
+func (s *CategorizationRuleService) Suggest(userID int) []dto.RuleSuggestionOutput {
+	rules, err := loadCategorizationRules(s.db, userID)
+	if err != nil {
+		log.Println("Error loading categorization rules:", err)
+		return []dto.RuleSuggestionOutput{}
+	}
+	rows, err := s.db.Query(`SELECT t.account_id, t.description, t.amount, t.category_id, COALESCE(c.name, ''), `+uncategorizedSQL+`
+FROM finance_transactions t LEFT JOIN finance_categories c ON c.id = t.category_id
+WHERE t.user_id = ? AND t.category_rule_id IS NULL AND (t.category_id IS NOT NULL OR `+uncategorizedSQL+`)
+ORDER BY t.date DESC, t.id DESC`, userID)
+	if err != nil {
+		log.Println("Error querying transactions for rule suggestions:", err)
+		return []dto.RuleSuggestionOutput{}
+	}
+	defer closeWithLog(rows, "closing rule suggestion rows")
+
+	type merchant struct {
+		byCategory    map[int][]models.FinanceTransaction
+		categoryNames map[int]string
+		total         int
+	}
+	merchants := map[string]*merchant{}
+	var uncategorized []string
+	for rows.Next() {
+		var t models.FinanceTransaction
+		var categoryID sql.NullInt64
+		var categoryName string
+		var isUncategorized bool
+		if err := rows.Scan(&t.AccountID, &t.Description, &t.Amount, &categoryID, &categoryName, &isUncategorized); err != nil {
+			log.Println("Error scanning transaction for rule suggestions:", err)
+			continue
+		}
+		if isUncategorized {
+			uncategorized = append(uncategorized, normalizeDescription(t.Description))
+			continue
+		}
+		key := merchantKey(t.Description)
+		if key == "" {
+			continue
+		}
+		m := merchants[key]
+		if m == nil {
+			m = &merchant{byCategory: map[int][]models.FinanceTransaction{}, categoryNames: map[int]string{}}
+			merchants[key] = m
+		}
+		id := int(categoryID.Int64)
+		m.byCategory[id] = append(m.byCategory[id], t)
+		m.categoryNames[id] = categoryName
+		m.total++
+	}
+
+	out := []dto.RuleSuggestionOutput{}
+	for key, m := range merchants {
+		categoryID, txs := 0, []models.FinanceTransaction(nil)
+		for id, list := range m.byCategory {
+			if len(list) > len(txs) || (len(list) == len(txs) && id < categoryID) {
+				categoryID, txs = id, list
+			}
+		}
+		// Two consistent categorizations make a habit; mixed ones don't.
+		if len(txs) < 2 || float64(len(txs)) < 0.8*float64(m.total) {
+			continue
+		}
+		if matchingRule(rules, txs[0]) != nil {
+			continue
+		}
+		suggestion := dto.RuleSuggestionOutput{
+			Rule: dto.CreateCategorizationRuleInput{
+				Name: merchantName(key), MatchType: ruleMatchSubstring, Pattern: key, CategoryID: categoryID, Tags: []string{},
+			},
+			CategoryName: m.categoryNames[categoryID],
+			MatchCount:   len(txs),
+			Examples:     []string{},
+		}
+		for _, d := range uncategorized {
+			if strings.Contains(d, key) {
+				suggestion.Uncategorized++
+			}
+		}
+		for _, t := range txs {
+			if len(suggestion.Examples) == 3 {
+				break
+			}
+			suggestion.Examples = append(suggestion.Examples, t.Description)
+		}
+		out = append(out, suggestion)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].MatchCount != out[j].MatchCount {
+			return out[i].MatchCount > out[j].MatchCount
+		}
+		return out[i].Rule.Pattern < out[j].Rule.Pattern
+	})
+	return out
+}
+
+// Rule match types.
+const (
+	ruleMatchSubstring = "substring"
+	ruleMatchRegex     = "regex"
+)
+
+// validate checks a rule and normalizes it for storage.
+func (s *CategorizationRuleService) validate(userID int, input dto.CreateCategorizationRuleInput) (models.CategorizationRule, error) {
+	rule := models.CategorizationRule{
+		Name:       strings.TrimSpace(input.Name),
+		Priority:   input.Priority,
+		MatchType:  nonEmptyOr(strings.ToLower(strings.TrimSpace(input.MatchType)), ruleMatchSubstring),
+		Pattern:    strings.TrimSpace(input.Pattern),
+		MinAmount:  input.MinAmount,
+		MaxAmount:  input.MaxAmount,
+		CategoryID: input.CategoryID,
+		Tags:       []string{},
+		Disabled:   input.Disabled,
+	}
+	if rule.Name == "" {
+		return rule, fmt.Errorf("rule name is required")
+	}
+	switch rule.MatchType {
+	case ruleMatchSubstring:
+		// Matching ignores punctuation, so a pattern of only punctuation
+		// would match every transaction.
+		if rule.Pattern != "" && normalizeDescription(rule.Pattern) == "" {
+			return rule, fmt.Errorf("the pattern needs at least one letter or digit")
+		}
+	case ruleMatchRegex:
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+			return rule, fmt.Errorf("invalid pattern: %w", err)
+		}
+	default:
+		return rule, fmt.Errorf("unknown match type %q: use substring or regex", input.MatchType)
+	}
+	if rule.Pattern == "" && rule.MinAmount == nil && rule.MaxAmount == nil && input.AccountID <= 0 {
+		return rule, fmt.Errorf("a rule needs a pattern, an amount range or an account")
+	}
+	if (rule.MinAmount != nil && *rule.MinAmount < 0) || (rule.MaxAmount != nil && *rule.MaxAmount < 0) {
+		return rule, fmt.Errorf("amount bounds apply to the amount without its sign and cannot be negative")
+	}
+	if rule.MinAmount != nil && rule.MaxAmount != nil && *rule.MinAmount > *rule.MaxAmount {
+		return rule, fmt.Errorf("minimum amount is above the maximum")
+	}
+	for _, raw := range input.Tags {
+		name, err := normalizeTagName(raw)
+		if err != nil {
+			return rule, err
+		}
+		rule.Tags = append(rule.Tags, name)
+	}
+
+	var count int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM finance_categories WHERE id = ? AND user_id = ?", input.CategoryID, userID).Scan(&count); err != nil {
+		return rule, fmt.Errorf("failed to check category: %w", err)
+	}
+	if count == 0 {
+		return rule, fmt.Errorf("category not found")
+	}
+	if input.AccountID > 0 {
+		if err := s.db.QueryRow("SELECT COUNT(*) FROM finance_accounts WHERE id = ? AND user_id = ?", input.AccountID, userID).Scan(&count); err != nil {
+			return rule, fmt.Errorf("failed to check account: %w", err)
+		}
+		if count == 0 {
+			return rule, fmt.Errorf("account not found")
+		}
+	}
+	return rule, nil
+}
+
+func scanCategorizationRule(row rowScanner) (models.CategorizationRule, error) {
+	var r models.CategorizationRule
+	var minAmount, maxAmount sql.NullFloat64
+	var accountID sql.NullInt64
+	var tags string
+	err := row.Scan(&r.ID, &r.Name, &r.Priority, &r.MatchType, &r.Pattern, &minAmount, &maxAmount,
+		&accountID, &r.CategoryID, &r.CategoryName, &tags, &r.Disabled, &r.MatchCount)
+	if err != nil {
+		return r, err
+	}
+	if minAmount.Valid {
+		r.MinAmount = &minAmount.Float64
+	}
+	if maxAmount.Valid {
+		r.MaxAmount = &maxAmount.Float64
+	}
+	r.AccountID = nullIntPtr(accountID)
+	r.Tags = splitTags(tags)
+	return r, nil
+}
+
+// queryCategorizationRules returns the user's rules by priority, optionally
+// only the enabled ones.
+func queryCategorizationRules(db *sql.DB, userID int, enabledOnly bool) ([]models.CategorizationRule, error) {
+	query := "SELECT " + categorizationRuleColumns + categorizationRuleFrom + " WHERE r.user_id = ?"
+	if enabledOnly {
+		query += " AND COALESCE(r.disabled, 0) = 0"
+	}
+	rows, err := db.Query(query+" ORDER BY r.priority ASC, r.id ASC", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer closeWithLog(rows, "closing categorization rule rows")
+
+	var rules []models.CategorizationRule
+	for rows.Next() {
+		r, err := scanCategorizationRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+	return rules, rows.Err()
+}
+
+// compiledRule is an enabled rule ready to match transactions.
+type compiledRule struct {
+	models.CategorizationRule
+	pattern *regexp.Regexp // regex rules
+	words   string         // substring rules, normalized
+}
+
+// loadCategorizationRules returns the user's enabled rules in priority order.
+func loadCategorizationRules(db *sql.DB, userID int) ([]compiledRule, error) {
+	rules, err := queryCategorizationRules(db, userID, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load categorization rules: %w", err)
+	}
+	compiled := make([]compiledRule, 0, len(rules))
+	for _, r := range rules {
+		c := compiledRule{CategorizationRule: r}
+		if r.MatchType == ruleMatchRegex {
+			re, err := regexp.Compile("(?i)" + r.Pattern)
+			if err != nil {
+				log.Printf("Skipping categorization rule %d: %v", r.ID, err)
+				continue
+			}
+			c.pattern = re
+		} else {
+			c.words = normalizeDescription(r.Pattern)
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+// matches reports whether a transaction meets every condition of the rule.
+// Regexes are case-insensitive; substrings also ignore punctuation and spacing.
+func (r compiledRule) matches(t models.FinanceTransaction) bool {
+	if r.AccountID != nil && *r.AccountID != t.AccountID {
+		return false
+	}
+	amount := math.Abs(t.Amount)
+	if (r.MinAmount != nil && amount < *r.MinAmount) || (r.MaxAmount != nil && amount > *r.MaxAmount) {
+		return false
+	}
+	if r.pattern != nil {
+		return r.pattern.MatchString(t.Description)
+	}
+	return r.words == "" || strings.Contains(normalizeDescription(t.Description), r.words)
+}
+
+// matchingRule returns the first rule matching the transaction, or nil.
+func matchingRule(rules []compiledRule, t models.FinanceTransaction) *compiledRule {
+	for i := range rules {
+		if rules[i].matches(t) {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+// categorizeTransaction applies the first matching rule to a transaction,
+// adding the rule's tags to any it already has.
+func categorizeTransaction(exec sqlExecutor, userID int, t models.FinanceTransaction, rules []compiledRule) (bool, error) {
+	rule := matchingRule(rules, t)
+	if rule == nil {
+		return false, nil
+	}
+	if _, err := exec.Exec("UPDATE finance_transactions SET category_id = ?, category_rule_id = ?, updated_at = datetime('now') WHERE id = ? AND user_id = ?",
+		rule.CategoryID, rule.ID, t.ID, userID); err != nil {
+		return false, fmt.Errorf("failed to categorize transaction: %w", err)
+	}
+	if len(rule.Tags) == 0 {
+		return true, nil
+	}
+	var existing sql.NullString
+	if err := exec.QueryRow("SELECT "+entityTagsSQL("transaction", "?"), t.ID).Scan(&existing); err != nil {
+		return false, fmt.Errorf("failed to load transaction tags: %w", err)
+	}
+	if _, err := setEntityTags(exec, userID, "transaction", t.ID, append(splitTags(existing.String), rule.Tags...)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// merchantKey reduces a description to its first two words without digits,
+// which usually names the merchant: "TIM HORTONS #1234 TORONTO" gives
+// "tim hortons".
+func merchantKey(description string) string {
+	var words []string
+	for _, w := range strings.Fields(normalizeDescription(description)) {
+		if strings.IndexFunc(w, unicode.IsDigit) >= 0 || len(w) < 2 {
+			continue
+		}
+		words = append(words, w)
+		if len(words) == 2 {
+			break
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// merchantName capitalizes a merchant key for use as a rule name.
+func merchantName(key string) string {
+	words := strings.Fields(key)
+	for i, w := range words {
+		r := []rune(w)
+		words[i] = string(unicode.ToUpper(r[0])) + string(r[1:])
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,191 @@
+package services
+
+import (
+	"tally/internal/dto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCategorizationRuleService_CRUD(t *testing.T) {
+	db := setupFinanceTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	auth := NewAuthService(db)
+	user := createTestUser(t, auth, "rules_crud_user")
+	other := createTestUser(t, auth, "rules_crud_other")
+	finance := NewFinanceService(db, nil)
+	rules := NewCategorizationRuleService(db, nil)
+	food := finance.CreateCategory(user.ID, dto.CreateCategoryInput{Name: "Food", Type: "expense"})
+	theirs := finance.CreateCategory(other.ID, dto.CreateCategoryInput{Name: "Theirs", Type: "expense"})
+
+	_, err := rules.Create(user.ID, dto.CreateCategorizationRuleInput{Name: "Nothing", CategoryID: food.ID})
+	assert.ErrorContains(t, err, "needs a pattern")
+	_, err = rules.Create(user.ID, dto.CreateCategorizationRuleInput{Name: "Bad", MatchType: "regex", Pattern: "(", CategoryID: food.ID})
+	assert.ErrorContains(t, err, "invalid pattern")
+	_, err = rules.Create(user.ID, dto.CreateCategorizationRuleInput{Name: "Hash", Pattern: " # - ", CategoryID: food.ID})
+	assert.ErrorContains(t, err, "at least one letter or digit")
+	_, err = rules.Create(user.ID, dto.CreateCategorizationRuleInput{Name: "Foreign", Pattern: "x", CategoryID: theirs.ID})
+	assert.ErrorContains(t, err, "category not found")
+	minAmount, maxAmount := 50.0, 10.0
+	_, err = rules.Create(user.ID, dto.CreateCategorizationRuleInput{Name: "Range", MinAmount: &minAmount, MaxAmount: &maxAmount, CategoryID: food.ID})
+	assert.Error(t, err)
+
+	rule, err := rules.Create(user.ID, dto.CreateCategorizationRuleInput{Name: " Coffee ", Pattern: "coffee", CategoryID: food.ID, Tags: []string{"cafe"}})
+	require.NoError(t, err)
+	assert.Equal(t, "Coffee", rule.Name)
+	assert.Equal(t, "substring", rule.MatchType)
+	assert.Equal(t, "Food", rule.CategoryName)
+	assert.Equal(t, []string{"cafe"}, rule.Tags)
+
+	updated, err := rules.Update(user.ID, dto.UpdateCategorizationRuleInput{ID: rule.ID, Name: "Coffee", MatchType: "regex", Pattern: `^coffee\b`, CategoryID: food.ID, Disabled: true})
+	require.NoError(t, err)
+	assert.True(t, updated.Disabled)
+	assert.Empty(t, updated.Tags)
+	_, err = rules.Update(other.ID, dto.UpdateCategorizationRuleInput{ID: rule.ID, Name: "Coffee", Pattern: "x", CategoryID: theirs.ID})
+	assert.Error(t, err)
+
+	assert.Len(t, rules.List(user.ID), 1)
+	assert.Empty(t, rules.List(other.ID))
+	assert.Error(t, rules.Delete(other.ID, rule.ID))
+	require.NoError(t, rules.Delete(user.ID, rule.ID))
+	assert.Empty(t, rules.List(user.ID))
+
+	// Deleting the category removes its rules.
+	_, err = rules.Create(user.ID, dto.CreateCategorizationRuleInput{Name: "Coffee", Pattern: "coffee", CategoryID: food.ID})
+	require.NoError(t, err)
+	finance.DeleteCategory(user.ID, food.ID)
+	assert.Empty(t, rules.List(user.ID))
+}
+
+func TestCategorizationRules_ApplyOnImportAndRerun(t *testing.T) {
+	db := setupFinanceTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	user := createTestUser(t, NewAuthService(db), "rules_apply_user")
+	finance := NewFinanceService(db, nil)
+	rules := NewCategorizationRuleService(db, nil)
+	chequing := finance.CreateAccount(user.ID, dto.CreateAccountInput{Name: "Chequing", Type: "checking"})
+	card := finance.CreateAccount(user.ID, dto.CreateAccountInput{Name: "Card", Type: "credit"})
+	food := finance.CreateCategory(user.ID, dto.CreateCategoryInput{Name: "Food", Type: "expense"})
+	software := finance.CreateCategory(user.ID, dto.CreateCategoryInput{Name: "Software", Type: "expense"})
+	big := finance.CreateCategory(user.ID, dto.CreateCategoryInput{Name: "Big purchases", Type: "expense"})
+
+	// Rules run in priority order; the first match wins.
+	minAmount := 500.0
+	_, err := rules.Create(user.ID, dto.CreateCategorizationRuleInput{Name: "Large card spend", Priority: 1, MinAmount: &minAmount, AccountID: card.ID, CategoryID: big.ID})
+	require.NoError(t, err)
+	_, err = rules.Create(user.ID, dto.CreateCategorizationRuleInput{Name: "Software", Priority: 2, MatchType: "regex", Pattern: `github|jetbrains`, CategoryID: software.ID, Tags: []string{"business"}})
+	require.NoError(t, err)
+	coffee, err := rules.Create(user.ID, dto.CreateCategorizationRuleInput{Name: "Coffee", Priority: 3, Pattern: "tim-hortons", CategoryID: food.ID})
+	require.NoError(t, err)
+
+	_, err = finance.ImportTransactions(user.ID, dto.ImportTransactionsInput{AccountID: card.ID, FileContent: "2024-04-01,GITHUB.COM subscription,-4.00\n" +
+		"2024-04-02,JetBrains annual licence,-649.00\n" +
+		"2024-04-03,TIM HORTONS #0042,-2.25\n" +
+		"2024-04-04,Hardware store,-35.00\n"})
+	require.NoError(t, err)
+
+	byDescription := map[string]dto.TransactionOutput{}
+	for _, tx := range finance.GetTransactions(user.ID, dto.TransactionFilter{AccountID: card.ID}) {
+		byDescription[tx.Description] = tx
+	}
+	assert.Equal(t, "Software", byDescription["GITHUB.COM subscription"].CategoryName)
+	assert.Equal(t, []string{"business"}, byDescription["GITHUB.COM subscription"].Tags)
+	assert.Equal(t, "Big purchases", byDescription["JetBrains annual licence"].CategoryName)
+	assert.Equal(t, "Food", byDescription["TIM HORTONS #0042"].CategoryName, "substrings ignore case and punctuation")
+	assert.Nil(t, byDescription["Hardware store"].CategoryID)
+
+	// A new rule can be run over what is still uncategorized, keeping tags.
+	_, err = finance.ImportTransactions(user.ID, dto.ImportTransactionsInput{AccountID: chequing.ID, FileContent: "2024-04-05,Hardware store,-12.00\n"})
+	require.NoError(t, err)
+	hardware := finance.GetTransactions(user.ID, dto.TransactionFilter{AccountID: card.ID})
+	for _, tx := range hardware {
+		if tx.Description == "Hardware store" {
+			_, err = NewTagService(db).SetEntityTags(user.ID, dto.SetEntityTagsInput{EntityType: "transaction", EntityID: tx.ID, Tags: []string{"home"}})
+			require.NoError(t, err)
+		}
+	}
+	_, err = rules.Create(user.ID, dto.CreateCategorizationRuleInput{Name: "Hardware", Pattern: "hardware", AccountID: card.ID, CategoryID: big.ID, Tags: []string{"renovation"}})
+	require.NoError(t, err)
+	result, err := rules.ApplyToUncategorized(user.ID, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Checked)
+	assert.Equal(t, 1, result.Categorized, "the rule is limited to the card")
+
+	for _, tx := range finance.GetTransactions(user.ID, dto.TransactionFilter{AccountID: card.ID}) {
+		if tx.Description == "Hardware store" {
+			assert.Equal(t, "Big purchases", tx.CategoryName)
+			assert.Equal(t, []string{"home", "renovation"}, tx.Tags)
+		}
+	}
+	rule, err := rules.Get(user.ID, coffee.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, rule.MatchCount)
+}
+
+func TestCategorizationRuleService_Suggest(t *testing.T) {
+	db := setupFinanceTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	user := createTestUser(t, NewAuthService(db), "rules_suggest_user")
+	finance := NewFinanceService(db, nil)
+	rules := NewCategorizationRuleService(db, nil)
+	acc := finance.CreateAccount(user.ID, dto.CreateAccountInput{Name: "Chequing", Type: "checking"})
+	food := finance.CreateCategory(user.ID, dto.CreateCategoryInput{Name: "Food", Type: "expense"})
+	fuel := finance.CreateCategory(user.ID, dto.CreateCategoryInput{Name: "Fuel", Type: "expense"})
+	other := finance.CreateCategory(user.ID, dto.CreateCategoryInput{Name: "Other", Type: "expense"})
+
+	_, err := finance.ImportTransactions(user.ID, dto.ImportTransactionsInput{AccountID: acc.ID, FileContent: "2024-05-01,TIM HORTONS #1234 TORONTO,-2.25\n" +
+		"2024-05-02,Tim Hortons #88 Ottawa,-3.10\n" +
+		"2024-05-03,TIM HORTONS 55,-1.95\n" +
+		"2024-05-04,Shell 0042,-60.00\n" +
+		"2024-05-05,Shell 0077,-55.00\n" +
+		"2024-05-06,Amazon order,-20.00\n" +
+		"2024-05-07,Amazon order,-30.00\n"})
+	require.NoError(t, err)
+
+	var timID int
+	for _, tx := range finance.GetTransactions(user.ID, dto.TransactionFilter{AccountID: acc.ID}) {
+		switch tx.Description {
+		case "TIM HORTONS #1234 TORONTO", "Tim Hortons #88 Ottawa":
+			finance.UpdateTransaction(user.ID, tx.ID, &food.ID)
+		case "Shell 0042", "Shell 0077":
+			finance.UpdateTransaction(user.ID, tx.ID, &fuel.ID)
+		case "Amazon order":
+			// Mixed categorizations make no habit.
+			if tx.Amount < -25 {
+				finance.UpdateTransaction(user.ID, tx.ID, &other.ID)
+			} else {
+				finance.UpdateTransaction(user.ID, tx.ID, &food.ID)
+			}
+		case "TIM HORTONS 55":
+			timID = tx.ID
+		}
+	}
+	require.NotZero(t, timID)
+
+	suggestions := rules.Suggest(user.ID)
+	require.Len(t, suggestions, 2)
+	patterns := map[string]dto.RuleSuggestionOutput{}
+	for _, s := range suggestions {
+		patterns[s.Rule.Pattern] = s
+	}
+	require.Contains(t, patterns, "tim hortons")
+	assert.Equal(t, "Tim Hortons", patterns["tim hortons"].Rule.Name)
+	assert.Equal(t, "Food", patterns["tim hortons"].CategoryName)
+	assert.Equal(t, 2, patterns["tim hortons"].MatchCount)
+	assert.Equal(t, 1, patterns["tim hortons"].Uncategorized)
+	assert.Contains(t, patterns, "shell", "digits are not part of the merchant")
+
+	// Accepting a suggestion categorizes the rest and stops suggesting it.
+	_, err = rules.Create(user.ID, patterns["tim hortons"].Rule)
+	require.NoError(t, err)
+	result, err := rules.ApplyToUncategorized(user.ID, acc.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Categorized)
+	suggestions = rules.Suggest(user.ID)
+	require.Len(t, suggestions, 1)
+	assert.Equal(t, "shell", suggestions[0].Rule.Pattern)
+}
@@ -0,0 +1,253 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"tally/internal/dto"
+	"tally/internal/models"
+	"unicode"
+)
+
+// CategorySuggestionService proposes categories for uncategorized transactions
+// with a naive Bayes classifier trained on the user's categorized ones. It
+// runs locally and is retrained on every call, so it learns from each
+// categorization straight away.
+type CategorySuggestionService struct {
+	db      *sql.DB
+	budgets BudgetAlerter
+}
+
+// NewCategorySuggestionService creates a new CategorySuggestionService instance.
+// budgets is told when suggestions are accepted; nil skips budget alerts.
+func NewCategorySuggestionService(db *sql.DB, budgets BudgetAlerter) *CategorySuggestionService {
+	return &CategorySuggestionService{db: db, budgets: budgets}
+}
+
+// maxCategoryCandidates is how many ranked categories a suggestion carries.
+const maxCategoryCandidates = 3
+
+// Suggest ranks likely categories for each uncategorized transaction,
+// optionally in one account, newest first.
+func (s *CategorySuggestionService) Suggest(userID int, accountID int) []dto.CategorySuggestionOutput {
+	classifier, names, err := s.train(userID)
+	if err != nil {
+		log.Println("Error training category classifier:", err)
+		return []dto.CategorySuggestionOutput{}
+	}
+
+	query := "SELECT t.id, t.account_id, t.date, t.description, t.amount FROM finance_transactions t WHERE t.user_id = ? AND " + uncategorizedSQL
+	args := []interface{}{userID}
+	if accountID > 0 {
+		query += " AND t.account_id = ?"
+		args = append(args, accountID)
+	}
+	rows, err := s.db.Query(query+" ORDER BY t.date DESC, t.id DESC", args...)
+	if err != nil {
+		log.Println("Error querying uncategorized transactions:", err)
+		return []dto.CategorySuggestionOutput{}
+	}
+	defer closeWithLog(rows, "closing uncategorized rows")
+
+	out := []dto.CategorySuggestionOutput{}
+	for rows.Next() {
+		var t models.FinanceTransaction
+		var date string
+		if err := rows.Scan(&t.ID, &t.AccountID, &date, &t.Description, &t.Amount); err != nil {
+			log.Println("Error scanning uncategorized transaction:", err)
+			continue
+		}
+		suggestion := dto.CategorySuggestionOutput{
+			TransactionID: t.ID,
+			AccountID:     t.AccountID,
+			Description:   t.Description,
+			Amount:        t.Amount,
+			Candidates:    []dto.CategoryCandidate{},
+		}
+		if d, err := parseDate(date[:min(len(date), 10)]); err == nil {
+			suggestion.Date = d
+		}
+		for _, c := range classifier.rank(t) {
+			if len(suggestion.Candidates) == maxCategoryCandidates {
+				break
+			}
+			suggestion.Candidates = append(suggestion.Candidates, dto.CategoryCandidate{
+				CategoryID: c.categoryID, CategoryName: names[c.categoryID], Confidence: math.Round(c.probability*1000) / 1000,
+			})
+		}
+		out = append(out, suggestion)
+	}
+	return out
+}
+
+// Accept sets the chosen categories in one go, as if each had been picked by
+// hand, and returns how many transactions were categorized.
+func (s *CategorySuggestionService) Accept(userID int, input []dto.SetTransactionCategoryInput) (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	count := 0
+	for _, in := range input {
+		var categories int
+		if err := tx.QueryRow("SELECT COUNT(*) FROM finance_categories WHERE id = ? AND user_id = ?", in.CategoryID, userID).Scan(&categories); err != nil {
+			return 0, fmt.Errorf("failed to check category: %w", err)
+		}
+		if categories == 0 {
+			return 0, fmt.Errorf("category %d not found", in.CategoryID)
+		}
+		res, err := tx.Exec("UPDATE finance_transactions SET category_id = ?, category_rule_id = NULL, updated_at = datetime('now') WHERE id = ? AND user_id = ?",
+			in.CategoryID, in.TransactionID, userID)
+		if err != nil {
+			return 0, fmt.Errorf("failed to categorize transaction: %w", err)
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return 0, fmt.Errorf("transaction %d not found", in.TransactionID)
+		}
+		if _, err := tx.Exec("DELETE FROM transaction_splits WHERE transaction_id = ? AND user_id = ?", in.TransactionID, userID); err != nil {
+			return 0, fmt.Errorf("failed to clear splits: %w", err)
+		}
+		count++
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	checkBudgets(s.budgets, userID)
+	return count, nil
+}
+
+// train builds a classifier from the user's categorized transactions and
+// returns it with the category names.
+func (s *CategorySuggestionService) train(userID int) (*categoryClassifier, map[int]string, error) {
+	rows, err := s.db.Query(`SELECT t.account_id, t.description, t.amount, t.category_id, c.name
+FROM finance_transactions t JOIN finance_categories c ON c.id = t.category_id AND c.user_id = t.user_id
+WHERE t.user_id = ?`, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeWithLog(rows, "closing training rows")
+
+	classifier := newCategoryClassifier()
+	names := map[int]string{}
+	for rows.Next() {
+		var t models.FinanceTransaction
+		var categoryID int
+		var name string
+		if err := rows.Scan(&t.AccountID, &t.Description, &t.Amount, &categoryID, &name); err != nil {
+			return nil, nil, err
+		}
+		classifier.learn(t, categoryID)
+		names[categoryID] = name
+	}
+	return classifier, names, rows.Err()
+}
+
+// categoryClassifier is a naive Bayes classifier over the words of a
+// transaction's description plus tokens for its account and the sign and
+// order of magnitude of its amount. A feature counts once per transaction.
+type categoryClassifier struct {
+	examples   int
+	categories map[int]int            // transactions per category
+	features   map[int]map[string]int // transactions per category carrying a feature
+}
+
+type categoryScore struct {
+	categoryID  int
+	probability float64
+}
+
+func newCategoryClassifier() *categoryClassifier {
+	return &categoryClassifier{
+		categories: map[int]int{},
+		features:   map[int]map[string]int{},
+	}
+}
+
+// learn adds one categorized transaction.
+func (c *categoryClassifier) learn(t models.FinanceTransaction, categoryID int) {
+	c.examples++
+	c.categories[categoryID]++
+	if c.features[categoryID] == nil {
+		c.features[categoryID] = map[string]int{}
+	}
+	for _, f := range transactionFeatures(t) {
+		c.features[categoryID][f]++
+	}
+}
+
+// rank returns every known category with its posterior probability, best first.
+func (c *categoryClassifier) rank(t models.FinanceTransaction) []categoryScore {
+	if c.examples == 0 {
+		return nil
+	}
+	features := transactionFeatures(t)
+	scores := make([]categoryScore, 0, len(c.categories))
+	for categoryID, n := range c.categories {
+		// Laplace smoothing keeps unseen features from ruling a category out.
+		// A feature is present or absent in each of the category's n
+		// transactions, so the estimate is (count+1)/(n+2) whatever the
+		// number of distinct features.
+		score := math.Log(float64(n) / float64(c.examples))
+		for _, f := range features {
+			score += math.Log(float64(c.features[categoryID][f]+1) / float64(n+2))
+		}
+		scores = append(scores, categoryScore{categoryID: categoryID, probability: score})
+	}
+
+	// Turn log scores into probabilities that sum to one.
+	best := math.Inf(-1)
+	for _, s := range scores {
+		best = math.Max(best, s.probability)
+	}
+	sum := 0.0
+	for i := range scores {
+		scores[i].probability = math.Exp(scores[i].probability - best)
+		sum += scores[i].probability
+	}
+	for i := range scores {
+		scores[i].probability /= sum
+	}
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].probability != scores[j].probability {
+			return scores[i].probability > scores[j].probability
+		}
+		return scores[i].categoryID < scores[j].categoryID
+	})
+	return scores
+}
+
+// transactionFeatures lists the distinct features of a transaction: the
+// description's words (numbers such as store and card numbers left out), the
+// account, and the amount's sign and order of magnitude.
+func transactionFeatures(t models.FinanceTransaction) []string {
+	seen := map[string]bool{}
+	var features []string
+	add := func(f string) {
+		if !seen[f] {
+			seen[f] = true
+			features = append(features, f)
+		}
+	}
+	for _, w := range strings.Fields(normalizeDescription(t.Description)) {
+		if len(w) < 2 || strings.IndexFunc(w, unicode.IsLetter) < 0 {
+			continue
+		}
+		add("w:" + w)
+	}
+	add(fmt.Sprintf("account:%d", t.AccountID))
+	sign := "+"
+	if t.Amount < 0 {
+		sign = "-"
+	}
+	magnitude := 0
+	if a := math.Abs(t.Amount); a >= 1 {
+		magnitude = int(math.Log10(a)) + 1
+	}
+	add(fmt.Sprintf("amount:%s%d", sign, magnitude))
+	return features
+}
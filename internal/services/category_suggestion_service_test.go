@@ -0,0 +1,73 @@
+package services
+
+import (
+	"tally/internal/dto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCategorySuggestionService(t *testing.T) {
+	db := setupFinanceTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	auth := NewAuthService(db)
+	user := createTestUser(t, auth, "suggest_user")
+	other := createTestUser(t, auth, "suggest_other")
+	finance := NewFinanceService(db, nil)
+	suggestions := NewCategorySuggestionService(db, nil)
+	acc := finance.CreateAccount(user.ID, dto.CreateAccountInput{Name: "Chequing", Type: "checking"})
+	food := finance.CreateCategory(user.ID, dto.CreateCategoryInput{Name: "Food", Type: "expense"})
+	fuel := finance.CreateCategory(user.ID, dto.CreateCategoryInput{Name: "Fuel", Type: "expense"})
+	income := finance.CreateCategory(user.ID, dto.CreateCategoryInput{Name: "Income", Type: "income"})
+
+	_, err := finance.ImportTransactions(user.ID, dto.ImportTransactionsInput{AccountID: acc.ID, FileContent: "2024-06-01,TIM HORTONS #1234,-2.25\n" +
+		"2024-06-02,Starbucks coffee 0091,-5.40\n" +
+		"2024-06-03,Grocery Mart,-84.10\n" +
+		"2024-06-04,SHELL 0042 GAS,-60.00\n" +
+		"2024-06-05,Esso gas station,-55.00\n" +
+		"2024-06-06,ACME PAYROLL DEPOSIT,2500.00\n"})
+	require.NoError(t, err)
+
+	// Nothing is categorized yet, so nothing can be suggested.
+	pending := suggestions.Suggest(user.ID, 0)
+	require.Len(t, pending, 6)
+	assert.Empty(t, pending[0].Candidates)
+
+	categories := map[string]int{
+		"TIM HORTONS #1234": food.ID, "Starbucks coffee 0091": food.ID, "Grocery Mart": food.ID,
+		"SHELL 0042 GAS": fuel.ID, "Esso gas station": fuel.ID, "ACME PAYROLL DEPOSIT": income.ID,
+	}
+	var accepted []dto.SetTransactionCategoryInput
+	for _, tx := range finance.GetTransactions(user.ID, dto.TransactionFilter{}) {
+		accepted = append(accepted, dto.SetTransactionCategoryInput{TransactionID: tx.ID, CategoryID: categories[tx.Description]})
+	}
+	_, err = suggestions.Accept(other.ID, accepted)
+	assert.Error(t, err, "categories belong to another user")
+	count, err := suggestions.Accept(user.ID, accepted)
+	require.NoError(t, err)
+	assert.Equal(t, 6, count)
+
+	_, err = finance.ImportTransactions(user.ID, dto.ImportTransactionsInput{AccountID: acc.ID, FileContent: "2024-07-01,Tim Hortons #0077,-3.15\n" +
+		"2024-07-02,PETRO-CANADA GAS 12,-48.00\n" +
+		"2024-07-03,Acme payroll deposit,2500.00\n"})
+	require.NoError(t, err)
+
+	best := map[string]dto.CategoryCandidate{}
+	for _, s := range suggestions.Suggest(user.ID, acc.ID) {
+		require.NotEmpty(t, s.Candidates)
+		assert.LessOrEqual(t, len(s.Candidates), 3)
+		for i := 1; i < len(s.Candidates); i++ {
+			assert.GreaterOrEqual(t, s.Candidates[i-1].Confidence, s.Candidates[i].Confidence)
+		}
+		best[s.Description] = s.Candidates[0]
+	}
+	require.Len(t, best, 3)
+	assert.Equal(t, "Food", best["Tim Hortons #0077"].CategoryName)
+	assert.Equal(t, "Fuel", best["PETRO-CANADA GAS 12"].CategoryName)
+	assert.Equal(t, "Income", best["Acme payroll deposit"].CategoryName)
+	assert.Greater(t, best["Acme payroll deposit"].Confidence, 0.9)
+
+	assert.Empty(t, suggestions.Suggest(other.ID, 0))
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"tally/internal/dto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientService_ArchiveAndRestore(t *testing.T) {
+	db := setupFullTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	auth := NewAuthService(db)
+	user := createTestUser(t, auth, "archive_user")
+	clientSvc := NewClientService(db)
+	projectSvc := NewProjectService(db)
+
+	client := clientSvc.Create(user.ID, dto.CreateClientInput{Name: "Acme"})
+	kept := projectSvc.Create(user.ID, dto.CreateProjectInput{ClientID: client.ID, Name: "Kept"})
+	done := projectSvc.Create(user.ID, dto.CreateProjectInput{ClientID: client.ID, Name: "Done"})
+
+	// A project archived on its own stays archived when the client comes back.
+	assert.NoError(t, projectSvc.Archive(user.ID, done.ID))
+	assert.Len(t, projectSvc.List(user.ID), 1)
+
+	assert.NoError(t, clientSvc.Archive(user.ID, client.ID))
+	assert.Error(t, clientSvc.Archive(user.ID, client.ID))
+	assert.Len(t, clientSvc.List(user.ID), 0)
+	assert.Len(t, projectSvc.List(user.ID), 0)
+	archived := clientSvc.ListArchived(user.ID)
+	assert.Len(t, archived, 1)
+	assert.NotEmpty(t, archived[0].ArchivedAt)
+	assert.Len(t, projectSvc.ListArchived(user.ID), 2)
+
+	// Archived records are still readable directly.
+	got, err := clientSvc.Get(user.ID, client.ID)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, got.ArchivedAt)
+
+	assert.NoError(t, clientSvc.Restore(user.ID, client.ID))
+	assert.Error(t, clientSvc.Restore(user.ID, client.ID))
+	assert.Len(t, clientSvc.List(user.ID), 1)
+	active := projectSvc.List(user.ID)
+	if assert.Len(t, active, 1) {
+		assert.Equal(t, kept.ID, active[0].ID)
+	}
+
+	assert.NoError(t, projectSvc.Restore(user.ID, done.ID))
+	assert.Len(t, projectSvc.List(user.ID), 2)
+	assert.Empty(t, projectSvc.ListArchived(user.ID))
+}
+
+func TestClientService_DeleteRefusesDependents(t *testing.T) {
+	db := setupFullTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	auth := NewAuthService(db)
+	user := createTestUser(t, auth, "delete_user")
+	clientSvc := NewClientService(db)
+	projectSvc := NewProjectService(db)
+	timeSvc := NewTimesheetService(db)
+	invoiceSvc := NewInvoiceService(db)
+
+	client := clientSvc.Create(user.ID, dto.CreateClientInput{Name: "Acme"})
+	project := projectSvc.Create(user.ID, dto.CreateProjectInput{ClientID: client.ID, Name: "Site", HourlyRate: 100})
+	entry := timeSvc.Create(user.ID, dto.CreateTimeEntryInput{ProjectID: project.ID, Date: "2025-01-05", DurationSeconds: 3600, Billable: true})
+	inv := invoiceSvc.Create(user.ID, dto.CreateInvoiceInput{ClientID: client.ID, Number: "D-1", IssueDate: "2025-01-10", Status: "sent"})
+	_, err := invoiceSvc.SetTimeEntries(user.ID, dto.SetInvoiceTimeEntriesInput{InvoiceID: inv.ID, TimeEntryIDs: []int{entry.ID}})
+	assert.NoError(t, err)
+	_, err = db.Exec("INSERT INTO finance_transactions(user_id, account_id, date, description, amount, client_id, project_id) VALUES(?, 1, '2025-01-03', 'Hosting', -20, ?, ?)", user.ID, client.ID, project.ID)
+	assert.NoError(t, err)
+
+	deps, err := clientSvc.Dependents(user.ID, client.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, dto.Dependents{Projects: 1, TimeEntries: 1, InvoicedTimeEntries: 1, Invoices: 1, Transactions: 1}, deps)
+
+	err = clientSvc.Delete(user.ID, client.ID)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "1 invoice")
+	_, err = invoiceSvc.Get(user.ID, inv.ID)
+	assert.NoError(t, err, "invoice must survive a refused delete")
+
+	// Projects with invoiced time cannot be cascaded away.
+	assert.Error(t, projectSvc.Delete(user.ID, project.ID))
+	assert.Error(t, projectSvc.DeleteCascade(user.ID, dto.CascadeDeleteInput{ID: project.ID, ConfirmName: "Site"}))
+
+	// Cascading needs the exact name.
+	assert.Error(t, clientSvc.DeleteCascade(user.ID, dto.CascadeDeleteInput{ID: client.ID, ConfirmName: "acme"}))
+	assert.NoError(t, clientSvc.DeleteCascade(user.ID, dto.CascadeDeleteInput{ID: client.ID, ConfirmName: "Acme"}))
+
+	for _, table := range []string{"clients", "projects", "time_entries", "invoices"} {
+		var n int
+		assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM "+table+" WHERE user_id = ?", user.ID).Scan(&n))
+		assert.Equal(t, 0, n, table)
+	}
+	var clientID, projectID interface{}
+	assert.NoError(t, db.QueryRow("SELECT client_id, project_id FROM finance_transactions WHERE user_id = ?", user.ID).Scan(&clientID, &projectID))
+	assert.Nil(t, clientID)
+	assert.Nil(t, projectID)
+}
+
+func TestProjectService_DeleteCascade(t *testing.T) {
+	db := setupFullTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	auth := NewAuthService(db)
+	user := createTestUser(t, auth, "project_delete_user")
+	clientSvc := NewClientService(db)
+	projectSvc := NewProjectService(db)
+	timeSvc := NewTimesheetService(db)
+
+	client := clientSvc.Create(user.ID, dto.CreateClientInput{Name: "Acme"})
+	project := projectSvc.Create(user.ID, dto.CreateProjectInput{ClientID: client.ID, Name: "Spike"})
+	timeSvc.Create(user.ID, dto.CreateTimeEntryInput{ProjectID: project.ID, Date: "2025-01-05", DurationSeconds: 600})
+
+	err := projectSvc.Delete(user.ID, project.ID)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "1 time entry")
+
+	assert.NoError(t, projectSvc.DeleteCascade(user.ID, dto.CascadeDeleteInput{ID: project.ID, ConfirmName: "Spike"}))
+	assert.Len(t, timeSvc.List(user.ID, 0), 0)
+	assert.Len(t, clientSvc.List(user.ID), 1)
+}
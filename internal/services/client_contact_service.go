@@ -0,0 +1,167 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"net/mail"
+	"strings"
+	"tally/internal/dto"
+	"tally/internal/mapper"
+	"tally/internal/models"
+)
+
+// ClientContactService manages the people at a client and their roles.
+type ClientContactService struct {
+	db *sql.DB
+}
+
+// NewClientContactService creates a new ClientContactService instance.
+func NewClientContactService(db *sql.DB) *ClientContactService {
+	return &ClientContactService{db: db}
+}
+
+// List returns the contacts of a client, billing contacts first.
+func (s *ClientContactService) List(userID int, clientID int) []dto.ClientContactOutput {
+	rows, err := s.db.Query(`
+SELECT id, client_id, name, email, phone, title, role
+FROM client_contacts
+WHERE user_id = ? AND client_id = ?
+ORDER BY CASE role WHEN 'billing' THEN 0 WHEN 'cc' THEN 1 WHEN 'bcc' THEN 2 ELSE 3 END, name ASC, id ASC`, userID, clientID)
+	if err != nil {
+		log.Println("Error querying client contacts:", err)
+		return []dto.ClientContactOutput{}
+	}
+	defer closeWithLog(rows, "closing client contact rows")
+
+	var contacts []models.ClientContact
+	for rows.Next() {
+		var c models.ClientContact
+		var phone, title sql.NullString
+		if err := rows.Scan(&c.ID, &c.ClientID, &c.Name, &c.Email, &phone, &title, &c.Role); err != nil {
+			log.Println("Error scanning client contact:", err)
+			continue
+		}
+		c.Phone = phone.String
+		c.Title = title.String
+		contacts = append(contacts, c)
+	}
+	return mapper.ToClientContactOutputList(contacts)
+}
+
+// Create adds a contact to a client.
+func (s *ClientContactService) Create(userID int, input dto.CreateClientContactInput) (dto.ClientContactOutput, error) {
+	entity := mapper.ToClientContactEntity(input)
+	entity.Email = strings.TrimSpace(entity.Email)
+	if err := validateContact(entity); err != nil {
+		return dto.ClientContactOutput{}, err
+	}
+
+	var count int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM clients WHERE id = ? AND user_id = ?", entity.ClientID, userID).Scan(&count); err != nil {
+		return dto.ClientContactOutput{}, fmt.Errorf("failed to check client: %w", err)
+	}
+	if count == 0 {
+		return dto.ClientContactOutput{}, fmt.Errorf("client not found")
+	}
+
+	res, err := s.db.Exec(
+		"INSERT INTO client_contacts(user_id, client_id, name, email, phone, title, role) VALUES(?, ?, ?, ?, ?, ?, ?)",
+		userID, entity.ClientID, entity.Name, entity.Email, entity.Phone, entity.Title, entity.Role,
+	)
+	if err != nil {
+		return dto.ClientContactOutput{}, fmt.Errorf("failed to insert client contact: %w", err)
+	}
+	id, _ := res.LastInsertId()
+	entity.ID = int(id)
+	return mapper.ToClientContactOutput(entity), nil
+}
+
+// Update modifies an existing client contact.
+func (s *ClientContactService) Update(userID int, input dto.UpdateClientContactInput) (dto.ClientContactOutput, error) {
+	entity := models.ClientContact{
+		ID:    input.ID,
+		Name:  input.Name,
+		Email: strings.TrimSpace(input.Email),
+		Phone: input.Phone,
+		Title: input.Title,
+		Role:  input.Role,
+	}
+	if err := validateContact(entity); err != nil {
+		return dto.ClientContactOutput{}, err
+	}
+
+	res, err := s.db.Exec(
+		"UPDATE client_contacts SET name=?, email=?, phone=?, title=?, role=?, updated_at=datetime('now') WHERE id=? AND user_id=?",
+		entity.Name, entity.Email, entity.Phone, entity.Title, entity.Role, input.ID, userID,
+	)
+	if err != nil {
+		return dto.ClientContactOutput{}, fmt.Errorf("failed to update client contact: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return dto.ClientContactOutput{}, fmt.Errorf("client contact not found or not owned by user")
+	}
+	if err := s.db.QueryRow("SELECT client_id FROM client_contacts WHERE id = ?", input.ID).Scan(&entity.ClientID); err != nil {
+		return dto.ClientContactOutput{}, err
+	}
+	return mapper.ToClientContactOutput(entity), nil
+}
+
+// Delete removes a client contact.
+func (s *ClientContactService) Delete(userID int, id int) {
+	if _, err := s.db.Exec("DELETE FROM client_contacts WHERE id=? AND user_id=?", id, userID); err != nil {
+		log.Println("Error deleting client contact:", err)
+	}
+}
+
+// GetInvoiceRecipients returns who invoice emails for a client are addressed to.
+// Billing contacts are the recipients; without any, the client's own email is used.
+func (s *ClientContactService) GetInvoiceRecipients(userID int, clientID int) (dto.EmailRecipients, error) {
+	var clientEmail sql.NullString
+	if err := s.db.QueryRow("SELECT email FROM clients WHERE id = ? AND user_id = ?", clientID, userID).Scan(&clientEmail); err != nil {
+		return dto.EmailRecipients{}, fmt.Errorf("client not found: %w", err)
+	}
+
+	out := dto.EmailRecipients{To: []string{}, CC: []string{}, BCC: []string{}}
+	seen := map[string]bool{}
+	add := func(list *[]string, email string) {
+		key := strings.ToLower(strings.TrimSpace(email))
+		if key == "" || seen[key] {
+			return
+		}
+		seen[key] = true
+		*list = append(*list, strings.TrimSpace(email))
+	}
+
+	contacts := s.List(userID, clientID)
+	for _, c := range contacts {
+		if c.Role == "billing" {
+			add(&out.To, c.Email)
+		}
+	}
+	if len(out.To) == 0 {
+		add(&out.To, clientEmail.String)
+	}
+	for _, c := range contacts {
+		switch c.Role {
+		case "cc":
+			add(&out.CC, c.Email)
+		case "bcc":
+			add(&out.BCC, c.Email)
+		}
+	}
+	return out, nil
+}
+
+// validateContact checks the role and email address of a contact.
+func validateContact(c models.ClientContact) error {
+	switch c.Role {
+	case "billing", "technical", "cc", "bcc":
+	default:
+		return fmt.Errorf("unknown contact role: %s", c.Role)
+	}
+	if _, err := mail.ParseAddress(c.Email); err != nil {
+		return fmt.Errorf("invalid email address %q", c.Email)
+	}
+	return nil
+}
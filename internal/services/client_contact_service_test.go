@@ -0,0 +1,55 @@
+package services
+
+import (
+	"tally/internal/dto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientContactService_InvoiceRecipients(t *testing.T) {
+	db := setupFullTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	auth := NewAuthService(db)
+	user := createTestUser(t, auth, "contacts_user")
+
+	clientSvc := NewClientService(db)
+	contactSvc := NewClientContactService(db)
+
+	client := clientSvc.Create(user.ID, dto.CreateClientInput{Name: "Acme", Email: "hello@acme.test"})
+
+	// Without billing contacts the client's own email is used.
+	r, err := contactSvc.GetInvoiceRecipients(user.ID, client.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"hello@acme.test"}, r.To)
+	assert.Empty(t, r.CC)
+
+	for _, in := range []dto.CreateClientContactInput{
+		{ClientID: client.ID, Name: "AP", Email: "ap@acme.test"},
+		{ClientID: client.ID, Name: "Controller", Email: "controller@acme.test", Role: "billing"},
+		{ClientID: client.ID, Name: "PM", Email: "pm@acme.test", Role: "cc"},
+		{ClientID: client.ID, Name: "Dev", Email: "dev@acme.test", Role: "technical"},
+		{ClientID: client.ID, Name: "Archive", Email: "archive@acme.test", Role: "bcc"},
+		{ClientID: client.ID, Name: "Dup", Email: "AP@acme.test", Role: "cc"},
+	} {
+		_, err := contactSvc.Create(user.ID, in)
+		assert.NoError(t, err)
+	}
+
+	_, err = contactSvc.Create(user.ID, dto.CreateClientContactInput{ClientID: client.ID, Email: "not-an-email"})
+	assert.Error(t, err)
+	_, err = contactSvc.Create(user.ID, dto.CreateClientContactInput{ClientID: client.ID, Email: "x@acme.test", Role: "owner"})
+	assert.Error(t, err)
+
+	assert.Len(t, contactSvc.List(user.ID, client.ID), 6)
+
+	r, err = contactSvc.GetInvoiceRecipients(user.ID, client.ID)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"ap@acme.test", "controller@acme.test"}, r.To)
+	assert.Equal(t, []string{"pm@acme.test"}, r.CC)
+	assert.Equal(t, []string{"archive@acme.test"}, r.BCC)
+
+	email := outgoingEmail{To: r.To, CC: r.CC, BCC: r.BCC}
+	assert.Len(t, email.recipients(), 4)
+}
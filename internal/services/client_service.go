@@ -2,9 +2,12 @@ package services
 
 import (
 	"database/sql"
+	"fmt"
+	"strings"
 	"tally/internal/dto"
 	"tally/internal/mapper"
 	"tally/internal/models"
+	"tally/internal/utils"
 	"log"
 )
 
@@ -18,9 +21,22 @@ func NewClientService(db *sql.DB) *ClientService {
 	return &ClientService{db: db}
 }
 
-// List returns all clients for a specific user as DTOs.
+// List returns the active (non-archived) clients for a specific user as DTOs.
 func (s *ClientService) List(userID int) []dto.ClientOutput {
-	rows, err := s.db.Query("SELECT id, name, email, website, avatar, contact_person, address, currency, status, notes, billing_company, billing_address, billing_city, billing_province, billing_postal_code FROM clients WHERE user_id = ?", userID)
+	return s.list(userID, false)
+}
+
+// ListArchived returns the archived clients for a specific user as DTOs.
+func (s *ClientService) ListArchived(userID int) []dto.ClientOutput {
+	return s.list(userID, true)
+}
+
+func (s *ClientService) list(userID int, archived bool) []dto.ClientOutput {
+	query := "SELECT id, name, email, website, avatar, contact_person, address, currency, status, notes, billing_company, billing_address, billing_city, billing_province, billing_postal_code, payment_terms, COALESCE(requires_po, 0), archived_at FROM clients WHERE user_id = ? AND archived_at IS NULL"
+	if archived {
+		query = "SELECT id, name, email, website, avatar, contact_person, address, currency, status, notes, billing_company, billing_address, billing_city, billing_province, billing_postal_code, payment_terms, COALESCE(requires_po, 0), archived_at FROM clients WHERE user_id = ? AND archived_at IS NOT NULL"
+	}
+	rows, err := s.db.Query(query, userID)
 	if err != nil {
 		log.Println("Error querying clients:", err)
 		return []dto.ClientOutput{}
@@ -30,9 +46,9 @@ func (s *ClientService) List(userID int) []dto.ClientOutput {
 	var clients []models.Client
 	for rows.Next() {
 		var c models.Client
-		var billingCompany, billingAddress, billingCity, billingProvince, billingPostalCode sql.NullString
+		var billingCompany, billingAddress, billingCity, billingProvince, billingPostalCode, paymentTerms, archivedAt sql.NullString
 
-		err := rows.Scan(&c.ID, &c.Name, &c.Email, &c.Website, &c.Avatar, &c.ContactPerson, &c.Address, &c.Currency, &c.Status, &c.Notes, &billingCompany, &billingAddress, &billingCity, &billingProvince, &billingPostalCode)
+		err := rows.Scan(&c.ID, &c.Name, &c.Email, &c.Website, &c.Avatar, &c.ContactPerson, &c.Address, &c.Currency, &c.Status, &c.Notes, &billingCompany, &billingAddress, &billingCity, &billingProvince, &billingPostalCode, &paymentTerms, &c.RequiresPO, &archivedAt)
 		if err != nil {
 			log.Println("Error scanning client:", err)
 			continue
@@ -42,6 +58,8 @@ func (s *ClientService) List(userID int) []dto.ClientOutput {
 		c.BillingCity = billingCity.String
 		c.BillingProvince = billingProvince.String
 		c.BillingPostalCode = billingPostalCode.String
+		c.PaymentTerms = paymentTerms.String
+		c.ArchivedAt = archivedAt.String
 		clients = append(clients, c)
 	}
 	return mapper.ToClientOutputList(clients)
@@ -49,11 +67,11 @@ func (s *ClientService) List(userID int) []dto.ClientOutput {
 
 // Get returns a single client by ID for a specific user.
 func (s *ClientService) Get(userID int, id int) (dto.ClientOutput, error) {
-	row := s.db.QueryRow("SELECT id, name, email, website, avatar, contact_person, address, currency, status, notes, billing_company, billing_address, billing_city, billing_province, billing_postal_code FROM clients WHERE id = ? AND user_id = ?", id, userID)
+	row := s.db.QueryRow("SELECT id, name, email, website, avatar, contact_person, address, currency, status, notes, billing_company, billing_address, billing_city, billing_province, billing_postal_code, payment_terms, COALESCE(requires_po, 0), archived_at FROM clients WHERE id = ? AND user_id = ?", id, userID)
 	var c models.Client
-	var billingCompany, billingAddress, billingCity, billingProvince, billingPostalCode sql.NullString
+	var billingCompany, billingAddress, billingCity, billingProvince, billingPostalCode, paymentTerms, archivedAt sql.NullString
 
-	err := row.Scan(&c.ID, &c.Name, &c.Email, &c.Website, &c.Avatar, &c.ContactPerson, &c.Address, &c.Currency, &c.Status, &c.Notes, &billingCompany, &billingAddress, &billingCity, &billingProvince, &billingPostalCode)
+	err := row.Scan(&c.ID, &c.Name, &c.Email, &c.Website, &c.Avatar, &c.ContactPerson, &c.Address, &c.Currency, &c.Status, &c.Notes, &billingCompany, &billingAddress, &billingCity, &billingProvince, &billingPostalCode, &paymentTerms, &c.RequiresPO, &archivedAt)
 	if err != nil {
 		return dto.ClientOutput{}, err
 	}
@@ -62,21 +80,27 @@ func (s *ClientService) Get(userID int, id int) (dto.ClientOutput, error) {
 	c.BillingCity = billingCity.String
 	c.BillingProvince = billingProvince.String
 	c.BillingPostalCode = billingPostalCode.String
+	c.PaymentTerms = paymentTerms.String
+	c.ArchivedAt = archivedAt.String
 	return mapper.ToClientOutput(c), nil
 }
 
 // Create adds a new client for a specific user and returns the created client as DTO.
 func (s *ClientService) Create(userID int, input dto.CreateClientInput) dto.ClientOutput {
 	entity := mapper.ToClientEntity(input)
+	if err := utils.ValidatePaymentTerms(entity.PaymentTerms); err != nil {
+		log.Println("Error creating client:", err)
+		return dto.ClientOutput{}
+	}
 
-	stmt, err := s.db.Prepare("INSERT INTO clients(user_id, name, email, website, avatar, contact_person, address, currency, status, notes, billing_company, billing_address, billing_city, billing_province, billing_postal_code) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+	stmt, err := s.db.Prepare("INSERT INTO clients(user_id, name, email, website, avatar, contact_person, address, currency, status, notes, billing_company, billing_address, billing_city, billing_province, billing_postal_code, payment_terms, requires_po) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
 	if err != nil {
 		log.Println("Error preparing insert:", err)
 		return dto.ClientOutput{}
 	}
 	defer closeWithLog(stmt, "closing client insert statement")
 
-	res, err := stmt.Exec(userID, entity.Name, entity.Email, entity.Website, entity.Avatar, entity.ContactPerson, entity.Address, entity.Currency, entity.Status, entity.Notes, entity.BillingCompany, entity.BillingAddress, entity.BillingCity, entity.BillingProvince, entity.BillingPostalCode)
+	res, err := stmt.Exec(userID, entity.Name, entity.Email, entity.Website, entity.Avatar, entity.ContactPerson, entity.Address, entity.Currency, entity.Status, entity.Notes, entity.BillingCompany, entity.BillingAddress, entity.BillingCity, entity.BillingProvince, entity.BillingPostalCode, entity.PaymentTerms, entity.RequiresPO)
 	if err != nil {
 		log.Println("Error inserting client:", err)
 		return dto.ClientOutput{}
@@ -89,14 +113,18 @@ func (s *ClientService) Create(userID int, input dto.CreateClientInput) dto.Clie
 
 // Update modifies an existing client for a specific user and returns the updated client as DTO.
 func (s *ClientService) Update(userID int, input dto.UpdateClientInput) dto.ClientOutput {
-	stmt, err := s.db.Prepare("UPDATE clients SET name=?, email=?, website=?, avatar=?, contact_person=?, address=?, currency=?, status=?, notes=?, billing_company=?, billing_address=?, billing_city=?, billing_province=?, billing_postal_code=? WHERE id=? AND user_id=?")
+	if err := utils.ValidatePaymentTerms(input.PaymentTerms); err != nil {
+		log.Println("Error updating client:", err)
+		return dto.ClientOutput{}
+	}
+	stmt, err := s.db.Prepare("UPDATE clients SET name=?, email=?, website=?, avatar=?, contact_person=?, address=?, currency=?, status=?, notes=?, billing_company=?, billing_address=?, billing_city=?, billing_province=?, billing_postal_code=?, payment_terms=?, requires_po=? WHERE id=? AND user_id=?")
 	if err != nil {
 		log.Println("Error preparing update:", err)
 		return dto.ClientOutput{}
 	}
 	defer closeWithLog(stmt, "closing client update statement")
 
-	_, err = stmt.Exec(input.Name, input.Email, input.Website, input.Avatar, input.ContactPerson, input.Address, input.Currency, input.Status, input.Notes, input.BillingCompany, input.BillingAddress, input.BillingCity, input.BillingProvince, input.BillingPostalCode, input.ID, userID)
+	_, err = stmt.Exec(input.Name, input.Email, input.Website, input.Avatar, input.ContactPerson, input.Address, input.Currency, input.Status, input.Notes, input.BillingCompany, input.BillingAddress, input.BillingCity, input.BillingProvince, input.BillingPostalCode, input.PaymentTerms, input.RequiresPO, input.ID, userID)
 	if err != nil {
 		log.Println("Error updating client:", err)
 		return dto.ClientOutput{}
@@ -107,10 +135,324 @@ func (s *ClientService) Update(userID int, input dto.UpdateClientInput) dto.Clie
 	return output
 }
 
-// Delete removes a client by ID for a specific user.
-func (s *ClientService) Delete(userID int, id int) {
-	_, err := s.db.Exec("DELETE FROM clients WHERE id=? AND user_id=?", id, userID)
+// Archive hides a client, and with it the client's projects, from list views.
+// Invoices, payments and time entries are kept so history stays intact.
+func (s *ClientService) Archive(userID int, id int) error {
+	res, err := s.db.Exec("UPDATE clients SET archived_at = datetime('now') WHERE id = ? AND user_id = ? AND archived_at IS NULL", id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to archive client: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("client not found or already archived")
+	}
+	return nil
+}
+
+// Restore brings an archived client back. Projects that were archived on
+// their own stay archived.
+func (s *ClientService) Restore(userID int, id int) error {
+	res, err := s.db.Exec("UPDATE clients SET archived_at = NULL WHERE id = ? AND user_id = ? AND archived_at IS NOT NULL", id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to restore client: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("client not found or not archived")
+	}
+	return nil
+}
+
+// Dependents counts the records that reference a client, directly or through its projects.
+func (s *ClientService) Dependents(userID int, id int) (dto.Dependents, error) {
+	var d dto.Dependents
+	err := s.db.QueryRow(`
+SELECT
+  (SELECT COUNT(*) FROM projects WHERE user_id = ? AND client_id = ?),
+  (SELECT COUNT(*) FROM time_entries WHERE user_id = ? AND project_id IN (SELECT id FROM projects WHERE client_id = ?)),
+  (SELECT COUNT(*) FROM time_entries WHERE user_id = ? AND invoiced = 1 AND project_id IN (SELECT id FROM projects WHERE client_id = ?)),
+  (SELECT COUNT(*) FROM invoices WHERE user_id = ? AND client_id = ?),
+  (SELECT COUNT(*) FROM payments WHERE user_id = ? AND client_id = ?),
+  (SELECT COUNT(*) FROM client_contacts WHERE user_id = ? AND client_id = ?),
+  (SELECT COUNT(*) FROM rate_cards WHERE user_id = ? AND (client_id = ? OR project_id IN (SELECT id FROM projects WHERE client_id = ?))),
+  (SELECT COUNT(*) FROM finance_transactions WHERE user_id = ? AND (client_id = ?
+     OR project_id IN (SELECT id FROM projects WHERE client_id = ?)
+     OR invoice_id IN (SELECT id FROM invoices WHERE client_id = ?))),
+  (SELECT COUNT(*) FROM purchase_orders WHERE user_id = ? AND client_id = ?)`,
+		userID, id, userID, id, userID, id, userID, id, userID, id, userID, id,
+		userID, id, id, userID, id, id, id, userID, id,
+	).Scan(&d.Projects, &d.TimeEntries, &d.InvoicedTimeEntries, &d.Invoices, &d.Payments, &d.Contacts, &d.RateCards, &d.Transactions, &d.PurchaseOrders)
+	if err != nil {
+		return dto.Dependents{}, fmt.Errorf("failed to count client dependents: %w", err)
+	}
+	return d, nil
+}
+
+// Delete permanently removes a client that nothing references. Clients with
+// projects, invoices or other history are refused; archive them instead, or
+// use DeleteCascade.
+func (s *ClientService) Delete(userID int, id int) error {
+	if _, err := s.Get(userID, id); err != nil {
+		return fmt.Errorf("client not found: %w", err)
+	}
+	d, err := s.Dependents(userID, id)
 	if err != nil {
+		return err
+	}
+	if hasDependents(d) {
+		return fmt.Errorf("client has %s; archive it instead or confirm a cascading delete", describeDependents(d))
+	}
+	if _, err := s.db.Exec("DELETE FROM clients WHERE id=? AND user_id=?", id, userID); err != nil {
 		log.Println("Error deleting client:", err)
+		return fmt.Errorf("failed to delete client: %w", err)
+	}
+	return nil
+}
+
+// DeleteCascade permanently removes a client together with its projects, time
+// entries, invoices, payments, contacts, rate cards and purchase orders. Finance transactions
+// are kept and only unlinked. The caller confirms by repeating the client name.
+func (s *ClientService) DeleteCascade(userID int, input dto.CascadeDeleteInput) error {
+	client, err := s.Get(userID, input.ID)
+	if err != nil {
+		return fmt.Errorf("client not found: %w", err)
+	}
+	if input.ConfirmName != client.Name {
+		return fmt.Errorf("confirmation does not match client name")
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	const projectIDs = "(SELECT id FROM projects WHERE user_id = ? AND client_id = ?)"
+	const invoiceIDs = "(SELECT id FROM invoices WHERE user_id = ? AND client_id = ?)"
+	steps := []struct {
+		query string
+		args  []interface{}
+	}{
+		{"UPDATE finance_transactions SET invoice_id = NULL WHERE user_id = ? AND invoice_id IN " + invoiceIDs, []interface{}{userID, userID, input.ID}},
+		{"UPDATE finance_transactions SET project_id = NULL WHERE user_id = ? AND project_id IN " + projectIDs, []interface{}{userID, userID, input.ID}},
+		{"UPDATE finance_transactions SET client_id = NULL WHERE user_id = ? AND client_id = ?", []interface{}{userID, input.ID}},
+		{"DELETE FROM invoice_late_fees WHERE user_id = ? AND invoice_id IN " + invoiceIDs, []interface{}{userID, userID, input.ID}},
+		{"DELETE FROM time_entries WHERE user_id = ? AND project_id IN " + projectIDs, []interface{}{userID, userID, input.ID}},
+		{"DELETE FROM rate_cards WHERE user_id = ? AND (client_id = ? OR project_id IN " + projectIDs + ")", []interface{}{userID, input.ID, userID, input.ID}},
+		{"DELETE FROM payments WHERE user_id = ? AND client_id = ?", []interface{}{userID, input.ID}},
+		{"DELETE FROM client_contacts WHERE user_id = ? AND client_id = ?", []interface{}{userID, input.ID}},
+		{"DELETE FROM invoices WHERE user_id = ? AND client_id = ?", []interface{}{userID, input.ID}},
+		{"DELETE FROM purchase_orders WHERE user_id = ? AND client_id = ?", []interface{}{userID, input.ID}},
+		{"DELETE FROM projects WHERE user_id = ? AND client_id = ?", []interface{}{userID, input.ID}},
+		{"DELETE FROM clients WHERE user_id = ? AND id = ?", []interface{}{userID, input.ID}},
+	}
+	for _, step := range steps {
+		if _, err := tx.Exec(step.query, step.args...); err != nil {
+			return fmt.Errorf("failed to delete client: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// FindDuplicates returns groups of clients that are probably the same
+// organization: equal names once case, punctuation and legal suffixes are
+// ignored, or a shared business email domain.
+func (s *ClientService) FindDuplicates(userID int) ([]dto.DuplicateGroup, error) {
+	rows, err := s.db.Query("SELECT id, name, COALESCE(email, ''), COALESCE(archived_at, '') FROM clients WHERE user_id = ?", userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query clients: %w", err)
+	}
+	defer closeWithLog(rows, "closing duplicate client rows")
+
+	var records []dto.DuplicateRecord
+	for rows.Next() {
+		var r dto.DuplicateRecord
+		if err := rows.Scan(&r.ID, &r.Name, &r.Email, &r.ArchivedAt); err != nil {
+			log.Println("Error scanning client:", err)
+			continue
+		}
+		r.ClientID = r.ID
+		records = append(records, r)
+	}
+
+	groups := groupDuplicates(records, "name", func(r dto.DuplicateRecord) string { return normalizeName(r.Name) })
+	for _, g := range groupDuplicates(records, "email_domain", func(r dto.DuplicateRecord) string { return businessDomain(r.Email) }) {
+		redundant := false
+		for _, existing := range groups {
+			if sameMembers(existing, g) {
+				redundant = true
+				break
+			}
+		}
+		if !redundant {
+			groups = append(groups, g)
+		}
+	}
+	return groups, nil
+}
+
+// Merge folds the source client into the target in one transaction: projects
+// (with their time entries), invoices, payments, contacts, rate cards,
+// purchase orders and finance links move to the target, blank target details are filled from the
+// source, and the source is deleted.
+func (s *ClientService) Merge(userID int, input dto.MergeInput) (dto.MergeReport, error) {
+	if input.SourceID == input.TargetID {
+		return dto.MergeReport{}, fmt.Errorf("cannot merge a client into itself")
+	}
+	source, err := s.Get(userID, input.SourceID)
+	if err != nil {
+		return dto.MergeReport{}, fmt.Errorf("source client not found: %w", err)
+	}
+	target, err := s.Get(userID, input.TargetID)
+	if err != nil {
+		return dto.MergeReport{}, fmt.Errorf("target client not found: %w", err)
+	}
+	report := dto.MergeReport{
+		SourceID:     source.ID,
+		SourceName:   source.Name,
+		TargetID:     target.ID,
+		TargetName:   target.Name,
+		FilledFields: []string{},
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return dto.MergeReport{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := tx.QueryRow(
+		"SELECT COUNT(*) FROM time_entries WHERE user_id = ? AND project_id IN (SELECT id FROM projects WHERE user_id = ? AND client_id = ?)",
+		userID, userID, source.ID,
+	).Scan(&report.TimeEntries); err != nil {
+		return dto.MergeReport{}, fmt.Errorf("failed to count time entries: %w", err)
+	}
+	// Contacts the target already has (same email and role) are dropped rather than duplicated.
+	if _, err := tx.Exec(`
+DELETE FROM client_contacts
+WHERE user_id = ? AND client_id = ? AND EXISTS (
+  SELECT 1 FROM client_contacts t
+  WHERE t.user_id = client_contacts.user_id AND t.client_id = ?
+    AND LOWER(t.email) = LOWER(client_contacts.email) AND t.role = client_contacts.role)`,
+		userID, source.ID, target.ID,
+	); err != nil {
+		return dto.MergeReport{}, fmt.Errorf("failed to merge contacts: %w", err)
+	}
+	// A purchase order the target already has under the same number is the
+	// same order: its invoices are repointed and the source copy dropped.
+	const samePO = `SELECT t.id FROM purchase_orders t
+  WHERE t.user_id = purchase_orders.user_id AND t.client_id = ? AND t.number = purchase_orders.number`
+	if _, err := tx.Exec(`
+UPDATE invoices SET purchase_order_id = (
+  SELECT t.id FROM purchase_orders src JOIN purchase_orders t ON t.user_id = src.user_id AND t.client_id = ? AND t.number = src.number
+  WHERE src.id = invoices.purchase_order_id)
+WHERE user_id = ? AND purchase_order_id IN (
+  SELECT id FROM purchase_orders WHERE user_id = ? AND client_id = ? AND EXISTS (`+samePO+`))`,
+		target.ID, userID, userID, source.ID, target.ID,
+	); err != nil {
+		return dto.MergeReport{}, fmt.Errorf("failed to merge purchase orders: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM purchase_orders WHERE user_id = ? AND client_id = ? AND EXISTS ("+samePO+")",
+		userID, source.ID, target.ID,
+	); err != nil {
+		return dto.MergeReport{}, fmt.Errorf("failed to merge purchase orders: %w", err)
+	}
+
+	moves := []struct {
+		table string
+		count *int
+	}{
+		{"projects", &report.Projects},
+		{"invoices", &report.Invoices},
+		{"payments", &report.Payments},
+		{"client_contacts", &report.Contacts},
+		{"rate_cards", &report.RateCards},
+		{"finance_transactions", &report.Transactions},
+		{"purchase_orders", &report.PurchaseOrders},
+	}
+	for _, m := range moves {
+		// #nosec G202 -- table names come from the fixed list above.
+		res, err := tx.Exec("UPDATE "+m.table+" SET client_id = ? WHERE user_id = ? AND client_id = ?", target.ID, userID, source.ID)
+		if err != nil {
+			return dto.MergeReport{}, fmt.Errorf("failed to move %s: %w", m.table, err)
+		}
+		n, _ := res.RowsAffected()
+		*m.count = int(n)
+	}
+
+	fills := []struct {
+		column, field  string
+		target, source string
+	}{
+		{"email", "email", target.Email, source.Email},
+		{"website", "website", target.Website, source.Website},
+		{"contact_person", "contactPerson", target.ContactPerson, source.ContactPerson},
+		{"address", "address", target.Address, source.Address},
+		{"billing_company", "billingCompany", target.BillingCompany, source.BillingCompany},
+		{"billing_address", "billingAddress", target.BillingAddress, source.BillingAddress},
+		{"billing_city", "billingCity", target.BillingCity, source.BillingCity},
+		{"billing_province", "billingProvince", target.BillingProvince, source.BillingProvince},
+		{"billing_postal_code", "billingPostalCode", target.BillingPostalCode, source.BillingPostalCode},
+		{"payment_terms", "paymentTerms", target.PaymentTerms, source.PaymentTerms},
+	}
+	for _, f := range fills {
+		if strings.TrimSpace(f.target) != "" || strings.TrimSpace(f.source) == "" {
+			continue
+		}
+		// #nosec G202 -- column names come from the fixed list above.
+		if _, err := tx.Exec("UPDATE clients SET "+f.column+" = ? WHERE id = ? AND user_id = ?", f.source, target.ID, userID); err != nil {
+			return dto.MergeReport{}, fmt.Errorf("failed to update target client: %w", err)
+		}
+		report.FilledFields = append(report.FilledFields, f.field)
+	}
+
+	filled, err := mergeCustomFieldValues(tx, userID, "client", source.ID, target.ID)
+	if err != nil {
+		return dto.MergeReport{}, err
+	}
+	if filled {
+		report.FilledFields = append(report.FilledFields, "customFields")
+	}
+
+	// Files the target already has stay behind and go with the source.
+	res, err := tx.Exec("UPDATE OR IGNORE attachments SET entity_id = ? WHERE user_id = ? AND entity_type = 'client' AND entity_id = ?",
+		target.ID, userID, source.ID)
+	if err != nil {
+		return dto.MergeReport{}, fmt.Errorf("failed to move attachments: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	report.Attachments = int(n)
+
+	if _, err := tx.Exec("DELETE FROM clients WHERE id = ? AND user_id = ?", source.ID, userID); err != nil {
+		return dto.MergeReport{}, fmt.Errorf("failed to delete merged client: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return dto.MergeReport{}, err
+	}
+	return report, nil
+}
+
+// hasDependents reports whether any record still references the client or project.
+func hasDependents(d dto.Dependents) bool {
+	return d.Projects+d.TimeEntries+d.Invoices+d.Payments+d.Contacts+d.RateCards+d.Transactions+d.PurchaseOrders > 0
+}
+
+// describeDependents lists the non-zero dependent counts, e.g. "2 projects, 1 invoice".
+func describeDependents(d dto.Dependents) string {
+	var parts []string
+	add := func(n int, singular, plural string) {
+		switch {
+		case n == 1:
+			parts = append(parts, "1 "+singular)
+		case n > 1:
+			parts = append(parts, fmt.Sprintf("%d %s", n, plural))
+		}
 	}
+	add(d.Projects, "project", "projects")
+	add(d.TimeEntries, "time entry", "time entries")
+	add(d.Invoices, "invoice", "invoices")
+	add(d.Payments, "payment", "payments")
+	add(d.Contacts, "contact", "contacts")
+	add(d.RateCards, "rate card", "rate cards")
+	add(d.Transactions, "linked transaction", "linked transactions")
+	add(d.PurchaseOrders, "purchase order", "purchase orders")
+	return strings.Join(parts, ", ")
 }
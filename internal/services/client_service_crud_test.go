@@ -59,7 +59,7 @@ func TestClientService_CRUD(t *testing.T) {
 	assert.Equal(t, "CAD", updated.Currency)
 
 	// Delete
-	clientSvc.Delete(user.ID, created.ID)
+	assert.NoError(t, clientSvc.Delete(user.ID, created.ID))
 	listAfter := clientSvc.List(user.ID)
 	assert.Len(t, listAfter, 0)
 }
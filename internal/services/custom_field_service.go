@@ -0,0 +1,415 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"tally/internal/dto"
+	"tally/internal/mapper"
+	"tally/internal/models"
+	"time"
+)
+
+// CustomFieldService manages user-defined fields on clients, projects and
+// invoices, and their values.
+type CustomFieldService struct {
+	db *sql.DB
+}
+
+// NewCustomFieldService creates a new CustomFieldService instance.
+func NewCustomFieldService(db *sql.DB) *CustomFieldService {
+	return &CustomFieldService{db: db}
+}
+
+// customFieldTables maps an entity type to the table holding its records.
+var customFieldTables = map[string]string{
+	"client":  "clients",
+	"project": "projects",
+	"invoice": "invoices",
+}
+
+var customFieldTypes = map[string]bool{"text": true, "number": true, "date": true, "select": true}
+
+// customFieldKeyPattern matches keys usable as {{key}} template placeholders.
+var customFieldKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+const customFieldColumns = "id, entity_type, field_key, label, field_type, COALESCE(options_json, '[]'), show_on_invoice, sort_order"
+
+// List returns the user's custom field definitions, optionally for one entity type.
+func (s *CustomFieldService) List(userID int, entityType string) []dto.CustomFieldOutput {
+	query := "SELECT " + customFieldColumns + " FROM custom_field_definitions WHERE user_id = ?"
+	args := []interface{}{userID}
+	if entityType != "" {
+		query += " AND entity_type = ?"
+		args = append(args, entityType)
+	}
+	query += " ORDER BY entity_type, sort_order, id"
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		log.Println("Error querying custom fields:", err)
+		return []dto.CustomFieldOutput{}
+	}
+	defer closeWithLog(rows, "closing custom field rows")
+
+	var fields []models.CustomField
+	for rows.Next() {
+		f, err := scanCustomField(rows)
+		if err != nil {
+			log.Println("Error scanning custom field:", err)
+			continue
+		}
+		fields = append(fields, f)
+	}
+	return mapper.ToCustomFieldOutputList(fields)
+}
+
+// Create defines a new custom field.
+func (s *CustomFieldService) Create(userID int, input dto.CreateCustomFieldInput) (dto.CustomFieldOutput, error) {
+	if _, ok := customFieldTables[input.EntityType]; !ok {
+		return dto.CustomFieldOutput{}, fmt.Errorf("custom fields are not supported on %q", input.EntityType)
+	}
+	if !customFieldTypes[input.FieldType] {
+		return dto.CustomFieldOutput{}, fmt.Errorf("unknown field type: %s", input.FieldType)
+	}
+	label := strings.TrimSpace(input.Label)
+	if label == "" {
+		return dto.CustomFieldOutput{}, fmt.Errorf("label is required")
+	}
+	key := strings.TrimSpace(input.Key)
+	if key == "" {
+		key = customFieldKey(label)
+	}
+	if !customFieldKeyPattern.MatchString(key) {
+		return dto.CustomFieldOutput{}, fmt.Errorf("invalid key %q: use lowercase letters, digits and underscores, starting with a letter", key)
+	}
+	options, err := customFieldOptions(input.FieldType, input.Options)
+	if err != nil {
+		return dto.CustomFieldOutput{}, err
+	}
+	optionsJSON, _ := json.Marshal(options)
+
+	res, err := s.db.Exec(`INSERT INTO custom_field_definitions(user_id, entity_type, field_key, label, field_type, options_json, show_on_invoice, sort_order)
+VALUES(?, ?, ?, ?, ?, ?, ?, ?)`, userID, input.EntityType, key, label, input.FieldType, string(optionsJSON), input.ShowOnInvoice, input.SortOrder)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			return dto.CustomFieldOutput{}, fmt.Errorf("a %s field with key %q already exists", input.EntityType, key)
+		}
+		return dto.CustomFieldOutput{}, fmt.Errorf("failed to insert custom field: %w", err)
+	}
+	id, _ := res.LastInsertId()
+	return mapper.ToCustomFieldOutput(models.CustomField{
+		ID:            int(id),
+		EntityType:    input.EntityType,
+		Key:           key,
+		Label:         label,
+		FieldType:     input.FieldType,
+		Options:       options,
+		ShowOnInvoice: input.ShowOnInvoice,
+		SortOrder:     input.SortOrder,
+	}), nil
+}
+
+// Update changes the label, options, ordering or invoice visibility of a field.
+// Select options still used by a record cannot be removed.
+func (s *CustomFieldService) Update(userID int, input dto.UpdateCustomFieldInput) (dto.CustomFieldOutput, error) {
+	field, err := s.get(userID, input.ID)
+	if err != nil {
+		return dto.CustomFieldOutput{}, err
+	}
+	label := strings.TrimSpace(input.Label)
+	if label == "" {
+		return dto.CustomFieldOutput{}, fmt.Errorf("label is required")
+	}
+	options, err := customFieldOptions(field.FieldType, input.Options)
+	if err != nil {
+		return dto.CustomFieldOutput{}, err
+	}
+	if field.FieldType == "select" {
+		var inUse int
+		args := []interface{}{field.ID}
+		for _, o := range options {
+			args = append(args, o)
+		}
+		// #nosec G202 -- only placeholders are concatenated.
+		query := "SELECT COUNT(*) FROM custom_field_values WHERE field_id = ? AND value NOT IN (" + strings.TrimSuffix(strings.Repeat("?, ", len(options)), ", ") + ")"
+		if err := s.db.QueryRow(query, args...).Scan(&inUse); err != nil {
+			return dto.CustomFieldOutput{}, fmt.Errorf("failed to check option usage: %w", err)
+		}
+		if inUse > 0 {
+			return dto.CustomFieldOutput{}, fmt.Errorf("%d record(s) use an option that would be removed", inUse)
+		}
+	}
+	optionsJSON, _ := json.Marshal(options)
+
+	if _, err := s.db.Exec("UPDATE custom_field_definitions SET label = ?, options_json = ?, show_on_invoice = ?, sort_order = ? WHERE id = ? AND user_id = ?",
+		label, string(optionsJSON), input.ShowOnInvoice, input.SortOrder, field.ID, userID); err != nil {
+		return dto.CustomFieldOutput{}, fmt.Errorf("failed to update custom field: %w", err)
+	}
+	field.Label = label
+	field.Options = options
+	field.ShowOnInvoice = input.ShowOnInvoice
+	field.SortOrder = input.SortOrder
+	return mapper.ToCustomFieldOutput(field), nil
+}
+
+// Delete removes a custom field and all its values.
+func (s *CustomFieldService) Delete(userID int, id int) error {
+	if _, err := s.get(userID, id); err != nil {
+		return err
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec("DELETE FROM custom_field_values WHERE field_id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete custom field values: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM custom_field_definitions WHERE id = ? AND user_id = ?", id, userID); err != nil {
+		return fmt.Errorf("failed to delete custom field: %w", err)
+	}
+	return tx.Commit()
+}
+
+// GetValues returns every field defined for the entity type with its value on
+// the record; unset fields have an empty value.
+func (s *CustomFieldService) GetValues(userID int, entityType string, entityID int) ([]dto.CustomFieldValueOutput, error) {
+	if err := s.checkEntity(userID, entityType, entityID); err != nil {
+		return nil, err
+	}
+	rows, err := s.db.Query(`
+SELECT d.id, d.entity_type, d.field_key, d.label, d.field_type, COALESCE(v.value, ''), d.show_on_invoice
+FROM custom_field_definitions d
+LEFT JOIN custom_field_values v ON v.field_id = d.id AND v.entity_id = ?
+WHERE d.user_id = ? AND d.entity_type = ?
+ORDER BY d.sort_order, d.id`, entityID, userID, entityType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query custom field values: %w", err)
+	}
+	defer closeWithLog(rows, "closing custom field value rows")
+
+	values := []dto.CustomFieldValueOutput{}
+	for rows.Next() {
+		var v dto.CustomFieldValueOutput
+		if err := rows.Scan(&v.FieldID, &v.EntityType, &v.Key, &v.Label, &v.FieldType, &v.Value, &v.ShowOnInvoice); err != nil {
+			return nil, fmt.Errorf("failed to scan custom field value: %w", err)
+		}
+		values = append(values, v)
+	}
+	return values, rows.Err()
+}
+
+// SetValues validates and stores custom field values on one record.
+func (s *CustomFieldService) SetValues(userID int, input dto.SetCustomFieldValuesInput) ([]dto.CustomFieldValueOutput, error) {
+	if err := s.checkEntity(userID, input.EntityType, input.EntityID); err != nil {
+		return nil, err
+	}
+	fields := map[string]models.CustomField{}
+	for _, f := range s.List(userID, input.EntityType) {
+		fields[f.Key] = models.CustomField{ID: f.ID, Key: f.Key, Label: f.Label, FieldType: f.FieldType, Options: f.Options}
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for key, raw := range input.Values {
+		field, ok := fields[key]
+		if !ok {
+			return nil, fmt.Errorf("unknown %s field: %s", input.EntityType, key)
+		}
+		value, err := validateCustomFieldValue(field, raw)
+		if err != nil {
+			return nil, err
+		}
+		if value == "" {
+			_, err = tx.Exec("DELETE FROM custom_field_values WHERE field_id = ? AND entity_id = ?", field.ID, input.EntityID)
+		} else {
+			_, err = tx.Exec(`INSERT INTO custom_field_values(field_id, entity_id, value) VALUES(?, ?, ?)
+ON CONFLICT(field_id, entity_id) DO UPDATE SET value = excluded.value`, field.ID, input.EntityID, value)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to save %s: %w", field.Label, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return s.GetValues(userID, input.EntityType, input.EntityID)
+}
+
+func (s *CustomFieldService) get(userID int, id int) (models.CustomField, error) {
+	row := s.db.QueryRow("SELECT "+customFieldColumns+" FROM custom_field_definitions WHERE id = ? AND user_id = ?", id, userID)
+	field, err := scanCustomField(row)
+	if err != nil {
+		return models.CustomField{}, fmt.Errorf("custom field not found: %w", err)
+	}
+	return field, nil
+}
+
+// checkEntity verifies that a record exists and belongs to the user.
+func (s *CustomFieldService) checkEntity(userID int, entityType string, entityID int) error {
+	table, ok := customFieldTables[entityType]
+	if !ok {
+		return fmt.Errorf("custom fields are not supported on %q", entityType)
+	}
+	var count int
+	// #nosec G202 -- table name comes from customFieldTables.
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM "+table+" WHERE id = ? AND user_id = ?", entityID, userID).Scan(&count); err != nil {
+		return fmt.Errorf("failed to check %s: %w", entityType, err)
+	}
+	if count == 0 {
+		return fmt.Errorf("%s not found", entityType)
+	}
+	return nil
+}
+
+func scanCustomField(row rowScanner) (models.CustomField, error) {
+	var f models.CustomField
+	var optionsJSON string
+	if err := row.Scan(&f.ID, &f.EntityType, &f.Key, &f.Label, &f.FieldType, &optionsJSON, &f.ShowOnInvoice, &f.SortOrder); err != nil {
+		return models.CustomField{}, err
+	}
+	if err := json.Unmarshal([]byte(optionsJSON), &f.Options); err != nil {
+		log.Printf("Error decoding options of custom field %d: %v", f.ID, err)
+	}
+	return f, nil
+}
+
+// customFieldKey derives a placeholder key from a label: "PO Number" -> "po_number".
+func customFieldKey(label string) string {
+	var b strings.Builder
+	underscore := false
+	for _, r := range strings.ToLower(label) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			underscore = false
+		} else if !underscore && b.Len() > 0 {
+			b.WriteByte('_')
+			underscore = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "_")
+}
+
+// customFieldOptions cleans the option list; only select fields have options.
+func customFieldOptions(fieldType string, options []string) ([]string, error) {
+	if fieldType != "select" {
+		return []string{}, nil
+	}
+	cleaned := []string{}
+	seen := map[string]bool{}
+	for _, o := range options {
+		o = strings.TrimSpace(o)
+		if o == "" || seen[strings.ToLower(o)] {
+			continue
+		}
+		seen[strings.ToLower(o)] = true
+		cleaned = append(cleaned, o)
+	}
+	if len(cleaned) == 0 {
+		return nil, fmt.Errorf("select fields need at least one option")
+	}
+	return cleaned, nil
+}
+
+// validateCustomFieldValue checks a value against the field type and returns
+// it in canonical form. Empty means unset.
+func validateCustomFieldValue(field models.CustomField, raw string) (string, error) {
+	value := strings.TrimSpace(raw)
+	if value == "" {
+		return "", nil
+	}
+	switch field.FieldType {
+	case "number":
+		n, err := strconv.ParseFloat(strings.ReplaceAll(value, ",", ""), 64)
+		if err != nil {
+			return "", fmt.Errorf("%s must be a number", field.Label)
+		}
+		return strconv.FormatFloat(n, 'f', -1, 64), nil
+	case "date":
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return "", fmt.Errorf("%s must be a date (YYYY-MM-DD)", field.Label)
+		}
+	case "select":
+		for _, o := range field.Options {
+			if strings.EqualFold(o, value) {
+				return o, nil
+			}
+		}
+		return "", fmt.Errorf("%s must be one of: %s", field.Label, strings.Join(field.Options, ", "))
+	}
+	return value, nil
+}
+
+// invoiceCustomFields returns the custom field values that apply to an
+// invoice: its client's, those of the projects billed on it, and its own. When
+// the same key is set at several levels the invoice wins, then the project.
+func invoiceCustomFields(db *sql.DB, userID int, invoiceID int, clientID int) ([]dto.CustomFieldValueOutput, error) {
+	rows, err := db.Query(`
+SELECT d.id, d.entity_type, d.field_key, d.label, d.field_type, v.value, d.show_on_invoice
+FROM custom_field_definitions d
+JOIN custom_field_values v ON v.field_id = d.id
+WHERE d.user_id = ? AND (
+  (d.entity_type = 'client' AND v.entity_id = ?) OR
+  (d.entity_type = 'project' AND v.entity_id IN (SELECT DISTINCT project_id FROM time_entries WHERE invoice_id = ? AND user_id = ?)) OR
+  (d.entity_type = 'invoice' AND v.entity_id = ?))
+ORDER BY CASE d.entity_type WHEN 'client' THEN 0 WHEN 'project' THEN 1 ELSE 2 END, d.sort_order, d.id, v.entity_id`,
+		userID, clientID, invoiceID, userID, invoiceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query invoice custom fields: %w", err)
+	}
+	defer closeWithLog(rows, "closing invoice custom field rows")
+
+	fields := []dto.CustomFieldValueOutput{}
+	index := map[string]int{}
+	for rows.Next() {
+		var v dto.CustomFieldValueOutput
+		if err := rows.Scan(&v.FieldID, &v.EntityType, &v.Key, &v.Label, &v.FieldType, &v.Value, &v.ShowOnInvoice); err != nil {
+			return nil, fmt.Errorf("failed to scan invoice custom field: %w", err)
+		}
+		if i, ok := index[v.Key]; ok {
+			if fields[i].EntityType == v.EntityType {
+				continue // several projects set it; keep the first
+			}
+			fields[i] = v
+			continue
+		}
+		index[v.Key] = len(fields)
+		fields = append(fields, v)
+	}
+	return fields, rows.Err()
+}
+
+// mergeCustomFieldValues copies the source record's custom field values to the
+// target where the target has none, and reports whether anything was copied.
+func mergeCustomFieldValues(exec sqlExecutor, userID int, entityType string, sourceID int, targetID int) (bool, error) {
+	res, err := exec.Exec(`
+INSERT OR IGNORE INTO custom_field_values(field_id, entity_id, value)
+SELECT v.field_id, ?, v.value
+FROM custom_field_values v
+JOIN custom_field_definitions d ON d.id = v.field_id
+WHERE d.user_id = ? AND d.entity_type = ? AND v.entity_id = ?`, targetID, userID, entityType, sourceID)
+	if err != nil {
+		return false, fmt.Errorf("failed to merge custom fields: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+// customFieldPlaceholders maps field keys to values for utils.ApplyTemplate.
+func customFieldPlaceholders(fields []dto.CustomFieldValueOutput) map[string]string {
+	values := make(map[string]string, len(fields))
+	for _, f := range fields {
+		values[f.Key] = f.Value
+	}
+	return values
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"tally/internal/dto"
+	"tally/internal/pdf"
+	"tally/internal/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomFieldKey(t *testing.T) {
+	assert.Equal(t, "po_number", customFieldKey("PO Number"))
+	assert.Equal(t, "cost_centre", customFieldKey("  Cost-Centre: "))
+	assert.Equal(t, "vendor_id_2", customFieldKey("Vendor ID #2"))
+}
+
+func TestCustomFieldService_DefinitionsAndValues(t *testing.T) {
+	db := setupFullTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	auth := NewAuthService(db)
+	user := createTestUser(t, auth, "fields_user")
+	other := createTestUser(t, auth, "fields_other")
+	fieldSvc := NewCustomFieldService(db)
+	client := NewClientService(db).Create(user.ID, dto.CreateClientInput{Name: "Acme"})
+
+	vendor, err := fieldSvc.Create(user.ID, dto.CreateCustomFieldInput{EntityType: "client", Label: "Vendor ID", FieldType: "text", ShowOnInvoice: true})
+	require.NoError(t, err)
+	assert.Equal(t, "vendor_id", vendor.Key)
+	limit, err := fieldSvc.Create(user.ID, dto.CreateCustomFieldInput{EntityType: "client", Label: "Credit limit", FieldType: "number", SortOrder: 1})
+	require.NoError(t, err)
+	since, err := fieldSvc.Create(user.ID, dto.CreateCustomFieldInput{EntityType: "client", Label: "Customer since", FieldType: "date", SortOrder: 2})
+	require.NoError(t, err)
+	region, err := fieldSvc.Create(user.ID, dto.CreateCustomFieldInput{EntityType: "client", Label: "Region", FieldType: "select", Options: []string{"East", " West ", "east"}, SortOrder: 3})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"East", "West"}, region.Options)
+
+	_, err = fieldSvc.Create(user.ID, dto.CreateCustomFieldInput{EntityType: "client", Label: "Vendor ID", FieldType: "text"})
+	assert.Error(t, err, "duplicate key")
+	_, err = fieldSvc.Create(user.ID, dto.CreateCustomFieldInput{EntityType: "client", Label: "Tier", FieldType: "select"})
+	assert.Error(t, err, "select without options")
+	_, err = fieldSvc.Create(user.ID, dto.CreateCustomFieldInput{EntityType: "time_entry", Label: "Ref", FieldType: "text"})
+	assert.Error(t, err)
+	_, err = fieldSvc.Create(user.ID, dto.CreateCustomFieldInput{EntityType: "client", Key: "1st", Label: "First", FieldType: "text"})
+	assert.Error(t, err)
+	assert.Len(t, fieldSvc.List(user.ID, "client"), 4)
+	assert.Len(t, fieldSvc.List(other.ID, ""), 0)
+
+	values, err := fieldSvc.SetValues(user.ID, dto.SetCustomFieldValuesInput{EntityType: "client", EntityID: client.ID, Values: map[string]string{
+		"vendor_id":      " V-77 ",
+		"credit_limit":   "5,000.50",
+		"customer_since": "2023-04-01",
+		"region":         "west",
+	}})
+	require.NoError(t, err)
+	require.Len(t, values, 4)
+	assert.Equal(t, "V-77", values[0].Value)
+	assert.Equal(t, "5000.5", values[1].Value)
+	assert.Equal(t, "2023-04-01", values[2].Value)
+	assert.Equal(t, "West", values[3].Value)
+
+	for key, bad := range map[string]string{"credit_limit": "lots", "customer_since": "April", "region": "North", "unknown": "x"} {
+		_, err := fieldSvc.SetValues(user.ID, dto.SetCustomFieldValuesInput{EntityType: "client", EntityID: client.ID, Values: map[string]string{key: bad}})
+		assert.Error(t, err, key)
+	}
+	_, err = fieldSvc.SetValues(other.ID, dto.SetCustomFieldValuesInput{EntityType: "client", EntityID: client.ID, Values: map[string]string{"vendor_id": "x"}})
+	assert.Error(t, err)
+
+	// An option in use cannot be removed; clearing the value frees it.
+	_, err = fieldSvc.Update(user.ID, dto.UpdateCustomFieldInput{ID: region.ID, Label: "Region", Options: []string{"East"}})
+	assert.Error(t, err)
+	_, err = fieldSvc.SetValues(user.ID, dto.SetCustomFieldValuesInput{EntityType: "client", EntityID: client.ID, Values: map[string]string{"region": ""}})
+	require.NoError(t, err)
+	updated, err := fieldSvc.Update(user.ID, dto.UpdateCustomFieldInput{ID: region.ID, Label: "Sales region", Options: []string{"East"}})
+	require.NoError(t, err)
+	assert.Equal(t, "Sales region", updated.Label)
+	assert.Equal(t, "region", updated.Key)
+
+	// Deleting a field drops its values; deleting the client drops the rest.
+	require.NoError(t, fieldSvc.Delete(user.ID, limit.ID))
+	values, err = fieldSvc.GetValues(user.ID, "client", client.ID)
+	require.NoError(t, err)
+	assert.Len(t, values, 3)
+	assert.Error(t, fieldSvc.Delete(other.ID, since.ID))
+
+	require.NoError(t, NewClientService(db).Delete(user.ID, client.ID))
+	var remaining int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM custom_field_values WHERE field_id = ?", vendor.ID).Scan(&remaining))
+	assert.Equal(t, 0, remaining)
+}
+
+func TestCustomFields_OnInvoices(t *testing.T) {
+	db := setupFullTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	auth := NewAuthService(db)
+	user := createTestUser(t, auth, "invoice_fields_user")
+	fieldSvc := NewCustomFieldService(db)
+	clientSvc := NewClientService(db)
+	projectSvc := NewProjectService(db)
+	timeSvc := NewTimesheetService(db)
+	invoiceSvc := NewInvoiceService(db)
+
+	client := clientSvc.Create(user.ID, dto.CreateClientInput{Name: "Acme"})
+	project := projectSvc.Create(user.ID, dto.CreateProjectInput{ClientID: client.ID, Name: "Site", HourlyRate: 100})
+	inv := invoiceSvc.Create(user.ID, dto.CreateInvoiceInput{ClientID: client.ID, Number: "CF-1", IssueDate: "2025-01-10", Status: "draft"})
+	entry := timeSvc.Create(user.ID, dto.CreateTimeEntryInput{ProjectID: project.ID, Date: "2025-01-05", DurationSeconds: 3600, Billable: true})
+	_, err := invoiceSvc.SetTimeEntries(user.ID, dto.SetInvoiceTimeEntriesInput{InvoiceID: inv.ID, TimeEntryIDs: []int{entry.ID}})
+	require.NoError(t, err)
+
+	define := func(entityType, label string, show bool) {
+		_, err := fieldSvc.Create(user.ID, dto.CreateCustomFieldInput{EntityType: entityType, Label: label, FieldType: "text", ShowOnInvoice: show})
+		require.NoError(t, err)
+	}
+	define("client", "Vendor ID", true)
+	define("client", "PO Number", true)
+	define("project", "Cost Centre", true)
+	define("invoice", "PO Number", true)
+	define("invoice", "Internal note", false)
+
+	set := func(entityType string, id int, values map[string]string) {
+		_, err := fieldSvc.SetValues(user.ID, dto.SetCustomFieldValuesInput{EntityType: entityType, EntityID: id, Values: values})
+		require.NoError(t, err)
+	}
+	set("client", client.ID, map[string]string{"vendor_id": "V-77", "po_number": "PO-DEFAULT"})
+	set("project", project.ID, map[string]string{"cost_centre": "CC-9"})
+	set("invoice", inv.ID, map[string]string{"po_number": "PO-123", "internal_note": "call first"})
+
+	got, err := invoiceSvc.Get(user.ID, inv.ID)
+	require.NoError(t, err)
+	byKey := map[string]dto.CustomFieldValueOutput{}
+	for _, f := range got.CustomFields {
+		byKey[f.Key] = f
+	}
+	assert.Len(t, got.CustomFields, 4)
+	assert.Equal(t, "V-77", byKey["vendor_id"].Value)
+	assert.Equal(t, "CC-9", byKey["cost_centre"].Value)
+	assert.Equal(t, "PO-123", byKey["po_number"].Value, "the invoice value overrides the client default")
+	assert.Equal(t, "invoice", byKey["po_number"].EntityType)
+	assert.False(t, byKey["internal_note"].ShowOnInvoice)
+
+	// Email subjects can use custom fields; built-in fields take precedence.
+	subject := utils.ApplyTemplate("Invoice {{number}} / PO {{po_number}} / {{ missing }}", got, customFieldPlaceholders(got.CustomFields))
+	assert.Equal(t, "Invoice CF-1 / PO PO-123 / {{ missing }}", subject)
+
+	// Templates print the fields marked for the invoice.
+	html, err := pdf.NewTemplateRenderer(pdf.GetTemplatesDir()).RenderHTML("quickbooks", pdf.InvoiceTemplateData{
+		InvoiceNumber: got.Number,
+		CustomFields:  []pdf.CustomFieldData{{Key: "po_number", Label: "PO Number", Value: "PO-123"}},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, html, "PO Number</span> PO-123")
+}
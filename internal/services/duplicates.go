@@ -0,0 +1,81 @@
+package services
+
+import (
+	"sort"
+	"strings"
+	"tally/internal/dto"
+	"unicode"
+)
+
+// legalSuffixes are dropped from the end of names before comparing them,
+// so "Acme Inc." and "ACME" normalize to the same key.
+var legalSuffixes = map[string]bool{
+	"inc": true, "incorporated": true, "llc": true, "llp": true, "ltd": true, "limited": true,
+	"corp": true, "corporation": true, "co": true, "company": true, "gmbh": true, "plc": true,
+}
+
+// freeMailDomains are shared by unrelated people and never indicate a duplicate.
+var freeMailDomains = map[string]bool{
+	"gmail.com": true, "googlemail.com": true, "outlook.com": true, "hotmail.com": true,
+	"live.com": true, "yahoo.com": true, "icloud.com": true, "me.com": true,
+	"aol.com": true, "proton.me": true, "protonmail.com": true, "gmx.com": true,
+}
+
+// normalizeName lowercases a name, drops punctuation and trailing legal suffixes.
+func normalizeName(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for len(words) > 1 && legalSuffixes[words[len(words)-1]] {
+		words = words[:len(words)-1]
+	}
+	return strings.Join(words, " ")
+}
+
+// businessDomain returns the lowercased domain of an email address, or "" for
+// invalid addresses and free mail providers.
+func businessDomain(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return ""
+	}
+	domain := strings.ToLower(strings.TrimSpace(email[at+1:]))
+	if domain == "" || freeMailDomains[domain] {
+		return ""
+	}
+	return domain
+}
+
+// groupDuplicates groups records sharing a non-empty key. Only groups with at
+// least two records are returned, ordered by key.
+func groupDuplicates(records []dto.DuplicateRecord, reason string, key func(dto.DuplicateRecord) string) []dto.DuplicateGroup {
+	byKey := map[string][]dto.DuplicateRecord{}
+	for _, r := range records {
+		if k := key(r); k != "" {
+			byKey[k] = append(byKey[k], r)
+		}
+	}
+	groups := []dto.DuplicateGroup{}
+	for k, members := range byKey {
+		if len(members) < 2 {
+			continue
+		}
+		sort.Slice(members, func(i, j int) bool { return members[i].ID < members[j].ID })
+		groups = append(groups, dto.DuplicateGroup{Reason: reason, Key: k, Records: members})
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Key < groups[j].Key })
+	return groups
+}
+
+// sameMembers reports whether two groups contain the same records.
+func sameMembers(a, b dto.DuplicateGroup) bool {
+	if len(a.Records) != len(b.Records) {
+		return false
+	}
+	for i := range a.Records {
+		if a.Records[i].ID != b.Records[i].ID {
+			return false
+		}
+	}
+	return true
+}
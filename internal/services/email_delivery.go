@@ -0,0 +1,284 @@
+package services
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"net/smtp"
+	"os"
+	"strings"
+	"tally/internal/dto"
+
+	"github.com/resend/resend-go/v3"
+)
+
+// outgoingEmail is a rendered message with a single PDF attachment.
+type outgoingEmail struct {
+	To             []string
+	CC             []string
+	BCC            []string
+	Subject        string
+	Body           string
+	AttachmentName string
+	// SMTPAttachmentName, when set, names the attachment sent over SMTP,
+	// which has always used its own name for invoices.
+	SMTPAttachmentName string
+	Attachment         []byte
+}
+
+// recipients returns every envelope address: To, CC and BCC.
+func (e outgoingEmail) recipients() []string {
+	all := make([]string, 0, len(e.To)+len(e.CC)+len(e.BCC))
+	all = append(all, e.To...)
+	all = append(all, e.CC...)
+	return append(all, e.BCC...)
+}
+
+// deliverEmail sends a message through the user's configured provider.
+// It reports whether the message actually left the app: mailto (the frontend
+// opens the mail client) and dry runs return false.
+func deliverEmail(settings dto.InvoiceEmailSettings, email outgoingEmail) (bool, error) {
+	if settings.Provider == "" || settings.Provider == "mailto" {
+		return false, nil
+	}
+	if len(email.To) == 0 {
+		return false, fmt.Errorf("recipient email is missing")
+	}
+
+	switch settings.Provider {
+	case "resend":
+		if settings.ResendAPIKey == "" {
+			return false, fmt.Errorf("resend API key is missing")
+		}
+		if os.Getenv("RESEND_DRY_RUN") == "1" {
+			log.Println("SendEmail: RESEND_DRY_RUN enabled, skipping network call")
+			return false, nil
+		}
+
+		clientResend := resend.NewClient(settings.ResendAPIKey)
+		_, err := clientResend.Emails.Send(&resend.SendEmailRequest{
+			From:    settings.FromEmail,
+			To:      email.To,
+			Cc:      email.CC,
+			Bcc:     email.BCC,
+			Subject: email.Subject,
+			Html:    email.Body,
+			Attachments: []*resend.Attachment{
+				{
+					Filename: email.AttachmentName,
+					Content:  email.Attachment,
+				},
+			},
+		})
+		if err != nil {
+			return false, fmt.Errorf("resend failed: %v", err)
+		}
+		return true, nil
+
+	case "smtp":
+		// Validate SMTP settings
+		if settings.SMTPHost == "" {
+			return false, fmt.Errorf("smtp host is missing")
+		}
+		if settings.SMTPUsername == "" {
+			return false, fmt.Errorf("smtp username is missing")
+		}
+		if settings.SMTPPassword == "" {
+			return false, fmt.Errorf("smtp password is missing")
+		}
+		if settings.FromEmail == "" {
+			return false, fmt.Errorf("smtp from email is missing")
+		}
+
+		// Add signature if provided
+		if settings.Signature != "" {
+			email.Body += "\n\n" + settings.Signature
+		}
+
+		if os.Getenv("SMTP_DRY_RUN") == "1" {
+			log.Println("SendEmail: SMTP_DRY_RUN enabled, skipping network call")
+			return false, nil
+		}
+
+		if err := sendViaSMTP(settings, email); err != nil {
+			return false, fmt.Errorf("smtp failed: %w", err)
+		}
+		return true, nil
+	}
+
+	return false, fmt.Errorf("unknown email provider: %s", settings.Provider)
+}
+
+// sendViaSMTP writes a multipart message with a PDF attachment to the configured SMTP server.
+func sendViaSMTP(settings dto.InvoiceEmailSettings, email outgoingEmail) error {
+	// Setup auth
+	auth := smtp.PlainAuth("", settings.SMTPUsername, settings.SMTPPassword, settings.SMTPHost)
+
+	// Setup headers
+	headers := make(map[string]string)
+	headers["From"] = settings.FromEmail
+	headers["To"] = strings.Join(email.To, ", ")
+	if len(email.CC) > 0 {
+		headers["Cc"] = strings.Join(email.CC, ", ")
+	}
+	// BCC recipients only appear in the envelope, never in the headers.
+	headers["Subject"] = email.Subject
+	headers["MIME-Version"] = "1.0"
+	boundary := "f46d043c8aa2b9211f43924705572551" // Random boundary
+	headers["Content-Type"] = "multipart/mixed; boundary=" + boundary
+
+	// Body buffer
+	var msg bytes.Buffer
+	for k, v := range headers {
+		msg.WriteString(fmt.Sprintf("%s: %s\r\n", k, v))
+	}
+	msg.WriteString("\r\n")
+
+	// Message body
+	msg.WriteString("--" + boundary + "\r\n")
+	msg.WriteString("Content-Type: text/html; charset=\"UTF-8\"\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(email.Body)
+	msg.WriteString("\r\n")
+
+	// Attachment
+	attachmentName := email.AttachmentName
+	if email.SMTPAttachmentName != "" {
+		attachmentName = email.SMTPAttachmentName
+	}
+	msg.WriteString("--" + boundary + "\r\n")
+	msg.WriteString("Content-Type: application/pdf; name=\"" + attachmentName + "\"\r\n")
+	msg.WriteString("Content-Transfer-Encoding: base64\r\n")
+	msg.WriteString("Content-Disposition: attachment; filename=\"" + attachmentName + "\"\r\n")
+	msg.WriteString("\r\n")
+
+	b64 := base64.StdEncoding.EncodeToString(email.Attachment)
+	// Split base64 lines (max 76 chars)
+	for i := 0; i < len(b64); i += 76 {
+		end := i + 76
+		if end > len(b64) {
+			end = len(b64)
+		}
+		msg.WriteString(b64[i:end] + "\r\n")
+	}
+	msg.WriteString("\r\n")
+	msg.WriteString("--" + boundary + "--")
+
+	// SMTP Connection (support TLS/StartTLS)
+	addr := fmt.Sprintf("%s:%d", settings.SMTPHost, settings.SMTPPort)
+
+	// If port 465, use implicit TLS
+	if settings.SMTPPort == 465 {
+		tlsConfig := &tls.Config{
+			InsecureSkipVerify: false,
+			MinVersion:         tls.VersionTLS12,
+			ServerName:         settings.SMTPHost,
+		}
+		conn, err := tls.Dial("tcp", addr, tlsConfig)
+		if err != nil {
+			log.Printf("SMTP: Auth failed: %v", err)
+			return fmt.Errorf("authentication failed: %w", err)
+		}
+
+		client, err := smtp.NewClient(conn, settings.SMTPHost)
+		if err != nil {
+			log.Printf("SMTP: NewClient failed: %v", err)
+			return fmt.Errorf("smtp client creation failed: %w", err)
+		}
+		defer func() {
+			if err := client.Quit(); err != nil {
+				log.Printf("SMTP: Quit failed: %v", err)
+			}
+		}()
+
+		if err := client.Auth(auth); err != nil {
+			log.Printf("SMTP: Auth failed: %v", err)
+			return fmt.Errorf("authentication failed: %w", err)
+		}
+
+		if err := client.Mail(settings.FromEmail); err != nil {
+			log.Printf("SMTP: Mail command failed: %v", err)
+			return fmt.Errorf("mail command failed: %w", err)
+		}
+
+		for _, rcpt := range email.recipients() {
+			if err := client.Rcpt(rcpt); err != nil {
+				log.Printf("SMTP: Rcpt command failed: %v", err)
+				return fmt.Errorf("rcpt command failed (check recipient address): %w", err)
+			}
+		}
+
+		w, err := client.Data()
+		if err != nil {
+			log.Printf("SMTP: Data command failed: %v", err)
+			return fmt.Errorf("data command failed: %w", err)
+		}
+
+		if _, err := w.Write(msg.Bytes()); err != nil {
+			log.Printf("SMTP: Write failed: %v", err)
+			if closeErr := w.Close(); closeErr != nil {
+				log.Printf("SMTP: Close writer after write failure failed: %v", closeErr)
+			}
+			return fmt.Errorf("message write failed: %w", err)
+		}
+
+		if err := w.Close(); err != nil {
+			log.Printf("SMTP: Close writer failed: %v", err)
+			return fmt.Errorf("message close failed: %w", err)
+		}
+
+		return nil
+	}
+
+	// Use plain SMTP without TLS
+	client, err := smtp.Dial(addr)
+	if err != nil {
+		log.Printf("SMTP: Dial failed: %v", err)
+		return fmt.Errorf("smtp dial failed: %w", err)
+	}
+	defer func() {
+		if err := client.Quit(); err != nil {
+			log.Printf("SMTP: Quit failed: %v", err)
+		}
+	}()
+
+	if err := client.Auth(auth); err != nil {
+		log.Printf("SMTP: Auth failed: %v", err)
+		return fmt.Errorf("authentication failed: %w", err)
+	}
+
+	if err := client.Mail(settings.FromEmail); err != nil {
+		log.Printf("SMTP: Mail command failed: %v", err)
+		return fmt.Errorf("mail command failed: %w", err)
+	}
+
+	for _, rcpt := range email.recipients() {
+		if err := client.Rcpt(rcpt); err != nil {
+			log.Printf("SMTP: Rcpt command failed: %v", err)
+			return fmt.Errorf("rcpt command failed: %w", err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		log.Printf("SMTP: Data command failed: %v", err)
+		return fmt.Errorf("data command failed: %w", err)
+	}
+
+	if _, err := w.Write(msg.Bytes()); err != nil {
+		log.Printf("SMTP: Write failed: %v", err)
+		if closeErr := w.Close(); closeErr != nil {
+			log.Printf("SMTP: Close writer after write failure failed: %v", closeErr)
+		}
+		return fmt.Errorf("message write failed: %w", err)
+	}
+
+	if err := w.Close(); err != nil {
+		log.Printf("SMTP: Close writer failed: %v", err)
+		return fmt.Errorf("message close failed: %w", err)
+	}
+
+	return nil
+}
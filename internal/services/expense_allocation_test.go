@@ -0,0 +1,144 @@
+package services
+
+import (
+	"tally/internal/dto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpenseAllocation_ProfitabilityAndInvoicing(t *testing.T) {
+	db := setupFinanceTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	auth := NewAuthService(db)
+	user := createTestUser(t, auth, "expense_user")
+
+	clientSvc := NewClientService(db)
+	projectSvc := NewProjectService(db)
+	timeSvc := NewTimesheetService(db)
+	financeSvc := NewFinanceService(db, nil)
+	invoiceSvc := NewInvoiceService(db)
+
+	client := clientSvc.Create(user.ID, dto.CreateClientInput{Name: "Acme"})
+	project := projectSvc.Create(user.ID, dto.CreateProjectInput{ClientID: client.ID, Name: "Site", HourlyRate: 100})
+	account := financeSvc.CreateAccount(user.ID, dto.CreateAccountInput{Name: "Chequing", Type: "checking"})
+
+	insertTx := func(date, description string, amount float64) int {
+		res, err := db.Exec("INSERT INTO finance_transactions(user_id, account_id, date, description, amount) VALUES(?, ?, ?, ?, ?)",
+			user.ID, account.ID, date, description, amount)
+		assert.NoError(t, err)
+		id, _ := res.LastInsertId()
+		return int(id)
+	}
+	hosting := insertTx("2025-01-05", "Hosting", -40)
+	travel := insertTx("2025-01-06", "Train ticket", -60)
+
+	assert.NoError(t, financeSvc.AllocateExpense(user.ID, dto.AllocateExpenseInput{TransactionID: hosting, ProjectID: project.ID, ExpenseType: "non_reimbursable"}))
+	assert.NoError(t, financeSvc.AllocateExpense(user.ID, dto.AllocateExpenseInput{TransactionID: travel, ProjectID: project.ID, ExpenseType: "reimbursable"}))
+	assert.Error(t, financeSvc.AllocateExpense(user.ID, dto.AllocateExpenseInput{TransactionID: travel, ProjectID: project.ID, ExpenseType: "other"}))
+
+	txs := financeSvc.GetTransactions(user.ID, dto.TransactionFilter{ProjectID: project.ID})
+	assert.Len(t, txs, 2)
+	for _, tx := range txs {
+		if assert.NotNil(t, tx.ClientID) {
+			assert.Equal(t, client.ID, *tx.ClientID)
+		}
+	}
+
+	// Two hours of work, both invoiced along with the reimbursable expense.
+	timeSvc.Create(user.ID, dto.CreateTimeEntryInput{ProjectID: project.ID, Date: "2025-01-10", DurationSeconds: 7200, Billable: true})
+	inv := invoiceSvc.Create(user.ID, dto.CreateInvoiceInput{ClientID: client.ID, Number: "E-1", IssueDate: "2025-01-31", Status: "draft"})
+	var ids []int
+	for _, e := range timeSvc.List(user.ID, project.ID) {
+		ids = append(ids, e.ID)
+	}
+	_, err := invoiceSvc.SetTimeEntries(user.ID, dto.SetInvoiceTimeEntriesInput{InvoiceID: inv.ID, TimeEntryIDs: ids})
+	assert.NoError(t, err)
+
+	assert.Len(t, invoiceSvc.ListBillableExpenses(user.ID, client.ID), 1)
+	out, err := invoiceSvc.PullReimbursableExpenses(user.ID, inv.ID)
+	assert.NoError(t, err)
+	assert.Len(t, out.Items, 2)
+	assert.InDelta(t, 260.0, out.Subtotal, 0.001)
+	assert.Empty(t, invoiceSvc.ListBillableExpenses(user.ID, client.ID))
+
+	// Billed expenses can no longer be re-allocated.
+	assert.Error(t, financeSvc.AllocateExpense(user.ID, dto.AllocateExpenseInput{TransactionID: travel, ClientID: client.ID, ExpenseType: "non_reimbursable"}))
+
+	// Removing the expense from the invoice drops its line.
+	out, err = invoiceSvc.SetExpenses(user.ID, dto.SetInvoiceExpensesInput{InvoiceID: inv.ID})
+	assert.NoError(t, err)
+	assert.Len(t, out.Items, 1)
+	assert.InDelta(t, 200.0, out.Subtotal, 0.001)
+
+	// Non-reimbursable expenses cannot be billed.
+	_, err = invoiceSvc.SetExpenses(user.ID, dto.SetInvoiceExpensesInput{InvoiceID: inv.ID, TransactionIDs: []int{hosting}})
+	assert.Error(t, err)
+
+	// Unbilled work adds hours but no revenue.
+	timeSvc.Create(user.ID, dto.CreateTimeEntryInput{ProjectID: project.ID, Date: "2025-01-20", DurationSeconds: 3600, Billable: true})
+
+	report, err := NewReportService(db).GetProjectProfitability(user.ID, dto.ReportFilter{StartDate: "2025-01-01", EndDate: "2025-01-31"})
+	assert.NoError(t, err)
+	if assert.Len(t, report.Rows, 1) {
+		row := report.Rows[0]
+		assert.InDelta(t, 3.0, row.Hours, 0.001)
+		assert.InDelta(t, 200.0, row.Revenue, 0.001)
+		assert.InDelta(t, 40.0, row.DirectExpenses, 0.001)
+		assert.InDelta(t, 60.0, row.ReimbursableExpenses, 0.001)
+		assert.InDelta(t, 160.0, row.Profit, 0.001)
+		assert.InDelta(t, 160.0/3, row.EffectiveHourlyRate, 0.001)
+	}
+	assert.InDelta(t, 160.0/3, report.EffectiveHourlyRate, 0.001)
+}
+
+func TestReportService_ProfitabilityRevenueFromInvoices(t *testing.T) {
+	db := setupFinanceTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	user := createTestUser(t, NewAuthService(db), "profit_invoice_user")
+	projectSvc := NewProjectService(db)
+	timeSvc := NewTimesheetService(db)
+	invoiceSvc := NewInvoiceService(db)
+	client := NewClientService(db).Create(user.ID, dto.CreateClientInput{Name: "Acme"})
+	design := projectSvc.Create(user.ID, dto.CreateProjectInput{ClientID: client.ID, Name: "Design", HourlyRate: 100})
+	build := projectSvc.Create(user.ID, dto.CreateProjectInput{ClientID: client.ID, Name: "Build", HourlyRate: 100})
+	timeSvc.Create(user.ID, dto.CreateTimeEntryInput{ProjectID: design.ID, Date: "2025-03-03", DurationSeconds: 3600, Billable: true})
+	timeSvc.Create(user.ID, dto.CreateTimeEntryInput{ProjectID: build.ID, Date: "2025-03-04", DurationSeconds: 3 * 3600, Billable: true})
+
+	// One invoice bills both projects, and a setup fee is added by hand.
+	inv := invoiceSvc.Create(user.ID, dto.CreateInvoiceInput{ClientID: client.ID, Number: "P-1", IssueDate: "2025-03-31", Status: "draft"})
+	var ids []int
+	for _, p := range []int{design.ID, build.ID} {
+		for _, e := range timeSvc.List(user.ID, p) {
+			ids = append(ids, e.ID)
+		}
+	}
+	out, err := invoiceSvc.SetTimeEntries(user.ID, dto.SetInvoiceTimeEntriesInput{InvoiceID: inv.ID, TimeEntryIDs: ids})
+	require.NoError(t, err)
+	items := []dto.InvoiceItemInput{{Description: "Setup fee", Quantity: 1, UnitPrice: 200, Amount: 200}}
+	for _, it := range out.Items {
+		items = append(items, dto.InvoiceItemInput{Description: it.Description, Quantity: it.Quantity, UnitPrice: it.UnitPrice, Amount: it.Amount})
+	}
+	invoiceSvc.Update(user.ID, dto.UpdateInvoiceInput{ID: inv.ID, ClientID: client.ID, Number: "P-1", IssueDate: "2025-03-31", Status: "draft",
+		Subtotal: out.Subtotal + 200, Total: out.Subtotal + 200, Items: items})
+
+	revenue := func(filter dto.ReportFilter) map[int]float64 {
+		report, err := NewReportService(db).GetProjectProfitability(user.ID, filter)
+		require.NoError(t, err)
+		byProject := map[int]float64{}
+		for _, r := range report.Rows {
+			byProject[r.ProjectID] = r.Revenue
+		}
+		return byProject
+	}
+	march := revenue(dto.ReportFilter{StartDate: "2025-03-01", EndDate: "2025-03-31"})
+	assert.InDelta(t, 150.0, march[design.ID], 0.001, "a quarter of the invoice, setup fee included")
+	assert.InDelta(t, 450.0, march[build.ID], 0.001)
+
+	// Revenue follows the invoice date, not the work date.
+	april := revenue(dto.ReportFilter{StartDate: "2025-04-01", EndDate: "2025-04-30"})
+	assert.Zero(t, april[design.ID])
+}
@@ -2,32 +2,62 @@ package services
 
 import (
 	"database/sql"
-	"encoding/csv"
 	"fmt"
 	"log"
-	"strconv"
+	"math"
 	"strings"
 	"time"
 
 	"tally/internal/dto"
-	"tally/internal/models"
 )
 
 // FinanceService handles all finance-related operations.
 type FinanceService struct {
-	db *sql.DB
+	db      *sql.DB
+	budgets BudgetAlerter
 }
 
-// NewFinanceService creates a new FinanceService instance.
-func NewFinanceService(db *sql.DB) *FinanceService {
-	return &FinanceService{db: db}
+// NewFinanceService creates a new FinanceService instance. budgets is told
+// when transactions are categorized; nil skips budget alerts.
+func NewFinanceService(db *sql.DB, budgets BudgetAlerter) *FinanceService {
+	return &FinanceService{db: db, budgets: budgets}
 }
 
 // --- Accounts ---
 
+// accountColumns selects an account (alias a) with its balances derived from
+// the opening balance and the transactions dated on or after the opening date.
+var accountColumns = `a.id, a.name, a.type, COALESCE(a.currency, ''), COALESCE(a.opening_balance, 0), COALESCE(a.opening_date, ''),
+  ` + accountBalanceSQL("") + `, ` + accountBalanceSQL("AND t.status IN ('cleared', 'reconciled')") + `,
+  COALESCE(a.bank_name, ''), COALESCE(a.updated_at, ''),
+  COALESCE((SELECT MAX(r.statement_date) FROM reconciliations r WHERE r.account_id = a.id AND r.status = 'completed'), '')`
+
+// accountBalanceSQL returns the balance of account a counting the
+// transactions that also meet filter.
+func accountBalanceSQL(filter string) string {
+	return `COALESCE(a.opening_balance, 0) + COALESCE((SELECT SUM(t.amount) FROM finance_transactions t
+    WHERE t.account_id = a.id AND (COALESCE(a.opening_date, '') = '' OR t.date >= a.opening_date) ` + filter + `), 0)`
+}
+
+func scanAccount(row rowScanner) (dto.AccountOutput, error) {
+	var acc dto.AccountOutput
+	var updatedAtStr string
+	err := row.Scan(&acc.ID, &acc.Name, &acc.Type, &acc.Currency, &acc.OpeningBalance, &acc.OpeningDate,
+		&acc.Balance, &acc.ClearedBalance, &acc.BankName, &updatedAtStr, &acc.LastReconciledDate)
+	if err != nil {
+		return acc, err
+	}
+	acc.Balance = math.Round(acc.Balance*100) / 100
+	acc.ClearedBalance = math.Round(acc.ClearedBalance*100) / 100
+	if t, err := time.Parse("2006-01-02 15:04:05", updatedAtStr); err == nil {
+		acc.UpdatedAt = t
+	}
+	return acc, nil
+}
+
 // GetAccounts returns all accounts for a user.
 func (s *FinanceService) GetAccounts(userID int) []dto.AccountOutput {
-	rows, err := s.db.Query("SELECT id, name, type, currency, balance, bank_name, updated_at FROM finance_accounts WHERE user_id = ?", userID)
+	rows, err := s.db.Query("SELECT "+accountColumns+" FROM finance_accounts a WHERE a.user_id = ?", userID)
 	if err != nil {
 		log.Println("Error querying finance accounts:", err)
 		return []dto.AccountOutput{}
@@ -36,76 +66,114 @@ func (s *FinanceService) GetAccounts(userID int) []dto.AccountOutput {
 
 	var accounts []dto.AccountOutput
 	for rows.Next() {
-		var acc dto.AccountOutput
-		var bankName sql.NullString
-		var updatedAtStr string
-		err := rows.Scan(&acc.ID, &acc.Name, &acc.Type, &acc.Currency, &acc.Balance, &bankName, &updatedAtStr)
+		acc, err := scanAccount(rows)
 		if err != nil {
 			log.Println("Error scanning finance account:", err)
 			continue
 		}
-		acc.BankName = bankName.String
-		if t, err := time.Parse("2006-01-02 15:04:05", updatedAtStr); err == nil {
-			acc.UpdatedAt = t
-		}
 		accounts = append(accounts, acc)
 	}
 	return accounts
 }
 
-// CreateAccount creates a new account.
+// GetAccount returns one account with its balances.
+func (s *FinanceService) GetAccount(userID int, id int) (dto.AccountOutput, error) {
+	acc, err := scanAccount(s.db.QueryRow("SELECT "+accountColumns+" FROM finance_accounts a WHERE a.id = ? AND a.user_id = ?", id, userID))
+	if err != nil {
+		return dto.AccountOutput{}, fmt.Errorf("account not found: %w", err)
+	}
+	return acc, nil
+}
+
+// normalizeOpeningDate validates an optional opening date as YYYY-MM-DD.
+func normalizeOpeningDate(date string) (interface{}, error) {
+	if strings.TrimSpace(date) == "" {
+		return nil, nil
+	}
+	d, err := parseDate(strings.TrimSpace(date))
+	if err != nil {
+		return nil, fmt.Errorf("invalid opening date: %w", err)
+	}
+	return d.Format("2006-01-02"), nil
+}
+
+// CreateAccount creates a new account. Its balance is the opening balance
+// plus the account's transactions.
 func (s *FinanceService) CreateAccount(userID int, input dto.CreateAccountInput) dto.AccountOutput {
-	stmt, err := s.db.Prepare("INSERT INTO finance_accounts(user_id, name, type, currency, balance, bank_name) VALUES(?, ?, ?, ?, ?, ?)")
+	openingDate, err := normalizeOpeningDate(input.OpeningDate)
+	if err != nil {
+		log.Println("Error creating finance account:", err)
+		return dto.AccountOutput{}
+	}
+	stmt, err := s.db.Prepare("INSERT INTO finance_accounts(user_id, name, type, currency, opening_balance, opening_date, bank_name) VALUES(?, ?, ?, ?, ?, ?, ?)")
 	if err != nil {
 		log.Println("Error preparing insert account:", err)
 		return dto.AccountOutput{}
 	}
 	defer stmt.Close()
 
-	res, err := stmt.Exec(userID, input.Name, input.Type, input.Currency, input.Balance, input.BankName)
+	res, err := stmt.Exec(userID, input.Name, input.Type, input.Currency, input.OpeningBalance, openingDate, input.BankName)
 	if err != nil {
 		log.Println("Error inserting finance account:", err)
 		return dto.AccountOutput{}
 	}
 
 	id, _ := res.LastInsertId()
-	return dto.AccountOutput{
-		ID:       int(id),
-		Name:     input.Name,
-		Type:     input.Type,
-		Currency: input.Currency,
-		Balance:  input.Balance,
-		BankName: input.BankName,
+	acc, err := s.GetAccount(userID, int(id))
+	if err != nil {
+		log.Println("Error loading finance account:", err)
+		return dto.AccountOutput{}
 	}
+	return acc
 }
 
 // UpdateAccount updates an existing account.
 func (s *FinanceService) UpdateAccount(userID int, input dto.UpdateAccountInput) dto.AccountOutput {
-	stmt, err := s.db.Prepare("UPDATE finance_accounts SET name=?, type=?, currency=?, balance=?, bank_name=?, updated_at=datetime('now') WHERE id=? AND user_id=?")
+	openingDate, err := normalizeOpeningDate(input.OpeningDate)
+	if err != nil {
+		log.Println("Error updating finance account:", err)
+		return dto.AccountOutput{}
+	}
+	// Reconciled balances build on the opening balance and date.
+	current, err := s.GetAccount(userID, input.ID)
+	if err != nil {
+		log.Println("Error updating finance account:", err)
+		return dto.AccountOutput{}
+	}
+	newDate, _ := openingDate.(string)
+	if current.OpeningBalance != input.OpeningBalance || current.OpeningDate != newDate {
+		if err := s.checkNotReconciled(userID, input.ID); err != nil {
+			log.Println("Error updating finance account:", err)
+			return dto.AccountOutput{}
+		}
+	}
+	stmt, err := s.db.Prepare("UPDATE finance_accounts SET name=?, type=?, currency=?, opening_balance=?, opening_date=?, bank_name=?, updated_at=datetime('now') WHERE id=? AND user_id=?")
 	if err != nil {
 		log.Println("Error preparing update account:", err)
 		return dto.AccountOutput{}
 	}
 	defer stmt.Close()
 
-	_, err = stmt.Exec(input.Name, input.Type, input.Currency, input.Balance, input.BankName, input.ID, userID)
+	_, err = stmt.Exec(input.Name, input.Type, input.Currency, input.OpeningBalance, openingDate, input.BankName, input.ID, userID)
 	if err != nil {
 		log.Println("Error updating finance account:", err)
 		return dto.AccountOutput{}
 	}
 
-	return dto.AccountOutput{
-		ID:       input.ID,
-		Name:     input.Name,
-		Type:     input.Type,
-		Currency: input.Currency,
-		Balance:  input.Balance,
-		BankName: input.BankName,
+	acc, err := s.GetAccount(userID, input.ID)
+	if err != nil {
+		log.Println("Error loading finance account:", err)
+		return dto.AccountOutput{}
 	}
+	return acc
 }
 
-// DeleteAccount deletes an account.
-func (s *FinanceService) DeleteAccount(userID int, id int) {
+// DeleteAccount deletes an account and its transactions. Accounts with
+// completed reconciliations are kept; reopen them first.
+func (s *FinanceService) DeleteAccount(userID int, id int) error {
+	if err := s.checkNotReconciled(userID, id); err != nil {
+		return err
+	}
 	// First delete transactions associated with this account to avoid constraints if any
 	// (Though foreign key cascade might handle this, SQLite needs PRAGMA foreign_keys=ON)
 	_, _ = s.db.Exec("DELETE FROM finance_transactions WHERE account_id=? AND user_id=?", id, userID)
@@ -113,7 +181,23 @@ func (s *FinanceService) DeleteAccount(userID int, id int) {
 	_, err := s.db.Exec("DELETE FROM finance_accounts WHERE id=? AND user_id=?", id, userID)
 	if err != nil {
 		log.Println("Error deleting finance account:", err)
+		return fmt.Errorf("failed to delete account: %w", err)
+	}
+	return nil
+}
+
+// checkNotReconciled refuses changes that would rewrite balances of an
+// account that has completed reconciliations.
+func (s *FinanceService) checkNotReconciled(userID int, accountID int) error {
+	var completed int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM reconciliations WHERE user_id = ? AND account_id = ? AND status = ?",
+		userID, accountID, reconciliationCompleted).Scan(&completed); err != nil {
+		return fmt.Errorf("failed to check reconciliations: %w", err)
 	}
+	if completed > 0 {
+		return fmt.Errorf("the account has %d completed reconciliation(s); reopen them first", completed)
+	}
+	return nil
 }
 
 // --- Categories ---
@@ -208,7 +292,9 @@ func (s *FinanceService) DeleteCategory(userID int, id int) {
 // GetTransactions returns filtered transactions.
 func (s *FinanceService) GetTransactions(userID int, filter dto.TransactionFilter) []dto.TransactionOutput {
 	query := `
-		SELECT t.id, t.account_id, t.category_id, c.name, c.color, t.date, t.description, t.amount, t.status, t.reference_id
+		SELECT t.id, t.account_id, t.category_id, c.name, c.color, t.date, t.description, t.amount, t.status, t.reference_id,
+		       t.client_id, t.project_id, t.expense_type, t.invoice_id, t.possible_duplicate_of_id, t.transfer_id, ` + entityTagsSQL("transaction", "t.id") + `,
+		       (SELECT COUNT(*) FROM attachments a WHERE a.entity_type = 'transaction' AND a.entity_id = t.id)
 		FROM finance_transactions t
 		LEFT JOIN finance_categories c ON t.category_id = c.id
 		WHERE t.user_id = ?
@@ -220,6 +306,16 @@ func (s *FinanceService) GetTransactions(userID int, filter dto.TransactionFilte
 		args = append(args, filter.AccountID)
 	}
 
+	if filter.ClientID > 0 {
+		query += " AND t.client_id = ?"
+		args = append(args, filter.ClientID)
+	}
+
+	if filter.ProjectID > 0 {
+		query += " AND t.project_id = ?"
+		args = append(args, filter.ProjectID)
+	}
+
 	if filter.StartDate != "" {
 		query += " AND t.date >= ?"
 		args = append(args, filter.StartDate)
@@ -230,6 +326,20 @@ func (s *FinanceService) GetTransactions(userID int, filter dto.TransactionFilte
 		args = append(args, filter.EndDate)
 	}
 
+	if filter.NeedsReview {
+		query += " AND t.possible_duplicate_of_id IS NOT NULL"
+	}
+
+	if filter.ExcludeTransfers {
+		query += " AND t.transfer_id IS NULL"
+	}
+
+	if len(filter.Tags) > 0 {
+		tagClause, tagArgs := tagFilterSQL("transaction", "t.id", userID, filter.Tags)
+		query += " AND " + tagClause
+		args = append(args, tagArgs...)
+	}
+
 	query += " ORDER BY t.date DESC"
 
 	rows, err := s.db.Query(query, args...)
@@ -242,11 +352,12 @@ func (s *FinanceService) GetTransactions(userID int, filter dto.TransactionFilte
 	var transactions []dto.TransactionOutput
 	for rows.Next() {
 		var t dto.TransactionOutput
-		var catID sql.NullInt64
-		var catName, catColor, refID sql.NullString
+		var catID, clientID, projectID, invoiceID, duplicateOfID, transferID sql.NullInt64
+		var catName, catColor, refID, expenseType, tags sql.NullString
 		var dateStr string
 
-		err := rows.Scan(&t.ID, &t.AccountID, &catID, &catName, &catColor, &dateStr, &t.Description, &t.Amount, &t.Status, &refID)
+		err := rows.Scan(&t.ID, &t.AccountID, &catID, &catName, &catColor, &dateStr, &t.Description, &t.Amount, &t.Status, &refID,
+			&clientID, &projectID, &expenseType, &invoiceID, &duplicateOfID, &transferID, &tags, &t.AttachmentCount)
 		if err != nil {
 			log.Println("Error scanning transaction:", err)
 			continue
@@ -259,6 +370,13 @@ func (s *FinanceService) GetTransactions(userID int, filter dto.TransactionFilte
 			t.CategoryColor = catColor.String
 		}
 		t.ReferenceID = refID.String
+		t.ClientID = nullIntPtr(clientID)
+		t.ProjectID = nullIntPtr(projectID)
+		t.ExpenseType = expenseType.String
+		t.InvoiceID = nullIntPtr(invoiceID)
+		t.PossibleDuplicateOfID = nullIntPtr(duplicateOfID)
+		t.TransferID = nullIntPtr(transferID)
+		t.Tags = splitTags(tags.String)
 		if date, err := time.Parse("2006-01-02", dateStr); err == nil {
 			t.Date = date
 		} else if date, err := time.Parse("2006-01-02 15:04:05", dateStr); err == nil {
@@ -269,194 +387,139 @@ func (s *FinanceService) GetTransactions(userID int, filter dto.TransactionFilte
 
 		transactions = append(transactions, t)
 	}
+
+	ids := make([]int, len(transactions))
+	for i, t := range transactions {
+		ids[i] = t.ID
+	}
+	splits, err := s.loadSplits(userID, ids)
+	if err != nil {
+		log.Println("Error loading transaction splits:", err)
+		return transactions
+	}
+	for i := range transactions {
+		transactions[i].Splits = splits[transactions[i].ID]
+	}
 	return transactions
 }
 
 // UpdateTransaction updates a transaction (mainly for categorization).
+// Setting a category replaces any splits.
 func (s *FinanceService) UpdateTransaction(userID int, transactionID int, categoryID *int) {
-	query := "UPDATE finance_transactions SET category_id=?, updated_at=datetime('now') WHERE id=? AND user_id=?"
-	_, err := s.db.Exec(query, categoryID, transactionID, userID)
+	query := "UPDATE finance_transactions SET category_id=?, category_rule_id=NULL, updated_at=datetime('now') WHERE id=? AND user_id=?"
+	res, err := s.db.Exec(query, categoryID, transactionID, userID)
 	if err != nil {
 		log.Println("Error updating transaction:", err)
+		return
 	}
-}
-
-// DeleteTransaction deletes a transaction.
-func (s *FinanceService) DeleteTransaction(userID int, id int) {
-	_, err := s.db.Exec("DELETE FROM finance_transactions WHERE id=? AND user_id=?", id, userID)
-	if err != nil {
-		log.Println("Error deleting transaction:", err)
+	if n, _ := res.RowsAffected(); n > 0 && categoryID != nil {
+		if _, err := s.db.Exec("DELETE FROM transaction_splits WHERE transaction_id=? AND user_id=?", transactionID, userID); err != nil {
+			log.Println("Error clearing transaction splits:", err)
+		}
+		checkBudgets(s.budgets, userID)
 	}
 }
 
-// ImportTransactions parses and saves transactions from CSV.
-func (s *FinanceService) ImportTransactions(userID int, input dto.ImportTransactionsInput) (int, error) {
-	reader := csv.NewReader(strings.NewReader(input.FileContent))
-	records, err := reader.ReadAll()
+// AllocateExpense tags a transaction as a reimbursable or non-reimbursable expense
+// of a client or project. Allocating to a project also records the project's client.
+func (s *FinanceService) AllocateExpense(userID int, input dto.AllocateExpenseInput) error {
+	var billedInvoice sql.NullInt64
+	err := s.db.QueryRow("SELECT invoice_id FROM finance_transactions WHERE id=? AND user_id=?", input.TransactionID, userID).Scan(&billedInvoice)
 	if err != nil {
-		return 0, fmt.Errorf("failed to parse CSV: %v", err)
+		return fmt.Errorf("transaction not found: %w", err)
 	}
-
-	if len(records) < 1 {
-		return 0, fmt.Errorf("empty CSV file")
+	if billedInvoice.Valid {
+		return fmt.Errorf("transaction is already billed on an invoice")
 	}
 
-	var transactions []models.FinanceTransaction
-	bankType := strings.ToUpper(input.BankType)
-
-	// Simple logic: Skip header usually.
-	// But we need to identify columns.
+	if input.ExpenseType == "" {
+		_, err := s.db.Exec("UPDATE finance_transactions SET client_id=NULL, project_id=NULL, expense_type=NULL, updated_at=datetime('now') WHERE id=? AND user_id=?", input.TransactionID, userID)
+		return err
+	}
+	if input.ExpenseType != "reimbursable" && input.ExpenseType != "non_reimbursable" {
+		return fmt.Errorf("unknown expense type: %s", input.ExpenseType)
+	}
 
-	for i, row := range records {
-		// Heuristic: Skip header row if it contains "Date" or "Description"
-		if i == 0 {
-			rowStr := strings.Join(row, ",")
-			if strings.Contains(strings.ToLower(rowStr), "date") || strings.Contains(strings.ToLower(rowStr), "description") {
-				continue
-			}
+	clientID := input.ClientID
+	if input.ProjectID > 0 {
+		if err := s.db.QueryRow("SELECT client_id FROM projects WHERE id=? AND user_id=?", input.ProjectID, userID).Scan(&clientID); err != nil {
+			return fmt.Errorf("project not found: %w", err)
 		}
-
-		t, err := s.parseRow(row, bankType)
-		if err != nil {
-			// Log error but continue? or fail?
-			// For now, log and skip
-			log.Printf("Skipping row %d: %v", i, err)
-			continue
+	} else if clientID > 0 {
+		var exists int
+		if err := s.db.QueryRow("SELECT COUNT(*) FROM clients WHERE id=? AND user_id=?", clientID, userID).Scan(&exists); err != nil || exists == 0 {
+			return fmt.Errorf("client not found")
 		}
-		t.UserID = userID
-		t.AccountID = input.AccountID
-		transactions = append(transactions, t)
+	} else {
+		return fmt.Errorf("expense requires a client or project")
 	}
 
-	count := 0
-	tx, err := s.db.Begin()
+	_, err = s.db.Exec(
+		"UPDATE finance_transactions SET client_id=?, project_id=?, expense_type=?, updated_at=datetime('now') WHERE id=? AND user_id=?",
+		clientID, nullableID(input.ProjectID), input.ExpenseType, input.TransactionID, userID,
+	)
 	if err != nil {
-		return 0, err
+		return fmt.Errorf("failed to allocate expense: %w", err)
 	}
-	defer tx.Rollback()
+	return nil
+}
 
-	stmt, err := tx.Prepare("INSERT INTO finance_transactions(user_id, account_id, date, description, amount, status, reference_id) VALUES(?, ?, ?, ?, ?, ?, ?)")
+// DeleteTransaction deletes a transaction. Reconciled transactions are locked.
+func (s *FinanceService) DeleteTransaction(userID int, id int) error {
+	res, err := s.db.Exec("DELETE FROM finance_transactions WHERE id=? AND user_id=? AND COALESCE(status, '') != 'reconciled'", id, userID)
 	if err != nil {
-		return 0, err
+		return fmt.Errorf("failed to delete transaction: %w", err)
 	}
-	defer stmt.Close()
-
-	for _, t := range transactions {
-		// Deduplication check: Same Date, Description, Amount
-		// Note: This is a weak check. ReferenceID is better if available.
-		// For MVP, we just insert. Or we can check existence.
-		// Let's implement a simple existence check.
-		var exists int
-		checkQuery := "SELECT COUNT(*) FROM finance_transactions WHERE user_id=? AND account_id=? AND date=? AND description=? AND amount=?"
-		err := tx.QueryRow(checkQuery, userID, input.AccountID, t.Date.Format("2006-01-02"), t.Description, t.Amount).Scan(&exists)
-		if err == nil && exists > 0 {
-			continue
-		}
-
-		_, err = stmt.Exec(userID, input.AccountID, t.Date.Format("2006-01-02"), t.Description, t.Amount, "imported", t.ReferenceID)
-		if err != nil {
-			log.Printf("Failed to insert transaction: %v", err)
-			continue
-		}
-		count++
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("transaction not found or reconciled")
 	}
+	return nil
+}
 
-	if err := tx.Commit(); err != nil {
+// ImportTransactions parses and saves transactions from a CSV, OFX/QFX or
+// CAMT.053 file in one step, importing every new row as a batch. CSV files are
+// read with the saved import profile, or the built-in layout named by the bank
+// type. Use TransactionImportService to review rows before importing.
+func (s *FinanceService) ImportTransactions(userID int, input dto.ImportTransactionsInput) (int, error) {
+	imports := NewTransactionImportService(s.db, s.budgets)
+	rows, format, balances, err := imports.classify(userID, input)
+	if err != nil {
 		return 0, err
 	}
-
-	return count, nil
-}
-
-func (s *FinanceService) parseRow(row []string, bankType string) (models.FinanceTransaction, error) {
-	var t models.FinanceTransaction
-	var err error
-
-	switch bankType {
-	case "CIBC":
-		// CIBC Export: Date, Description, Debit, Credit, CardName, CardNumber
-		// Example: 2024-01-01,AMAZON.CA,-10.00,,...
-		// OR: 2024-01-01,DEPOSIT,,,1000.00,...
-		if len(row) < 4 {
-			return t, fmt.Errorf("not enough columns for CIBC")
-		}
-
-		t.Date, err = parseDate(row[0])
-		if err != nil {
-			return t, err
-		}
-		t.Description = row[1]
-
-		debit := parseAmount(row[2])
-		credit := parseAmount(row[3])
-
-		if credit > 0 {
-			t.Amount = credit // Income
-		} else if debit != 0 {
-			// CIBC debits might be positive or negative in CSV?
-			// Usually in CIBC CSV: "Debit" column contains the amount spent.
-			// "Credit" column contains amount received.
-			// Let's assume Debit is positive number for expense -> we make it negative.
-			if debit > 0 {
-				t.Amount = -debit
-			} else {
-				t.Amount = debit // If already negative
-			}
-		}
-
-	case "RBC":
-		// RBC Export: Account Type, Account Number, Transaction Date, Cheque Number, Description 1, Description 2, CAD$, USD$
-		// Index: 0=Type, 1=Num, 2=Date, 3=Cheque, 4=Desc1, 5=Desc2, 6=CAD, 7=USD
-		if len(row) < 7 {
-			return t, fmt.Errorf("not enough columns for RBC")
-		}
-
-		t.Date, err = parseDate(row[2])
-		if err != nil {
-			return t, err
-		}
-		t.Description = row[4] + " " + row[5]
-		t.Amount = parseAmount(row[6]) // RBC uses negative for expense
-
-	case "TD":
-		// TD Export: Date, Description, Withdrawal, Deposit, Balance
-		if len(row) < 4 {
-			return t, fmt.Errorf("not enough columns for TD")
+	for _, b := range balances {
+		if err := b.check(); err != nil {
+			return 0, err
 		}
+	}
 
-		t.Date, err = parseDate(row[0])
-		if err != nil {
-			return t, err
+	var fresh []classifiedRow
+	for _, row := range rows {
+		if row.Status == importStatusError {
+			log.Printf("Skipping row %d: %s", row.Line, row.Reason)
 		}
-		t.Description = row[1]
-
-		withdrawal := parseAmount(row[2])
-		deposit := parseAmount(row[3])
-
-		if deposit > 0 {
-			t.Amount = deposit
-		} else {
-			if withdrawal > 0 {
-				t.Amount = -withdrawal
-			} else {
-				t.Amount = withdrawal
-			}
+		// Near-duplicates are imported flagged rather than silently dropped.
+		if row.Status == importStatusNew || row.Status == importStatusReview {
+			fresh = append(fresh, row)
 		}
+	}
+	if len(fresh) == 0 {
+		return 0, nil
+	}
 
-	default:
-		// Generic fallback: Date, Description, Amount
-		if len(row) < 3 {
-			return t, fmt.Errorf("not enough columns for Generic")
-		}
-		t.Date, err = parseDate(row[0])
-		if err != nil {
-			return t, err
-		}
-		t.Description = row[1]
-		t.Amount = parseAmount(row[2])
+	batch, err := imports.insertBatch(userID, input, format, len(rows), fresh, true)
+	if err != nil {
+		return 0, err
 	}
+	return batch.ImportedCount, nil
+}
 
-	return t, nil
+// nullIntPtr converts a nullable integer column into an optional DTO field.
+func nullIntPtr(v sql.NullInt64) *int {
+	if !v.Valid {
+		return nil
+	}
+	id := int(v.Int64)
+	return &id
 }
 
 func parseDate(s string) (time.Time, error) {
@@ -469,46 +532,28 @@ func parseDate(s string) (time.Time, error) {
 	return time.Time{}, fmt.Errorf("invalid date format: %s", s)
 }
 
-func parseAmount(s string) float64 {
-	if s == "" {
-		return 0
-	}
-	// Remove currency symbols and commas
-	clean := strings.ReplaceAll(s, "$", "")
-	clean = strings.ReplaceAll(clean, ",", "")
-	clean = strings.TrimSpace(clean)
-	val, _ := strconv.ParseFloat(clean, 64)
-	return val
-}
-
-// GetSummary returns finance summary.
+// GetSummary returns the all-time finance summary.
 func (s *FinanceService) GetSummary(userID int) dto.FinanceSummary {
 	var summary dto.FinanceSummary
 
-	// Total Balance from Accounts
-	row := s.db.QueryRow("SELECT COALESCE(SUM(balance), 0) FROM finance_accounts WHERE user_id = ?", userID)
+	// Total Balance from Accounts, each derived from its transactions
+	row := s.db.QueryRow("SELECT COALESCE(SUM("+accountBalanceSQL("")+"), 0) FROM finance_accounts a WHERE a.user_id = ?", userID)
 	_ = row.Scan(&summary.TotalBalance)
+	summary.TotalBalance = math.Round(summary.TotalBalance*100) / 100
 
-	// Income and Expense from Transactions (last 30 days? or all time?)
-	// Let's do All Time for now, or maybe month to date?
-	// User request didn't specify, but dashboard usually implies current context.
-	// Let's do ALL TIME for simplicty of the SQL, or maybe filtered by current month.
-	// Let's do All Time to match the Total Balance logic.
-
-	row = s.db.QueryRow("SELECT COALESCE(SUM(amount), 0) FROM finance_transactions WHERE user_id = ? AND amount > 0", userID)
+	// Income and expense are all-time, matching the balance; GetPeriodSummary
+	// covers a month, quarter, year or custom range.
+	// Splits count separately, so a purchase with a refunded part adds to both.
+	// Transfers between the user's accounts are neither.
+	row = s.db.QueryRow("SELECT COALESCE(SUM(amount), 0) FROM ("+transactionLinesSQL+") l WHERE l.user_id = ? AND l.transfer_id IS NULL AND l.amount > 0", userID)
 	_ = row.Scan(&summary.TotalIncome)
 
-	row = s.db.QueryRow("SELECT COALESCE(SUM(amount), 0) FROM finance_transactions WHERE user_id = ? AND amount < 0", userID)
+	row = s.db.QueryRow("SELECT COALESCE(SUM(amount), 0) FROM ("+transactionLinesSQL+") l WHERE l.user_id = ? AND l.transfer_id IS NULL AND l.amount < 0", userID)
 	_ = row.Scan(&summary.TotalExpense)
 
-	// Expense is usually shown as positive number in UI if labeled "Expense", but here we return raw sums.
-	// Actually, let's keep it signed. expense will be negative.
-
 	summary.CashFlow = summary.TotalIncome + summary.TotalExpense
 
-	// If expense is negative, we might want to flip it for display if the UI expects positive "Total Expense"
-	// But let's leave it to frontend.
-	// Wait, the UI mock shows "Total Expense" separately. Usually people expect to see "$500" not "$-500".
+	// Expense is reported as a positive amount.
 	if summary.TotalExpense < 0 {
 		summary.TotalExpense = -summary.TotalExpense
 	}
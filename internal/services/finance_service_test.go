@@ -8,58 +8,9 @@ import (
 	"tally/internal/dto"
 )
 
+// setupFinanceTestDB returns the shared schema, which includes the finance tables.
 func setupFinanceTestDB(t *testing.T) *sql.DB {
-	db := setupFullTestDB(t)
-
-	queries := []string{
-		`CREATE TABLE IF NOT EXISTS finance_accounts (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			user_id INTEGER NOT NULL,
-			name TEXT NOT NULL,
-			type TEXT NOT NULL,
-			currency TEXT DEFAULT 'CAD',
-			balance REAL DEFAULT 0,
-			bank_name TEXT,
-			created_at TEXT DEFAULT (datetime('now')),
-			updated_at TEXT DEFAULT (datetime('now')),
-			FOREIGN KEY(user_id) REFERENCES users(id)
-		);`,
-		`CREATE TABLE IF NOT EXISTS finance_categories (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			user_id INTEGER NOT NULL,
-			name TEXT NOT NULL,
-			type TEXT NOT NULL,
-			color TEXT,
-			icon TEXT,
-			created_at TEXT DEFAULT (datetime('now')),
-			updated_at TEXT DEFAULT (datetime('now')),
-			FOREIGN KEY(user_id) REFERENCES users(id)
-		);`,
-		`CREATE TABLE IF NOT EXISTS finance_transactions (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			user_id INTEGER NOT NULL,
-			account_id INTEGER NOT NULL,
-			category_id INTEGER,
-			date TEXT NOT NULL,
-			description TEXT NOT NULL,
-			amount REAL NOT NULL,
-			status TEXT DEFAULT 'pending',
-			reference_id TEXT,
-			created_at TEXT DEFAULT (datetime('now')),
-			updated_at TEXT DEFAULT (datetime('now')),
-			FOREIGN KEY(user_id) REFERENCES users(id),
-			FOREIGN KEY(account_id) REFERENCES finance_accounts(id),
-			FOREIGN KEY(category_id) REFERENCES finance_categories(id)
-		);`,
-	}
-
-	for _, query := range queries {
-		if _, err := db.Exec(query); err != nil {
-			t.Fatalf("failed to create finance table: %v", err)
-		}
-	}
-
-	return db
+	return setupFullTestDB(t)
 }
 
 func TestFinanceService_Accounts(t *testing.T) {
@@ -69,15 +20,15 @@ func TestFinanceService_Accounts(t *testing.T) {
 	authService := NewAuthService(db)
 	user := createTestUser(t, authService, "finance_user")
 
-	service := NewFinanceService(db)
+	service := NewFinanceService(db, nil)
 
 	// Test CreateAccount
 	input := dto.CreateAccountInput{
-		Name:     "TD Checking",
-		Type:     "checking",
-		Currency: "CAD",
-		Balance:  1000.50,
-		BankName: "TD",
+		Name:           "TD Checking",
+		Type:           "checking",
+		Currency:       "CAD",
+		OpeningBalance: 1000.50,
+		BankName:       "TD",
 	}
 
 	acc := service.CreateAccount(user.ID, input)
@@ -99,12 +50,12 @@ func TestFinanceService_Accounts(t *testing.T) {
 
 	// Test UpdateAccount
 	updateInput := dto.UpdateAccountInput{
-		ID:       acc.ID,
-		Name:     "TD Checking Updated",
-		Type:     "checking",
-		Currency: "CAD",
-		Balance:  2000.00,
-		BankName: "TD",
+		ID:             acc.ID,
+		Name:           "TD Checking Updated",
+		Type:           "checking",
+		Currency:       "CAD",
+		OpeningBalance: 2000.00,
+		BankName:       "TD",
 	}
 	updatedAcc := service.UpdateAccount(user.ID, updateInput)
 	if updatedAcc.Name != "TD Checking Updated" {
@@ -115,7 +66,9 @@ func TestFinanceService_Accounts(t *testing.T) {
 	}
 
 	// Test DeleteAccount
-	service.DeleteAccount(user.ID, acc.ID)
+	if err := service.DeleteAccount(user.ID, acc.ID); err != nil {
+		t.Fatalf("delete account failed: %v", err)
+	}
 	accounts = service.GetAccounts(user.ID)
 	if len(accounts) != 0 {
 		t.Errorf("expected 0 accounts after delete, got %d", len(accounts))
@@ -128,7 +81,7 @@ func TestFinanceService_Categories(t *testing.T) {
 
 	authService := NewAuthService(db)
 	user := createTestUser(t, authService, "cat_user")
-	service := NewFinanceService(db)
+	service := NewFinanceService(db, nil)
 
 	input := dto.CreateCategoryInput{
 		Name:  "Groceries",
@@ -160,7 +113,7 @@ func TestFinanceService_ImportTransactions(t *testing.T) {
 
 	authService := NewAuthService(db)
 	user := createTestUser(t, authService, "import_user")
-	service := NewFinanceService(db)
+	service := NewFinanceService(db, nil)
 
 	// Create account
 	accInput := dto.CreateAccountInput{Name: "TD", Type: "checking", Currency: "CAD"}
@@ -221,10 +174,10 @@ func TestFinanceService_Summary(t *testing.T) {
 
 	authService := NewAuthService(db)
 	user := createTestUser(t, authService, "summary_user")
-	service := NewFinanceService(db)
+	service := NewFinanceService(db, nil)
 
 	// Create account with balance 5000
-	service.CreateAccount(user.ID, dto.CreateAccountInput{Name: "Acc1", Balance: 5000})
+	service.CreateAccount(user.ID, dto.CreateAccountInput{Name: "Acc1", OpeningBalance: 5000})
 
 	// Add transactions manually (SQL) because Import is complex and we verified it.
 	// Income: 2000
@@ -241,8 +194,9 @@ func TestFinanceService_Summary(t *testing.T) {
 	}
 
 	summary := service.GetSummary(user.ID)
-	if summary.TotalBalance != 5000 {
-		t.Errorf("expected total balance 5000, got %f", summary.TotalBalance)
+	// The balance is derived: opening 5000 + 2000 - 500.
+	if summary.TotalBalance != 6500 {
+		t.Errorf("expected total balance 6500, got %f", summary.TotalBalance)
 	}
 	if summary.TotalIncome != 2000 {
 		t.Errorf("expected total income 2000, got %f", summary.TotalIncome)
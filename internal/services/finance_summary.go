@@ -0,0 +1,250 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"tally/internal/dto"
+	"time"
+)
+
+// Summary periods.
+const (
+	periodMonth   = "month"
+	periodQuarter = "quarter"
+	periodYear    = "year"
+	periodCustom  = "custom"
+)
+
+// GetPeriodSummary returns income, expenses, cash flow and the closing balance
+// for a month, quarter, year or custom range, compared with the period of
+// the same length just before it.
+func (s *FinanceService) GetPeriodSummary(userID int, input dto.SummaryPeriodInput) (dto.PeriodSummaryOutput, error) {
+	period := nonEmptyOr(input.Period, periodMonth)
+	start, end, err := summaryPeriod(period, input)
+	if err != nil {
+		return dto.PeriodSummaryOutput{}, err
+	}
+	var prevStart, prevEnd time.Time
+	if period == periodCustom {
+		days := int(end.Sub(start).Hours()/24) + 1
+		prevEnd = start.AddDate(0, 0, -1)
+		prevStart = prevEnd.AddDate(0, 0, 1-days)
+	} else {
+		months := monthsBetween(start, end) + 1
+		prevStart = start.AddDate(0, -months, 0)
+		prevEnd = start.AddDate(0, 0, -1)
+	}
+
+	out := dto.PeriodSummaryOutput{Period: period}
+	if out.Current, err = s.periodTotals(userID, start, end); err != nil {
+		return dto.PeriodSummaryOutput{}, err
+	}
+	if out.Previous, err = s.periodTotals(userID, prevStart, prevEnd); err != nil {
+		return dto.PeriodSummaryOutput{}, err
+	}
+	out.Change = dto.PeriodChange{
+		Income:          math.Round((out.Current.Income-out.Previous.Income)*100) / 100,
+		Expense:         math.Round((out.Current.Expense-out.Previous.Expense)*100) / 100,
+		CashFlow:        math.Round((out.Current.CashFlow-out.Previous.CashFlow)*100) / 100,
+		IncomePercent:   percentChange(out.Previous.Income, out.Current.Income),
+		ExpensePercent:  percentChange(out.Previous.Expense, out.Current.Expense),
+		CashFlowPercent: percentChange(out.Previous.CashFlow, out.Current.CashFlow),
+	}
+	return out, nil
+}
+
+// GetCashFlowSeries returns monthly income, expense and net in total and per
+// account and category, with every series covering every month.
+func (s *FinanceService) GetCashFlowSeries(userID int, input dto.CashFlowSeriesInput) (dto.CashFlowSeriesOutput, error) {
+	now := time.Now()
+	first := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	start, end := first.AddDate(0, -11, 0), first.AddDate(0, 1, -1)
+	if input.StartDate != "" {
+		d, err := parseDate(input.StartDate)
+		if err != nil {
+			return dto.CashFlowSeriesOutput{}, fmt.Errorf("invalid start date: %w", err)
+		}
+		start = d
+	}
+	if input.EndDate != "" {
+		d, err := parseDate(input.EndDate)
+		if err != nil {
+			return dto.CashFlowSeriesOutput{}, fmt.Errorf("invalid end date: %w", err)
+		}
+		end = d
+	}
+	if end.Before(start) {
+		return dto.CashFlowSeriesOutput{}, fmt.Errorf("the end date is before the start date")
+	}
+
+	out := dto.CashFlowSeriesOutput{}
+	index := map[string]int{}
+	for m := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.UTC); !m.After(end); m = m.AddDate(0, 1, 0) {
+		index[m.Format("2006-01")] = len(out.Months)
+		out.Months = append(out.Months, m.Format("2006-01"))
+	}
+	n := len(out.Months)
+	out.Income, out.Expense, out.Net = make([]float64, n), make([]float64, n), make([]float64, n)
+
+	query := `SELECT substr(l.date, 1, 7), l.account_id, COALESCE(a.name, ''), COALESCE(c.id, 0), COALESCE(c.name, 'Uncategorized'), COALESCE(c.color, ''),
+       COALESCE(SUM(CASE WHEN l.amount > 0 THEN l.amount END), 0),
+       COALESCE(SUM(CASE WHEN l.amount < 0 THEN -l.amount END), 0)
+FROM (` + transactionLinesSQL + `) l
+LEFT JOIN finance_accounts a ON a.id = l.account_id
+LEFT JOIN finance_categories c ON c.id = l.category_id AND c.user_id = l.user_id
+WHERE l.user_id = ? AND l.transfer_id IS NULL AND substr(l.date, 1, 10) BETWEEN ? AND ?`
+	args := []interface{}{userID, start.Format("2006-01-02"), end.Format("2006-01-02")}
+	if input.AccountID > 0 {
+		query += " AND l.account_id = ?"
+		args = append(args, input.AccountID)
+	}
+	if input.CategoryID > 0 {
+		query += " AND l.category_id = ?"
+		args = append(args, input.CategoryID)
+	}
+	// #nosec G202 -- the query is built from fixed predicates with parameter binding.
+	rows, err := s.db.Query(query+" GROUP BY 1, 2, 4", args...)
+	if err != nil {
+		return dto.CashFlowSeriesOutput{}, fmt.Errorf("failed to query cash flow: %w", err)
+	}
+	defer closeWithLog(rows, "closing cash flow rows")
+
+	accounts := map[int]*dto.CashFlowSeries{}
+	categories := map[int]*dto.CashFlowSeries{}
+	series := func(m map[int]*dto.CashFlowSeries, id int, name string, color string) *dto.CashFlowSeries {
+		if m[id] == nil {
+			m[id] = &dto.CashFlowSeries{ID: id, Name: name, Color: color, Income: make([]float64, n), Expense: make([]float64, n), Net: make([]float64, n)}
+		}
+		return m[id]
+	}
+	for rows.Next() {
+		var month, accountName, categoryName, categoryColor string
+		var accountID, categoryID int
+		var income, expense float64
+		if err := rows.Scan(&month, &accountID, &accountName, &categoryID, &categoryName, &categoryColor, &income, &expense); err != nil {
+			return dto.CashFlowSeriesOutput{}, fmt.Errorf("failed to scan cash flow: %w", err)
+		}
+		i, ok := index[month]
+		if !ok {
+			continue
+		}
+		for _, sr := range []*dto.CashFlowSeries{series(accounts, accountID, accountName, ""), series(categories, categoryID, categoryName, categoryColor)} {
+			sr.Income[i] += income
+			sr.Expense[i] += expense
+		}
+		out.Income[i] += income
+		out.Expense[i] += expense
+	}
+	if err := rows.Err(); err != nil {
+		return dto.CashFlowSeriesOutput{}, err
+	}
+
+	roundSeries(out.Income, out.Expense, out.Net)
+	out.ByAccount = sortedSeries(accounts)
+	out.ByCategory = sortedSeries(categories)
+	return out, nil
+}
+
+// periodTotals sums income and expenses between two days, inclusive, and
+// the balance of all accounts at the end.
+func (s *FinanceService) periodTotals(userID int, start time.Time, end time.Time) (dto.PeriodTotals, error) {
+	t := dto.PeriodTotals{StartDate: start.Format("2006-01-02"), EndDate: end.Format("2006-01-02")}
+	err := s.db.QueryRow(`SELECT COALESCE(SUM(CASE WHEN l.amount > 0 THEN l.amount END), 0), COALESCE(SUM(CASE WHEN l.amount < 0 THEN -l.amount END), 0)
+FROM (`+transactionLinesSQL+`) l WHERE l.user_id = ? AND l.transfer_id IS NULL AND substr(l.date, 1, 10) BETWEEN ? AND ?`,
+		userID, t.StartDate, t.EndDate).Scan(&t.Income, &t.Expense)
+	if err != nil {
+		return t, fmt.Errorf("failed to sum transactions: %w", err)
+	}
+	// Accounts opened after the period had no balance yet.
+	err = s.db.QueryRow("SELECT COALESCE(SUM("+accountBalanceSQL("AND substr(t.date, 1, 10) <= ?")+`), 0) FROM finance_accounts a
+WHERE a.user_id = ? AND (COALESCE(a.opening_date, '') = '' OR a.opening_date <= ?)`, t.EndDate, userID, t.EndDate).Scan(&t.ClosingBalance)
+	if err != nil {
+		return t, fmt.Errorf("failed to compute closing balance: %w", err)
+	}
+	t.Income = math.Round(t.Income*100) / 100
+	t.Expense = math.Round(t.Expense*100) / 100
+	t.CashFlow = math.Round((t.Income-t.Expense)*100) / 100
+	t.ClosingBalance = math.Round(t.ClosingBalance*100) / 100
+	return t, nil
+}
+
+// summaryPeriod returns the first and last day of the requested period.
+func summaryPeriod(period string, input dto.SummaryPeriodInput) (time.Time, time.Time, error) {
+	if period == periodCustom {
+		start, err := parseDate(input.StartDate)
+		if err != nil {
+			return start, start, fmt.Errorf("invalid start date: %w", err)
+		}
+		end, err := parseDate(input.EndDate)
+		if err != nil {
+			return start, end, fmt.Errorf("invalid end date: %w", err)
+		}
+		if end.Before(start) {
+			return start, end, fmt.Errorf("the end date is before the start date")
+		}
+		return start, end, nil
+	}
+
+	anchor := time.Now()
+	if input.Date != "" {
+		d, err := parseDate(input.Date)
+		if err != nil {
+			return d, d, fmt.Errorf("invalid date: %w", err)
+		}
+		anchor = d
+	}
+	var start time.Time
+	months := 1
+	switch period {
+	case periodMonth:
+		start = time.Date(anchor.Year(), anchor.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case periodQuarter:
+		start = time.Date(anchor.Year(), (anchor.Month()-1)/3*3+1, 1, 0, 0, 0, 0, time.UTC)
+		months = 3
+	case periodYear:
+		start = time.Date(anchor.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+		months = 12
+	default:
+		return anchor, anchor, fmt.Errorf("unknown period: %s", period)
+	}
+	return start, start.AddDate(0, months, -1), nil
+}
+
+// percentChange is the change from prev to curr in percent of prev's size,
+// rounded to one decimal, or nil when prev is zero.
+func percentChange(prev float64, curr float64) *float64 {
+	if prev == 0 {
+		return nil
+	}
+	p := math.Round((curr-prev)/math.Abs(prev)*1000) / 10
+	return &p
+}
+
+// sortedSeries lists series by name, with uncategorized last, rounding them.
+func sortedSeries(m map[int]*dto.CashFlowSeries) []dto.CashFlowSeries {
+	list := make([]dto.CashFlowSeries, 0, len(m))
+	for _, sr := range m {
+		roundSeries(sr.Income, sr.Expense, sr.Net)
+		list = append(list, *sr)
+	}
+	sort.Slice(list, func(a, b int) bool {
+		if (list[a].ID == 0) != (list[b].ID == 0) {
+			return list[b].ID == 0
+		}
+		if list[a].Name != list[b].Name {
+			return list[a].Name < list[b].Name
+		}
+		return list[a].ID < list[b].ID
+	})
+	return list
+}
+
+// roundSeries rounds income and expense to cents and fills in net.
+func roundSeries(income []float64, expense []float64, net []float64) {
+	for i := range income {
+		income[i] = math.Round(income[i]*100) / 100
+		expense[i] = math.Round(expense[i]*100) / 100
+		net[i] = math.Round((income[i]-expense[i])*100) / 100
+	}
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"tally/internal/dto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFinanceService_PeriodSummaryAndCashFlow(t *testing.T) {
+	db := setupFinanceTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	user := createTestUser(t, NewAuthService(db), "summary_period_user")
+	finance := NewFinanceService(db, nil)
+	rules := NewCategorizationRuleService(db, nil)
+	chequing := finance.CreateAccount(user.ID, dto.CreateAccountInput{Name: "Chequing", Type: "checking", OpeningBalance: 1000, OpeningDate: "2024-01-01"})
+	card := finance.CreateAccount(user.ID, dto.CreateAccountInput{Name: "Card", Type: "credit", OpeningDate: "2024-01-01"})
+	salary := finance.CreateCategory(user.ID, dto.CreateCategoryInput{Name: "Salary", Type: "income"})
+	food := finance.CreateCategory(user.ID, dto.CreateCategoryInput{Name: "Food", Type: "expense"})
+	_, err := rules.Create(user.ID, dto.CreateCategorizationRuleInput{Name: "Payroll", Pattern: "payroll", CategoryID: salary.ID})
+	require.NoError(t, err)
+	_, err = rules.Create(user.ID, dto.CreateCategorizationRuleInput{Name: "Grocer", Pattern: "grocer", CategoryID: food.ID})
+	require.NoError(t, err)
+
+	_, err = finance.ImportTransactions(user.ID, dto.ImportTransactionsInput{AccountID: chequing.ID, FileContent: "2024-01-15,PAYROLL ACME,3000.00\n" +
+		"2024-02-15,PAYROLL ACME,3000.00\n" +
+		"2024-02-20,VISA PAYMENT,-400.00\n" +
+		"2024-03-15,PAYROLL ACME,3200.00\n" +
+		"2024-04-02,PAYROLL ACME,3200.00\n"})
+	require.NoError(t, err)
+	_, err = finance.ImportTransactions(user.ID, dto.ImportTransactionsInput{AccountID: card.ID, FileContent: "2024-01-10,GROCER,-200.00\n" +
+		"2024-02-05,GROCER,-300.00\n" +
+		"2024-02-22,PAYMENT RECEIVED,400.00\n" +
+		"2024-03-03,DINNER,-150.00\n"})
+	require.NoError(t, err)
+	transfers := NewTransferService(db)
+	proposed, err := transfers.Detect(user.ID)
+	require.NoError(t, err)
+	require.Len(t, proposed, 1)
+	_, err = transfers.Link(user.ID, dto.LinkTransferInput{TransactionID: proposed[0].OutgoingID, CounterpartID: proposed[0].IncomingID})
+	require.NoError(t, err)
+
+	month, err := finance.GetPeriodSummary(user.ID, dto.SummaryPeriodInput{Date: "2024-02-10"})
+	require.NoError(t, err)
+	assert.Equal(t, "month", month.Period)
+	assert.Equal(t, dto.PeriodTotals{StartDate: "2024-02-01", EndDate: "2024-02-29", Income: 3000, Expense: 300, CashFlow: 2700, ClosingBalance: 6500},
+		month.Current, "the card payment is a transfer")
+	assert.Equal(t, dto.PeriodTotals{StartDate: "2024-01-01", EndDate: "2024-01-31", Income: 3000, Expense: 200, CashFlow: 2800, ClosingBalance: 3800},
+		month.Previous)
+	assert.Equal(t, 100.0, month.Change.Expense)
+	require.NotNil(t, month.Change.ExpensePercent)
+	assert.Equal(t, 50.0, *month.Change.ExpensePercent)
+	assert.Equal(t, 0.0, *month.Change.IncomePercent)
+	assert.Equal(t, -3.6, *month.Change.CashFlowPercent)
+
+	quarter, err := finance.GetPeriodSummary(user.ID, dto.SummaryPeriodInput{Period: "quarter", Date: "2024-03-31"})
+	require.NoError(t, err)
+	assert.Equal(t, "2024-01-01", quarter.Current.StartDate)
+	assert.Equal(t, "2024-03-31", quarter.Current.EndDate)
+	assert.Equal(t, 9200.0, quarter.Current.Income)
+	assert.Equal(t, 650.0, quarter.Current.Expense)
+	assert.Equal(t, "2023-10-01", quarter.Previous.StartDate)
+	assert.Zero(t, quarter.Previous.ClosingBalance, "the accounts were opened later")
+	assert.Nil(t, quarter.Change.IncomePercent)
+
+	year, err := finance.GetPeriodSummary(user.ID, dto.SummaryPeriodInput{Period: "year", Date: "2024-06-01"})
+	require.NoError(t, err)
+	assert.Equal(t, "2024-12-31", year.Current.EndDate)
+	assert.Equal(t, 12400.0, year.Current.Income)
+
+	custom, err := finance.GetPeriodSummary(user.ID, dto.SummaryPeriodInput{Period: "custom", StartDate: "2024-02-01", EndDate: "2024-02-29"})
+	require.NoError(t, err)
+	assert.Equal(t, "2024-01-03", custom.Previous.StartDate, "the previous period has the same 29 days")
+	assert.Equal(t, "2024-01-31", custom.Previous.EndDate)
+	_, err = finance.GetPeriodSummary(user.ID, dto.SummaryPeriodInput{Period: "custom", StartDate: "2024-02-01", EndDate: "2024-01-01"})
+	assert.Error(t, err)
+	_, err = finance.GetPeriodSummary(user.ID, dto.SummaryPeriodInput{Period: "fortnight"})
+	assert.Error(t, err)
+
+	series, err := finance.GetCashFlowSeries(user.ID, dto.CashFlowSeriesInput{StartDate: "2024-01-01", EndDate: "2024-03-31"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2024-01", "2024-02", "2024-03"}, series.Months)
+	assert.Equal(t, []float64{3000, 3000, 3200}, series.Income)
+	assert.Equal(t, []float64{200, 300, 150}, series.Expense)
+	assert.Equal(t, []float64{2800, 2700, 3050}, series.Net)
+	require.Len(t, series.ByAccount, 2)
+	assert.Equal(t, "Card", series.ByAccount[0].Name)
+	assert.Equal(t, []float64{0, 0, 0}, series.ByAccount[0].Income, "transfers are left out")
+	assert.Equal(t, []float64{3000, 3000, 3200}, series.ByAccount[1].Income)
+	require.Len(t, series.ByCategory, 3)
+	assert.Equal(t, []string{"Food", "Salary", "Uncategorized"}, []string{series.ByCategory[0].Name, series.ByCategory[1].Name, series.ByCategory[2].Name})
+	assert.Equal(t, []float64{0, 0, 150}, series.ByCategory[2].Expense)
+
+	series, err = finance.GetCashFlowSeries(user.ID, dto.CashFlowSeriesInput{StartDate: "2024-01-01", EndDate: "2024-04-30", CategoryID: food.ID})
+	require.NoError(t, err)
+	assert.Len(t, series.Months, 4)
+	require.Len(t, series.ByCategory, 1)
+	assert.Equal(t, []float64{-200, -300, 0, 0}, series.Net)
+
+	series, err = finance.GetCashFlowSeries(user.ID, dto.CashFlowSeriesInput{})
+	require.NoError(t, err)
+	assert.Len(t, series.Months, 12)
+}
@@ -0,0 +1,876 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"tally/internal/dto"
+	"tally/internal/mapper"
+	"tally/internal/models"
+	"time"
+)
+
+// ImportProfileService manages saved bank CSV layouts, detects the layout of
+// an unknown export and previews how a file will be read.
+type ImportProfileService struct {
+	db *sql.DB
+}
+
+// NewImportProfileService creates a new ImportProfileService instance.
+func NewImportProfileService(db *sql.DB) *ImportProfileService {
+	return &ImportProfileService{db: db}
+}
+
+// builtinImportProfiles are the bank layouts that shipped before profiles
+// could be configured. They are selected by name through the bank type.
+var builtinImportProfiles = []models.ImportProfile{
+	// Date, Description, Debit, Credit, CardName, CardNumber
+	{Name: "CIBC", DateColumn: "1", DescriptionColumns: []string{"2"}, DebitColumn: "3", CreditColumn: "4", SignConvention: "debit_credit"},
+	// Account Type, Account Number, Transaction Date, Cheque Number, Description 1, Description 2, CAD$, USD$
+	{Name: "RBC", DateColumn: "3", DescriptionColumns: []string{"5", "6"}, AmountColumn: "7", SignConvention: "signed"},
+	// Date, Description, Withdrawal, Deposit, Balance
+	{Name: "TD", DateColumn: "1", DescriptionColumns: []string{"2"}, DebitColumn: "3", CreditColumn: "4", SignConvention: "debit_credit"},
+	// Date, Description, Amount
+	{Name: "Generic", DateColumn: "1", DescriptionColumns: []string{"2"}, AmountColumn: "3", SignConvention: "signed"},
+}
+
+const importProfileColumns = `id, name, delimiter, skip_rows, date_column, COALESCE(description_columns_json, '[]'),
+  COALESCE(amount_column, ''), COALESCE(debit_column, ''), COALESCE(credit_column, ''), COALESCE(reference_column, ''),
+  COALESCE(date_format, ''), sign_convention, decimal_separator`
+
+// List returns the built-in layouts followed by the user's saved profiles.
+func (s *ImportProfileService) List(userID int) []dto.ImportProfileOutput {
+	profiles := make([]models.ImportProfile, 0, len(builtinImportProfiles))
+	for _, p := range builtinImportProfiles {
+		profiles = append(profiles, normalizedBuiltin(p))
+	}
+
+	rows, err := s.db.Query("SELECT "+importProfileColumns+" FROM import_profiles WHERE user_id = ? ORDER BY name ASC", userID)
+	if err != nil {
+		log.Println("Error querying import profiles:", err)
+		return mapper.ToImportProfileOutputList(profiles)
+	}
+	defer closeWithLog(rows, "closing import profile rows")
+
+	for rows.Next() {
+		p, err := scanImportProfile(rows)
+		if err != nil {
+			log.Println("Error scanning import profile:", err)
+			continue
+		}
+		profiles = append(profiles, p)
+	}
+	return mapper.ToImportProfileOutputList(profiles)
+}
+
+// Create saves a bank CSV layout.
+func (s *ImportProfileService) Create(userID int, input dto.CreateImportProfileInput) (dto.ImportProfileOutput, error) {
+	p := importProfileFromInput(input)
+	if err := validateImportProfile(&p); err != nil {
+		return dto.ImportProfileOutput{}, err
+	}
+	descriptionJSON, _ := json.Marshal(p.DescriptionColumns)
+
+	res, err := s.db.Exec(`INSERT INTO import_profiles(user_id, name, delimiter, skip_rows, date_column, description_columns_json,
+  amount_column, debit_column, credit_column, reference_column, date_format, sign_convention, decimal_separator)
+VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		userID, p.Name, p.Delimiter, p.SkipRows, p.DateColumn, string(descriptionJSON),
+		p.AmountColumn, p.DebitColumn, p.CreditColumn, p.ReferenceColumn, p.DateFormat, p.SignConvention, p.DecimalSeparator)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			return dto.ImportProfileOutput{}, fmt.Errorf("import profile %q already exists", p.Name)
+		}
+		return dto.ImportProfileOutput{}, fmt.Errorf("failed to insert import profile: %w", err)
+	}
+	id, _ := res.LastInsertId()
+	p.ID = int(id)
+	return mapper.ToImportProfileOutput(p), nil
+}
+
+// Update changes a saved layout.
+func (s *ImportProfileService) Update(userID int, input dto.UpdateImportProfileInput) (dto.ImportProfileOutput, error) {
+	p := importProfileFromInput(dto.CreateImportProfileInput{
+		Name:               input.Name,
+		Delimiter:          input.Delimiter,
+		SkipRows:           input.SkipRows,
+		DateColumn:         input.DateColumn,
+		DescriptionColumns: input.DescriptionColumns,
+		AmountColumn:       input.AmountColumn,
+		DebitColumn:        input.DebitColumn,
+		CreditColumn:       input.CreditColumn,
+		ReferenceColumn:    input.ReferenceColumn,
+		DateFormat:         input.DateFormat,
+		SignConvention:     input.SignConvention,
+		DecimalSeparator:   input.DecimalSeparator,
+	})
+	p.ID = input.ID
+	if err := validateImportProfile(&p); err != nil {
+		return dto.ImportProfileOutput{}, err
+	}
+	descriptionJSON, _ := json.Marshal(p.DescriptionColumns)
+
+	res, err := s.db.Exec(`UPDATE import_profiles SET name = ?, delimiter = ?, skip_rows = ?, date_column = ?, description_columns_json = ?,
+  amount_column = ?, debit_column = ?, credit_column = ?, reference_column = ?, date_format = ?, sign_convention = ?, decimal_separator = ?
+WHERE id = ? AND user_id = ?`,
+		p.Name, p.Delimiter, p.SkipRows, p.DateColumn, string(descriptionJSON),
+		p.AmountColumn, p.DebitColumn, p.CreditColumn, p.ReferenceColumn, p.DateFormat, p.SignConvention, p.DecimalSeparator,
+		input.ID, userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			return dto.ImportProfileOutput{}, fmt.Errorf("import profile %q already exists", p.Name)
+		}
+		return dto.ImportProfileOutput{}, fmt.Errorf("failed to update import profile: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return dto.ImportProfileOutput{}, fmt.Errorf("import profile not found or not owned by user")
+	}
+	return mapper.ToImportProfileOutput(p), nil
+}
+
+// Delete removes a saved layout.
+func (s *ImportProfileService) Delete(userID int, id int) error {
+	_, err := s.db.Exec("DELETE FROM import_profiles WHERE id = ? AND user_id = ?", id, userID)
+	return err
+}
+
+// Detect guesses the layout of a bank export from its content and previews
+// the first rows with it. The result is not saved; the user names it, fixes
+// what the guess got wrong and calls Create.
+func (s *ImportProfileService) Detect(userID int, fileContent string) (dto.ImportPreviewOutput, error) {
+	p, err := detectImportProfile(fileContent)
+	if err != nil {
+		return dto.ImportPreviewOutput{}, err
+	}
+	return previewImport(p, fileContent, 0)
+}
+
+// Preview shows how a file reads with a profile, without importing anything.
+func (s *ImportProfileService) Preview(userID int, input dto.PreviewImportInput) (dto.ImportPreviewOutput, error) {
+	var p models.ImportProfile
+	if input.Profile != nil {
+		p = importProfileFromInput(*input.Profile)
+		if err := validateImportProfile(&p); err != nil {
+			return dto.ImportPreviewOutput{}, err
+		}
+	} else {
+		var err error
+		if p, err = resolveImportProfile(s.db, userID, input.ProfileID, input.BankType); err != nil {
+			return dto.ImportPreviewOutput{}, err
+		}
+	}
+	return previewImport(p, input.FileContent, input.Limit)
+}
+
+func scanImportProfile(row rowScanner) (models.ImportProfile, error) {
+	var p models.ImportProfile
+	var descriptionJSON string
+	err := row.Scan(&p.ID, &p.Name, &p.Delimiter, &p.SkipRows, &p.DateColumn, &descriptionJSON,
+		&p.AmountColumn, &p.DebitColumn, &p.CreditColumn, &p.ReferenceColumn, &p.DateFormat, &p.SignConvention, &p.DecimalSeparator)
+	if err != nil {
+		return p, err
+	}
+	if err := json.Unmarshal([]byte(descriptionJSON), &p.DescriptionColumns); err != nil {
+		return p, fmt.Errorf("invalid description columns: %w", err)
+	}
+	return p, nil
+}
+
+func importProfileFromInput(input dto.CreateImportProfileInput) models.ImportProfile {
+	return models.ImportProfile{
+		Name:               input.Name,
+		Delimiter:          input.Delimiter,
+		SkipRows:           input.SkipRows,
+		DateColumn:         input.DateColumn,
+		DescriptionColumns: input.DescriptionColumns,
+		AmountColumn:       input.AmountColumn,
+		DebitColumn:        input.DebitColumn,
+		CreditColumn:       input.CreditColumn,
+		ReferenceColumn:    input.ReferenceColumn,
+		DateFormat:         input.DateFormat,
+		SignConvention:     input.SignConvention,
+		DecimalSeparator:   input.DecimalSeparator,
+	}
+}
+
+func normalizedBuiltin(p models.ImportProfile) models.ImportProfile {
+	p.Builtin = true
+	p.Delimiter = ","
+	p.DecimalSeparator = "."
+	return p
+}
+
+// resolveImportProfile picks the saved profile, or the built-in layout named
+// by bankType, falling back to the generic Date, Description, Amount layout.
+func resolveImportProfile(db *sql.DB, userID int, profileID int, bankType string) (models.ImportProfile, error) {
+	if profileID > 0 {
+		p, err := scanImportProfile(db.QueryRow("SELECT "+importProfileColumns+" FROM import_profiles WHERE id = ? AND user_id = ?", profileID, userID))
+		if err != nil {
+			return models.ImportProfile{}, fmt.Errorf("import profile not found: %w", err)
+		}
+		return p, nil
+	}
+	for _, p := range builtinImportProfiles {
+		if strings.EqualFold(p.Name, bankType) {
+			return normalizedBuiltin(p), nil
+		}
+	}
+	return normalizedBuiltin(builtinImportProfiles[len(builtinImportProfiles)-1]), nil
+}
+
+// validateImportProfile trims a profile, fills in defaults and checks that it
+// can read a transaction.
+func validateImportProfile(p *models.ImportProfile) error {
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" {
+		return fmt.Errorf("profile name is required")
+	}
+	if p.Delimiter == "" {
+		p.Delimiter = ","
+	}
+	if p.Delimiter == `\t` {
+		p.Delimiter = "\t"
+	}
+	if !strings.Contains(",;\t|", p.Delimiter) || len(p.Delimiter) != 1 {
+		return fmt.Errorf("unsupported delimiter %q", p.Delimiter)
+	}
+	if p.DecimalSeparator == "" {
+		p.DecimalSeparator = "."
+	}
+	if p.DecimalSeparator != "." && p.DecimalSeparator != "," {
+		return fmt.Errorf("decimal separator must be \".\" or \",\"")
+	}
+	if p.DecimalSeparator == p.Delimiter {
+		return fmt.Errorf("the decimal separator cannot also be the delimiter")
+	}
+	if p.SkipRows < 0 {
+		return fmt.Errorf("rows to skip cannot be negative")
+	}
+	if p.SignConvention == "" {
+		p.SignConvention = "signed"
+	}
+
+	p.DateColumn = strings.TrimSpace(p.DateColumn)
+	p.AmountColumn = strings.TrimSpace(p.AmountColumn)
+	p.DebitColumn = strings.TrimSpace(p.DebitColumn)
+	p.CreditColumn = strings.TrimSpace(p.CreditColumn)
+	p.ReferenceColumn = strings.TrimSpace(p.ReferenceColumn)
+	description := []string{}
+	for _, c := range p.DescriptionColumns {
+		if c = strings.TrimSpace(c); c != "" {
+			description = append(description, c)
+		}
+	}
+	p.DescriptionColumns = description
+
+	if p.DateColumn == "" {
+		return fmt.Errorf("date column is required")
+	}
+	if len(p.DescriptionColumns) == 0 {
+		return fmt.Errorf("at least one description column is required")
+	}
+	switch p.SignConvention {
+	case "signed", "inverted":
+		if p.AmountColumn == "" {
+			return fmt.Errorf("amount column is required")
+		}
+	case "debit_credit":
+		if p.DebitColumn == "" || p.CreditColumn == "" {
+			return fmt.Errorf("debit and credit columns are required")
+		}
+	default:
+		return fmt.Errorf("unknown sign convention: %s", p.SignConvention)
+	}
+	for _, c := range append([]string{p.DateColumn, p.AmountColumn, p.DebitColumn, p.CreditColumn, p.ReferenceColumn}, p.DescriptionColumns...) {
+		if n, err := strconv.Atoi(c); err == nil && n < 1 {
+			return fmt.Errorf("column numbers start at 1")
+		}
+	}
+
+	p.DateFormat = strings.TrimSpace(p.DateFormat)
+	if p.DateFormat != "" {
+		if _, err := dateLayout(p.DateFormat); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importRow is one data row of a bank export after parsing.
+type importRow struct {
+	Line        int // 1-based line of a CSV file, or entry number of a statement
+	Transaction models.FinanceTransaction
+	Err         error
+}
+
+// importColumns holds a profile's columns as 0-based indices; -1 is unset.
+type importColumns struct {
+	date, amount, debit, credit, reference int
+	description                            []int
+}
+
+// readImportCSV splits a bank export into records, tolerating ragged rows,
+// stray quotes and a byte order mark. Blank lines are kept as empty records
+// so that rows to skip and reported line numbers match the file.
+func readImportCSV(content string, delimiter string) ([][]string, error) {
+	content = strings.TrimPrefix(content, "\ufeff")
+	reader := csv.NewReader(strings.NewReader(content))
+	if delimiter != "" {
+		reader.Comma = rune(delimiter[0])
+	}
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	var records [][]string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse CSV: %v", err)
+		}
+		line, _ := reader.FieldPos(0)
+		for len(records) < line-1 {
+			records = append(records, []string{})
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// parseImportRecords reads the data rows of a file with a profile. Without
+// rows to skip, a first row mentioning "date" or "description" is taken as the
+// header. It fails only when a named column is missing from the header.
+func parseImportRecords(p models.ImportProfile, records [][]string) (header []string, rows []importRow, err error) {
+	start := p.SkipRows
+	if start > 0 && start <= len(records) {
+		header = records[start-1]
+	} else if start == 0 && len(records) > 0 {
+		first := strings.ToLower(strings.Join(records[0], ","))
+		if strings.Contains(first, "date") || strings.Contains(first, "description") {
+			header = records[0]
+			start = 1
+		}
+	}
+
+	cols, err := resolveImportColumns(p, header)
+	if err != nil {
+		return header, nil, err
+	}
+	for i := start; i < len(records); i++ {
+		if strings.TrimSpace(strings.Join(records[i], "")) == "" {
+			continue
+		}
+		t, err := parseImportRecord(p, cols, records[i])
+		rows = append(rows, importRow{Line: i + 1, Transaction: t, Err: err})
+	}
+	return header, rows, nil
+}
+
+func resolveImportColumns(p models.ImportProfile, header []string) (importColumns, error) {
+	var firstErr error
+	resolve := func(spec string) int {
+		if spec == "" {
+			return -1
+		}
+		if n, err := strconv.Atoi(spec); err == nil {
+			return n - 1
+		}
+		for i, name := range header {
+			if strings.EqualFold(strings.TrimSpace(name), spec) {
+				return i
+			}
+		}
+		if firstErr == nil {
+			firstErr = fmt.Errorf("column %q not found in the header row", spec)
+		}
+		return -1
+	}
+
+	cols := importColumns{
+		date:      resolve(p.DateColumn),
+		amount:    resolve(p.AmountColumn),
+		debit:     resolve(p.DebitColumn),
+		credit:    resolve(p.CreditColumn),
+		reference: resolve(p.ReferenceColumn),
+	}
+	for _, spec := range p.DescriptionColumns {
+		cols.description = append(cols.description, resolve(spec))
+	}
+	return cols, firstErr
+}
+
+func parseImportRecord(p models.ImportProfile, cols importColumns, row []string) (models.FinanceTransaction, error) {
+	var t models.FinanceTransaction
+	cell := func(i int) string {
+		if i < 0 || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+	if cols.date >= len(row) {
+		return t, fmt.Errorf("expected at least %d columns, got %d", cols.date+1, len(row))
+	}
+
+	var err error
+	if p.DateFormat != "" {
+		t.Date, err = parseDateFormat(cell(cols.date), p.DateFormat)
+	} else {
+		t.Date, err = parseDate(cell(cols.date))
+	}
+	if err != nil {
+		return t, err
+	}
+
+	var parts []string
+	for _, i := range cols.description {
+		if v := cell(i); v != "" {
+			parts = append(parts, v)
+		}
+	}
+	t.Description = strings.Join(parts, " ")
+	t.ReferenceID = cell(cols.reference)
+
+	switch p.SignConvention {
+	case "debit_credit":
+		debit, err := parseAmountWith(cell(cols.debit), p.DecimalSeparator)
+		if err != nil {
+			return t, err
+		}
+		credit, err := parseAmountWith(cell(cols.credit), p.DecimalSeparator)
+		if err != nil {
+			return t, err
+		}
+		if cell(cols.debit) == "" && cell(cols.credit) == "" {
+			return t, fmt.Errorf("no debit or credit amount")
+		}
+		// Banks disagree on whether debits carry a minus sign.
+		t.Amount = math.Abs(credit) - math.Abs(debit)
+	default:
+		if cell(cols.amount) == "" {
+			return t, fmt.Errorf("no amount")
+		}
+		t.Amount, err = parseAmountWith(cell(cols.amount), p.DecimalSeparator)
+		if err != nil {
+			return t, err
+		}
+		if p.SignConvention == "inverted" {
+			t.Amount = -t.Amount
+		}
+	}
+	return t, nil
+}
+
+var (
+	amountPattern       = regexp.MustCompile(`^[+-]?[0-9.,]*[0-9][0-9.,]*$`)
+	commaDecimalPattern = regexp.MustCompile(`,[0-9]{1,2}\)?-?$`)
+)
+
+// parseAmountWith parses a bank amount such as "$1,234.56", "(12.00)",
+// "12.00-" or, with a "," decimal separator, "1.234,56". Empty is zero.
+func parseAmountWith(s string, decimalSeparator string) (float64, error) {
+	clean := strings.TrimSpace(s)
+	if clean == "" {
+		return 0, nil
+	}
+	negative := false
+	if strings.HasPrefix(clean, "(") && strings.HasSuffix(clean, ")") {
+		negative = true
+		clean = clean[1 : len(clean)-1]
+	}
+	clean = strings.NewReplacer("$", "", "€", "", "£", "", "¥", "", " ", "", "\u00a0", "", "'", "").Replace(clean)
+	if strings.HasSuffix(clean, "-") {
+		negative = !negative
+		clean = strings.TrimSuffix(clean, "-")
+	}
+	if !amountPattern.MatchString(clean) {
+		return 0, fmt.Errorf("invalid amount: %s", s)
+	}
+
+	thousands := ","
+	if decimalSeparator == "," {
+		thousands = "."
+	}
+	clean = strings.ReplaceAll(clean, thousands, "")
+	clean = strings.Replace(clean, decimalSeparator, ".", 1)
+	val, err := strconv.ParseFloat(clean, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount: %s", s)
+	}
+	if negative {
+		val = -val
+	}
+	return val, nil
+}
+
+var dateFormatTokens = strings.NewReplacer("YYYY", "2006", "YY", "06", "MMMM", "January", "MMM", "Jan", "MM", "1", "M", "1", "DD", "2", "D", "2")
+
+// dateLayout turns a format such as DD/MM/YYYY or DD MMM YYYY into a Go
+// layout. Single-digit days and months are accepted either way.
+func dateLayout(format string) (string, error) {
+	layout := dateFormatTokens.Replace(strings.ToUpper(format))
+	sample := time.Date(2024, time.November, 23, 0, 0, 0, 0, time.UTC)
+	if parsed, err := time.Parse(layout, sample.Format(layout)); err != nil || !parsed.Equal(sample) {
+		return "", fmt.Errorf("date format %q needs a day, month and year, e.g. YYYY-MM-DD", format)
+	}
+	return layout, nil
+}
+
+func parseDateFormat(s string, format string) (time.Time, error) {
+	layout, err := dateLayout(format)
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, err := time.Parse(layout, strings.TrimSpace(s))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("date %q does not match %s", s, format)
+	}
+	return t, nil
+}
+
+// detectDateFormats are tried in order; month-first wins when a file only has
+// days up to 12.
+var detectDateFormats = []string{"YYYY-MM-DD", "MM/DD/YYYY", "DD/MM/YYYY", "YYYY/MM/DD", "DD.MM.YYYY", "MM-DD-YYYY", "DD-MM-YYYY", "YYYYMMDD", "DD MMM YYYY", "DD-MMM-YYYY", "MMM DD YYYY"}
+
+// detectHeaderKeywords maps profile columns to header words that name them.
+var detectHeaderKeywords = map[string][]string{
+	"date":        {"transaction date", "posted date", "posting date", "date"},
+	"description": {"description", "memo", "payee", "details", "narrative", "merchant", "name"},
+	"amount":      {"amount"},
+	"debit":       {"debit", "withdrawal", "money out", "paid out"},
+	"credit":      {"credit", "deposit", "money in", "paid in"},
+	"reference":   {"reference", "transaction id", "fitid"},
+}
+
+// detectImportProfile guesses the delimiter, header rows, columns, date format,
+// sign convention and decimal separator of a bank export.
+func detectImportProfile(content string) (models.ImportProfile, error) {
+	p := models.ImportProfile{Name: "Detected", Delimiter: detectDelimiter(content), SignConvention: "signed", DecimalSeparator: "."}
+	records, err := readImportCSV(content, p.Delimiter)
+	if err != nil {
+		return p, err
+	}
+
+	// Data starts at the first row with a date and another number in it.
+	start := -1
+	for i, row := range records {
+		if isDataRow(row) {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return p, fmt.Errorf("no rows with a date and an amount found")
+	}
+	p.SkipRows = start
+	var header []string
+	if start > 0 {
+		header = records[start-1]
+	}
+	data := records[start:]
+	if len(data) > 50 {
+		data = data[:50]
+	}
+
+	column := func(i int) []string {
+		var values []string
+		for _, row := range data {
+			if i < len(row) && strings.TrimSpace(row[i]) != "" {
+				values = append(values, strings.TrimSpace(row[i]))
+			}
+		}
+		return values
+	}
+	width := 0
+	for _, row := range data {
+		if len(row) > width {
+			width = len(row)
+		}
+	}
+
+	// Classify columns by content.
+	dateCol := -1
+	var numeric, text []int
+	for i := 0; i < width; i++ {
+		values := column(i)
+		if len(values) == 0 {
+			continue
+		}
+		switch {
+		case detectDateFormat(values) != "":
+			if dateCol < 0 {
+				dateCol = i
+			}
+		case allAmounts(values):
+			numeric = append(numeric, i)
+		default:
+			text = append(text, i)
+		}
+	}
+	if dateCol < 0 {
+		return p, fmt.Errorf("no date column found")
+	}
+	p.DateFormat = detectDateFormat(column(dateCol))
+
+	// Header names win over content guesses; they survive column reordering.
+	named := map[string][]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		for _, key := range []string{"date", "reference", "debit", "credit", "amount", "description"} {
+			if headerMatches(name, detectHeaderKeywords[key]) {
+				named[key] = append(named[key], i)
+				break
+			}
+		}
+	}
+	spec := func(i int) string {
+		if header != nil && i < len(header) && strings.TrimSpace(header[i]) != "" {
+			return strings.TrimSpace(header[i])
+		}
+		return strconv.Itoa(i + 1)
+	}
+	if cols := named["date"]; len(cols) > 0 {
+		dateCol = cols[0]
+		p.DateFormat = detectDateFormat(column(dateCol))
+	}
+	p.DateColumn = spec(dateCol)
+
+	if cols := named["description"]; len(cols) > 0 {
+		for _, i := range cols {
+			p.DescriptionColumns = append(p.DescriptionColumns, spec(i))
+		}
+	} else if len(text) > 0 {
+		// The longest text is usually the description.
+		best, bestLen := text[0], -1
+		for _, i := range text {
+			total := 0
+			for _, v := range column(i) {
+				total += len(v)
+			}
+			if total > bestLen {
+				best, bestLen = i, total
+			}
+		}
+		p.DescriptionColumns = []string{spec(best)}
+	}
+	if cols := named["reference"]; len(cols) > 0 {
+		p.ReferenceColumn = spec(cols[0])
+	}
+
+	var amountCols []int
+	switch {
+	case len(named["debit"]) > 0 && len(named["credit"]) > 0:
+		p.SignConvention = "debit_credit"
+		p.DebitColumn, p.CreditColumn = spec(named["debit"][0]), spec(named["credit"][0])
+		amountCols = []int{named["debit"][0], named["credit"][0]}
+	case len(named["amount"]) > 0:
+		p.AmountColumn = spec(named["amount"][0])
+		amountCols = named["amount"][:1]
+	default:
+		debit, credit, amount := detectAmountColumns(data, numeric)
+		if debit >= 0 {
+			p.SignConvention = "debit_credit"
+			p.DebitColumn, p.CreditColumn = spec(debit), spec(credit)
+			amountCols = []int{debit, credit}
+		} else if amount >= 0 {
+			p.AmountColumn = spec(amount)
+			amountCols = []int{amount}
+		}
+	}
+	if len(amountCols) == 0 {
+		return p, fmt.Errorf("no amount column found")
+	}
+
+	var amounts []string
+	for _, i := range amountCols {
+		amounts = append(amounts, column(i)...)
+	}
+	p.DecimalSeparator = detectDecimalSeparator(amounts, p.Delimiter)
+	return p, nil
+}
+
+// detectDelimiter picks the separator that splits the first lines into the
+// most rows of the same width.
+func detectDelimiter(content string) string {
+	lines := strings.Split(strings.TrimPrefix(content, "\ufeff"), "\n")
+	if len(lines) > 20 {
+		lines = lines[:20]
+	}
+	sample := strings.Join(lines, "\n")
+
+	best, bestScore, bestWidth := ",", 0, 0
+	for _, d := range []string{",", ";", "\t", "|"} {
+		records, err := readImportCSV(sample, d)
+		if err != nil {
+			continue
+		}
+		widths := map[int]int{}
+		for _, r := range records {
+			widths[len(r)]++
+		}
+		for width, count := range widths {
+			if width > 1 && (count > bestScore || (count == bestScore && width > bestWidth)) {
+				best, bestScore, bestWidth = d, count, width
+			}
+		}
+	}
+	return best
+}
+
+func isDataRow(row []string) bool {
+	dates, numbers := 0, 0
+	for _, v := range row {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if detectDateFormat([]string{v}) != "" {
+			dates++
+		} else if isAmount(v) {
+			numbers++
+		}
+	}
+	return dates > 0 && numbers > 0
+}
+
+// detectDateFormat returns the first known format every value matches.
+func detectDateFormat(values []string) string {
+	for _, format := range detectDateFormats {
+		ok := true
+		for _, v := range values {
+			if _, err := parseDateFormat(v, format); err != nil {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return format
+		}
+	}
+	return ""
+}
+
+func isAmount(v string) bool {
+	if _, err := parseAmountWith(v, "."); err == nil {
+		return true
+	}
+	_, err := parseAmountWith(v, ",")
+	return err == nil
+}
+
+func allAmounts(values []string) bool {
+	for _, v := range values {
+		if !isAmount(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func headerMatches(name string, keywords []string) bool {
+	for _, k := range keywords {
+		if strings.Contains(name, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// detectAmountColumns finds a debit/credit pair (two numeric columns never
+// filled on the same row, one filled on every row) or else the first numeric
+// column filled on every row.
+func detectAmountColumns(data [][]string, numeric []int) (debit int, credit int, amount int) {
+	filled := func(row []string, i int) bool {
+		return i < len(row) && strings.TrimSpace(row[i]) != ""
+	}
+	for a := 0; a < len(numeric); a++ {
+		for b := a + 1; b < len(numeric); b++ {
+			exclusive := true
+			for _, row := range data {
+				if filled(row, numeric[a]) == filled(row, numeric[b]) {
+					exclusive = false
+					break
+				}
+			}
+			if exclusive {
+				return numeric[a], numeric[b], -1
+			}
+		}
+	}
+	for _, i := range numeric {
+		all := true
+		for _, row := range data {
+			if !filled(row, i) {
+				all = false
+				break
+			}
+		}
+		if all {
+			return -1, -1, i
+		}
+	}
+	return -1, -1, -1
+}
+
+// detectDecimalSeparator returns "," when amounts end in a comma and one or
+// two digits, as in 1.234,56, and "." otherwise.
+func detectDecimalSeparator(values []string, delimiter string) string {
+	if delimiter == "," {
+		return "."
+	}
+	for _, v := range values {
+		if commaDecimalPattern.MatchString(v) {
+			return ","
+		}
+	}
+	return "."
+}
+
+// previewImport parses a file with a profile and returns the first limit rows.
+func previewImport(p models.ImportProfile, content string, limit int) (dto.ImportPreviewOutput, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	records, err := readImportCSV(content, p.Delimiter)
+	if err != nil {
+		return dto.ImportPreviewOutput{}, err
+	}
+	header, rows, err := parseImportRecords(p, records)
+	if err != nil {
+		return dto.ImportPreviewOutput{}, err
+	}
+
+	out := dto.ImportPreviewOutput{
+		Profile:   mapper.ToImportProfileOutput(p),
+		Headers:   header,
+		Rows:      []dto.ImportPreviewRow{},
+		TotalRows: len(rows),
+	}
+	if out.Headers == nil {
+		out.Headers = []string{}
+	}
+	for _, r := range rows {
+		if r.Err != nil {
+			out.ErrorCount++
+		}
+		if len(out.Rows) >= limit {
+			continue
+		}
+		row := dto.ImportPreviewRow{Line: r.Line, Description: r.Transaction.Description, Amount: r.Transaction.Amount, ReferenceID: r.Transaction.ReferenceID}
+		if r.Err != nil {
+			row.Error = r.Err.Error()
+		} else {
+			row.Date = r.Transaction.Date.Format("2006-01-02")
+		}
+		out.Rows = append(out.Rows, row)
+	}
+	return out, nil
+}
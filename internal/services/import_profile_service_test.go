@@ -0,0 +1,176 @@
+package services
+
+import (
+	"tally/internal/dto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAmountWith(t *testing.T) {
+	cases := []struct {
+		in      string
+		sep     string
+		want    float64
+		wantErr bool
+	}{
+		{"$1,234.56", ".", 1234.56, false},
+		{"(12.00)", ".", -12, false},
+		{"12.00-", ".", -12, false},
+		{"-1.234,56", ",", -1234.56, false},
+		{"1 234,5", ",", 1234.5, false},
+		{"", ".", 0, false},
+		{"Order 12", ".", 0, true},
+	}
+	for _, c := range cases {
+		got, err := parseAmountWith(c.in, c.sep)
+		if c.wantErr {
+			assert.Error(t, err, c.in)
+			continue
+		}
+		require.NoError(t, err, c.in)
+		assert.InDelta(t, c.want, got, 0.001, c.in)
+	}
+}
+
+func TestDateLayout(t *testing.T) {
+	d, err := parseDateFormat("3/1/2024", "DD/MM/YYYY")
+	require.NoError(t, err)
+	assert.Equal(t, "2024-01-03", d.Format("2006-01-02"))
+	d, err = parseDateFormat("05 Feb 2024", "DD MMM YYYY")
+	require.NoError(t, err)
+	assert.Equal(t, "2024-02-05", d.Format("2006-01-02"))
+	d, err = parseDateFormat("20240115", "YYYYMMDD")
+	require.NoError(t, err)
+	assert.Equal(t, "2024-01-15", d.Format("2006-01-02"))
+
+	_, err = dateLayout("MM/YYYY")
+	assert.Error(t, err)
+}
+
+func TestImportProfileService_CRUDAndImport(t *testing.T) {
+	db := setupFullTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	auth := NewAuthService(db)
+	user := createTestUser(t, auth, "profile_user")
+	other := createTestUser(t, auth, "profile_other")
+	profileSvc := NewImportProfileService(db)
+	finance := NewFinanceService(db, nil)
+	acc := finance.CreateAccount(user.ID, dto.CreateAccountInput{Name: "Girokonto", Type: "checking", Currency: "EUR"})
+
+	// A European export: preamble, semicolons, day-first dates, comma decimals.
+	content := "Kontonummer;DE123\n\nBuchungstag;Verwendungszweck;Betrag;Referenz\n" +
+		"03.01.2024;Miete Januar;-1.200,00;R-1\n" +
+		"15.01.2024;Gehalt;3.450,50;R-2\n" +
+		"31.02.2024;Kaputt;1,00;R-3\n"
+
+	_, err := profileSvc.Create(user.ID, dto.CreateImportProfileInput{Name: "Bad", DateColumn: "1", DescriptionColumns: []string{"2"}})
+	assert.Error(t, err, "signed convention needs an amount column")
+	_, err = profileSvc.Create(user.ID, dto.CreateImportProfileInput{Name: "Bad", DateColumn: "0", DescriptionColumns: []string{"2"}, AmountColumn: "3"})
+	assert.Error(t, err, "columns are 1-based")
+	_, err = profileSvc.Create(user.ID, dto.CreateImportProfileInput{Name: "Bad", DateColumn: "1", DescriptionColumns: []string{"2"}, AmountColumn: "3", DateFormat: "YYYY"})
+	assert.Error(t, err)
+
+	profile, err := profileSvc.Create(user.ID, dto.CreateImportProfileInput{
+		Name:               " Sparkasse ",
+		Delimiter:          ";",
+		SkipRows:           3,
+		DateColumn:         "Buchungstag",
+		DescriptionColumns: []string{"Verwendungszweck"},
+		AmountColumn:       "Betrag",
+		ReferenceColumn:    "4",
+		DateFormat:         "DD.MM.YYYY",
+		DecimalSeparator:   ",",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Sparkasse", profile.Name)
+	assert.Equal(t, "signed", profile.SignConvention)
+	_, err = profileSvc.Create(user.ID, dto.CreateImportProfileInput{Name: "Sparkasse", DateColumn: "1", DescriptionColumns: []string{"2"}, AmountColumn: "3"})
+	assert.Error(t, err, "duplicate name")
+
+	preview, err := profileSvc.Preview(user.ID, dto.PreviewImportInput{FileContent: content, ProfileID: profile.ID})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Buchungstag", "Verwendungszweck", "Betrag", "Referenz"}, preview.Headers)
+	assert.Equal(t, 3, preview.TotalRows)
+	assert.Equal(t, 1, preview.ErrorCount)
+	assert.Equal(t, "2024-01-03", preview.Rows[0].Date)
+	assert.InDelta(t, -1200.0, preview.Rows[0].Amount, 0.001)
+	assert.Equal(t, "R-1", preview.Rows[0].ReferenceID)
+	assert.InDelta(t, 3450.5, preview.Rows[1].Amount, 0.001)
+	assert.Equal(t, 6, preview.Rows[2].Line)
+	assert.NotEmpty(t, preview.Rows[2].Error)
+
+	count, err := finance.ImportTransactions(user.ID, dto.ImportTransactionsInput{AccountID: acc.ID, ProfileID: profile.ID, FileContent: content})
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	txs := finance.GetTransactions(user.ID, dto.TransactionFilter{AccountID: acc.ID})
+	require.Len(t, txs, 2)
+	assert.Equal(t, "Gehalt", txs[0].Description)
+	assert.Equal(t, "R-2", txs[0].ReferenceID)
+
+	// Profiles are private; built-in layouts are listed first.
+	_, err = finance.ImportTransactions(other.ID, dto.ImportTransactionsInput{AccountID: acc.ID, ProfileID: profile.ID, FileContent: content})
+	assert.Error(t, err)
+	list := profileSvc.List(user.ID)
+	require.Len(t, list, 5)
+	assert.True(t, list[0].Builtin)
+	assert.Equal(t, "Sparkasse", list[4].Name)
+
+	// A renamed header column is reported instead of silently importing nothing.
+	updated, err := profileSvc.Update(user.ID, dto.UpdateImportProfileInput{
+		ID: profile.ID, Name: "Sparkasse", Delimiter: ";", SkipRows: 3, DateColumn: "Datum",
+		DescriptionColumns: []string{"2"}, AmountColumn: "3", DateFormat: "DD.MM.YYYY", DecimalSeparator: ",",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Datum", updated.DateColumn)
+	_, err = finance.ImportTransactions(user.ID, dto.ImportTransactionsInput{AccountID: acc.ID, ProfileID: profile.ID, FileContent: content})
+	assert.ErrorContains(t, err, `column "Datum" not found`)
+
+	require.NoError(t, profileSvc.Delete(user.ID, profile.ID))
+	assert.Len(t, profileSvc.List(user.ID), 4)
+}
+
+func TestImportProfileService_Detect(t *testing.T) {
+	db := setupFullTestDB(t)
+	defer func() { _ = db.Close() }()
+	profileSvc := NewImportProfileService(db)
+
+	t.Run("header names", func(t *testing.T) {
+		content := "Posted Date,Payee,Withdrawals,Deposits,Balance\n" +
+			"2024-01-15,Walmart,50.00,,950.00\n" +
+			"2024-01-16,Salary,,2000.00,2950.00\n"
+		out, err := profileSvc.Detect(0, content)
+		require.NoError(t, err)
+		assert.Equal(t, 1, out.Profile.SkipRows)
+		assert.Equal(t, "Posted Date", out.Profile.DateColumn)
+		assert.Equal(t, []string{"Payee"}, out.Profile.DescriptionColumns)
+		assert.Equal(t, "debit_credit", out.Profile.SignConvention)
+		assert.Equal(t, "Withdrawals", out.Profile.DebitColumn)
+		assert.Equal(t, "YYYY-MM-DD", out.Profile.DateFormat)
+		require.Len(t, out.Rows, 2)
+		assert.InDelta(t, -50.0, out.Rows[0].Amount, 0.001)
+		assert.InDelta(t, 2000.0, out.Rows[1].Amount, 0.001)
+	})
+
+	t.Run("content only", func(t *testing.T) {
+		content := "15/01/2024\tCoffee shop downtown\t-4,50\n" +
+			"28/01/2024\tRefund from online store\t12,00\n"
+		out, err := profileSvc.Detect(0, content)
+		require.NoError(t, err)
+		assert.Equal(t, "\t", out.Profile.Delimiter)
+		assert.Equal(t, 0, out.Profile.SkipRows)
+		assert.Equal(t, "1", out.Profile.DateColumn)
+		assert.Equal(t, "DD/MM/YYYY", out.Profile.DateFormat)
+		assert.Equal(t, []string{"2"}, out.Profile.DescriptionColumns)
+		assert.Equal(t, "3", out.Profile.AmountColumn)
+		assert.Equal(t, ",", out.Profile.DecimalSeparator)
+		assert.Equal(t, 0, out.ErrorCount)
+		assert.InDelta(t, -4.5, out.Rows[0].Amount, 0.001)
+		assert.Equal(t, "2024-01-28", out.Rows[1].Date)
+	})
+
+	_, err := profileSvc.Detect(0, "just,some,words\nand,more,words\n")
+	assert.Error(t, err)
+}
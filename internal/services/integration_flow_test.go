@@ -61,6 +61,9 @@ func TestMultiUserFlow_Integration(t *testing.T) {
 			billing_city TEXT,
 			billing_province TEXT,
 			billing_postal_code TEXT,
+			payment_terms TEXT,
+			requires_po BOOLEAN DEFAULT 0,
+			archived_at DATETIME,
 			FOREIGN KEY(user_id) REFERENCES users(id)
 		);`,
 		`CREATE TABLE projects (
@@ -75,6 +78,7 @@ func TestMultiUserFlow_Integration(t *testing.T) {
 			deadline TEXT,
 			tags TEXT,
 			service_type TEXT,
+			archived_at DATETIME,
 			FOREIGN KEY(user_id) REFERENCES users(id),
 			FOREIGN KEY(client_id) REFERENCES clients(id)
 		);`,
@@ -107,9 +111,15 @@ func TestMultiUserFlow_Integration(t *testing.T) {
 			total REAL,
 			status TEXT,
 			items_json TEXT, 
+			purchase_order_id INTEGER,
 			FOREIGN KEY(user_id) REFERENCES users(id),
 			FOREIGN KEY(client_id) REFERENCES clients(id)
 		);`,
+		`CREATE TABLE tags (id INTEGER PRIMARY KEY AUTOINCREMENT, user_id INTEGER, name TEXT NOT NULL COLLATE NOCASE, color TEXT, UNIQUE(user_id, name));`,
+		`CREATE TABLE entity_tags (tag_id INTEGER NOT NULL, entity_type TEXT NOT NULL, entity_id INTEGER NOT NULL, PRIMARY KEY(tag_id, entity_type, entity_id));`,
+		`CREATE TABLE custom_field_definitions (id INTEGER PRIMARY KEY AUTOINCREMENT, user_id INTEGER, entity_type TEXT, field_key TEXT, label TEXT, field_type TEXT, options_json TEXT DEFAULT '[]', show_on_invoice BOOLEAN DEFAULT 1, sort_order INTEGER DEFAULT 0);`,
+		`CREATE TABLE purchase_orders (id INTEGER PRIMARY KEY AUTOINCREMENT, user_id INTEGER, client_id INTEGER, number TEXT, authorized_amount REAL, currency TEXT, valid_from TEXT, valid_to TEXT, notes TEXT);`,
+		`CREATE TABLE custom_field_values (field_id INTEGER NOT NULL, entity_id INTEGER NOT NULL, value TEXT NOT NULL, PRIMARY KEY(field_id, entity_id));`,
 	}
 
 	for _, query := range queries {
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"tally/internal/dto"
+	"unicode"
+)
+
+// InvoiceMatchService matches imported bank deposits to the invoices they pay.
+// A deposit is proposed for an outstanding invoice when it equals the amount
+// still owing and arrives within invoiceMatchWindowDays of the issue date;
+// deposits whose description names the client are proposed first.
+type InvoiceMatchService struct {
+	db *sql.DB
+}
+
+// NewInvoiceMatchService creates a new InvoiceMatchService instance.
+func NewInvoiceMatchService(db *sql.DB) *InvoiceMatchService {
+	return &InvoiceMatchService{db: db}
+}
+
+// invoiceMatchWindowDays is how long after its issue date a deposit may pay
+// an invoice.
+const invoiceMatchWindowDays = 90
+
+// clientNameNoise are words of company names too common to identify a client.
+var clientNameNoise = map[string]bool{
+	"inc": true, "ltd": true, "llc": true, "llp": true, "corp": true, "corporation": true,
+	"company": true, "limited": true, "the": true, "and": true, "group": true, "services": true,
+}
+
+// Suggest proposes deposit matches for the user's sent and overdue invoices.
+// Each invoice and each deposit appears in at most one proposal.
+func (s *InvoiceMatchService) Suggest(userID int) []dto.InvoiceMatchOutput {
+	rows, err := s.db.Query(`
+SELECT i.id, COALESCE(i.number, ''), i.client_id, COALESCE(c.name, ''), COALESCE(c.billing_company, ''), i.issue_date, i.outstanding,
+       t.id, t.account_id, t.date, t.description, t.amount,
+       CAST(julianday(substr(t.date, 1, 10)) - julianday(i.issue_date) AS INTEGER)
+FROM (SELECT inv.*, inv.total - COALESCE((SELECT SUM(amount) FROM payments WHERE invoice_id = inv.id), 0) AS outstanding
+      FROM invoices inv WHERE inv.user_id = ? AND inv.status IN ('sent', 'overdue')) i
+JOIN finance_transactions t ON t.user_id = i.user_id
+  AND t.amount > 0 AND abs(t.amount - i.outstanding) < 0.005
+  AND substr(t.date, 1, 10) >= i.issue_date AND julianday(substr(t.date, 1, 10)) - julianday(i.issue_date) <= ?
+  AND t.transfer_id IS NULL AND t.possible_duplicate_of_id IS NULL
+  AND NOT EXISTS (SELECT 1 FROM payments p WHERE p.transaction_id = t.id)
+LEFT JOIN clients c ON c.id = i.client_id
+WHERE i.outstanding > 0.005`, userID, invoiceMatchWindowDays)
+	if err != nil {
+		log.Println("Error querying invoice matches:", err)
+		return []dto.InvoiceMatchOutput{}
+	}
+	defer closeWithLog(rows, "closing invoice match rows")
+
+	var candidates []dto.InvoiceMatchOutput
+	for rows.Next() {
+		var m dto.InvoiceMatchOutput
+		var billingCompany string
+		if err := rows.Scan(&m.InvoiceID, &m.InvoiceNumber, &m.ClientID, &m.ClientName, &billingCompany, &m.IssueDate, &m.Outstanding,
+			&m.TransactionID, &m.AccountID, &m.Date, &m.Description, &m.Amount, &m.DaysAfterIssue); err != nil {
+			log.Println("Error scanning invoice match:", err)
+			continue
+		}
+		m.Date = m.Date[:min(len(m.Date), 10)]
+		m.Outstanding = math.Round(m.Outstanding*100) / 100
+		m.NameMatch = mentionsClient(m.Description, m.ClientName, billingCompany)
+		candidates = append(candidates, m)
+	}
+
+	// Best first: the client is named, then the deposit came soonest.
+	sort.Slice(candidates, func(a, b int) bool {
+		ca, cb := candidates[a], candidates[b]
+		if ca.NameMatch != cb.NameMatch {
+			return ca.NameMatch
+		}
+		if ca.DaysAfterIssue != cb.DaysAfterIssue {
+			return ca.DaysAfterIssue < cb.DaysAfterIssue
+		}
+		if ca.InvoiceID != cb.InvoiceID {
+			return ca.InvoiceID < cb.InvoiceID
+		}
+		return ca.TransactionID < cb.TransactionID
+	})
+	out := []dto.InvoiceMatchOutput{}
+	invoices, deposits := map[int]bool{}, map[int]bool{}
+	for _, m := range candidates {
+		if invoices[m.InvoiceID] || deposits[m.TransactionID] {
+			continue
+		}
+		invoices[m.InvoiceID], deposits[m.TransactionID] = true, true
+		out = append(out, m)
+	}
+	return out
+}
+
+// Confirm records the deposit as a payment on the invoice, which marks the
+// invoice paid once it is settled.
+func (s *InvoiceMatchService) Confirm(userID int, input dto.ConfirmInvoiceMatchInput) (dto.PaymentOutput, error) {
+	var status string
+	if err := s.db.QueryRow("SELECT status FROM invoices WHERE id = ? AND user_id = ?", input.InvoiceID, userID).Scan(&status); err != nil {
+		return dto.PaymentOutput{}, fmt.Errorf("invoice not found: %w", err)
+	}
+	if status == "paid" {
+		return dto.PaymentOutput{}, fmt.Errorf("invoice is already paid")
+	}
+
+	var date, description string
+	var reference sql.NullString
+	var amount float64
+	if err := s.db.QueryRow("SELECT date, description, reference_id, amount FROM finance_transactions WHERE id = ? AND user_id = ?",
+		input.TransactionID, userID).Scan(&date, &description, &reference, &amount); err != nil {
+		return dto.PaymentOutput{}, fmt.Errorf("transaction not found: %w", err)
+	}
+
+	return NewPaymentService(s.db).Create(userID, dto.CreatePaymentInput{
+		InvoiceID:     input.InvoiceID,
+		Kind:          "payment",
+		Date:          date[:min(len(date), 10)],
+		Amount:        amount,
+		Method:        "bank deposit",
+		Reference:     nonEmptyOr(reference.String, description),
+		TransactionID: input.TransactionID,
+	})
+}
+
+// mentionsClient reports whether a bank description contains a distinctive
+// word of the client's name or billing company.
+func mentionsClient(description string, names ...string) bool {
+	words := map[string]bool{}
+	for _, w := range strings.Fields(normalizeDescription(description)) {
+		words[w] = true
+	}
+	for _, name := range names {
+		for _, w := range strings.Fields(normalizeDescription(name)) {
+			if len(w) < 3 || clientNameNoise[w] || strings.IndexFunc(w, unicode.IsLetter) < 0 {
+				continue
+			}
+			if words[w] {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"tally/internal/dto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvoiceMatchService_SuggestAndConfirm(t *testing.T) {
+	db := setupFullTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	user := createTestUser(t, NewAuthService(db), "invoice_match_user")
+	clients := NewClientService(db)
+	invoices := NewInvoiceService(db)
+	finance := NewFinanceService(db, nil)
+	matches := NewInvoiceMatchService(db)
+
+	acme := clients.Create(user.ID, dto.CreateClientInput{Name: "Acme Widgets Inc.", Currency: "CAD"})
+	globex := clients.Create(user.ID, dto.CreateClientInput{Name: "Globex", Currency: "CAD"})
+	a1 := invoices.Create(user.ID, dto.CreateInvoiceInput{ClientID: acme.ID, Number: "M-1", IssueDate: "2025-03-01", Total: 1500, Status: "sent"})
+	g1 := invoices.Create(user.ID, dto.CreateInvoiceInput{ClientID: globex.ID, Number: "M-2", IssueDate: "2025-03-05", Total: 1500, Status: "overdue"})
+	g2 := invoices.Create(user.ID, dto.CreateInvoiceInput{ClientID: globex.ID, Number: "M-3", IssueDate: "2025-03-10", Total: 800, Status: "sent"})
+	invoices.Create(user.ID, dto.CreateInvoiceInput{ClientID: globex.ID, Number: "M-4", IssueDate: "2025-03-10", Total: 250, Status: "draft"})
+
+	// Part of M-3 was already paid, so 500 is what a deposit must match.
+	_, err := NewPaymentService(db).Create(user.ID, dto.CreatePaymentInput{InvoiceID: g2.ID, Date: "2025-03-12", Amount: 300})
+	require.NoError(t, err)
+
+	acc := finance.CreateAccount(user.ID, dto.CreateAccountInput{Name: "Business chequing", Type: "checking"})
+	_, err = finance.ImportTransactions(user.ID, dto.ImportTransactionsInput{AccountID: acc.ID, FileContent: "2025-02-20,E-TRANSFER ACME WIDGETS,1500.00\n" +
+		"2025-03-20,DEPOSIT,1500.00\n" +
+		"2025-03-22,E-TRANSFER FROM ACME WIDGETS,1500.00\n" +
+		"2025-03-25,GLOBEX CORP PAYMENT,500.00\n" +
+		"2025-03-26,MOBILE DEPOSIT,250.00\n" +
+		"2025-08-01,GLOBEX CORP PAYMENT,800.00\n"})
+	require.NoError(t, err)
+
+	suggestions := matches.Suggest(user.ID)
+	byInvoice := map[string]dto.InvoiceMatchOutput{}
+	for _, m := range suggestions {
+		byInvoice[m.InvoiceNumber] = m
+	}
+	require.Len(t, suggestions, 3, "the draft, and deposits before issue or long after, are not proposed")
+	assert.Equal(t, "E-TRANSFER FROM ACME WIDGETS", byInvoice["M-1"].Description, "the named deposit wins over an earlier anonymous one")
+	assert.True(t, byInvoice["M-1"].NameMatch)
+	assert.Equal(t, 21, byInvoice["M-1"].DaysAfterIssue)
+	assert.Equal(t, "DEPOSIT", byInvoice["M-2"].Description)
+	assert.False(t, byInvoice["M-2"].NameMatch)
+	assert.InDelta(t, 500, byInvoice["M-3"].Outstanding, 0.001)
+	assert.True(t, byInvoice["M-3"].NameMatch)
+
+	payment, err := matches.Confirm(user.ID, dto.ConfirmInvoiceMatchInput{InvoiceID: a1.ID, TransactionID: byInvoice["M-1"].TransactionID})
+	require.NoError(t, err)
+	assert.Equal(t, "2025-03-22", payment.Date)
+	assert.Equal(t, byInvoice["M-1"].TransactionID, payment.TransactionID)
+	inv, err := invoices.Get(user.ID, a1.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "paid", inv.Status)
+
+	_, err = matches.Confirm(user.ID, dto.ConfirmInvoiceMatchInput{InvoiceID: a1.ID, TransactionID: byInvoice["M-2"].TransactionID})
+	assert.ErrorContains(t, err, "already paid")
+	_, err = matches.Confirm(user.ID, dto.ConfirmInvoiceMatchInput{InvoiceID: g1.ID, TransactionID: byInvoice["M-1"].TransactionID})
+	assert.ErrorContains(t, err, "already recorded")
+
+	_, err = matches.Confirm(user.ID, dto.ConfirmInvoiceMatchInput{InvoiceID: g2.ID, TransactionID: byInvoice["M-3"].TransactionID})
+	require.NoError(t, err)
+	inv, err = invoices.Get(user.ID, g2.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "paid", inv.Status, "the deposit settles the rest")
+
+	// With Acme settled the earlier anonymous deposit is still offered for M-2.
+	suggestions = matches.Suggest(user.ID)
+	require.Len(t, suggestions, 1)
+	assert.Equal(t, g1.ID, suggestions[0].InvoiceID)
+	assert.Len(t, NewPaymentService(db).List(user.ID, dto.PaymentFilter{ClientID: globex.ID}), 2)
+}
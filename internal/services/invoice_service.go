@@ -1,23 +1,19 @@
 package services
 
 import (
-	"bytes"
-	"crypto/tls"
 	"database/sql"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"log"
-	"net/smtp"
-	"os"
+	"math"
 	"strings"
 	"tally/internal/dto"
 	"tally/internal/mapper"
 	"tally/internal/models"
 	"tally/internal/pdf"
 	"tally/internal/utils"
-
-	"github.com/resend/resend-go/v3"
+	"time"
 )
 
 // InvoiceService handles all invoice-related operations.
@@ -35,8 +31,10 @@ func (s *InvoiceService) List(userID int) []dto.InvoiceOutput {
 	rows, err := s.db.Query(`
 		SELECT i.id, i.client_id, 
 		(SELECT project_id FROM time_entries WHERE invoice_id = i.id LIMIT 1) as project_id,
-		i.number, i.issue_date, i.due_date, i.subtotal, i.tax_rate, i.tax_amount, i.total, i.status, i.items_json 
-		FROM invoices i WHERE i.user_id = ?`, userID)
+		i.number, i.issue_date, i.due_date, i.subtotal, i.tax_rate, i.tax_amount, i.total, i.status, i.items_json,
+		COALESCE(i.purchase_order_id, 0), COALESCE(po.number, '')
+		FROM invoices i LEFT JOIN purchase_orders po ON po.id = i.purchase_order_id
+		WHERE i.user_id = ?`, userID)
 	if err != nil {
 		log.Println("Error querying invoices:", err)
 		return []dto.InvoiceOutput{}
@@ -50,10 +48,11 @@ func (s *InvoiceService) List(userID int) []dto.InvoiceOutput {
 	for rows.Next() {
 		var id, clientId int
 		var projectId sql.NullInt64
-		var number, issueDate, dueDate, status, itemsJSON string
+		var number, issueDate, dueDate, status, itemsJSON, poNumber string
 		var subtotal, taxRate, taxAmount, total float64
+		var poID int
 
-		err := rows.Scan(&id, &clientId, &projectId, &number, &issueDate, &dueDate, &subtotal, &taxRate, &taxAmount, &total, &status, &itemsJSON)
+		err := rows.Scan(&id, &clientId, &projectId, &number, &issueDate, &dueDate, &subtotal, &taxRate, &taxAmount, &total, &status, &itemsJSON, &poID, &poNumber)
 		if err != nil {
 			log.Println("Error scanning invoice:", err)
 			continue
@@ -82,6 +81,11 @@ func (s *InvoiceService) List(userID int) []dto.InvoiceOutput {
 			Total:     total,
 			Status:    status,
 			Items:     items,
+
+			PurchaseOrderID:     poID,
+			PurchaseOrderNumber: poNumber,
+			Warnings:            []string{},
+			CustomFields:        []dto.CustomFieldValueOutput{},
 		})
 	}
 	return invoices
@@ -92,15 +96,17 @@ func (s *InvoiceService) Get(userID int, id int) (dto.InvoiceOutput, error) {
 	row := s.db.QueryRow(`
 		SELECT i.id, i.client_id, 
 		(SELECT project_id FROM time_entries WHERE invoice_id = i.id LIMIT 1) as project_id,
-		i.number, i.issue_date, i.due_date, i.subtotal, i.tax_rate, i.tax_amount, i.total, i.status, i.items_json 
-		FROM invoices i WHERE i.id = ? AND i.user_id = ?`, id, userID)
+		i.number, i.issue_date, i.due_date, i.subtotal, i.tax_rate, i.tax_amount, i.total, i.status, i.items_json,
+		COALESCE(i.purchase_order_id, 0), COALESCE(po.number, '')
+		FROM invoices i LEFT JOIN purchase_orders po ON po.id = i.purchase_order_id
+		WHERE i.id = ? AND i.user_id = ?`, id, userID)
 
-	var invId, clientId int
+	var invId, clientId, poID int
 	var projectId sql.NullInt64
-	var number, issueDate, dueDate, status, itemsJSON string
+	var number, issueDate, dueDate, status, itemsJSON, poNumber string
 	var subtotal, taxRate, taxAmount, total float64
 
-	err := row.Scan(&invId, &clientId, &projectId, &number, &issueDate, &dueDate, &subtotal, &taxRate, &taxAmount, &total, &status, &itemsJSON)
+	err := row.Scan(&invId, &clientId, &projectId, &number, &issueDate, &dueDate, &subtotal, &taxRate, &taxAmount, &total, &status, &itemsJSON, &poID, &poNumber)
 	if err != nil {
 		return dto.InvoiceOutput{}, err
 	}
@@ -117,7 +123,12 @@ func (s *InvoiceService) Get(userID int, id int) (dto.InvoiceOutput, error) {
 		items = []dto.InvoiceItemOutput{}
 	}
 
-	return dto.InvoiceOutput{
+	customFields, err := invoiceCustomFields(s.db, userID, invId, clientId)
+	if err != nil {
+		return dto.InvoiceOutput{}, err
+	}
+
+	out := dto.InvoiceOutput{
 		ID:        invId,
 		ClientID:  clientId,
 		ProjectID: int(projectId.Int64),
@@ -130,23 +141,50 @@ func (s *InvoiceService) Get(userID int, id int) (dto.InvoiceOutput, error) {
 		Total:     total,
 		Status:    status,
 		Items:     items,
-	}, nil
+
+		PurchaseOrderID:     poID,
+		PurchaseOrderNumber: poNumber,
+		CustomFields:        customFields,
+	}
+	out.Warnings, _ = purchaseOrderWarnings(s.db, userID, out)
+	return out, nil
 }
 
 // Create adds a new invoice for a specific user and returns the created invoice as DTO.
 func (s *InvoiceService) Create(userID int, input dto.CreateInvoiceInput) dto.InvoiceOutput {
 	entity := mapper.ToInvoiceEntity(input)
+	if entity.DueDate == "" && entity.IssueDate != "" {
+		if terms := s.paymentTerms(userID, entity.ClientID); terms != "" {
+			if due, err := utils.DueDateForTerms(entity.IssueDate, terms); err == nil {
+				entity.DueDate = due
+			} else {
+				log.Println("Error computing due date from payment terms:", err)
+			}
+		}
+	}
+	if entity.PurchaseOrderID != 0 {
+		if err := s.checkPurchaseOrderClient(userID, entity.PurchaseOrderID, entity.ClientID); err != nil {
+			log.Println("Error creating invoice:", err)
+			return dto.InvoiceOutput{}
+		}
+	}
+	if entity.Status == "sent" {
+		if err := s.requirePurchaseOrder(userID, dto.InvoiceOutput{ClientID: entity.ClientID, Number: entity.Number, IssueDate: entity.IssueDate, PurchaseOrderID: entity.PurchaseOrderID}); err != nil {
+			log.Println("Error creating invoice:", err)
+			return dto.InvoiceOutput{}
+		}
+	}
 	itemsBytes, _ := json.Marshal(entity.Items)
 	itemsJSON := string(itemsBytes)
 
-	stmt, err := s.db.Prepare("INSERT INTO invoices(user_id, client_id, number, issue_date, due_date, subtotal, tax_rate, tax_amount, total, status, items_json) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+	stmt, err := s.db.Prepare("INSERT INTO invoices(user_id, client_id, number, issue_date, due_date, subtotal, tax_rate, tax_amount, total, status, items_json, purchase_order_id) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
 	if err != nil {
 		log.Println("Error preparing invoice insert:", err)
 		return dto.InvoiceOutput{}
 	}
 	defer closeWithLog(stmt, "closing invoice insert statement")
 
-	res, err := stmt.Exec(userID, entity.ClientID, entity.Number, entity.IssueDate, entity.DueDate, entity.Subtotal, entity.TaxRate, entity.TaxAmount, entity.Total, entity.Status, itemsJSON)
+	res, err := stmt.Exec(userID, entity.ClientID, entity.Number, entity.IssueDate, entity.DueDate, entity.Subtotal, entity.TaxRate, entity.TaxAmount, entity.Total, entity.Status, itemsJSON, nullableID(entity.PurchaseOrderID))
 	if err != nil {
 		log.Println("Error inserting invoice:", err)
 		return dto.InvoiceOutput{}
@@ -154,6 +192,9 @@ func (s *InvoiceService) Create(userID int, input dto.CreateInvoiceInput) dto.In
 
 	id, _ := res.LastInsertId()
 	entity.ID = int(id)
+	if output, err := s.Get(userID, entity.ID); err == nil {
+		return output
+	}
 	return mapper.ToInvoiceOutput(entity)
 }
 
@@ -164,14 +205,33 @@ func (s *InvoiceService) Update(userID int, input dto.UpdateInvoiceInput) dto.In
 	itemsBytes, _ := json.Marshal(items)
 	itemsJSON := string(itemsBytes)
 
-	stmt, err := s.db.Prepare("UPDATE invoices SET client_id=?, number=?, issue_date=?, due_date=?, subtotal=?, tax_rate=?, tax_amount=?, total=?, status=?, items_json=? WHERE id=? AND user_id=?")
+	current, err := s.Get(userID, input.ID)
+	if err != nil {
+		log.Println("Error updating invoice:", err)
+		return dto.InvoiceOutput{}
+	}
+	// A PO belongs to one client; moving the invoice to another client unlinks it.
+	if current.PurchaseOrderID != 0 && input.ClientID != current.ClientID {
+		if err := s.checkPurchaseOrderClient(userID, current.PurchaseOrderID, input.ClientID); err != nil {
+			current.PurchaseOrderID = 0
+		}
+	}
+	if input.Status == "sent" && current.Status != "sent" {
+		current.ClientID, current.IssueDate = input.ClientID, input.IssueDate
+		if err := s.requirePurchaseOrder(userID, current); err != nil {
+			log.Println("Error updating invoice:", err)
+			return dto.InvoiceOutput{}
+		}
+	}
+
+	stmt, err := s.db.Prepare("UPDATE invoices SET client_id=?, number=?, issue_date=?, due_date=?, subtotal=?, tax_rate=?, tax_amount=?, total=?, status=?, items_json=?, purchase_order_id=? WHERE id=? AND user_id=?")
 	if err != nil {
 		log.Println("Error preparing invoice update:", err)
 		return dto.InvoiceOutput{}
 	}
 	defer closeWithLog(stmt, "closing invoice update statement")
 
-	_, err = stmt.Exec(input.ClientID, input.Number, input.IssueDate, input.DueDate, input.Subtotal, input.TaxRate, input.TaxAmount, input.Total, input.Status, itemsJSON, input.ID, userID)
+	_, err = stmt.Exec(input.ClientID, input.Number, input.IssueDate, input.DueDate, input.Subtotal, input.TaxRate, input.TaxAmount, input.Total, input.Status, itemsJSON, nullableID(current.PurchaseOrderID), input.ID, userID)
 	if err != nil {
 		log.Println("Error updating invoice:", err)
 		return dto.InvoiceOutput{}
@@ -181,8 +241,18 @@ func (s *InvoiceService) Update(userID int, input dto.UpdateInvoiceInput) dto.In
 	return output
 }
 
-// UpdateStatus updates the status of an invoice.
+// UpdateStatus updates the status of an invoice. Marking an invoice sent
+// needs a valid purchase order when the client requires one.
 func (s *InvoiceService) UpdateStatus(userID int, invoiceID int, status string) error {
+	if status == "sent" {
+		inv, err := s.Get(userID, invoiceID)
+		if err != nil {
+			return fmt.Errorf("invoice not found or not owned by user")
+		}
+		if err := s.requirePurchaseOrder(userID, inv); err != nil {
+			return err
+		}
+	}
 	stmt, err := s.db.Prepare("UPDATE invoices SET status=? WHERE id=? AND user_id=?")
 	if err != nil {
 		log.Println("Error preparing invoice status update:", err)
@@ -209,6 +279,11 @@ func (s *InvoiceService) Delete(userID int, id int) {
 	_, err := s.db.Exec("DELETE FROM invoices WHERE id=? AND user_id=?", id, userID)
 	if err != nil {
 		log.Println("Error deleting invoice:", err)
+		return
+	}
+	// Release billed expenses so they can be pulled onto another invoice.
+	if _, err := s.db.Exec("UPDATE finance_transactions SET invoice_id = NULL WHERE invoice_id=? AND user_id=?", id, userID); err != nil {
+		log.Println("Error releasing invoice expenses:", err)
 	}
 }
 
@@ -242,109 +317,90 @@ func (s *InvoiceService) SendEmail(userID int, invoiceID int) error {
 		return fmt.Errorf("failed to decode PDF: %w", err)
 	}
 
-	// mailto just indicate success (front-end opens client)
-	if emailSettings.Provider == "mailto" {
-		return nil
+	fields := customFieldPlaceholders(invoice.CustomFields)
+	if err := s.requirePurchaseOrder(userID, invoice); err != nil {
+		return err
 	}
 
-	// Resend provider
-	if emailSettings.Provider == "resend" {
-		apiKey := emailSettings.ResendAPIKey
-		if apiKey == "" {
-			return fmt.Errorf("resend API key is missing")
-		}
-		subject := utils.ApplyTemplate(emailSettings.SubjectTemplate, invoice)
-		if subject == "" {
-			subject = fmt.Sprintf("Invoice %s", invoice.Number)
-		}
-		body := utils.ApplyTemplate(emailSettings.BodyTemplate, invoice)
-		if body == "" {
-			body = "Please see attached invoice."
-		}
-
-		if os.Getenv("RESEND_DRY_RUN") == "1" {
-			log.Println("SendEmail: RESEND_DRY_RUN enabled, skipping network call")
-			return nil
-		}
-
-		clientResend := resend.NewClient(apiKey)
-		_, err := clientResend.Emails.Send(&resend.SendEmailRequest{
-			From:    emailSettings.FromEmail,
-			To:      []string{client.Email},
-			Subject: subject,
-			Html:    body,
-			Attachments: []*resend.Attachment{
-				{
-					Filename: fmt.Sprintf("INV-%s.pdf", invoice.Number),
-					Content:  pdfBytes,
-				},
-			},
-		})
-		if err != nil {
-			log.Println("SendEmail: resend send failed:", err)
-			return fmt.Errorf("resend failed: %v", err)
-		}
-		// Auto-update status to 'sent' if currently 'draft'
-		if invoice.Status == "draft" {
-			if err := s.UpdateStatus(userID, invoiceID, "sent"); err != nil {
-				log.Println("SendEmail: failed to update status after resend:", err)
-				// Don't return error - email was sent successfully
-			}
-		}
-		return nil
+	subject := utils.ApplyTemplate(emailSettings.SubjectTemplate, invoice, fields)
+	if subject == "" {
+		subject = fmt.Sprintf("Invoice %s", invoice.Number)
+	}
+	body := utils.ApplyTemplate(emailSettings.BodyTemplate, invoice, fields)
+	if body == "" {
+		body = "Please see attached invoice."
 	}
 
-	// SMTP provider
-	if emailSettings.Provider == "smtp" {
-		// Validate SMTP settings
-		if emailSettings.SMTPHost == "" {
-			return fmt.Errorf("smtp host is missing")
-		}
-		if emailSettings.SMTPUsername == "" {
-			return fmt.Errorf("smtp username is missing")
-		}
-		if emailSettings.SMTPPassword == "" {
-			return fmt.Errorf("smtp password is missing")
-		}
-		if emailSettings.FromEmail == "" {
-			return fmt.Errorf("smtp from email is missing")
-		}
-
-		subject := utils.ApplyTemplate(emailSettings.SubjectTemplate, invoice)
-		if subject == "" {
-			subject = fmt.Sprintf("Invoice %s", invoice.Number)
-		}
-		body := utils.ApplyTemplate(emailSettings.BodyTemplate, invoice)
-		if body == "" {
-			body = "Please see attached invoice."
-		}
-
-		// Add signature if provided
-		if emailSettings.Signature != "" {
-			body += "\n\n" + emailSettings.Signature
+	recipients, err := NewClientContactService(s.db).GetInvoiceRecipients(userID, client.ID)
+	if err != nil {
+		return err
+	}
+
+	delivered, err := deliverEmail(emailSettings, outgoingEmail{
+		To:                 recipients.To,
+		CC:                 recipients.CC,
+		BCC:                recipients.BCC,
+		Subject:            subject,
+		Body:               body,
+		AttachmentName:     fmt.Sprintf("INV-%s.pdf", invoice.Number),
+		SMTPAttachmentName: fmt.Sprintf("invoice-%s.pdf", invoice.Number),
+		Attachment:         pdfBytes,
+	})
+	if err != nil {
+		log.Println("SendEmail: delivery failed:", err)
+		return err
+	}
+	// Auto-update status to 'sent' if currently 'draft'
+	if delivered && invoice.Status == "draft" {
+		if err := s.UpdateStatus(userID, invoiceID, "sent"); err != nil {
+			log.Println("SendEmail: failed to update status after send:", err)
+			// Don't return error - email was sent successfully
 		}
+	}
+	return nil
+}
 
-		if os.Getenv("SMTP_DRY_RUN") == "1" {
-			log.Println("SendEmail: SMTP_DRY_RUN enabled, skipping network call")
-			return nil
+// SetPurchaseOrder bills an invoice against one of its client's purchase
+// orders, or unlinks it when PurchaseOrderID is 0. Check Warnings on the
+// result for an exceeded authorized amount or a PO outside its validity.
+func (s *InvoiceService) SetPurchaseOrder(userID int, input dto.SetInvoicePurchaseOrderInput) (dto.InvoiceOutput, error) {
+	inv, err := s.Get(userID, input.InvoiceID)
+	if err != nil {
+		return dto.InvoiceOutput{}, fmt.Errorf("invoice not found: %w", err)
+	}
+	if input.PurchaseOrderID != 0 {
+		if err := s.checkPurchaseOrderClient(userID, input.PurchaseOrderID, inv.ClientID); err != nil {
+			return dto.InvoiceOutput{}, err
 		}
+	}
+	if _, err := s.db.Exec("UPDATE invoices SET purchase_order_id = ? WHERE id = ? AND user_id = ?", nullableID(input.PurchaseOrderID), inv.ID, userID); err != nil {
+		return dto.InvoiceOutput{}, fmt.Errorf("failed to link purchase order: %w", err)
+	}
+	return s.Get(userID, inv.ID)
+}
 
-		// Send email via SMTP
-		if err := s.sendViaSMTP(emailSettings, client.Email, subject, body, pdfBytes, invoice.Number); err != nil {
-			log.Println("SendEmail: SMTP send failed:", err)
-			return fmt.Errorf("smtp failed: %w", err)
-		}
-		// Auto-update status to 'sent' if currently 'draft'
-		if invoice.Status == "draft" {
-			if err := s.UpdateStatus(userID, invoiceID, "sent"); err != nil {
-				log.Println("SendEmail: failed to update status after smtp:", err)
-				// Don't return error - email was sent successfully
-			}
-		}
-		return nil
+// requirePurchaseOrder refuses an invoice for a client that requires a
+// purchase order unless it is billed against a valid one. Drafts may be
+// created, edited and previewed without a PO; creating an invoice as sent,
+// marking it sent and emailing it are where the requirement is enforced.
+func (s *InvoiceService) requirePurchaseOrder(userID int, inv dto.InvoiceOutput) error {
+	if warnings, blocked := purchaseOrderWarnings(s.db, userID, inv); blocked {
+		return fmt.Errorf("invoice %s needs a valid purchase order: %s", inv.Number, strings.Join(warnings, " "))
 	}
+	return nil
+}
 
-	return fmt.Errorf("unknown email provider: %s", emailSettings.Provider)
+// checkPurchaseOrderClient verifies that a PO belongs to the user and client.
+func (s *InvoiceService) checkPurchaseOrderClient(userID int, purchaseOrderID int, clientID int) error {
+	var poClientID int
+	err := s.db.QueryRow("SELECT client_id FROM purchase_orders WHERE id = ? AND user_id = ?", purchaseOrderID, userID).Scan(&poClientID)
+	if err != nil {
+		return fmt.Errorf("purchase order not found: %w", err)
+	}
+	if poClientID != clientID {
+		return fmt.Errorf("purchase order belongs to another client")
+	}
+	return nil
 }
 
 // SetTimeEntries associates time entries with an invoice and recalculates totals.
@@ -380,6 +436,90 @@ func (s *InvoiceService) SetTimeEntries(userID int, input dto.SetInvoiceTimeEntr
 	return updated, nil
 }
 
+// ListBillableExpenses returns reimbursable expenses for a client that are not yet on an invoice.
+func (s *InvoiceService) ListBillableExpenses(userID int, clientID int) []dto.TransactionOutput {
+	rows, err := s.db.Query(`
+SELECT id, account_id, date, description, amount, project_id
+FROM finance_transactions
+WHERE user_id = ? AND client_id = ? AND expense_type = 'reimbursable' AND invoice_id IS NULL
+ORDER BY date ASC, id ASC`, userID, clientID)
+	if err != nil {
+		log.Println("Error querying billable expenses:", err)
+		return []dto.TransactionOutput{}
+	}
+	defer closeWithLog(rows, "closing billable expense rows")
+
+	out := []dto.TransactionOutput{}
+	for rows.Next() {
+		var t dto.TransactionOutput
+		var dateStr string
+		var projectID sql.NullInt64
+		if err := rows.Scan(&t.ID, &t.AccountID, &dateStr, &t.Description, &t.Amount, &projectID); err != nil {
+			log.Println("Error scanning billable expense:", err)
+			continue
+		}
+		if d, err := parseDate(dateStr); err == nil {
+			t.Date = d
+		}
+		cid := clientID
+		t.ClientID = &cid
+		t.ProjectID = nullIntPtr(projectID)
+		t.ExpenseType = "reimbursable"
+		out = append(out, t)
+	}
+	return out
+}
+
+// SetExpenses associates reimbursable expenses with an invoice and recalculates totals.
+func (s *InvoiceService) SetExpenses(userID int, input dto.SetInvoiceExpensesInput) (dto.InvoiceOutput, error) {
+	inv, err := s.Get(userID, input.InvoiceID)
+	if err != nil {
+		return dto.InvoiceOutput{}, fmt.Errorf("invoice not found: %w", err)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return dto.InvoiceOutput{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec("UPDATE finance_transactions SET invoice_id = NULL WHERE user_id = ? AND invoice_id = ?", userID, input.InvoiceID); err != nil {
+		return dto.InvoiceOutput{}, fmt.Errorf("failed to clear previous expenses: %w", err)
+	}
+	for _, id := range input.TransactionIDs {
+		res, err := tx.Exec(`
+UPDATE finance_transactions SET invoice_id = ?
+WHERE user_id = ? AND id = ? AND client_id = ? AND expense_type = 'reimbursable' AND invoice_id IS NULL`,
+			input.InvoiceID, userID, id, inv.ClientID)
+		if err != nil {
+			return dto.InvoiceOutput{}, fmt.Errorf("failed to link expense %d: %w", id, err)
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return dto.InvoiceOutput{}, fmt.Errorf("expense %d is not an unbilled reimbursable expense of this client", id)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return dto.InvoiceOutput{}, err
+	}
+
+	return s.recalculateInvoiceFromTimeEntries(userID, input.InvoiceID, inv.TaxRate)
+}
+
+// PullReimbursableExpenses adds every unbilled reimbursable expense of the invoice's client to the invoice.
+func (s *InvoiceService) PullReimbursableExpenses(userID int, invoiceID int) (dto.InvoiceOutput, error) {
+	inv, err := s.Get(userID, invoiceID)
+	if err != nil {
+		return dto.InvoiceOutput{}, fmt.Errorf("invoice not found: %w", err)
+	}
+	if _, err := s.db.Exec(`
+UPDATE finance_transactions SET invoice_id = ?
+WHERE user_id = ? AND client_id = ? AND expense_type = 'reimbursable' AND invoice_id IS NULL`,
+		invoiceID, userID, inv.ClientID); err != nil {
+		return dto.InvoiceOutput{}, fmt.Errorf("failed to pull expenses: %w", err)
+	}
+	return s.recalculateInvoiceFromTimeEntries(userID, invoiceID, inv.TaxRate)
+}
+
 // GeneratePDF builds a PDF based on invoice, client, settings, and linked time entries.
 func (s *InvoiceService) GeneratePDF(userID int, invoiceID int, message string) (string, error) {
 	invoice, err := s.Get(userID, invoiceID)
@@ -402,6 +542,10 @@ func (s *InvoiceService) GeneratePDF(userID int, invoiceID int, message string)
 		finalMessage = s.buildDefaultMessage(userID, invoice.ID, settings)
 	}
 
+	if terms := s.paymentTerms(userID, client.ID); terms != "" {
+		settings.InvoiceTerms = utils.PaymentTermsLabel(terms)
+	}
+
 	// Ensure we use latest totals and items derived from linked time entries
 	invoice = s.ensureInvoiceRecalcForPDF(userID, invoice, settings)
 
@@ -434,15 +578,142 @@ func (s *InvoiceService) GetDefaultMessage(userID int, invoiceID int) (string, e
 
 // getClient fetches client detail for invoices.
 func (s *InvoiceService) getClient(userID int, clientID int) (models.Client, error) {
-	row := s.db.QueryRow("SELECT id, name, email, website, avatar, contact_person, address, currency, status, notes, billing_company, billing_address, billing_city, billing_province, billing_postal_code FROM clients WHERE id = ? AND user_id = ?", clientID, userID)
+	row := s.db.QueryRow("SELECT id, name, email, website, avatar, contact_person, address, currency, status, notes, billing_company, billing_address, billing_city, billing_province, billing_postal_code, COALESCE(payment_terms, '') FROM clients WHERE id = ? AND user_id = ?", clientID, userID)
 	var c models.Client
-	err := row.Scan(&c.ID, &c.Name, &c.Email, &c.Website, &c.Avatar, &c.ContactPerson, &c.Address, &c.Currency, &c.Status, &c.Notes, &c.BillingCompany, &c.BillingAddress, &c.BillingCity, &c.BillingProvince, &c.BillingPostalCode)
+	err := row.Scan(&c.ID, &c.Name, &c.Email, &c.Website, &c.Avatar, &c.ContactPerson, &c.Address, &c.Currency, &c.Status, &c.Notes, &c.BillingCompany, &c.BillingAddress, &c.BillingCity, &c.BillingProvince, &c.BillingPostalCode, &c.PaymentTerms)
 	if err != nil {
 		return models.Client{}, err
 	}
 	return c, nil
 }
 
+// paymentTerms returns the terms code for a client's invoices: the client's own
+// terms when set, otherwise the user's default terms.
+func (s *InvoiceService) paymentTerms(userID int, clientID int) string {
+	var terms string
+	err := s.db.QueryRow(`
+SELECT COALESCE(NULLIF(c.payment_terms, ''), uis.payment_terms, '')
+FROM clients c
+LEFT JOIN user_invoice_settings uis ON uis.user_id = c.user_id
+WHERE c.id = ? AND c.user_id = ?`, clientID, userID).Scan(&terms)
+	if err != nil {
+		log.Println("Error loading payment terms:", err)
+		return ""
+	}
+	return terms
+}
+
+// ApplyLateFees charges the configured late fee on unpaid invoices past their due date
+// and marks them overdue. One fee is charged per month (or part month) overdue as of
+// asOf (YYYY-MM-DD); fees already charged are not repeated. It returns the number of
+// fees added.
+func (s *InvoiceService) ApplyLateFees(userID int, asOf string) (int, error) {
+	asOfDate, err := time.Parse("2006-01-02", asOf)
+	if err != nil {
+		return 0, fmt.Errorf("invalid date %q: expected YYYY-MM-DD", asOf)
+	}
+	rule, _ := NewUserInvoiceSettingsService(s.db).Get(userID)
+
+	rows, err := s.db.Query(`
+SELECT i.id, i.due_date, i.tax_rate,
+       i.total - COALESCE((SELECT SUM(amount) FROM payments WHERE invoice_id = i.id), 0),
+       COALESCE((SELECT MAX(period) FROM invoice_late_fees WHERE invoice_id = i.id), 0),
+       COALESCE((SELECT SUM(amount) FROM invoice_late_fees WHERE invoice_id = i.id), 0)
+FROM invoices i
+WHERE i.user_id = ? AND i.status IN ('sent', 'overdue') AND i.due_date != '' AND i.due_date < ?`, userID, asOf)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query overdue invoices: %w", err)
+	}
+	var overdue []overdueInvoice
+	for rows.Next() {
+		var inv overdueInvoice
+		var due string
+		if err := rows.Scan(&inv.id, &due, &inv.taxRate, &inv.balance, &inv.charged, &inv.feeTotal); err != nil {
+			log.Println("Error scanning overdue invoice:", err)
+			continue
+		}
+		if inv.due, err = time.Parse("2006-01-02", due); err != nil {
+			continue
+		}
+		overdue = append(overdue, inv)
+	}
+	closeWithLog(rows, "closing overdue invoice rows")
+
+	added := 0
+	for _, inv := range overdue {
+		if inv.balance <= 0.005 {
+			continue
+		}
+		n, err := s.applyLateFees(userID, inv, rule, asOfDate)
+		if err != nil {
+			return added, err
+		}
+		added += n
+	}
+	return added, nil
+}
+
+// overdueInvoice is an unpaid invoice past its due date.
+type overdueInvoice struct {
+	id       int
+	due      time.Time
+	taxRate  float64
+	balance  float64
+	charged  int // months already charged a late fee
+	feeTotal float64
+}
+
+// applyLateFees marks one invoice overdue, charges the fees for the months
+// not yet charged and recalculates its totals, all in one transaction.
+func (s *InvoiceService) applyLateFees(userID int, inv overdueInvoice, rule dto.UserInvoiceSettings, asOf time.Time) (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec("UPDATE invoices SET status = 'overdue' WHERE id = ? AND user_id = ?", inv.id, userID); err != nil {
+		return 0, fmt.Errorf("failed to mark invoice overdue: %w", err)
+	}
+	added := 0
+	if rule.LateFeeType != "" && rule.LateFeeValue > 0 {
+		// Percentage fees apply to the unpaid balance, excluding earlier fees.
+		base := inv.balance - inv.feeTotal
+		periods := 0
+		for periods < 120 && inv.due.AddDate(0, periods, 0).Before(asOf) {
+			periods++
+		}
+		for period := inv.charged + 1; period <= periods; period++ {
+			amount := rule.LateFeeValue
+			description := fmt.Sprintf("Late fee - month %d overdue", period)
+			if rule.LateFeeType == "percent" {
+				amount = base * rule.LateFeeValue / 100
+				description = fmt.Sprintf("Late fee %.2f%% - month %d overdue", rule.LateFeeValue, period)
+			}
+			amount = math.Round(amount*100) / 100
+			if amount <= 0 {
+				continue
+			}
+			if _, err := tx.Exec(
+				"INSERT INTO invoice_late_fees(user_id, invoice_id, period, amount, description) VALUES(?, ?, ?, ?, ?)",
+				userID, inv.id, period, amount, description,
+			); err != nil {
+				return 0, fmt.Errorf("failed to add late fee: %w", err)
+			}
+			added++
+		}
+		if added > 0 {
+			if err := s.recalculateInvoice(tx, userID, inv.id, inv.taxRate); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return added, nil
+}
+
 // getUserSettings loads settings from the new dedicated tables (preferences, tax, invoice).
 func (s *InvoiceService) getUserSettings(userID int) (models.UserSettings, error) {
 	prefsSvc := NewUserPreferencesService(s.db)
@@ -523,27 +794,41 @@ ORDER BY date ASC, start_time ASC`
 }
 
 // recalculateInvoiceFromTimeEntries recalculates subtotal/tax/total and items_json based on linked time entries.
+// Entries are priced with the rate in force on their date, so one project may yield several lines.
+// Reimbursable expenses billed on the invoice are appended as their own lines.
 func (s *InvoiceService) recalculateInvoiceFromTimeEntries(userID int, invoiceID int, taxRate float64) (dto.InvoiceOutput, error) {
+	if err := s.recalculateInvoice(s.db, userID, invoiceID, taxRate); err != nil {
+		return dto.InvoiceOutput{}, err
+	}
+	return s.Get(userID, invoiceID)
+}
+
+// recalculateInvoice rebuilds an invoice's lines and totals through exec, so
+// callers can do it inside their own transaction.
+func (s *InvoiceService) recalculateInvoice(exec sqlExecutor, userID int, invoiceID int, taxRate float64) error {
+	type lineKey struct {
+		ProjectID int
+		Hourly    float64
+	}
 	type entryRow struct {
-		ProjectID   int
 		Project     string
-		Hourly      float64
 		Currency    string
 		ServiceType string
 		Hours       float64
 	}
 
-	rows, err := s.db.Query(`
-SELECT p.id, p.name, COALESCE(p.hourly_rate, 0), COALESCE(p.currency, ''), COALESCE(p.service_type, ''), te.duration_seconds
+	rows, err := exec.Query(`
+SELECT p.id, p.name, `+effectiveRateSQL+`, COALESCE(p.currency, ''), COALESCE(p.service_type, ''), te.duration_seconds
 FROM time_entries te
 JOIN projects p ON te.project_id = p.id
-WHERE te.user_id = ? AND te.invoice_id = ?`, userID, invoiceID)
+WHERE te.user_id = ? AND te.invoice_id = ?
+ORDER BY p.id ASC, te.date ASC`, userID, invoiceID)
 	if err != nil {
-		return dto.InvoiceOutput{}, fmt.Errorf("failed to load time entries for invoice: %w", err)
+		return fmt.Errorf("failed to load time entries for invoice: %w", err)
 	}
-	defer closeWithLog(rows, "closing recalc time entries rows")
 
-	projectHours := map[int]entryRow{}
+	lines := map[lineKey]entryRow{}
+	var order []lineKey
 	for rows.Next() {
 		var pid int
 		var name, currency, serviceType string
@@ -553,22 +838,22 @@ WHERE te.user_id = ? AND te.invoice_id = ?`, userID, invoiceID)
 			log.Println("Error scanning time entry for recalc:", err)
 			continue
 		}
-		r := projectHours[pid]
-		if r.ProjectID == 0 {
-			r.ProjectID = pid
-			r.Project = name
-			r.Hourly = rate
-			r.Currency = currency
-			r.ServiceType = serviceType
+		key := lineKey{ProjectID: pid, Hourly: rate}
+		r, ok := lines[key]
+		if !ok {
+			r = entryRow{Project: name, Currency: currency, ServiceType: serviceType}
+			order = append(order, key)
 		}
 		r.Hours += float64(seconds) / 3600
-		projectHours[pid] = r
+		lines[key] = r
 	}
+	closeWithLog(rows, "closing recalc time entries rows")
 
 	var items []models.InvoiceItem
 	var subtotal float64
-	for _, r := range projectHours {
-		amount := r.Hours * r.Hourly
+	for _, key := range order {
+		r := lines[key]
+		amount := r.Hours * key.Hourly
 		description := utils.FormatServiceType(r.ServiceType)
 		if description == "" {
 			description = r.Project
@@ -576,185 +861,101 @@ WHERE te.user_id = ? AND te.invoice_id = ?`, userID, invoiceID)
 		item := models.InvoiceItem{
 			Description: description,
 			Quantity:    r.Hours,
-			UnitPrice:   r.Hourly,
+			UnitPrice:   key.Hourly,
 			Amount:      amount,
 		}
 		items = append(items, item)
 		subtotal += amount
 	}
 
+	expenseItems, err := s.loadExpenseItems(exec, userID, invoiceID)
+	if err != nil {
+		return err
+	}
+	for _, item := range expenseItems {
+		items = append(items, item)
+		subtotal += item.Amount
+	}
+
+	// Late fees are billed as their own lines but are not taxed.
+	taxAmount := subtotal * taxRate
+	feeItems, err := s.loadLateFeeItems(exec, userID, invoiceID)
+	if err != nil {
+		return err
+	}
+	for _, item := range feeItems {
+		items = append(items, item)
+		subtotal += item.Amount
+	}
+
 	// Update invoice record
 	itemsBytes, _ := json.Marshal(items)
 	itemsJSON := string(itemsBytes)
-	taxAmount := subtotal * taxRate
 	total := subtotal + taxAmount
 
-	if _, err := s.db.Exec(`
+	if _, err := exec.Exec(`
 UPDATE invoices
 SET subtotal=?, tax_amount=?, total=?, items_json=?
 WHERE id=? AND user_id=?`, subtotal, taxAmount, total, itemsJSON, invoiceID, userID); err != nil {
-		return dto.InvoiceOutput{}, fmt.Errorf("failed to update invoice totals: %w", err)
+		return fmt.Errorf("failed to update invoice totals: %w", err)
 	}
-
-	// Fetch updated
-	return s.Get(userID, invoiceID)
+	return nil
 }
 
-func (s *InvoiceService) sendViaSMTP(settings dto.InvoiceEmailSettings, toEmail, subject, body string, pdfBytes []byte, invoiceNumber string) error {
-	// Setup auth
-	auth := smtp.PlainAuth("", settings.SMTPUsername, settings.SMTPPassword, settings.SMTPHost)
-
-	// Setup headers
-	headers := make(map[string]string)
-	headers["From"] = settings.FromEmail
-	headers["To"] = toEmail
-	headers["Subject"] = subject
-	headers["MIME-Version"] = "1.0"
-	boundary := "f46d043c8aa2b9211f43924705572551" // Random boundary
-	headers["Content-Type"] = "multipart/mixed; boundary=" + boundary
-
-	// Body buffer
-	var msg bytes.Buffer
-	for k, v := range headers {
-		msg.WriteString(fmt.Sprintf("%s: %s\r\n", k, v))
-	}
-	msg.WriteString("\r\n")
-
-	// Message body
-	msg.WriteString("--" + boundary + "\r\n")
-	msg.WriteString("Content-Type: text/html; charset=\"UTF-8\"\r\n")
-	msg.WriteString("\r\n")
-	msg.WriteString(body)
-	msg.WriteString("\r\n")
-
-	// Attachment
-	msg.WriteString("--" + boundary + "\r\n")
-	msg.WriteString("Content-Type: application/pdf; name=\"invoice-" + invoiceNumber + ".pdf\"\r\n")
-	msg.WriteString("Content-Transfer-Encoding: base64\r\n")
-	msg.WriteString("Content-Disposition: attachment; filename=\"invoice-" + invoiceNumber + ".pdf\"\r\n")
-	msg.WriteString("\r\n")
-
-	b64 := base64.StdEncoding.EncodeToString(pdfBytes)
-	// Split base64 lines (max 76 chars)
-	for i := 0; i < len(b64); i += 76 {
-		end := i + 76
-		if end > len(b64) {
-			end = len(b64)
-		}
-		msg.WriteString(b64[i:end] + "\r\n")
-	}
-	msg.WriteString("\r\n")
-	msg.WriteString("--" + boundary + "--")
-
-	// SMTP Connection (support TLS/StartTLS)
-	addr := fmt.Sprintf("%s:%d", settings.SMTPHost, settings.SMTPPort)
-
-	// If port 465, use implicit TLS
-	if settings.SMTPPort == 465 {
-		tlsConfig := &tls.Config{
-			InsecureSkipVerify: false,
-			MinVersion:         tls.VersionTLS12,
-			ServerName:         settings.SMTPHost,
-		}
-		conn, err := tls.Dial("tcp", addr, tlsConfig)
-		if err != nil {
-			log.Printf("SMTP: Auth failed: %v", err)
-			return fmt.Errorf("authentication failed: %w", err)
-		}
-
-		client, err := smtp.NewClient(conn, settings.SMTPHost)
-		if err != nil {
-			log.Printf("SMTP: NewClient failed: %v", err)
-			return fmt.Errorf("smtp client creation failed: %w", err)
-		}
-		defer func() {
-			if err := client.Quit(); err != nil {
-				log.Printf("SMTP: Quit failed: %v", err)
-			}
-		}()
-
-		if err := client.Auth(auth); err != nil {
-			log.Printf("SMTP: Auth failed: %v", err)
-			return fmt.Errorf("authentication failed: %w", err)
-		}
-
-		if err := client.Mail(settings.FromEmail); err != nil {
-			log.Printf("SMTP: Mail command failed: %v", err)
-			return fmt.Errorf("mail command failed: %w", err)
-		}
-
-		if err := client.Rcpt(toEmail); err != nil {
-			log.Printf("SMTP: Rcpt command failed: %v", err)
-			return fmt.Errorf("rcpt command failed (check recipient address): %w", err)
-		}
-
-		w, err := client.Data()
-		if err != nil {
-			log.Printf("SMTP: Data command failed: %v", err)
-			return fmt.Errorf("data command failed: %w", err)
-		}
-
-		if _, err := w.Write(msg.Bytes()); err != nil {
-			log.Printf("SMTP: Write failed: %v", err)
-			if closeErr := w.Close(); closeErr != nil {
-				log.Printf("SMTP: Close writer after write failure failed: %v", closeErr)
-			}
-			return fmt.Errorf("message write failed: %w", err)
-		}
-
-		if err := w.Close(); err != nil {
-			log.Printf("SMTP: Close writer failed: %v", err)
-			return fmt.Errorf("message close failed: %w", err)
-		}
-
-		return nil
-	}
-
-	// Use plain SMTP without TLS
-	client, err := smtp.Dial(addr)
+// loadLateFeeItems builds invoice lines for late fees charged on the invoice.
+func (s *InvoiceService) loadLateFeeItems(exec sqlExecutor, userID int, invoiceID int) ([]models.InvoiceItem, error) {
+	rows, err := exec.Query(`
+SELECT description, amount
+FROM invoice_late_fees
+WHERE user_id = ? AND invoice_id = ?
+ORDER BY period ASC`, userID, invoiceID)
 	if err != nil {
-		log.Printf("SMTP: Dial failed: %v", err)
-		return fmt.Errorf("smtp dial failed: %w", err)
-	}
-	defer func() {
-		if err := client.Quit(); err != nil {
-			log.Printf("SMTP: Quit failed: %v", err)
-		}
-	}()
-
-	if err := client.Auth(auth); err != nil {
-		log.Printf("SMTP: Auth failed: %v", err)
-		return fmt.Errorf("authentication failed: %w", err)
+		return nil, fmt.Errorf("failed to load late fees for invoice: %w", err)
 	}
+	defer closeWithLog(rows, "closing invoice late fee rows")
 
-	if err := client.Mail(settings.FromEmail); err != nil {
-		log.Printf("SMTP: Mail command failed: %v", err)
-		return fmt.Errorf("mail command failed: %w", err)
-	}
-
-	if err := client.Rcpt(toEmail); err != nil {
-		log.Printf("SMTP: Rcpt command failed: %v", err)
-		return fmt.Errorf("rcpt command failed: %w", err)
+	var items []models.InvoiceItem
+	for rows.Next() {
+		var item models.InvoiceItem
+		if err := rows.Scan(&item.Description, &item.Amount); err != nil {
+			log.Println("Error scanning late fee for recalc:", err)
+			continue
+		}
+		item.Quantity = 1
+		item.UnitPrice = item.Amount
+		items = append(items, item)
 	}
+	return items, nil
+}
 
-	w, err := client.Data()
+// loadExpenseItems builds invoice lines for reimbursable expenses billed on the invoice.
+func (s *InvoiceService) loadExpenseItems(exec sqlExecutor, userID int, invoiceID int) ([]models.InvoiceItem, error) {
+	rows, err := exec.Query(`
+SELECT date, description, amount
+FROM finance_transactions
+WHERE user_id = ? AND invoice_id = ?
+ORDER BY date ASC, id ASC`, userID, invoiceID)
 	if err != nil {
-		log.Printf("SMTP: Data command failed: %v", err)
-		return fmt.Errorf("data command failed: %w", err)
+		return nil, fmt.Errorf("failed to load expenses for invoice: %w", err)
 	}
+	defer closeWithLog(rows, "closing invoice expense rows")
 
-	if _, err := w.Write(msg.Bytes()); err != nil {
-		log.Printf("SMTP: Write failed: %v", err)
-		if closeErr := w.Close(); closeErr != nil {
-			log.Printf("SMTP: Close writer after write failure failed: %v", closeErr)
+	var items []models.InvoiceItem
+	for rows.Next() {
+		var date, description string
+		var amount float64
+		if err := rows.Scan(&date, &description, &amount); err != nil {
+			log.Println("Error scanning expense for recalc:", err)
+			continue
 		}
-		return fmt.Errorf("message write failed: %w", err)
-	}
-
-	if err := w.Close(); err != nil {
-		log.Printf("SMTP: Close writer failed: %v", err)
-		return fmt.Errorf("message close failed: %w", err)
+		// Expenses are stored as negative bank amounts; bill them as positive charges.
+		cost := math.Abs(amount)
+		items = append(items, models.InvoiceItem{
+			Description: fmt.Sprintf("Expense: %s (%s)", strings.TrimSpace(description), date),
+			Quantity:    1,
+			UnitPrice:   cost,
+			Amount:      cost,
+		})
 	}
-
-	return nil
+	return items, nil
 }
@@ -21,9 +21,15 @@ func TestInvoiceService_SetTimeEntries_RecalculateTotals(t *testing.T) {
 
 	schema := []string{
 		`CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, uuid TEXT, username TEXT, password_hash TEXT, settings_json TEXT DEFAULT '{}');`,
-		`CREATE TABLE clients (id INTEGER PRIMARY KEY AUTOINCREMENT, user_id INTEGER, name TEXT, billing_company TEXT, billing_address TEXT, billing_city TEXT, billing_province TEXT, billing_postal_code TEXT);`,
-		`CREATE TABLE projects (id INTEGER PRIMARY KEY AUTOINCREMENT, user_id INTEGER, client_id INTEGER, name TEXT, hourly_rate REAL, currency TEXT, service_type TEXT);`,
-		`CREATE TABLE invoices (id INTEGER PRIMARY KEY AUTOINCREMENT, user_id INTEGER, client_id INTEGER, number TEXT, issue_date TEXT, due_date TEXT, subtotal REAL, tax_rate REAL, tax_amount REAL, total REAL, status TEXT, items_json TEXT);`,
+		`CREATE TABLE clients (id INTEGER PRIMARY KEY AUTOINCREMENT, user_id INTEGER, name TEXT, billing_company TEXT, billing_address TEXT, billing_city TEXT, billing_province TEXT, billing_postal_code TEXT, requires_po BOOLEAN DEFAULT 0, archived_at DATETIME);`,
+		`CREATE TABLE projects (id INTEGER PRIMARY KEY AUTOINCREMENT, user_id INTEGER, client_id INTEGER, name TEXT, hourly_rate REAL, currency TEXT, service_type TEXT, archived_at DATETIME);`,
+		`CREATE TABLE invoices (id INTEGER PRIMARY KEY AUTOINCREMENT, user_id INTEGER, client_id INTEGER, number TEXT, issue_date TEXT, due_date TEXT, subtotal REAL, tax_rate REAL, tax_amount REAL, total REAL, status TEXT, items_json TEXT, purchase_order_id INTEGER);`,
+		`CREATE TABLE rate_cards (id INTEGER PRIMARY KEY AUTOINCREMENT, user_id INTEGER, client_id INTEGER, project_id INTEGER, rate REAL, currency TEXT, effective_from TEXT, notes TEXT);`,
+		`CREATE TABLE finance_transactions (id INTEGER PRIMARY KEY AUTOINCREMENT, user_id INTEGER, account_id INTEGER, date TEXT, description TEXT, amount REAL, client_id INTEGER, project_id INTEGER, expense_type TEXT, invoice_id INTEGER);`,
+		`CREATE TABLE invoice_late_fees (id INTEGER PRIMARY KEY AUTOINCREMENT, user_id INTEGER, invoice_id INTEGER, period INTEGER, amount REAL, description TEXT);`,
+		`CREATE TABLE custom_field_definitions (id INTEGER PRIMARY KEY AUTOINCREMENT, user_id INTEGER, entity_type TEXT, field_key TEXT, label TEXT, field_type TEXT, options_json TEXT DEFAULT '[]', show_on_invoice BOOLEAN DEFAULT 1, sort_order INTEGER DEFAULT 0);`,
+		`CREATE TABLE purchase_orders (id INTEGER PRIMARY KEY AUTOINCREMENT, user_id INTEGER, client_id INTEGER, number TEXT, authorized_amount REAL, currency TEXT, valid_from TEXT, valid_to TEXT, notes TEXT);`,
+		`CREATE TABLE custom_field_values (field_id INTEGER NOT NULL, entity_id INTEGER NOT NULL, value TEXT NOT NULL, PRIMARY KEY(field_id, entity_id));`,
 		`CREATE TABLE time_entries (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
 			user_id INTEGER,
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"tally/internal/dto"
+	"tally/internal/utils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDueDateForTerms(t *testing.T) {
+	cases := []struct {
+		terms string
+		want  string
+	}{
+		{"due_on_receipt", "2025-01-20"},
+		{"net_15", "2025-02-04"},
+		{"net_30", "2025-02-19"},
+		{"net_45", "2025-03-06"},
+		{"eom_0", "2025-01-31"},
+		{"eom_10", "2025-02-10"},
+	}
+	for _, c := range cases {
+		got, err := utils.DueDateForTerms("2025-01-20", c.terms)
+		assert.NoError(t, err, c.terms)
+		assert.Equal(t, c.want, got, c.terms)
+	}
+	_, err := utils.DueDateForTerms("2025-01-20", "net_x")
+	assert.Error(t, err)
+	assert.Equal(t, "Net 30", utils.PaymentTermsLabel("net_30"))
+	assert.Equal(t, "End of month + 10", utils.PaymentTermsLabel("eom_10"))
+}
+
+func TestInvoiceService_PaymentTermsAndLateFees(t *testing.T) {
+	db := setupFullTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	auth := NewAuthService(db)
+	user := createTestUser(t, auth, "terms_user")
+
+	settingsSvc := NewUserInvoiceSettingsService(db)
+	clientSvc := NewClientService(db)
+	invoiceSvc := NewInvoiceService(db)
+
+	// Without terms set anywhere the user's own terms text stays on the PDF.
+	unset := clientSvc.Create(user.ID, dto.CreateClientInput{Name: "Unset"})
+	assert.Empty(t, invoiceSvc.paymentTerms(user.ID, unset.ID))
+	defaults, err := settingsSvc.Get(user.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, defaults.PaymentTerms)
+
+	_, err = settingsSvc.Update(user.ID, dto.UserInvoiceSettings{PaymentTerms: "net_30", LateFeeType: "percent", LateFeeValue: 2})
+	assert.NoError(t, err)
+	_, err = settingsSvc.Update(user.ID, dto.UserInvoiceSettings{PaymentTerms: "net_thirty"})
+	assert.Error(t, err)
+
+	standard := clientSvc.Create(user.ID, dto.CreateClientInput{Name: "Standard"})
+	eom := clientSvc.Create(user.ID, dto.CreateClientInput{Name: "Month end", PaymentTerms: "eom_15"})
+	assert.Equal(t, "eom_15", eom.PaymentTerms)
+
+	// Due dates follow the user's terms unless the client overrides them.
+	inv := invoiceSvc.Create(user.ID, dto.CreateInvoiceInput{ClientID: standard.ID, Number: "T-1", IssueDate: "2025-01-10", Status: "sent"})
+	assert.Equal(t, "2025-02-09", inv.DueDate)
+	other := invoiceSvc.Create(user.ID, dto.CreateInvoiceInput{ClientID: eom.ID, Number: "T-2", IssueDate: "2025-01-10", Status: "sent"})
+	assert.Equal(t, "2025-02-15", other.DueDate)
+	manual := invoiceSvc.Create(user.ID, dto.CreateInvoiceInput{ClientID: eom.ID, Number: "T-3", IssueDate: "2025-01-10", DueDate: "2025-01-11", Status: "draft"})
+	assert.Equal(t, "2025-01-11", manual.DueDate)
+
+	// Bill 10 hours at 100 on T-1 so it has a balance.
+	projectSvc := NewProjectService(db)
+	timeSvc := NewTimesheetService(db)
+	project := projectSvc.Create(user.ID, dto.CreateProjectInput{ClientID: standard.ID, Name: "Work", HourlyRate: 100})
+	entry := timeSvc.Create(user.ID, dto.CreateTimeEntryInput{ProjectID: project.ID, Date: "2025-01-05", DurationSeconds: 36000, Billable: true})
+	_, err = invoiceSvc.SetTimeEntries(user.ID, dto.SetInvoiceTimeEntriesInput{InvoiceID: inv.ID, TimeEntryIDs: []int{entry.ID}})
+	assert.NoError(t, err)
+
+	// Not yet due: nothing happens.
+	added, err := invoiceSvc.ApplyLateFees(user.ID, "2025-02-09")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, added)
+
+	// Two months (one full, one started) overdue: two 2% fees on the 1000 balance.
+	added, err = invoiceSvc.ApplyLateFees(user.ID, "2025-03-20")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, added)
+
+	got, err := invoiceSvc.Get(user.ID, inv.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "overdue", got.Status)
+	assert.Len(t, got.Items, 3)
+	assert.InDelta(t, 1040.0, got.Total, 0.001)
+
+	// Re-running on the same date does not charge twice.
+	added, err = invoiceSvc.ApplyLateFees(user.ID, "2025-03-20")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, added)
+
+	// Recalculation keeps the fees.
+	got, err = invoiceSvc.SetTimeEntries(user.ID, dto.SetInvoiceTimeEntriesInput{InvoiceID: inv.ID, TimeEntryIDs: []int{entry.ID}})
+	assert.NoError(t, err)
+	assert.InDelta(t, 1040.0, got.Total, 0.001)
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"encoding/base64"
+	"tally/internal/dto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeName(t *testing.T) {
+	assert.Equal(t, "acme", normalizeName("Acme Inc"))
+	assert.Equal(t, "acme", normalizeName("ACME, Inc."))
+	assert.Equal(t, "acme widgets", normalizeName("  Acme   Widgets LLC "))
+	assert.Equal(t, "co", normalizeName("Co"))
+	assert.Equal(t, "acme.com", businessDomain("Bob@ACME.com"))
+	assert.Equal(t, "", businessDomain("someone@gmail.com"))
+}
+
+func TestClientService_FindDuplicatesAndMerge(t *testing.T) {
+	db := setupFullTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	auth := NewAuthService(db)
+	user := createTestUser(t, auth, "merge_user")
+	clientSvc := NewClientService(db)
+	projectSvc := NewProjectService(db)
+	timeSvc := NewTimesheetService(db)
+	invoiceSvc := NewInvoiceService(db)
+	contactSvc := NewClientContactService(db)
+
+	target := clientSvc.Create(user.ID, dto.CreateClientInput{Name: "Acme Inc", Email: "billing@acme.com"})
+	source := clientSvc.Create(user.ID, dto.CreateClientInput{Name: "ACME Inc.", Website: "acme.com", PaymentTerms: "net_30"})
+	sameDomain := clientSvc.Create(user.ID, dto.CreateClientInput{Name: "Acme Europe", Email: "eu@acme.com"})
+	clientSvc.Create(user.ID, dto.CreateClientInput{Name: "Solo", Email: "solo@gmail.com"})
+	clientSvc.Create(user.ID, dto.CreateClientInput{Name: "Other", Email: "other@gmail.com"})
+
+	groups, err := clientSvc.FindDuplicates(user.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, groups, 2) {
+		assert.Equal(t, "name", groups[0].Reason)
+		assert.Equal(t, "acme", groups[0].Key)
+		assert.Equal(t, []int{target.ID, source.ID}, []int{groups[0].Records[0].ID, groups[0].Records[1].ID})
+		assert.Equal(t, "email_domain", groups[1].Reason)
+		assert.Equal(t, "acme.com", groups[1].Key)
+		assert.Equal(t, []int{target.ID, sameDomain.ID}, []int{groups[1].Records[0].ID, groups[1].Records[1].ID})
+	}
+
+	project := projectSvc.Create(user.ID, dto.CreateProjectInput{ClientID: source.ID, Name: "Site", HourlyRate: 100})
+	timeSvc.Create(user.ID, dto.CreateTimeEntryInput{ProjectID: project.ID, Date: "2025-01-05", DurationSeconds: 3600})
+	inv := invoiceSvc.Create(user.ID, dto.CreateInvoiceInput{ClientID: source.ID, Number: "M-1", IssueDate: "2025-01-10", Status: "sent"})
+	_, err = NewPaymentService(db).Create(user.ID, dto.CreatePaymentInput{ClientID: source.ID, Date: "2025-01-20", Amount: 10})
+	assert.NoError(t, err)
+	_, err = contactSvc.Create(user.ID, dto.CreateClientContactInput{ClientID: target.ID, Name: "Ann", Email: "ann@acme.com", Role: "billing"})
+	assert.NoError(t, err)
+	_, err = contactSvc.Create(user.ID, dto.CreateClientContactInput{ClientID: source.ID, Name: "Ann", Email: "ANN@acme.com", Role: "billing"})
+	assert.NoError(t, err)
+	_, err = contactSvc.Create(user.ID, dto.CreateClientContactInput{ClientID: source.ID, Name: "Bo", Email: "bo@acme.com", Role: "cc"})
+	assert.NoError(t, err)
+	_, err = db.Exec("INSERT INTO finance_transactions(user_id, account_id, date, description, amount, client_id) VALUES(?, 1, '2025-01-03', 'Hosting', -20, ?)", user.ID, source.ID)
+	assert.NoError(t, err)
+	// PO-7 exists on both clients; PO-8 only on the source.
+	poSvc := NewPurchaseOrderService(db)
+	targetPO, err := poSvc.Create(user.ID, dto.CreatePurchaseOrderInput{ClientID: target.ID, Number: "PO-7", AuthorizedAmount: 1000})
+	assert.NoError(t, err)
+	dupPO, err := poSvc.Create(user.ID, dto.CreatePurchaseOrderInput{ClientID: source.ID, Number: "PO-7", AuthorizedAmount: 1000})
+	assert.NoError(t, err)
+	sourcePO, err := poSvc.Create(user.ID, dto.CreatePurchaseOrderInput{ClientID: source.ID, Number: "PO-8", AuthorizedAmount: 500})
+	assert.NoError(t, err)
+	poInv := invoiceSvc.Create(user.ID, dto.CreateInvoiceInput{ClientID: source.ID, Number: "M-2", IssueDate: "2025-01-10", Status: "draft", PurchaseOrderID: dupPO.ID})
+	deps, err := clientSvc.Dependents(user.ID, source.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, deps.PurchaseOrders)
+
+	// The same contract is attached to both clients; the source has a quote too.
+	attachments := &AttachmentService{db: db, dir: t.TempDir()}
+	contract := base64.StdEncoding.EncodeToString([]byte("%PDF-1.4\nservices contract"))
+	quote := base64.StdEncoding.EncodeToString([]byte("%PDF-1.4\nquote"))
+	_, err = attachments.Add(user.ID, dto.AddAttachmentInput{EntityType: "client", EntityID: target.ID, FileName: "contract.pdf", Content: contract})
+	assert.NoError(t, err)
+	for name, content := range map[string]string{"contract.pdf": contract, "quote.pdf": quote} {
+		_, err = attachments.Add(user.ID, dto.AddAttachmentInput{EntityType: "client", EntityID: source.ID, FileName: name, Content: content})
+		assert.NoError(t, err)
+	}
+
+	_, err = clientSvc.Merge(user.ID, dto.MergeInput{SourceID: target.ID, TargetID: target.ID})
+	assert.Error(t, err)
+
+	report, err := clientSvc.Merge(user.ID, dto.MergeInput{SourceID: source.ID, TargetID: target.ID})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.Projects)
+	assert.Equal(t, 1, report.TimeEntries)
+	assert.Equal(t, 2, report.Invoices)
+	assert.Equal(t, 1, report.Payments)
+	assert.Equal(t, 1, report.Contacts)
+	assert.Equal(t, 1, report.Transactions)
+	assert.Equal(t, 1, report.PurchaseOrders)
+	assert.Equal(t, 1, report.Attachments)
+	assert.ElementsMatch(t, []string{"website", "paymentTerms"}, report.FilledFields)
+
+	_, err = clientSvc.Get(user.ID, source.ID)
+	assert.Error(t, err)
+	merged, err := clientSvc.Get(user.ID, target.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "billing@acme.com", merged.Email)
+	assert.Equal(t, "acme.com", merged.Website)
+	assert.Len(t, projectSvc.ListByClient(user.ID, target.ID), 1)
+	got, err := invoiceSvc.Get(user.ID, inv.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, target.ID, got.ClientID)
+	assert.Len(t, contactSvc.List(user.ID, target.ID), 2)
+
+	// The invoice now points at the target's PO-7, with nothing to warn about.
+	got, err = invoiceSvc.Get(user.ID, poInv.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, targetPO.ID, got.PurchaseOrderID)
+	assert.Empty(t, got.Warnings)
+	pos := poSvc.List(user.ID, target.ID)
+	assert.ElementsMatch(t, []int{targetPO.ID, sourcePO.ID}, []int{pos[0].ID, pos[1].ID})
+	_, err = poSvc.Get(user.ID, dupPO.ID)
+	assert.Error(t, err)
+
+	var files []string
+	for _, a := range attachments.List(user.ID, "client", target.ID) {
+		files = append(files, a.FileName)
+	}
+	assert.ElementsMatch(t, []string{"contract.pdf", "quote.pdf"}, files)
+	var left int
+	assert.NoError(t, db.QueryRow("SELECT COUNT(*) FROM attachments WHERE entity_type = 'client' AND entity_id = ?", source.ID).Scan(&left))
+	assert.Zero(t, left)
+}
+
+func TestProjectService_FindDuplicatesAndMerge(t *testing.T) {
+	db := setupFullTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	auth := NewAuthService(db)
+	user := createTestUser(t, auth, "project_merge_user")
+	clientSvc := NewClientService(db)
+	projectSvc := NewProjectService(db)
+	timeSvc := NewTimesheetService(db)
+
+	acme := clientSvc.Create(user.ID, dto.CreateClientInput{Name: "Acme"})
+	other := clientSvc.Create(user.ID, dto.CreateClientInput{Name: "Other"})
+	target := projectSvc.Create(user.ID, dto.CreateProjectInput{ClientID: acme.ID, Name: "Website Redesign", Tags: []string{"web"}})
+	source := projectSvc.Create(user.ID, dto.CreateProjectInput{ClientID: acme.ID, Name: "website redesign!", Description: "Phase 1", Tags: []string{"Web", "design"}})
+	elsewhere := projectSvc.Create(user.ID, dto.CreateProjectInput{ClientID: other.ID, Name: "Website Redesign"})
+	timeSvc.Create(user.ID, dto.CreateTimeEntryInput{ProjectID: source.ID, Date: "2025-01-05", DurationSeconds: 3600})
+	timeSvc.Create(user.ID, dto.CreateTimeEntryInput{ProjectID: source.ID, Date: "2025-01-06", DurationSeconds: 3600})
+
+	groups, err := projectSvc.FindDuplicates(user.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, groups, 1) {
+		assert.Equal(t, "website redesign", groups[0].Key)
+		assert.Len(t, groups[0].Records, 2)
+	}
+
+	_, err = projectSvc.Merge(user.ID, dto.MergeInput{SourceID: elsewhere.ID, TargetID: target.ID})
+	assert.Error(t, err)
+
+	report, err := projectSvc.Merge(user.ID, dto.MergeInput{SourceID: source.ID, TargetID: target.ID})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, report.TimeEntries)
+	assert.ElementsMatch(t, []string{"description", "tags"}, report.FilledFields)
+
+	merged, err := projectSvc.Get(user.ID, target.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Phase 1", merged.Description)
+	assert.Equal(t, []string{"web", "design"}, merged.Tags)
+	assert.Len(t, timeSvc.List(user.ID, target.ID), 2)
+	_, err = projectSvc.Get(user.ID, source.ID)
+	assert.Error(t, err)
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"tally/internal/dto"
+	"tally/internal/mapper"
+	"tally/internal/models"
+	"time"
+)
+
+// PaymentService records payments received and credits issued to clients.
+type PaymentService struct {
+	db *sql.DB
+}
+
+// NewPaymentService creates a new PaymentService instance.
+func NewPaymentService(db *sql.DB) *PaymentService {
+	return &PaymentService{db: db}
+}
+
+// List returns payments and credits for a user, newest first.
+func (s *PaymentService) List(userID int, filter dto.PaymentFilter) []dto.PaymentOutput {
+	query := `
+SELECT pm.id, pm.client_id, pm.invoice_id, pm.kind, pm.date, pm.amount, pm.method, pm.reference, pm.notes, COALESCE(pm.transaction_id, 0), COALESCE(i.number, '')
+FROM payments pm
+LEFT JOIN invoices i ON i.id = pm.invoice_id
+WHERE pm.user_id = ?`
+	args := []interface{}{userID}
+	if filter.ClientID > 0 {
+		query += " AND pm.client_id = ?"
+		args = append(args, filter.ClientID)
+	}
+	if filter.InvoiceID > 0 {
+		query += " AND pm.invoice_id = ?"
+		args = append(args, filter.InvoiceID)
+	}
+	query += " ORDER BY pm.date DESC, pm.id DESC"
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		log.Println("Error querying payments:", err)
+		return []dto.PaymentOutput{}
+	}
+	defer closeWithLog(rows, "closing payment rows")
+
+	out := []dto.PaymentOutput{}
+	for rows.Next() {
+		var p models.Payment
+		var invoiceID sql.NullInt64
+		var method, reference, notes sql.NullString
+		var number string
+		if err := rows.Scan(&p.ID, &p.ClientID, &invoiceID, &p.Kind, &p.Date, &p.Amount, &method, &reference, &notes, &p.TransactionID, &number); err != nil {
+			log.Println("Error scanning payment:", err)
+			continue
+		}
+		p.InvoiceID = int(invoiceID.Int64)
+		p.Method = method.String
+		p.Reference = reference.String
+		p.Notes = notes.String
+		o := mapper.ToPaymentOutput(p)
+		o.InvoiceNumber = number
+		out = append(out, o)
+	}
+	return out
+}
+
+// Create records a payment or credit. A payment that settles its invoice marks it paid.
+func (s *PaymentService) Create(userID int, input dto.CreatePaymentInput) (dto.PaymentOutput, error) {
+	p := mapper.ToPaymentEntity(input)
+	if p.Kind != "payment" && p.Kind != "credit" {
+		return dto.PaymentOutput{}, fmt.Errorf("unknown payment kind: %s", p.Kind)
+	}
+	if p.Amount <= 0 {
+		return dto.PaymentOutput{}, fmt.Errorf("amount must be positive")
+	}
+	if _, err := time.Parse("2006-01-02", p.Date); err != nil {
+		return dto.PaymentOutput{}, fmt.Errorf("invalid date %q: expected YYYY-MM-DD", p.Date)
+	}
+
+	if p.InvoiceID > 0 {
+		if err := s.db.QueryRow("SELECT client_id FROM invoices WHERE id = ? AND user_id = ?", p.InvoiceID, userID).Scan(&p.ClientID); err != nil {
+			return dto.PaymentOutput{}, fmt.Errorf("invoice not found: %w", err)
+		}
+	} else {
+		var count int
+		if err := s.db.QueryRow("SELECT COUNT(*) FROM clients WHERE id = ? AND user_id = ?", p.ClientID, userID).Scan(&count); err != nil {
+			return dto.PaymentOutput{}, fmt.Errorf("failed to check client: %w", err)
+		}
+		if count == 0 {
+			return dto.PaymentOutput{}, fmt.Errorf("client not found")
+		}
+	}
+
+	if p.TransactionID > 0 {
+		var amount float64
+		var payments int
+		err := s.db.QueryRow("SELECT amount, (SELECT COUNT(*) FROM payments WHERE transaction_id = t.id) FROM finance_transactions t WHERE t.id = ? AND t.user_id = ?",
+			p.TransactionID, userID).Scan(&amount, &payments)
+		if err != nil {
+			return dto.PaymentOutput{}, fmt.Errorf("transaction not found: %w", err)
+		}
+		if amount <= 0 {
+			return dto.PaymentOutput{}, fmt.Errorf("transaction is not a deposit")
+		}
+		if payments > 0 {
+			return dto.PaymentOutput{}, fmt.Errorf("transaction is already recorded as a payment")
+		}
+	}
+
+	res, err := s.db.Exec(
+		"INSERT INTO payments(user_id, client_id, invoice_id, kind, date, amount, method, reference, notes, transaction_id) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		userID, p.ClientID, nullableID(p.InvoiceID), p.Kind, p.Date, p.Amount, p.Method, p.Reference, p.Notes, nullableID(p.TransactionID),
+	)
+	if err != nil {
+		return dto.PaymentOutput{}, fmt.Errorf("failed to insert payment: %w", err)
+	}
+	id, _ := res.LastInsertId()
+	p.ID = int(id)
+
+	if p.InvoiceID > 0 {
+		if err := s.syncInvoiceStatus(userID, p.InvoiceID); err != nil {
+			log.Println("Error updating invoice status after payment:", err)
+		}
+	}
+	return mapper.ToPaymentOutput(p), nil
+}
+
+// Delete removes a payment and reopens its invoice if it is no longer settled.
+func (s *PaymentService) Delete(userID int, id int) {
+	var invoiceID sql.NullInt64
+	if err := s.db.QueryRow("SELECT invoice_id FROM payments WHERE id = ? AND user_id = ?", id, userID).Scan(&invoiceID); err != nil {
+		log.Println("Error loading payment for delete:", err)
+		return
+	}
+	if _, err := s.db.Exec("DELETE FROM payments WHERE id = ? AND user_id = ?", id, userID); err != nil {
+		log.Println("Error deleting payment:", err)
+		return
+	}
+	if invoiceID.Valid {
+		if err := s.syncInvoiceStatus(userID, int(invoiceID.Int64)); err != nil {
+			log.Println("Error updating invoice status after payment delete:", err)
+		}
+	}
+}
+
+// syncInvoiceStatus marks an invoice paid once payments cover its total, and
+// moves a paid invoice back to sent when they no longer do.
+func (s *PaymentService) syncInvoiceStatus(userID int, invoiceID int) error {
+	var total, paid float64
+	var status string
+	err := s.db.QueryRow(`
+SELECT i.total, i.status, COALESCE((SELECT SUM(amount) FROM payments WHERE invoice_id = i.id), 0)
+FROM invoices i WHERE i.id = ? AND i.user_id = ?`, invoiceID, userID).Scan(&total, &status, &paid)
+	if err != nil {
+		return err
+	}
+
+	next := status
+	switch {
+	case paid >= total-0.005 && status != "paid":
+		next = "paid"
+	case paid < total-0.005 && status == "paid":
+		next = "sent"
+	}
+	if next == status {
+		return nil
+	}
+	_, err = s.db.Exec("UPDATE invoices SET status = ? WHERE id = ? AND user_id = ?", next, invoiceID, userID)
+	return err
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"database/sql"
+	"fmt"
 	"tally/internal/dto"
 	"tally/internal/mapper"
 	"tally/internal/models"
@@ -19,63 +20,47 @@ func NewProjectService(db *sql.DB) *ProjectService {
 	return &ProjectService{db: db}
 }
 
-// List returns all projects for a specific user as DTOs.
-func (s *ProjectService) List(userID int) []dto.ProjectOutput {
-	rows, err := s.db.Query("SELECT id, client_id, name, description, hourly_rate, currency, status, deadline, tags, service_type FROM projects WHERE user_id = ?", userID)
-	if err != nil {
-		log.Println("Error querying projects:", err)
-		return []dto.ProjectOutput{}
-	}
-	defer closeWithLog(rows, "closing project list rows")
+// projectColumns is the column list shared by the project queries. Tags come
+// from entity_tags as a comma-joined list.
+var projectColumns = "id, client_id, name, description, hourly_rate, currency, status, deadline, " + entityTagsSQL("project", "projects.id") + ", service_type, archived_at"
 
-	var projects []models.Project
-	for rows.Next() {
-		var p models.Project
-		var tagsStr string
-		var serviceType sql.NullString
+// List returns the active projects for a specific user as DTOs. Projects that
+// are archived, or whose client is archived, are left out.
+func (s *ProjectService) List(userID int) []dto.ProjectOutput {
+	return s.query("SELECT "+projectColumns+" FROM projects WHERE user_id = ? AND archived_at IS NULL AND client_id NOT IN (SELECT id FROM clients WHERE archived_at IS NOT NULL)", userID)
+}
 
-		err := rows.Scan(&p.ID, &p.ClientID, &p.Name, &p.Description, &p.HourlyRate, &p.Currency, &p.Status, &p.Deadline, &tagsStr, &serviceType)
-		if err != nil {
-			log.Println("Error scanning project:", err)
-			continue
-		}
-		if tagsStr != "" {
-			p.Tags = strings.Split(tagsStr, ",")
-		} else {
-			p.Tags = []string{}
-		}
-		p.ServiceType = serviceType.String
-		projects = append(projects, p)
-	}
-	return mapper.ToProjectOutputList(projects)
+// ListArchived returns the projects that are archived themselves or through their client.
+func (s *ProjectService) ListArchived(userID int) []dto.ProjectOutput {
+	return s.query("SELECT "+projectColumns+" FROM projects WHERE user_id = ? AND (archived_at IS NOT NULL OR client_id IN (SELECT id FROM clients WHERE archived_at IS NOT NULL))", userID)
 }
 
-// ListByClient returns all projects for a specific client of a specific user.
+// ListByClient returns the active projects for a specific client of a specific user.
 func (s *ProjectService) ListByClient(userID int, clientID int) []dto.ProjectOutput {
-	rows, err := s.db.Query("SELECT id, client_id, name, description, hourly_rate, currency, status, deadline, tags, service_type FROM projects WHERE client_id = ? AND user_id = ?", clientID, userID)
+	return s.query("SELECT "+projectColumns+" FROM projects WHERE client_id = ? AND user_id = ? AND archived_at IS NULL", clientID, userID)
+}
+
+func (s *ProjectService) query(query string, args ...interface{}) []dto.ProjectOutput {
+	rows, err := s.db.Query(query, args...)
 	if err != nil {
-		log.Println("Error querying projects by client:", err)
+		log.Println("Error querying projects:", err)
 		return []dto.ProjectOutput{}
 	}
-	defer closeWithLog(rows, "closing project by client rows")
+	defer closeWithLog(rows, "closing project list rows")
 
 	var projects []models.Project
 	for rows.Next() {
 		var p models.Project
-		var tagsStr string
-		var serviceType sql.NullString
+		var tags, serviceType, archivedAt sql.NullString
 
-		err := rows.Scan(&p.ID, &p.ClientID, &p.Name, &p.Description, &p.HourlyRate, &p.Currency, &p.Status, &p.Deadline, &tagsStr, &serviceType)
+		err := rows.Scan(&p.ID, &p.ClientID, &p.Name, &p.Description, &p.HourlyRate, &p.Currency, &p.Status, &p.Deadline, &tags, &serviceType, &archivedAt)
 		if err != nil {
 			log.Println("Error scanning project:", err)
 			continue
 		}
-		if tagsStr != "" {
-			p.Tags = strings.Split(tagsStr, ",")
-		} else {
-			p.Tags = []string{}
-		}
+		p.Tags = splitTags(tags.String)
 		p.ServiceType = serviceType.String
+		p.ArchivedAt = archivedAt.String
 		projects = append(projects, p)
 	}
 	return mapper.ToProjectOutputList(projects)
@@ -83,36 +68,32 @@ func (s *ProjectService) ListByClient(userID int, clientID int) []dto.ProjectOut
 
 // Get returns a single project by ID for a specific user.
 func (s *ProjectService) Get(userID int, id int) (dto.ProjectOutput, error) {
-	row := s.db.QueryRow("SELECT id, client_id, name, description, hourly_rate, currency, status, deadline, tags, service_type FROM projects WHERE id = ? AND user_id = ?", id, userID)
+	row := s.db.QueryRow("SELECT "+projectColumns+" FROM projects WHERE id = ? AND user_id = ?", id, userID)
 	var p models.Project
-	var tagsStr string
-	var serviceType sql.NullString
-	err := row.Scan(&p.ID, &p.ClientID, &p.Name, &p.Description, &p.HourlyRate, &p.Currency, &p.Status, &p.Deadline, &tagsStr, &serviceType)
+	var tags, serviceType, archivedAt sql.NullString
+	err := row.Scan(&p.ID, &p.ClientID, &p.Name, &p.Description, &p.HourlyRate, &p.Currency, &p.Status, &p.Deadline, &tags, &serviceType, &archivedAt)
 	if err != nil {
 		return dto.ProjectOutput{}, err
 	}
-	if tagsStr != "" {
-		p.Tags = strings.Split(tagsStr, ",")
-	} else {
-		p.Tags = []string{}
-	}
+	p.Tags = splitTags(tags.String)
 	p.ServiceType = serviceType.String
+	p.ArchivedAt = archivedAt.String
 	return mapper.ToProjectOutput(p), nil
 }
 
 // Create adds a new project for a specific user and returns the created project as DTO.
 func (s *ProjectService) Create(userID int, input dto.CreateProjectInput) dto.ProjectOutput {
 	entity := mapper.ToProjectEntity(input)
-	tagsStr := strings.Join(entity.Tags, ",")
 
-	stmt, err := s.db.Prepare("INSERT INTO projects(user_id, client_id, name, description, hourly_rate, currency, status, deadline, tags, service_type) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+	tx, err := s.db.Begin()
 	if err != nil {
-		log.Println("Error preparing project insert:", err)
+		log.Println("Error starting project insert:", err)
 		return dto.ProjectOutput{}
 	}
-	defer closeWithLog(stmt, "closing project insert statement")
+	defer func() { _ = tx.Rollback() }()
 
-	res, err := stmt.Exec(userID, entity.ClientID, entity.Name, entity.Description, entity.HourlyRate, entity.Currency, entity.Status, entity.Deadline, tagsStr, entity.ServiceType)
+	res, err := tx.Exec("INSERT INTO projects(user_id, client_id, name, description, hourly_rate, currency, status, deadline, service_type) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		userID, entity.ClientID, entity.Name, entity.Description, entity.HourlyRate, entity.Currency, entity.Status, entity.Deadline, entity.ServiceType)
 	if err != nil {
 		log.Println("Error inserting project:", err)
 		return dto.ProjectOutput{}
@@ -120,34 +101,277 @@ func (s *ProjectService) Create(userID int, input dto.CreateProjectInput) dto.Pr
 
 	id, _ := res.LastInsertId()
 	entity.ID = int(id)
+	if entity.Tags, err = setEntityTags(tx, userID, "project", entity.ID, entity.Tags); err != nil {
+		log.Println("Error tagging project:", err)
+		return dto.ProjectOutput{}
+	}
+	if err := tx.Commit(); err != nil {
+		log.Println("Error committing project insert:", err)
+		return dto.ProjectOutput{}
+	}
 	return mapper.ToProjectOutput(entity)
 }
 
 // Update modifies an existing project for a specific user and returns the updated project as DTO.
 func (s *ProjectService) Update(userID int, input dto.UpdateProjectInput) dto.ProjectOutput {
-	tagsStr := strings.Join(input.Tags, ",")
-
-	stmt, err := s.db.Prepare("UPDATE projects SET client_id=?, name=?, description=?, hourly_rate=?, currency=?, status=?, deadline=?, tags=?, service_type=? WHERE id=? AND user_id=?")
+	tx, err := s.db.Begin()
 	if err != nil {
-		log.Println("Error preparing project update:", err)
+		log.Println("Error starting project update:", err)
 		return dto.ProjectOutput{}
 	}
-	defer closeWithLog(stmt, "closing project update statement")
+	defer func() { _ = tx.Rollback() }()
 
-	_, err = stmt.Exec(input.ClientID, input.Name, input.Description, input.HourlyRate, input.Currency, input.Status, input.Deadline, tagsStr, input.ServiceType, input.ID, userID)
+	res, err := tx.Exec("UPDATE projects SET client_id=?, name=?, description=?, hourly_rate=?, currency=?, status=?, deadline=?, service_type=? WHERE id=? AND user_id=?",
+		input.ClientID, input.Name, input.Description, input.HourlyRate, input.Currency, input.Status, input.Deadline, input.ServiceType, input.ID, userID)
 	if err != nil {
 		log.Println("Error updating project:", err)
 		return dto.ProjectOutput{}
 	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return dto.ProjectOutput{}
+	}
+	if _, err := setEntityTags(tx, userID, "project", input.ID, input.Tags); err != nil {
+		log.Println("Error tagging project:", err)
+		return dto.ProjectOutput{}
+	}
+	if err := tx.Commit(); err != nil {
+		log.Println("Error committing project update:", err)
+		return dto.ProjectOutput{}
+	}
 
 	output, _ := s.Get(userID, input.ID)
 	return output
 }
 
-// Delete removes a project by ID for a specific user.
-func (s *ProjectService) Delete(userID int, id int) {
-	_, err := s.db.Exec("DELETE FROM projects WHERE id=? AND user_id=?", id, userID)
+// Archive hides a project from list views while keeping its time entries.
+func (s *ProjectService) Archive(userID int, id int) error {
+	res, err := s.db.Exec("UPDATE projects SET archived_at = datetime('now') WHERE id = ? AND user_id = ? AND archived_at IS NULL", id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to archive project: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("project not found or already archived")
+	}
+	return nil
+}
+
+// Restore brings an archived project back. Projects of an archived client
+// stay hidden until the client is restored.
+func (s *ProjectService) Restore(userID int, id int) error {
+	res, err := s.db.Exec("UPDATE projects SET archived_at = NULL WHERE id = ? AND user_id = ? AND archived_at IS NOT NULL", id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to restore project: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("project not found or not archived")
+	}
+	return nil
+}
+
+// Dependents counts the records that reference a project.
+func (s *ProjectService) Dependents(userID int, id int) (dto.Dependents, error) {
+	var d dto.Dependents
+	err := s.db.QueryRow(`
+SELECT
+  (SELECT COUNT(*) FROM time_entries WHERE user_id = ? AND project_id = ?),
+  (SELECT COUNT(*) FROM time_entries WHERE user_id = ? AND project_id = ? AND invoiced = 1),
+  (SELECT COUNT(*) FROM rate_cards WHERE user_id = ? AND project_id = ?),
+  (SELECT COUNT(*) FROM finance_transactions WHERE user_id = ? AND project_id = ?)`,
+		userID, id, userID, id, userID, id, userID, id,
+	).Scan(&d.TimeEntries, &d.InvoicedTimeEntries, &d.RateCards, &d.Transactions)
+	if err != nil {
+		return dto.Dependents{}, fmt.Errorf("failed to count project dependents: %w", err)
+	}
+	return d, nil
+}
+
+// Delete permanently removes a project that nothing references. Projects with
+// time entries, rate cards or linked expenses are refused; archive them
+// instead, or use DeleteCascade.
+func (s *ProjectService) Delete(userID int, id int) error {
+	if _, err := s.Get(userID, id); err != nil {
+		return fmt.Errorf("project not found: %w", err)
+	}
+	d, err := s.Dependents(userID, id)
 	if err != nil {
+		return err
+	}
+	if hasDependents(d) {
+		return fmt.Errorf("project has %s; archive it instead or confirm a cascading delete", describeDependents(d))
+	}
+	if _, err := s.db.Exec("DELETE FROM projects WHERE id=? AND user_id=?", id, userID); err != nil {
 		log.Println("Error deleting project:", err)
+		return fmt.Errorf("failed to delete project: %w", err)
+	}
+	return nil
+}
+
+// DeleteCascade permanently removes a project with its time entries and rate
+// cards; linked expenses are unlinked. Projects with invoiced time are always
+// refused so invoice history cannot be lost. The caller confirms by repeating
+// the project name.
+func (s *ProjectService) DeleteCascade(userID int, input dto.CascadeDeleteInput) error {
+	project, err := s.Get(userID, input.ID)
+	if err != nil {
+		return fmt.Errorf("project not found: %w", err)
+	}
+	if input.ConfirmName != project.Name {
+		return fmt.Errorf("confirmation does not match project name")
+	}
+	d, err := s.Dependents(userID, input.ID)
+	if err != nil {
+		return err
+	}
+	if d.InvoicedTimeEntries > 0 {
+		return fmt.Errorf("project has %d invoiced time entries; archive it instead", d.InvoicedTimeEntries)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, query := range []string{
+		"UPDATE finance_transactions SET project_id = NULL WHERE user_id = ? AND project_id = ?",
+		"DELETE FROM time_entries WHERE user_id = ? AND project_id = ?",
+		"DELETE FROM rate_cards WHERE user_id = ? AND project_id = ?",
+		"DELETE FROM projects WHERE user_id = ? AND id = ?",
+	} {
+		if _, err := tx.Exec(query, userID, input.ID); err != nil {
+			return fmt.Errorf("failed to delete project: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// FindDuplicates returns groups of projects of the same client whose names
+// match once case and punctuation are ignored.
+func (s *ProjectService) FindDuplicates(userID int) ([]dto.DuplicateGroup, error) {
+	rows, err := s.db.Query("SELECT id, client_id, name, COALESCE(archived_at, '') FROM projects WHERE user_id = ?", userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query projects: %w", err)
+	}
+	defer closeWithLog(rows, "closing duplicate project rows")
+
+	var records []dto.DuplicateRecord
+	for rows.Next() {
+		var r dto.DuplicateRecord
+		if err := rows.Scan(&r.ID, &r.ClientID, &r.Name, &r.ArchivedAt); err != nil {
+			log.Println("Error scanning project:", err)
+			continue
+		}
+		records = append(records, r)
+	}
+	groups := groupDuplicates(records, "name", func(r dto.DuplicateRecord) string {
+		if n := normalizeName(r.Name); n != "" {
+			return fmt.Sprintf("%d:%s", r.ClientID, n)
+		}
+		return ""
+	})
+	for i := range groups {
+		groups[i].Key = normalizeName(groups[i].Records[0].Name)
+	}
+	return groups, nil
+}
+
+// Merge folds the source project into the target in one transaction: time
+// entries, rate cards and finance links move to the target, tags are combined,
+// a blank description or deadline is filled from the source, and the source is
+// deleted. Both projects must belong to the same client.
+func (s *ProjectService) Merge(userID int, input dto.MergeInput) (dto.MergeReport, error) {
+	if input.SourceID == input.TargetID {
+		return dto.MergeReport{}, fmt.Errorf("cannot merge a project into itself")
+	}
+	source, err := s.Get(userID, input.SourceID)
+	if err != nil {
+		return dto.MergeReport{}, fmt.Errorf("source project not found: %w", err)
+	}
+	target, err := s.Get(userID, input.TargetID)
+	if err != nil {
+		return dto.MergeReport{}, fmt.Errorf("target project not found: %w", err)
+	}
+	if source.ClientID != target.ClientID {
+		return dto.MergeReport{}, fmt.Errorf("projects belong to different clients; merge the clients first")
+	}
+	report := dto.MergeReport{
+		SourceID:     source.ID,
+		SourceName:   source.Name,
+		TargetID:     target.ID,
+		TargetName:   target.Name,
+		FilledFields: []string{},
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return dto.MergeReport{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	moves := []struct {
+		table string
+		count *int
+	}{
+		{"time_entries", &report.TimeEntries},
+		{"rate_cards", &report.RateCards},
+		{"finance_transactions", &report.Transactions},
+	}
+	for _, m := range moves {
+		// #nosec G202 -- table names come from the fixed list above.
+		res, err := tx.Exec("UPDATE "+m.table+" SET project_id = ? WHERE user_id = ? AND project_id = ?", target.ID, userID, source.ID)
+		if err != nil {
+			return dto.MergeReport{}, fmt.Errorf("failed to move %s: %w", m.table, err)
+		}
+		n, _ := res.RowsAffected()
+		*m.count = int(n)
+	}
+
+	description, deadline := target.Description, target.Deadline
+	if strings.TrimSpace(description) == "" && strings.TrimSpace(source.Description) != "" {
+		description = source.Description
+		report.FilledFields = append(report.FilledFields, "description")
+	}
+	if strings.TrimSpace(deadline) == "" && strings.TrimSpace(source.Deadline) != "" {
+		deadline = source.Deadline
+		report.FilledFields = append(report.FilledFields, "deadline")
+	}
+	tags := append([]string{}, target.Tags...)
+	for _, tag := range source.Tags {
+		found := false
+		for _, existing := range tags {
+			if strings.EqualFold(existing, tag) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) != len(target.Tags) {
+		report.FilledFields = append(report.FilledFields, "tags")
+	}
+	if _, err := tx.Exec("UPDATE projects SET description = ?, deadline = ? WHERE id = ? AND user_id = ?",
+		description, deadline, target.ID, userID); err != nil {
+		return dto.MergeReport{}, fmt.Errorf("failed to update target project: %w", err)
+	}
+	if _, err := setEntityTags(tx, userID, "project", target.ID, tags); err != nil {
+		return dto.MergeReport{}, fmt.Errorf("failed to combine tags: %w", err)
+	}
+
+	filled, err := mergeCustomFieldValues(tx, userID, "project", source.ID, target.ID)
+	if err != nil {
+		return dto.MergeReport{}, err
+	}
+	if filled {
+		report.FilledFields = append(report.FilledFields, "customFields")
+	}
+
+	if _, err := tx.Exec("DELETE FROM projects WHERE id = ? AND user_id = ?", source.ID, userID); err != nil {
+		return dto.MergeReport{}, fmt.Errorf("failed to delete merged project: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return dto.MergeReport{}, err
 	}
+	return report, nil
 }
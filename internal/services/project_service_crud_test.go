@@ -59,7 +59,7 @@ func TestProjectService_CRUD(t *testing.T) {
 	assert.ElementsMatch(t, []string{"x"}, updated.Tags)
 
 	// Delete
-	projectSvc.Delete(user.ID, created.ID)
+	assert.NoError(t, projectSvc.Delete(user.ID, created.ID))
 	assert.Len(t, projectSvc.List(user.ID), 0)
 }
 
@@ -0,0 +1,223 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"tally/internal/dto"
+	"tally/internal/mapper"
+	"tally/internal/models"
+	"time"
+)
+
+// PurchaseOrderService manages client purchase orders and their balances.
+type PurchaseOrderService struct {
+	db *sql.DB
+}
+
+// NewPurchaseOrderService creates a new PurchaseOrderService instance.
+func NewPurchaseOrderService(db *sql.DB) *PurchaseOrderService {
+	return &PurchaseOrderService{db: db}
+}
+
+// purchaseOrderColumns selects a PO (alias po) with the totals of the invoices billed against it.
+const purchaseOrderColumns = `po.id, po.client_id, po.number, po.authorized_amount, COALESCE(po.currency, ''),
+  COALESCE(po.valid_from, ''), COALESCE(po.valid_to, ''), COALESCE(po.notes, ''),
+  COALESCE((SELECT SUM(i.total) FROM invoices i WHERE i.purchase_order_id = po.id AND i.user_id = po.user_id), 0),
+  (SELECT COUNT(*) FROM invoices i WHERE i.purchase_order_id = po.id AND i.user_id = po.user_id)`
+
+// List returns the user's purchase orders, optionally for one client, newest first.
+func (s *PurchaseOrderService) List(userID int, clientID int) []dto.PurchaseOrderOutput {
+	query := "SELECT " + purchaseOrderColumns + " FROM purchase_orders po WHERE po.user_id = ?"
+	args := []interface{}{userID}
+	if clientID > 0 {
+		query += " AND po.client_id = ?"
+		args = append(args, clientID)
+	}
+	query += " ORDER BY COALESCE(po.valid_from, '') DESC, po.id DESC"
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		log.Println("Error querying purchase orders:", err)
+		return []dto.PurchaseOrderOutput{}
+	}
+	defer closeWithLog(rows, "closing purchase order rows")
+
+	var orders []models.PurchaseOrder
+	for rows.Next() {
+		po, err := scanPurchaseOrder(rows)
+		if err != nil {
+			log.Println("Error scanning purchase order:", err)
+			continue
+		}
+		orders = append(orders, po)
+	}
+	out := mapper.ToPurchaseOrderOutputList(orders)
+	today := time.Now().Format("2006-01-02")
+	for i := range out {
+		out[i].Status = purchaseOrderStatus(out[i], today)
+	}
+	return out
+}
+
+// Get returns a single purchase order with its balance.
+func (s *PurchaseOrderService) Get(userID int, id int) (dto.PurchaseOrderOutput, error) {
+	row := s.db.QueryRow("SELECT "+purchaseOrderColumns+" FROM purchase_orders po WHERE po.id = ? AND po.user_id = ?", id, userID)
+	po, err := scanPurchaseOrder(row)
+	if err != nil {
+		return dto.PurchaseOrderOutput{}, fmt.Errorf("purchase order not found: %w", err)
+	}
+	out := mapper.ToPurchaseOrderOutput(po)
+	out.Status = purchaseOrderStatus(out, time.Now().Format("2006-01-02"))
+	return out, nil
+}
+
+// Create records a purchase order for one of the user's clients.
+func (s *PurchaseOrderService) Create(userID int, input dto.CreatePurchaseOrderInput) (dto.PurchaseOrderOutput, error) {
+	number, err := validatePurchaseOrder(input.Number, input.AuthorizedAmount, input.ValidFrom, input.ValidTo)
+	if err != nil {
+		return dto.PurchaseOrderOutput{}, err
+	}
+	var clients int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM clients WHERE id = ? AND user_id = ?", input.ClientID, userID).Scan(&clients); err != nil {
+		return dto.PurchaseOrderOutput{}, fmt.Errorf("failed to check client: %w", err)
+	}
+	if clients == 0 {
+		return dto.PurchaseOrderOutput{}, fmt.Errorf("client not found")
+	}
+
+	res, err := s.db.Exec(`INSERT INTO purchase_orders(user_id, client_id, number, authorized_amount, currency, valid_from, valid_to, notes)
+VALUES(?, ?, ?, ?, ?, ?, ?, ?)`, userID, input.ClientID, number, input.AuthorizedAmount, input.Currency, input.ValidFrom, input.ValidTo, input.Notes)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			return dto.PurchaseOrderOutput{}, fmt.Errorf("purchase order %q already exists for this client", number)
+		}
+		return dto.PurchaseOrderOutput{}, fmt.Errorf("failed to insert purchase order: %w", err)
+	}
+	id, _ := res.LastInsertId()
+	return s.Get(userID, int(id))
+}
+
+// Update changes a purchase order. Lowering the authorized amount below what
+// is already invoiced is allowed; the order then shows a negative balance.
+func (s *PurchaseOrderService) Update(userID int, input dto.UpdatePurchaseOrderInput) (dto.PurchaseOrderOutput, error) {
+	number, err := validatePurchaseOrder(input.Number, input.AuthorizedAmount, input.ValidFrom, input.ValidTo)
+	if err != nil {
+		return dto.PurchaseOrderOutput{}, err
+	}
+	res, err := s.db.Exec(`UPDATE purchase_orders SET number = ?, authorized_amount = ?, currency = ?, valid_from = ?, valid_to = ?, notes = ?
+WHERE id = ? AND user_id = ?`, number, input.AuthorizedAmount, input.Currency, input.ValidFrom, input.ValidTo, input.Notes, input.ID, userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			return dto.PurchaseOrderOutput{}, fmt.Errorf("purchase order %q already exists for this client", number)
+		}
+		return dto.PurchaseOrderOutput{}, fmt.Errorf("failed to update purchase order: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return dto.PurchaseOrderOutput{}, fmt.Errorf("purchase order not found or not owned by user")
+	}
+	return s.Get(userID, input.ID)
+}
+
+// Delete removes a purchase order that no invoice references.
+func (s *PurchaseOrderService) Delete(userID int, id int) error {
+	po, err := s.Get(userID, id)
+	if err != nil {
+		return err
+	}
+	if po.InvoiceCount > 0 {
+		return fmt.Errorf("purchase order %s is referenced by %d invoice(s)", po.Number, po.InvoiceCount)
+	}
+	_, err = s.db.Exec("DELETE FROM purchase_orders WHERE id = ? AND user_id = ?", id, userID)
+	return err
+}
+
+func scanPurchaseOrder(row rowScanner) (models.PurchaseOrder, error) {
+	var po models.PurchaseOrder
+	err := row.Scan(&po.ID, &po.ClientID, &po.Number, &po.AuthorizedAmount, &po.Currency,
+		&po.ValidFrom, &po.ValidTo, &po.Notes, &po.InvoicedAmount, &po.InvoiceCount)
+	return po, err
+}
+
+func validatePurchaseOrder(number string, amount float64, validFrom string, validTo string) (string, error) {
+	number = strings.TrimSpace(number)
+	if number == "" {
+		return "", fmt.Errorf("purchase order number is required")
+	}
+	if amount <= 0 {
+		return "", fmt.Errorf("authorized amount must be positive")
+	}
+	for _, d := range []string{validFrom, validTo} {
+		if d == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", d); err != nil {
+			return "", fmt.Errorf("invalid date %q: use YYYY-MM-DD", d)
+		}
+	}
+	if validFrom != "" && validTo != "" && validTo < validFrom {
+		return "", fmt.Errorf("purchase order ends before it starts")
+	}
+	return number, nil
+}
+
+// purchaseOrderStatus classifies a PO on the given date.
+func purchaseOrderStatus(po dto.PurchaseOrderOutput, today string) string {
+	switch {
+	case po.ValidFrom != "" && today < po.ValidFrom:
+		return "not_started"
+	case po.ValidTo != "" && today > po.ValidTo:
+		return "expired"
+	case po.RemainingAmount <= 0.005:
+		return "exhausted"
+	}
+	return "active"
+}
+
+// purchaseOrderWarnings checks an invoice against its purchase order and its
+// client's PO requirement. blocked is true when the client requires a PO and
+// the invoice has no valid one; going over the authorized amount only warns.
+func purchaseOrderWarnings(db *sql.DB, userID int, inv dto.InvoiceOutput) (warnings []string, blocked bool) {
+	warnings = []string{}
+	var requiresPO bool
+	if err := db.QueryRow("SELECT COALESCE(requires_po, 0) FROM clients WHERE id = ? AND user_id = ?", inv.ClientID, userID).Scan(&requiresPO); err != nil && err != sql.ErrNoRows {
+		log.Println("Error loading PO requirement:", err)
+	}
+	if inv.PurchaseOrderID == 0 {
+		if requiresPO {
+			warnings = append(warnings, "This client requires a purchase order reference.")
+			return warnings, true
+		}
+		return warnings, false
+	}
+
+	row := db.QueryRow("SELECT "+purchaseOrderColumns+" FROM purchase_orders po WHERE po.id = ? AND po.user_id = ?", inv.PurchaseOrderID, userID)
+	po, err := scanPurchaseOrder(row)
+	if err != nil {
+		warnings = append(warnings, "The linked purchase order no longer exists.")
+		return warnings, requiresPO
+	}
+	valid := true
+	if po.ClientID != inv.ClientID {
+		warnings = append(warnings, fmt.Sprintf("Purchase order %s belongs to another client.", po.Number))
+		valid = false
+	}
+	if inv.IssueDate != "" && ((po.ValidFrom != "" && inv.IssueDate < po.ValidFrom) || (po.ValidTo != "" && inv.IssueDate > po.ValidTo)) {
+		warnings = append(warnings, fmt.Sprintf("Purchase order %s is not valid on %s (valid %s to %s).",
+			po.Number, inv.IssueDate, nonEmptyOr(po.ValidFrom, "any date"), nonEmptyOr(po.ValidTo, "open-ended")))
+		valid = false
+	}
+	if over := po.InvoicedAmount - po.AuthorizedAmount; over > 0.005 {
+		warnings = append(warnings, fmt.Sprintf("Invoices on purchase order %s total %.2f, exceeding the authorized %.2f by %.2f.",
+			po.Number, po.InvoicedAmount, po.AuthorizedAmount, over))
+	}
+	return warnings, requiresPO && !valid
+}
+
+func nonEmptyOr(s string, fallback string) string {
+	if s == "" {
+		return fallback
+	}
+	return s
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"tally/internal/dto"
+	"tally/internal/pdf"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPurchaseOrderService_CRUD(t *testing.T) {
+	db := setupFullTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	auth := NewAuthService(db)
+	user := createTestUser(t, auth, "po_user")
+	other := createTestUser(t, auth, "po_other")
+	poSvc := NewPurchaseOrderService(db)
+	client := NewClientService(db).Create(user.ID, dto.CreateClientInput{Name: "Enterprise Co"})
+
+	po, err := poSvc.Create(user.ID, dto.CreatePurchaseOrderInput{ClientID: client.ID, Number: " PO-1 ", AuthorizedAmount: 1000, ValidFrom: "2025-01-01", ValidTo: "2025-12-31"})
+	require.NoError(t, err)
+	assert.Equal(t, "PO-1", po.Number)
+	assert.InDelta(t, 1000.0, po.RemainingAmount, 0.001)
+
+	_, err = poSvc.Create(user.ID, dto.CreatePurchaseOrderInput{ClientID: client.ID, Number: "PO-1", AuthorizedAmount: 5})
+	assert.Error(t, err, "duplicate number")
+	_, err = poSvc.Create(user.ID, dto.CreatePurchaseOrderInput{ClientID: client.ID, Number: "PO-2", AuthorizedAmount: 0})
+	assert.Error(t, err)
+	_, err = poSvc.Create(user.ID, dto.CreatePurchaseOrderInput{ClientID: client.ID, Number: "PO-2", AuthorizedAmount: 5, ValidFrom: "2025-02-01", ValidTo: "2025-01-01"})
+	assert.Error(t, err)
+	_, err = poSvc.Create(other.ID, dto.CreatePurchaseOrderInput{ClientID: client.ID, Number: "PO-X", AuthorizedAmount: 5})
+	assert.Error(t, err, "client of another user")
+
+	updated, err := poSvc.Update(user.ID, dto.UpdatePurchaseOrderInput{ID: po.ID, Number: "PO-1", AuthorizedAmount: 1500, ValidFrom: "2025-01-01", ValidTo: "2025-12-31"})
+	require.NoError(t, err)
+	assert.InDelta(t, 1500.0, updated.AuthorizedAmount, 0.001)
+	assert.Len(t, poSvc.List(user.ID, client.ID), 1)
+	assert.Len(t, poSvc.List(other.ID, 0), 0)
+
+	assert.Equal(t, "expired", purchaseOrderStatus(updated, "2026-01-01"))
+	assert.Equal(t, "not_started", purchaseOrderStatus(updated, "2024-12-31"))
+	assert.Equal(t, "active", purchaseOrderStatus(updated, "2025-06-01"))
+
+	require.NoError(t, poSvc.Delete(user.ID, po.ID))
+	_, err = poSvc.Get(user.ID, po.ID)
+	assert.Error(t, err)
+}
+
+func TestPurchaseOrders_OnInvoices(t *testing.T) {
+	db := setupFullTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	auth := NewAuthService(db)
+	user := createTestUser(t, auth, "po_invoice_user")
+	clientSvc := NewClientService(db)
+	projectSvc := NewProjectService(db)
+	timeSvc := NewTimesheetService(db)
+	invoiceSvc := NewInvoiceService(db)
+	poSvc := NewPurchaseOrderService(db)
+
+	client := clientSvc.Create(user.ID, dto.CreateClientInput{Name: "Enterprise Co", RequiresPO: true})
+	assert.True(t, client.RequiresPO)
+	otherClient := clientSvc.Create(user.ID, dto.CreateClientInput{Name: "Small Co"})
+	po, err := poSvc.Create(user.ID, dto.CreatePurchaseOrderInput{ClientID: client.ID, Number: "PO-77", AuthorizedAmount: 1000, ValidFrom: "2025-01-01", ValidTo: "2025-06-30"})
+	require.NoError(t, err)
+	foreign, err := poSvc.Create(user.ID, dto.CreatePurchaseOrderInput{ClientID: otherClient.ID, Number: "PO-SMALL", AuthorizedAmount: 10})
+	require.NoError(t, err)
+
+	// Without a PO the invoice warns and cannot be sent.
+	inv := invoiceSvc.Create(user.ID, dto.CreateInvoiceInput{ClientID: client.ID, Number: "PO-INV-1", IssueDate: "2025-02-01", Status: "draft"})
+	require.NotZero(t, inv.ID)
+	assert.Len(t, inv.Warnings, 1)
+	err = invoiceSvc.SendEmail(user.ID, inv.ID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "purchase order")
+	assert.ErrorContains(t, invoiceSvc.UpdateStatus(user.ID, inv.ID, "sent"), "purchase order")
+	assert.Zero(t, invoiceSvc.Update(user.ID, dto.UpdateInvoiceInput{ID: inv.ID, ClientID: client.ID, Number: inv.Number, IssueDate: inv.IssueDate, Status: "sent"}).ID)
+	unsent, err := invoiceSvc.Get(user.ID, inv.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "draft", unsent.Status)
+	issued := invoiceSvc.Create(user.ID, dto.CreateInvoiceInput{ClientID: client.ID, Number: "PO-INV-0", IssueDate: "2025-02-01", Status: "sent"})
+	assert.Zero(t, issued.ID, "cannot be created as sent without a PO")
+
+	_, err = invoiceSvc.SetPurchaseOrder(user.ID, dto.SetInvoicePurchaseOrderInput{InvoiceID: inv.ID, PurchaseOrderID: foreign.ID})
+	assert.Error(t, err, "PO of another client")
+
+	linked, err := invoiceSvc.SetPurchaseOrder(user.ID, dto.SetInvoicePurchaseOrderInput{InvoiceID: inv.ID, PurchaseOrderID: po.ID})
+	require.NoError(t, err)
+	assert.Equal(t, "PO-77", linked.PurchaseOrderNumber)
+	assert.Empty(t, linked.Warnings)
+	require.NoError(t, invoiceSvc.UpdateStatus(user.ID, inv.ID, "sent"))
+
+	// Bill 8h at 100 on the first invoice; the PO balance follows.
+	project := projectSvc.Create(user.ID, dto.CreateProjectInput{ClientID: client.ID, Name: "Platform", HourlyRate: 100})
+	e1 := timeSvc.Create(user.ID, dto.CreateTimeEntryInput{ProjectID: project.ID, Date: "2025-01-20", DurationSeconds: 8 * 3600, Billable: true})
+	_, err = invoiceSvc.SetTimeEntries(user.ID, dto.SetInvoiceTimeEntriesInput{InvoiceID: inv.ID, TimeEntryIDs: []int{e1.ID}})
+	require.NoError(t, err)
+	got, err := poSvc.Get(user.ID, po.ID)
+	require.NoError(t, err)
+	assert.InDelta(t, 800.0, got.InvoicedAmount, 0.001)
+	assert.InDelta(t, 200.0, got.RemainingAmount, 0.001)
+	assert.Equal(t, 1, got.InvoiceCount)
+
+	// A second invoice pushes the PO over its authorized amount: warn, don't block.
+	second := invoiceSvc.Create(user.ID, dto.CreateInvoiceInput{ClientID: client.ID, Number: "PO-INV-2", IssueDate: "2025-03-01", Status: "draft", PurchaseOrderID: po.ID})
+	require.NotZero(t, second.ID)
+	e2 := timeSvc.Create(user.ID, dto.CreateTimeEntryInput{ProjectID: project.ID, Date: "2025-02-20", DurationSeconds: 3 * 3600, Billable: true})
+	billed, err := invoiceSvc.SetTimeEntries(user.ID, dto.SetInvoiceTimeEntriesInput{InvoiceID: second.ID, TimeEntryIDs: []int{e2.ID}})
+	require.NoError(t, err)
+	require.Len(t, billed.Warnings, 1)
+	assert.Contains(t, billed.Warnings[0], "exceeding the authorized 1000.00 by 100.00")
+	got, _ = poSvc.Get(user.ID, po.ID)
+	assert.InDelta(t, -100.0, got.RemainingAmount, 0.001)
+	assert.Equal(t, "exhausted", purchaseOrderStatus(got, "2025-03-01"))
+
+	// Issued after the PO expired: the reference is no longer valid.
+	late := invoiceSvc.Create(user.ID, dto.CreateInvoiceInput{ClientID: client.ID, Number: "PO-INV-3", IssueDate: "2025-08-01", Status: "draft", PurchaseOrderID: po.ID})
+	warnings, blocked := purchaseOrderWarnings(db, user.ID, late)
+	assert.True(t, blocked)
+	assert.Contains(t, warnings[0], "not valid on 2025-08-01")
+
+	// Referenced POs cannot be deleted; creating with a foreign PO fails.
+	assert.Error(t, poSvc.Delete(user.ID, po.ID))
+	bad := invoiceSvc.Create(user.ID, dto.CreateInvoiceInput{ClientID: client.ID, Number: "PO-INV-4", IssueDate: "2025-02-01", PurchaseOrderID: foreign.ID})
+	assert.Zero(t, bad.ID)
+
+	// Moving an invoice to another client drops the PO it no longer applies to.
+	moved := invoiceSvc.Update(user.ID, dto.UpdateInvoiceInput{ID: late.ID, ClientID: otherClient.ID, Number: late.Number, IssueDate: late.IssueDate, Status: "draft"})
+	assert.Equal(t, otherClient.ID, moved.ClientID)
+	assert.Zero(t, moved.PurchaseOrderID)
+	kept := invoiceSvc.Update(user.ID, dto.UpdateInvoiceInput{ID: second.ID, ClientID: client.ID, Number: second.Number, IssueDate: second.IssueDate, Status: "draft"})
+	assert.Equal(t, po.ID, kept.PurchaseOrderID, "editing without changing the client keeps the PO")
+
+	// The PO number prints on the invoice.
+	html, err := pdf.NewTemplateRenderer(pdf.GetTemplatesDir()).RenderHTML("quickbooks", pdf.InvoiceTemplateData{InvoiceNumber: "PO-INV-1", PONumber: linked.PurchaseOrderNumber})
+	require.NoError(t, err)
+	assert.Contains(t, html, "P.O.#</span> PO-77")
+}
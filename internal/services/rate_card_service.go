@@ -0,0 +1,201 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"tally/internal/dto"
+	"tally/internal/mapper"
+	"tally/internal/models"
+	"time"
+)
+
+// effectiveRateSQL resolves the hourly rate in force on a time entry's date.
+// It expects the aliases te (time_entries) and p (projects) in the outer query.
+// Precedence: latest project card, then latest client card, then projects.hourly_rate.
+const effectiveRateSQL = `COALESCE(
+	(SELECT rc.rate FROM rate_cards rc
+	 WHERE rc.user_id = te.user_id AND rc.project_id = p.id AND rc.effective_from <= te.date
+	 ORDER BY rc.effective_from DESC, rc.id DESC LIMIT 1),
+	(SELECT rc.rate FROM rate_cards rc
+	 WHERE rc.user_id = te.user_id AND rc.client_id = p.client_id AND rc.project_id IS NULL AND rc.effective_from <= te.date
+	 ORDER BY rc.effective_from DESC, rc.id DESC LIMIT 1),
+	p.hourly_rate, 0)`
+
+// RateCardService manages dated client and project rates.
+type RateCardService struct {
+	db *sql.DB
+}
+
+// NewRateCardService creates a new RateCardService instance.
+func NewRateCardService(db *sql.DB) *RateCardService {
+	return &RateCardService{db: db}
+}
+
+// List returns the rate history for a user, newest effective date first.
+func (s *RateCardService) List(userID int, filter dto.RateCardFilter) []dto.RateCardOutput {
+	query := "SELECT id, client_id, project_id, rate, currency, effective_from, notes FROM rate_cards WHERE user_id = ?"
+	args := []interface{}{userID}
+	if filter.ProjectID > 0 {
+		query += " AND project_id = ?"
+		args = append(args, filter.ProjectID)
+	} else if filter.ClientID > 0 {
+		query += " AND client_id = ? AND project_id IS NULL"
+		args = append(args, filter.ClientID)
+	}
+	query += " ORDER BY effective_from DESC, id DESC"
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		log.Println("Error querying rate cards:", err)
+		return []dto.RateCardOutput{}
+	}
+	defer closeWithLog(rows, "closing rate card rows")
+
+	var cards []models.RateCard
+	for rows.Next() {
+		c, err := scanRateCard(rows)
+		if err != nil {
+			log.Println("Error scanning rate card:", err)
+			continue
+		}
+		cards = append(cards, c)
+	}
+	return mapper.ToRateCardOutputList(cards)
+}
+
+// Get returns a single rate card by ID for a specific user.
+func (s *RateCardService) Get(userID int, id int) (dto.RateCardOutput, error) {
+	row := s.db.QueryRow("SELECT id, client_id, project_id, rate, currency, effective_from, notes FROM rate_cards WHERE id = ? AND user_id = ?", id, userID)
+	c, err := scanRateCard(row)
+	if err != nil {
+		return dto.RateCardOutput{}, err
+	}
+	return mapper.ToRateCardOutput(c), nil
+}
+
+// Create adds a dated rate for a client or project.
+func (s *RateCardService) Create(userID int, input dto.CreateRateCardInput) (dto.RateCardOutput, error) {
+	entity := mapper.ToRateCardEntity(input)
+	if err := s.validate(userID, entity); err != nil {
+		return dto.RateCardOutput{}, err
+	}
+
+	res, err := s.db.Exec(
+		"INSERT INTO rate_cards(user_id, client_id, project_id, rate, currency, effective_from, notes) VALUES(?, ?, ?, ?, ?, ?, ?)",
+		userID, nullableID(entity.ClientID), nullableID(entity.ProjectID), entity.Rate, entity.Currency, entity.EffectiveFrom, entity.Notes,
+	)
+	if err != nil {
+		return dto.RateCardOutput{}, fmt.Errorf("failed to insert rate card: %w", err)
+	}
+
+	id, _ := res.LastInsertId()
+	entity.ID = int(id)
+	return mapper.ToRateCardOutput(entity), nil
+}
+
+// Update modifies an existing dated rate.
+func (s *RateCardService) Update(userID int, input dto.UpdateRateCardInput) (dto.RateCardOutput, error) {
+	entity := models.RateCard{
+		ID:            input.ID,
+		ClientID:      input.ClientID,
+		ProjectID:     input.ProjectID,
+		Rate:          input.Rate,
+		Currency:      input.Currency,
+		EffectiveFrom: input.EffectiveFrom,
+		Notes:         input.Notes,
+	}
+	if err := s.validate(userID, entity); err != nil {
+		return dto.RateCardOutput{}, err
+	}
+
+	res, err := s.db.Exec(
+		"UPDATE rate_cards SET client_id=?, project_id=?, rate=?, currency=?, effective_from=?, notes=?, updated_at=datetime('now') WHERE id=? AND user_id=?",
+		nullableID(entity.ClientID), nullableID(entity.ProjectID), entity.Rate, entity.Currency, entity.EffectiveFrom, entity.Notes, input.ID, userID,
+	)
+	if err != nil {
+		return dto.RateCardOutput{}, fmt.Errorf("failed to update rate card: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return dto.RateCardOutput{}, fmt.Errorf("rate card not found or not owned by user")
+	}
+	return s.Get(userID, input.ID)
+}
+
+// Delete removes a dated rate.
+func (s *RateCardService) Delete(userID int, id int) {
+	_, err := s.db.Exec("DELETE FROM rate_cards WHERE id=? AND user_id=?", id, userID)
+	if err != nil {
+		log.Println("Error deleting rate card:", err)
+	}
+}
+
+// ResolveRate returns the hourly rate in force for a project on the given date (YYYY-MM-DD).
+func (s *RateCardService) ResolveRate(userID int, projectID int, date string) (float64, error) {
+	query := `SELECT ` + effectiveRateSQL + `
+FROM projects p, (SELECT ? AS user_id, ? AS date) te
+WHERE p.id = ? AND p.user_id = ?`
+
+	var rate float64
+	if err := s.db.QueryRow(query, userID, date, projectID, userID).Scan(&rate); err != nil {
+		return 0, fmt.Errorf("failed to resolve rate: %w", err)
+	}
+	return rate, nil
+}
+
+// validate checks the scope and effective date of a rate card.
+func (s *RateCardService) validate(userID int, c models.RateCard) error {
+	if c.ClientID <= 0 && c.ProjectID <= 0 {
+		return fmt.Errorf("rate card requires a client or project")
+	}
+	if c.Rate < 0 {
+		return fmt.Errorf("rate must not be negative")
+	}
+	if _, err := time.Parse("2006-01-02", c.EffectiveFrom); err != nil {
+		return fmt.Errorf("invalid effective date %q: expected YYYY-MM-DD", c.EffectiveFrom)
+	}
+
+	var count int
+	if c.ProjectID > 0 {
+		if err := s.db.QueryRow("SELECT COUNT(*) FROM projects WHERE id = ? AND user_id = ?", c.ProjectID, userID).Scan(&count); err != nil {
+			return fmt.Errorf("failed to check project: %w", err)
+		}
+		if count == 0 {
+			return fmt.Errorf("project not found")
+		}
+		return nil
+	}
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM clients WHERE id = ? AND user_id = ?", c.ClientID, userID).Scan(&count); err != nil {
+		return fmt.Errorf("failed to check client: %w", err)
+	}
+	if count == 0 {
+		return fmt.Errorf("client not found")
+	}
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanRateCard(row rowScanner) (models.RateCard, error) {
+	var c models.RateCard
+	var clientID, projectID sql.NullInt64
+	var currency, notes sql.NullString
+	if err := row.Scan(&c.ID, &clientID, &projectID, &c.Rate, &currency, &c.EffectiveFrom, &notes); err != nil {
+		return models.RateCard{}, err
+	}
+	c.ClientID = int(clientID.Int64)
+	c.ProjectID = int(projectID.Int64)
+	c.Currency = currency.String
+	c.Notes = notes.String
+	return c, nil
+}
+
+// nullableID maps a zero ID to SQL NULL for optional foreign keys.
+func nullableID(id int) interface{} {
+	if id <= 0 {
+		return nil
+	}
+	return id
+}
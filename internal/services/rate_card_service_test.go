@@ -0,0 +1,79 @@
+package services
+
+import (
+	"tally/internal/dto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateCardService_EffectiveRates(t *testing.T) {
+	db := setupFullTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	auth := NewAuthService(db)
+	user := createTestUser(t, auth, "rate_user")
+
+	clientSvc := NewClientService(db)
+	projectSvc := NewProjectService(db)
+	timeSvc := NewTimesheetService(db)
+	rateSvc := NewRateCardService(db)
+
+	client := clientSvc.Create(user.ID, dto.CreateClientInput{Name: "Acme"})
+	project := projectSvc.Create(user.ID, dto.CreateProjectInput{ClientID: client.ID, Name: "Site", HourlyRate: 80})
+
+	// No cards: falls back to the project's base rate.
+	rate, err := rateSvc.ResolveRate(user.ID, project.ID, "2025-01-10")
+	assert.NoError(t, err)
+	assert.Equal(t, 80.0, rate)
+
+	// Client-wide rate applies from February.
+	_, err = rateSvc.Create(user.ID, dto.CreateRateCardInput{ClientID: client.ID, Rate: 100, EffectiveFrom: "2025-02-01"})
+	assert.NoError(t, err)
+	// Project rate overrides the client rate from March.
+	_, err = rateSvc.Create(user.ID, dto.CreateRateCardInput{ProjectID: project.ID, Rate: 120, EffectiveFrom: "2025-03-01"})
+	assert.NoError(t, err)
+
+	cases := map[string]float64{
+		"2025-01-31": 80,
+		"2025-02-01": 100,
+		"2025-02-28": 100,
+		"2025-03-01": 120,
+	}
+	for date, want := range cases {
+		got, err := rateSvc.ResolveRate(user.ID, project.ID, date)
+		assert.NoError(t, err)
+		assert.Equal(t, want, got, "rate on %s", date)
+	}
+
+	assert.Len(t, rateSvc.List(user.ID, dto.RateCardFilter{ClientID: client.ID}), 1)
+	assert.Len(t, rateSvc.List(user.ID, dto.RateCardFilter{ProjectID: project.ID}), 1)
+
+	// Validation
+	_, err = rateSvc.Create(user.ID, dto.CreateRateCardInput{Rate: 50, EffectiveFrom: "2025-01-01"})
+	assert.Error(t, err)
+	_, err = rateSvc.Create(user.ID, dto.CreateRateCardInput{ClientID: client.ID, Rate: 50, EffectiveFrom: "01/01/2025"})
+	assert.Error(t, err)
+
+	// Reports price each entry with the rate in force on its date.
+	for _, date := range []string{"2025-01-15", "2025-02-15", "2025-03-15"} {
+		timeSvc.Create(user.ID, dto.CreateTimeEntryInput{ProjectID: project.ID, Date: date, DurationSeconds: 3600, Billable: true})
+	}
+	report, err := NewReportService(db).Get(user.ID, dto.ReportFilter{})
+	assert.NoError(t, err)
+	assert.InDelta(t, 3.0, report.TotalHours, 0.001)
+	assert.InDelta(t, 80.0+100.0+120.0, report.TotalIncome, 0.001)
+
+	// Invoice recalculation splits lines per rate.
+	invoiceSvc := NewInvoiceService(db)
+	inv := invoiceSvc.Create(user.ID, dto.CreateInvoiceInput{ClientID: client.ID, Number: "R-1", IssueDate: "2025-03-31", Status: "draft"})
+	entries := timeSvc.List(user.ID, project.ID)
+	var ids []int
+	for _, e := range entries {
+		ids = append(ids, e.ID)
+	}
+	out, err := invoiceSvc.SetTimeEntries(user.ID, dto.SetInvoiceTimeEntriesInput{InvoiceID: inv.ID, TimeEntryIDs: ids})
+	assert.NoError(t, err)
+	assert.Len(t, out.Items, 3)
+	assert.InDelta(t, 300.0, out.Subtotal, 0.001)
+}
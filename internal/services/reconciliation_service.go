@@ -0,0 +1,279 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"tally/internal/dto"
+	"tally/internal/mapper"
+	"tally/internal/models"
+)
+
+// ReconciliationService reconciles accounts against bank statements: the user
+// enters the statement's ending balance and date, ticks the transactions that
+// cleared, and completes the reconciliation once the cleared balance matches.
+// Completing marks the cleared transactions reconciled, which locks them.
+type ReconciliationService struct {
+	db *sql.DB
+}
+
+// NewReconciliationService creates a new ReconciliationService instance.
+func NewReconciliationService(db *sql.DB) *ReconciliationService {
+	return &ReconciliationService{db: db}
+}
+
+// Reconciliation statuses.
+const (
+	reconciliationInProgress = "in_progress"
+	reconciliationCompleted  = "completed"
+)
+
+const reconciliationColumns = `r.id, r.account_id, COALESCE(a.name, ''), r.statement_date, r.statement_balance, r.status,
+  (SELECT COUNT(*) FROM finance_transactions t WHERE t.reconciliation_id = r.id), COALESCE(r.created_at, ''), COALESCE(r.completed_at, '')`
+
+const reconciliationFrom = " FROM reconciliations r LEFT JOIN finance_accounts a ON a.id = r.account_id"
+
+// List returns the user's reconciliations, newest first, optionally for one account.
+func (s *ReconciliationService) List(userID int, accountID int) []dto.ReconciliationOutput {
+	query := "SELECT " + reconciliationColumns + reconciliationFrom + " WHERE r.user_id = ?"
+	args := []interface{}{userID}
+	if accountID > 0 {
+		query += " AND r.account_id = ?"
+		args = append(args, accountID)
+	}
+	rows, err := s.db.Query(query+" ORDER BY r.statement_date DESC, r.id DESC", args...)
+	if err != nil {
+		log.Println("Error querying reconciliations:", err)
+		return []dto.ReconciliationOutput{}
+	}
+	defer closeWithLog(rows, "closing reconciliation rows")
+
+	var list []models.Reconciliation
+	for rows.Next() {
+		r, err := scanReconciliation(rows)
+		if err != nil {
+			log.Println("Error scanning reconciliation:", err)
+			continue
+		}
+		list = append(list, r)
+	}
+	out := mapper.ToReconciliationOutputList(list)
+	for i := range out {
+		if out[i].Status == reconciliationCompleted {
+			out[i].ClearedBalance = out[i].StatementBalance
+		} else if err := s.fillBalances(userID, &out[i]); err != nil {
+			log.Println("Error computing reconciliation balance:", err)
+		}
+	}
+	return out
+}
+
+// Get returns a reconciliation. While it is in progress, the transactions
+// that can still be ticked are included: unreconciled ones up to the
+// statement date, and any already cleared.
+func (s *ReconciliationService) Get(userID int, id int) (dto.ReconciliationOutput, error) {
+	r, err := scanReconciliation(s.db.QueryRow("SELECT "+reconciliationColumns+reconciliationFrom+" WHERE r.id = ? AND r.user_id = ?", id, userID))
+	if err != nil {
+		return dto.ReconciliationOutput{}, fmt.Errorf("reconciliation not found: %w", err)
+	}
+	out := mapper.ToReconciliationOutput(r)
+	if out.Status == reconciliationCompleted {
+		out.ClearedBalance = out.StatementBalance
+		return out, nil
+	}
+	if err := s.fillBalances(userID, &out); err != nil {
+		return dto.ReconciliationOutput{}, err
+	}
+
+	account, err := NewFinanceService(s.db, nil).GetAccount(userID, out.AccountID)
+	if err != nil {
+		return dto.ReconciliationOutput{}, err
+	}
+	out.Transactions = []dto.TransactionOutput{}
+	for _, t := range NewFinanceService(s.db, nil).GetTransactions(userID, dto.TransactionFilter{AccountID: out.AccountID, StartDate: account.OpeningDate}) {
+		date := t.Date.Format("2006-01-02")
+		if t.Status != "reconciled" && (date <= out.StatementDate || t.Status == "cleared") {
+			out.Transactions = append(out.Transactions, t)
+		}
+	}
+	return out, nil
+}
+
+// Start opens a reconciliation for an account. An account has at most one
+// open reconciliation, and statements are reconciled in date order.
+func (s *ReconciliationService) Start(userID int, input dto.StartReconciliationInput) (dto.ReconciliationOutput, error) {
+	if _, err := NewFinanceService(s.db, nil).GetAccount(userID, input.AccountID); err != nil {
+		return dto.ReconciliationOutput{}, err
+	}
+	date, err := parseDate(input.StatementDate)
+	if err != nil {
+		return dto.ReconciliationOutput{}, fmt.Errorf("invalid statement date: %w", err)
+	}
+	statementDate := date.Format("2006-01-02")
+
+	var open int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM reconciliations WHERE account_id = ? AND user_id = ? AND status = ?",
+		input.AccountID, userID, reconciliationInProgress).Scan(&open); err != nil {
+		return dto.ReconciliationOutput{}, fmt.Errorf("failed to check open reconciliations: %w", err)
+	}
+	if open > 0 {
+		return dto.ReconciliationOutput{}, fmt.Errorf("this account already has a reconciliation in progress")
+	}
+	var last string
+	if err := s.db.QueryRow("SELECT COALESCE(MAX(statement_date), '') FROM reconciliations WHERE account_id = ? AND user_id = ? AND status = ?",
+		input.AccountID, userID, reconciliationCompleted).Scan(&last); err != nil {
+		return dto.ReconciliationOutput{}, fmt.Errorf("failed to check previous reconciliations: %w", err)
+	}
+	if last != "" && statementDate <= last {
+		return dto.ReconciliationOutput{}, fmt.Errorf("the account is already reconciled through %s", last)
+	}
+
+	res, err := s.db.Exec("INSERT INTO reconciliations(user_id, account_id, statement_date, statement_balance, status) VALUES(?, ?, ?, ?, ?)",
+		userID, input.AccountID, statementDate, input.StatementBalance, reconciliationInProgress)
+	if err != nil {
+		return dto.ReconciliationOutput{}, fmt.Errorf("failed to start reconciliation: %w", err)
+	}
+	id, _ := res.LastInsertId()
+	return s.Get(userID, int(id))
+}
+
+// SetCleared ticks or unticks transactions of the reconciled account.
+// Reconciled transactions are locked and cannot be unticked.
+func (s *ReconciliationService) SetCleared(userID int, input dto.SetClearedInput) (dto.ReconciliationOutput, error) {
+	r, err := s.open(userID, input.ReconciliationID)
+	if err != nil {
+		return dto.ReconciliationOutput{}, err
+	}
+	status := "pending"
+	if input.Cleared {
+		status = "cleared"
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return dto.ReconciliationOutput{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	for _, id := range input.TransactionIDs {
+		res, err := tx.Exec("UPDATE finance_transactions SET status = ?, updated_at = datetime('now') WHERE id = ? AND user_id = ? AND account_id = ? AND COALESCE(status, '') != 'reconciled'",
+			status, id, userID, r.AccountID)
+		if err != nil {
+			return dto.ReconciliationOutput{}, fmt.Errorf("failed to update transaction: %w", err)
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return dto.ReconciliationOutput{}, fmt.Errorf("transaction %d is not an unreconciled transaction of this account", id)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return dto.ReconciliationOutput{}, err
+	}
+	return s.Get(userID, r.ID)
+}
+
+// Complete finishes a reconciliation whose cleared balance matches the
+// statement, reconciling and locking the cleared transactions.
+func (s *ReconciliationService) Complete(userID int, id int) (dto.ReconciliationOutput, error) {
+	r, err := s.open(userID, id)
+	if err != nil {
+		return dto.ReconciliationOutput{}, err
+	}
+	if math.Abs(r.Difference) >= 0.005 {
+		return dto.ReconciliationOutput{}, fmt.Errorf("cleared balance %.2f differs from the statement balance %.2f by %.2f",
+			r.ClearedBalance, r.StatementBalance, r.Difference)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return dto.ReconciliationOutput{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	if _, err := tx.Exec(`UPDATE finance_transactions SET status = 'reconciled', reconciliation_id = ?, updated_at = datetime('now')
+WHERE account_id = ? AND user_id = ? AND status = 'cleared'`, id, r.AccountID, userID); err != nil {
+		return dto.ReconciliationOutput{}, fmt.Errorf("failed to reconcile transactions: %w", err)
+	}
+	if _, err := tx.Exec("UPDATE reconciliations SET status = ?, completed_at = datetime('now') WHERE id = ? AND user_id = ?",
+		reconciliationCompleted, id, userID); err != nil {
+		return dto.ReconciliationOutput{}, fmt.Errorf("failed to complete reconciliation: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return dto.ReconciliationOutput{}, err
+	}
+	return s.Get(userID, id)
+}
+
+// Cancel discards a reconciliation in progress. Ticked transactions stay cleared.
+func (s *ReconciliationService) Cancel(userID int, id int) error {
+	if _, err := s.open(userID, id); err != nil {
+		return err
+	}
+	_, err := s.db.Exec("DELETE FROM reconciliations WHERE id = ? AND user_id = ?", id, userID)
+	return err
+}
+
+// Reopen unlocks the account's latest completed reconciliation to correct it:
+// its transactions go back to cleared and it is in progress again.
+func (s *ReconciliationService) Reopen(userID int, id int) (dto.ReconciliationOutput, error) {
+	r, err := s.Get(userID, id)
+	if err != nil {
+		return dto.ReconciliationOutput{}, err
+	}
+	if r.Status != reconciliationCompleted {
+		return dto.ReconciliationOutput{}, fmt.Errorf("reconciliation is not completed")
+	}
+	var later int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM reconciliations WHERE account_id = ? AND user_id = ? AND id != ? AND (status = ? OR statement_date > ?)",
+		r.AccountID, userID, id, reconciliationInProgress, r.StatementDate).Scan(&later); err != nil {
+		return dto.ReconciliationOutput{}, fmt.Errorf("failed to check later reconciliations: %w", err)
+	}
+	if later > 0 {
+		return dto.ReconciliationOutput{}, fmt.Errorf("only the latest reconciliation of an account can be reopened")
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return dto.ReconciliationOutput{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	if _, err := tx.Exec("UPDATE finance_transactions SET status = 'cleared', reconciliation_id = NULL WHERE reconciliation_id = ? AND user_id = ?", id, userID); err != nil {
+		return dto.ReconciliationOutput{}, fmt.Errorf("failed to unlock transactions: %w", err)
+	}
+	if _, err := tx.Exec("UPDATE reconciliations SET status = ?, completed_at = NULL WHERE id = ? AND user_id = ?", reconciliationInProgress, id, userID); err != nil {
+		return dto.ReconciliationOutput{}, fmt.Errorf("failed to reopen reconciliation: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return dto.ReconciliationOutput{}, err
+	}
+	return s.Get(userID, id)
+}
+
+// open returns a reconciliation that is still in progress.
+func (s *ReconciliationService) open(userID int, id int) (dto.ReconciliationOutput, error) {
+	r, err := s.Get(userID, id)
+	if err != nil {
+		return dto.ReconciliationOutput{}, err
+	}
+	if r.Status != reconciliationInProgress {
+		return dto.ReconciliationOutput{}, fmt.Errorf("reconciliation is already completed")
+	}
+	return r, nil
+}
+
+// fillBalances sets the cleared balance of an open reconciliation's account
+// and its difference from the statement.
+func (s *ReconciliationService) fillBalances(userID int, r *dto.ReconciliationOutput) error {
+	account, err := NewFinanceService(s.db, nil).GetAccount(userID, r.AccountID)
+	if err != nil {
+		return err
+	}
+	r.ClearedBalance = account.ClearedBalance
+	r.Difference = math.Round((r.StatementBalance-r.ClearedBalance)*100) / 100
+	return nil
+}
+
+func scanReconciliation(row rowScanner) (models.Reconciliation, error) {
+	var r models.Reconciliation
+	err := row.Scan(&r.ID, &r.AccountID, &r.AccountName, &r.StatementDate, &r.StatementBalance, &r.Status,
+		&r.ReconciledCount, &r.CreatedAt, &r.CompletedAt)
+	return r, err
+}
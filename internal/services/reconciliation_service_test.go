@@ -0,0 +1,122 @@
+package services
+
+import (
+	"tally/internal/dto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFinanceService_DerivedBalances(t *testing.T) {
+	db := setupFinanceTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	user := createTestUser(t, NewAuthService(db), "derived_balance_user")
+	finance := NewFinanceService(db, nil)
+	acc := finance.CreateAccount(user.ID, dto.CreateAccountInput{Name: "Chequing", Type: "checking", OpeningBalance: 1000, OpeningDate: "2024-01-01"})
+	require.NotZero(t, acc.ID)
+	assert.Equal(t, 1000.0, acc.Balance)
+	assert.Equal(t, "2024-01-01", acc.OpeningDate)
+
+	_, err := finance.ImportTransactions(user.ID, dto.ImportTransactionsInput{AccountID: acc.ID, FileContent: "2023-12-30,Before opening,-99.00\n" +
+		"2024-01-05,Rent,-800.00\n" +
+		"2024-01-10,Client payment,1250.50\n"})
+	require.NoError(t, err)
+
+	acc, err = finance.GetAccount(user.ID, acc.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1450.5, acc.Balance, "transactions before the opening date don't count")
+	assert.Equal(t, 1000.0, acc.ClearedBalance)
+	assert.Equal(t, 1450.5, finance.GetSummary(user.ID).TotalBalance)
+
+	acc = finance.UpdateAccount(user.ID, dto.UpdateAccountInput{ID: acc.ID, Name: "Chequing", Type: "checking", OpeningBalance: 500})
+	assert.Equal(t, 851.5, acc.Balance, "without an opening date every transaction counts")
+}
+
+func TestReconciliationService_Workflow(t *testing.T) {
+	db := setupFinanceTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	auth := NewAuthService(db)
+	user := createTestUser(t, auth, "reconcile_user")
+	other := createTestUser(t, auth, "reconcile_other")
+	finance := NewFinanceService(db, nil)
+	imports := NewTransactionImportService(db, nil)
+	reconciliations := NewReconciliationService(db)
+	acc := finance.CreateAccount(user.ID, dto.CreateAccountInput{Name: "Chequing", Type: "checking", OpeningBalance: 1000})
+
+	batch, err := imports.Commit(user.ID, dto.CommitImportInput{Import: dto.ImportTransactionsInput{AccountID: acc.ID, FileContent: "2024-01-05,Rent,-800.00\n" +
+		"2024-01-10,Client payment,1250.00\n" +
+		"2024-01-30,Cheque 101,-50.00\n" +
+		"2024-02-03,Groceries,-75.00\n"}, Lines: []int{1, 2, 3, 4}})
+	require.NoError(t, err)
+	ids := map[string]int{}
+	for _, tx := range finance.GetTransactions(user.ID, dto.TransactionFilter{AccountID: acc.ID}) {
+		assert.Equal(t, "pending", tx.Status)
+		ids[tx.Description] = tx.ID
+	}
+
+	_, err = reconciliations.Start(other.ID, dto.StartReconciliationInput{AccountID: acc.ID, StatementDate: "2024-01-31", StatementBalance: 1450})
+	assert.Error(t, err)
+	rec, err := reconciliations.Start(user.ID, dto.StartReconciliationInput{AccountID: acc.ID, StatementDate: "2024-01-31", StatementBalance: 1450})
+	require.NoError(t, err)
+	assert.Equal(t, "in_progress", rec.Status)
+	assert.Len(t, rec.Transactions, 3, "transactions after the statement date are left out")
+	assert.Equal(t, 1000.0, rec.ClearedBalance)
+	assert.Equal(t, 450.0, rec.Difference)
+	_, err = reconciliations.Start(user.ID, dto.StartReconciliationInput{AccountID: acc.ID, StatementDate: "2024-02-29", StatementBalance: 0})
+	assert.ErrorContains(t, err, "in progress")
+
+	// The cheque hasn't cleared the bank yet.
+	rec, err = reconciliations.SetCleared(user.ID, dto.SetClearedInput{ReconciliationID: rec.ID, TransactionIDs: []int{ids["Rent"], ids["Client payment"], ids["Cheque 101"]}, Cleared: true})
+	require.NoError(t, err)
+	assert.Equal(t, 50.0, rec.Difference)
+	_, err = reconciliations.Complete(user.ID, rec.ID)
+	assert.ErrorContains(t, err, "differs")
+	rec, err = reconciliations.SetCleared(user.ID, dto.SetClearedInput{ReconciliationID: rec.ID, TransactionIDs: []int{ids["Cheque 101"]}, Cleared: false})
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, rec.Difference)
+
+	rec, err = reconciliations.Complete(user.ID, rec.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "completed", rec.Status)
+	assert.Equal(t, 2, rec.ReconciledCount)
+	acc, err = finance.GetAccount(user.ID, acc.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "2024-01-31", acc.LastReconciledDate)
+	assert.Equal(t, 1450.0, acc.ClearedBalance)
+	assert.Equal(t, 1325.0, acc.Balance)
+
+	// Reconciled transactions are locked.
+	assert.Error(t, finance.DeleteTransaction(user.ID, ids["Rent"]))
+	_, err = reconciliations.SetCleared(user.ID, dto.SetClearedInput{ReconciliationID: rec.ID, TransactionIDs: []int{ids["Rent"]}})
+	assert.ErrorContains(t, err, "already completed")
+	_, err = imports.RollbackBatch(user.ID, batch.ID)
+	assert.ErrorContains(t, err, "reconciled")
+	require.NoError(t, finance.DeleteTransaction(user.ID, ids["Groceries"]))
+	// So are the opening balance and date the reconciled balance builds on,
+	// and the account itself; renaming it is fine.
+	renamed := finance.UpdateAccount(user.ID, dto.UpdateAccountInput{ID: acc.ID, Name: "Main", Type: "checking", OpeningBalance: 1000})
+	assert.Equal(t, "Main", renamed.Name)
+	assert.Zero(t, finance.UpdateAccount(user.ID, dto.UpdateAccountInput{ID: acc.ID, Name: "Main", Type: "checking", OpeningBalance: 900}).ID)
+	assert.Zero(t, finance.UpdateAccount(user.ID, dto.UpdateAccountInput{ID: acc.ID, Name: "Main", Type: "checking", OpeningBalance: 1000, OpeningDate: "2024-01-06"}).ID)
+	assert.ErrorContains(t, finance.DeleteAccount(user.ID, acc.ID), "reopen")
+
+	// Statements are reconciled in order; the latest one can be reopened.
+	_, err = reconciliations.Start(user.ID, dto.StartReconciliationInput{AccountID: acc.ID, StatementDate: "2024-01-15", StatementBalance: 0})
+	assert.ErrorContains(t, err, "already reconciled")
+	next, err := reconciliations.Start(user.ID, dto.StartReconciliationInput{AccountID: acc.ID, StatementDate: "2024-02-29", StatementBalance: 1400})
+	require.NoError(t, err)
+	_, err = reconciliations.Reopen(user.ID, rec.ID)
+	assert.Error(t, err)
+	require.NoError(t, reconciliations.Cancel(user.ID, next.ID))
+
+	rec, err = reconciliations.Reopen(user.ID, rec.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "in_progress", rec.Status)
+	assert.Len(t, reconciliations.List(user.ID, acc.ID), 1)
+	assert.Empty(t, reconciliations.List(other.ID, 0))
+	require.NoError(t, finance.DeleteTransaction(user.ID, ids["Rent"]))
+	require.NoError(t, finance.DeleteAccount(user.ID, acc.ID))
+}
@@ -0,0 +1,248 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"tally/internal/dto"
+	"time"
+	"unicode"
+)
+
+// RecurringExpenseService finds subscriptions and other charges that repeat
+// at a regular interval in the user's transactions.
+type RecurringExpenseService struct {
+	db *sql.DB
+}
+
+// NewRecurringExpenseService creates a new RecurringExpenseService instance.
+func NewRecurringExpenseService(db *sql.DB) *RecurringExpenseService {
+	return &RecurringExpenseService{db: db}
+}
+
+// recurringFrequency is an interval recurring charges may follow.
+type recurringFrequency struct {
+	name       string
+	days       int // nominal interval
+	minDays    int // shortest accepted interval
+	maxDays    int // longest accepted interval
+	months     int // calendar months to the next charge, 0 to count days
+	perYear    float64
+	minCharges int
+}
+
+var recurringFrequencies = []recurringFrequency{
+	{name: "weekly", days: 7, minDays: 5, maxDays: 9, perYear: 52, minCharges: 3},
+	{name: "biweekly", days: 14, minDays: 12, maxDays: 17, perYear: 26, minCharges: 3},
+	{name: "monthly", days: 30, minDays: 26, maxDays: 35, months: 1, perYear: 12, minCharges: 3},
+	{name: "quarterly", days: 91, minDays: 80, maxDays: 100, months: 3, perYear: 4, minCharges: 3},
+	{name: "annual", days: 365, minDays: 350, maxDays: 380, months: 12, perYear: 1, minCharges: 2},
+}
+
+// variableAmountTolerance is how far a charge of variable amount, like a
+// utility bill, may be from the typical amount and still count as recurring.
+const variableAmountTolerance = 0.25
+
+// recurringCharge is one expense considered for recurrence.
+type recurringCharge struct {
+	id           int
+	accountID    int
+	accountName  string
+	categoryName string
+	description  string
+	date         time.Time
+	amount       float64 // positive
+}
+
+// Detect lists the user's recurring expenses: charges with a similar
+// description and amount at a regular interval. Active ones come first,
+// most expensive per year first.
+func (s *RecurringExpenseService) Detect(userID int) ([]dto.RecurringExpenseOutput, error) {
+	return s.detect(userID, time.Now())
+}
+
+func (s *RecurringExpenseService) detect(userID int, asOf time.Time) ([]dto.RecurringExpenseOutput, error) {
+	rows, err := s.db.Query(`SELECT t.id, t.account_id, COALESCE(a.name, ''), COALESCE(c.name, ''), t.description, substr(t.date, 1, 10), t.amount
+FROM finance_transactions t
+LEFT JOIN finance_accounts a ON a.id = t.account_id
+LEFT JOIN finance_categories c ON c.id = t.category_id AND c.user_id = t.user_id
+WHERE t.user_id = ? AND t.amount < 0 AND t.transfer_id IS NULL AND t.possible_duplicate_of_id IS NULL
+ORDER BY t.date, t.id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query expenses: %w", err)
+	}
+	defer closeWithLog(rows, "closing recurring expense rows")
+
+	// Charges from the same merchant are split into streams of similar
+	// amounts, so two subscriptions billed under one name stay apart while
+	// a price change stays in its stream.
+	var keys []string
+	streams := map[string][][]recurringCharge{}
+	for rows.Next() {
+		var c recurringCharge
+		var date string
+		if err := rows.Scan(&c.id, &c.accountID, &c.accountName, &c.categoryName, &c.description, &date, &c.amount); err != nil {
+			return nil, fmt.Errorf("failed to scan expense: %w", err)
+		}
+		key := recurringKey(c.description)
+		d, err := parseDate(date)
+		if key == "" || err != nil {
+			continue
+		}
+		c.date, c.amount = d, -c.amount
+
+		if _, ok := streams[key]; !ok {
+			keys = append(keys, key)
+		}
+		best, bestDistance := -1, math.Log(2)
+		for i, stream := range streams[key] {
+			last := stream[len(stream)-1]
+			if last.date.Equal(c.date) {
+				continue
+			}
+			if distance := math.Abs(math.Log(c.amount / last.amount)); distance <= bestDistance {
+				best, bestDistance = i, distance
+			}
+		}
+		if best < 0 {
+			streams[key] = append(streams[key], []recurringCharge{c})
+		} else {
+			streams[key][best] = append(streams[key][best], c)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	out := []dto.RecurringExpenseOutput{}
+	for _, key := range keys {
+		for _, stream := range streams[key] {
+			if r, ok := recurringExpense(stream, asOf); ok {
+				out = append(out, r)
+			}
+		}
+	}
+	sort.SliceStable(out, func(a, b int) bool {
+		if out[a].Active != out[b].Active {
+			return out[a].Active
+		}
+		if out[a].AnnualCost != out[b].AnnualCost {
+			return out[a].AnnualCost > out[b].AnnualCost
+		}
+		return out[a].Description < out[b].Description
+	})
+	return out, nil
+}
+
+// recurringExpense describes a stream of charges, oldest first, if they
+// repeat at a regular interval.
+func recurringExpense(charges []recurringCharge, asOf time.Time) (dto.RecurringExpenseOutput, bool) {
+	if len(charges) < 2 {
+		return dto.RecurringExpenseOutput{}, false
+	}
+	intervals := make([]int, 0, len(charges)-1)
+	for i := 1; i < len(charges); i++ {
+		intervals = append(intervals, int(charges[i].date.Sub(charges[i-1].date).Hours()/24))
+	}
+	sorted := append([]int(nil), intervals...)
+	sort.Ints(sorted)
+	median := sorted[len(sorted)/2]
+
+	var freq recurringFrequency
+	for _, f := range recurringFrequencies {
+		if median >= f.minDays && median <= f.maxDays {
+			freq = f
+		}
+	}
+	if freq.name == "" || len(charges) < freq.minCharges {
+		return dto.RecurringExpenseOutput{}, false
+	}
+	// A missed or late charge is fine, but most must be on schedule.
+	regular := 0
+	for _, days := range intervals {
+		if days >= freq.minDays && days <= freq.maxDays {
+			regular++
+		}
+	}
+	if regular*4 < len(intervals)*3 {
+		return dto.RecurringExpenseOutput{}, false
+	}
+
+	// A subscription keeps its price for a while; a bill changes every time
+	// and must then stay close to its usual amount.
+	unchanged := 0
+	total := 0.0
+	for i, c := range charges {
+		total += c.amount
+		if i > 0 && math.Abs(c.amount-charges[i-1].amount) < 0.005 {
+			unchanged++
+		}
+	}
+	mean := total / float64(len(charges))
+	latest := charges[len(charges)-1]
+	r := dto.RecurringExpenseOutput{
+		Description:    latest.description,
+		AccountID:      latest.accountID,
+		AccountName:    latest.accountName,
+		CategoryName:   latest.categoryName,
+		Frequency:      freq.name,
+		Amount:         latest.amount,
+		Variable:       unchanged*2 < len(intervals),
+		Occurrences:    len(charges),
+		FirstDate:      charges[0].date.Format("2006-01-02"),
+		LastDate:       latest.date.Format("2006-01-02"),
+		PriceChanges:   []dto.PriceChangeOutput{},
+		TransactionIDs: make([]int, 0, len(charges)),
+	}
+	for i, c := range charges {
+		r.TransactionIDs = append(r.TransactionIDs, c.id)
+		if r.Variable {
+			if math.Abs(c.amount-mean) > mean*variableAmountTolerance {
+				return dto.RecurringExpenseOutput{}, false
+			}
+			continue
+		}
+		if i == 0 {
+			continue
+		}
+		if prev := charges[i-1].amount; math.Abs(c.amount-prev) >= 0.005 {
+			r.PriceChanges = append(r.PriceChanges, dto.PriceChangeOutput{
+				Date:      c.date.Format("2006-01-02"),
+				OldAmount: prev,
+				NewAmount: c.amount,
+				Percent:   math.Round((c.amount-prev)/prev*1000) / 10,
+			})
+		}
+	}
+	if r.Variable {
+		r.AnnualCost = math.Round(mean*freq.perYear*100) / 100
+	} else {
+		r.AnnualCost = math.Round(latest.amount*freq.perYear*100) / 100
+	}
+
+	next := latest.date.AddDate(0, 0, freq.days)
+	if freq.months > 0 {
+		// Keep the billing day, clamped to the length of the month.
+		first := time.Date(latest.date.Year(), latest.date.Month()+time.Month(freq.months), 1, 0, 0, 0, 0, time.UTC)
+		next = first.AddDate(0, 0, min(latest.date.Day(), first.AddDate(0, 1, -1).Day())-1)
+	}
+	r.NextDate = next.Format("2006-01-02")
+	// Half an interval overdue means the charge has most likely stopped.
+	r.Active = !asOf.After(next.AddDate(0, 0, freq.days/2))
+	return r, true
+}
+
+// recurringKey identifies the merchant of a charge by the words of its
+// description, leaving out reference numbers and dates that change from
+// charge to charge.
+func recurringKey(description string) string {
+	var words []string
+	for _, w := range strings.Fields(normalizeDescription(description)) {
+		if strings.IndexFunc(w, unicode.IsDigit) < 0 {
+			words = append(words, w)
+		}
+	}
+	return strings.Join(words, " ")
+}
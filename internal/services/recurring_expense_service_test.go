@@ -0,0 +1,101 @@
+package services
+
+import (
+	"tally/internal/dto"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecurringExpenseService_Detect(t *testing.T) {
+	db := setupFinanceTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	auth := NewAuthService(db)
+	user := createTestUser(t, auth, "recurring_user")
+	other := createTestUser(t, auth, "recurring_other")
+	finance := NewFinanceService(db, nil)
+	recurring := NewRecurringExpenseService(db)
+	card := finance.CreateAccount(user.ID, dto.CreateAccountInput{Name: "Card", Type: "credit"})
+	_, err := finance.ImportTransactions(user.ID, dto.ImportTransactionsInput{AccountID: card.ID, FileContent: "2024-01-05,NETFLIX.COM 1001,-15.49\n" +
+		"2024-02-05,NETFLIX.COM 1002,-15.49\n" +
+		"2024-03-06,NETFLIX.COM 1003,-15.49\n" +
+		"2024-04-05,NETFLIX.COM 1004,-17.99\n" +
+		"2024-05-05,NETFLIX.COM 1005,-17.99\n" +
+		"2024-06-05,NETFLIX.COM 1006,-17.99\n" +
+		"2024-03-12,APPLE.COM/BILL,-2.99\n" +
+		"2024-03-12,APPLE.COM/BILL,-10.99\n" +
+		"2024-04-12,APPLE.COM/BILL,-2.99\n" +
+		"2024-04-12,APPLE.COM/BILL,-10.99\n" +
+		"2024-05-12,APPLE.COM/BILL,-10.99\n" +
+		"2024-05-12,APPLE.COM/BILL,-2.99\n" +
+		"2024-06-12,APPLE.COM/BILL,-2.99\n" +
+		"2024-06-12,APPLE.COM/BILL,-10.99\n" +
+		"2023-03-01,DOMAIN RENEWAL,-12.00\n" +
+		"2024-03-01,DOMAIN RENEWAL,-12.00\n" +
+		"2024-01-20,CITY ELECTRIC,-80.10\n" +
+		"2024-02-20,CITY ELECTRIC,-95.40\n" +
+		"2024-03-20,CITY ELECTRIC,-70.00\n" +
+		"2024-04-19,CITY ELECTRIC,-88.25\n" +
+		"2024-05-04,GROCER,-45.00\n" +
+		"2024-05-11,GROCER,-120.00\n" +
+		"2024-05-18,GROCER,-30.00\n" +
+		"2024-05-25,GROCER,-80.00\n" +
+		"2024-01-03,COFFEE SHOP,-4.50\n" +
+		"2024-01-04,COFFEE SHOP,-4.50\n" +
+		"2024-02-20,COFFEE SHOP,-4.50\n" +
+		"2024-03-01,COFFEE SHOP,-4.50\n" +
+		"2024-05-01,CLIENT REFUND,40.00\n" +
+		"2024-06-01,CLIENT REFUND,40.00\n" +
+		"2024-07-01,CLIENT REFUND,40.00\n"})
+	require.NoError(t, err)
+
+	found, err := recurring.detect(user.ID, time.Date(2024, 6, 20, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, found, 5, "irregular and variable shopping and income are left out")
+
+	netflix := found[0]
+	assert.Equal(t, "NETFLIX.COM 1006", netflix.Description)
+	assert.Equal(t, "monthly", netflix.Frequency)
+	assert.Equal(t, 17.99, netflix.Amount)
+	assert.Equal(t, 215.88, netflix.AnnualCost)
+	assert.Equal(t, "2024-07-05", netflix.NextDate)
+	assert.True(t, netflix.Active)
+	assert.False(t, netflix.Variable)
+	assert.Equal(t, 6, netflix.Occurrences)
+	assert.Equal(t, "Card", netflix.AccountName)
+	assert.Equal(t, []dto.PriceChangeOutput{{Date: "2024-04-05", OldAmount: 15.49, NewAmount: 17.99, Percent: 16.1}}, netflix.PriceChanges)
+
+	// Two subscriptions billed under one name are kept apart.
+	assert.Equal(t, "APPLE.COM/BILL", found[1].Description)
+	assert.Equal(t, 10.99, found[1].Amount)
+	assert.Equal(t, "APPLE.COM/BILL", found[2].Description)
+	assert.Equal(t, 2.99, found[2].Amount)
+	assert.Empty(t, found[2].PriceChanges)
+	assert.Len(t, found[2].TransactionIDs, 4)
+
+	assert.Equal(t, "annual", found[3].Frequency)
+	assert.Equal(t, "2025-03-01", found[3].NextDate)
+	assert.Equal(t, 12.0, found[3].AnnualCost)
+
+	// A bill that varies is recurring; it stopped, so it comes last.
+	electric := found[4]
+	assert.Equal(t, "CITY ELECTRIC", electric.Description)
+	assert.True(t, electric.Variable)
+	assert.Equal(t, 1001.25, electric.AnnualCost)
+	assert.Equal(t, "2024-05-19", electric.NextDate)
+	assert.False(t, electric.Active)
+	assert.Empty(t, electric.PriceChanges)
+
+	found, err = recurring.detect(other.ID, time.Date(2024, 6, 20, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Empty(t, found)
+}
+
+func TestRecurringKey(t *testing.T) {
+	assert.Equal(t, "netflix com", recurringKey("NETFLIX.COM 12345"))
+	assert.Equal(t, "sq coffee", recurringKey("SQ *COFFEE 04/12"))
+	assert.Equal(t, "", recurringKey("#1234"))
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"tally/internal/dto"
 	"log"
+	"math"
 	"strings"
 )
 
@@ -62,6 +63,12 @@ func (s *ReportService) buildWhere(userID int, filter dto.ReportFilter) (string,
 		clauses = append(clauses, "p.id = ?")
 		args = append(args, filter.ProjectID)
 	}
+	if len(filter.Tags) > 0 {
+		entryTags, entryArgs := tagFilterSQL("time_entry", "te.id", userID, filter.Tags)
+		projectTags, projectArgs := tagFilterSQL("project", "p.id", userID, filter.Tags)
+		clauses = append(clauses, "("+entryTags+" OR "+projectTags+")")
+		args = append(append(args, entryArgs...), projectArgs...)
+	}
 
 	return "WHERE " + strings.Join(clauses, " AND "), args
 }
@@ -75,7 +82,7 @@ SELECT te.date,
        c.id, c.name,
        p.id, p.name,
        SUM(te.duration_seconds) / 3600.0 AS hours,
-       SUM((te.duration_seconds / 3600.0) * COALESCE(p.hourly_rate, 0)) AS income
+       SUM((te.duration_seconds / 3600.0) * ` + effectiveRateSQL + `) AS income
 FROM time_entries te
 JOIN projects p ON te.project_id = p.id
 JOIN clients c ON p.client_id = c.id
@@ -116,7 +123,7 @@ func (s *ReportService) queryChartSeries(userID int, filter dto.ReportFilter) (d
 	query := `
 SELECT te.date,
        SUM(te.duration_seconds) / 3600.0 AS hours,
-       SUM((te.duration_seconds / 3600.0) * COALESCE(p.hourly_rate, 0)) AS income
+       SUM((te.duration_seconds / 3600.0) * ` + effectiveRateSQL + `) AS income
 FROM time_entries te
 JOIN projects p ON te.project_id = p.id
 JOIN clients c ON p.client_id = c.id
@@ -151,3 +158,169 @@ ORDER BY te.date ASC`
 		Revenue: revenue,
 	}, nil
 }
+
+// GetProjectProfitability reports invoiced revenue, direct expenses and hours per project.
+// Revenue is the subtotal of the invoices issued in the filter's date range, so
+// manual lines, billed expenses, late fees and edits count as billed. An
+// invoice covering several projects is shared among them in proportion to the
+// value of the time and expenses it bills for each. Time and expenses are
+// limited to the filter's date range; hours include non-billable work.
+func (s *ReportService) GetProjectProfitability(userID int, filter dto.ReportFilter) (dto.ProjectProfitabilityOutput, error) {
+	var teRange, ftRange, invRange string
+	var rangeArgs []any
+	if filter.StartDate != "" {
+		teRange += " AND te.date >= ?"
+		ftRange += " AND ft.date >= ?"
+		invRange += " AND i.issue_date >= ?"
+		rangeArgs = append(rangeArgs, filter.StartDate)
+	}
+	if filter.EndDate != "" {
+		teRange += " AND te.date <= ?"
+		ftRange += " AND ft.date <= ?"
+		invRange += " AND i.issue_date <= ?"
+		rangeArgs = append(rangeArgs, filter.EndDate)
+	}
+
+	// #nosec G202 -- range and filter clauses are fixed predicates with parameter binding.
+	query := `
+WITH shares AS (
+  SELECT invoice_id, project_id, SUM(value) AS value FROM (
+    SELECT te.invoice_id, te.project_id, (te.duration_seconds / 3600.0) * ` + effectiveRateSQL + ` AS value
+    FROM time_entries te JOIN projects p ON p.id = te.project_id
+    WHERE te.user_id = ? AND te.invoice_id IS NOT NULL
+    UNION ALL
+    SELECT ft.invoice_id, ft.project_id, -ft.amount FROM finance_transactions ft
+    WHERE ft.user_id = ? AND ft.invoice_id IS NOT NULL AND ft.project_id IS NOT NULL
+  ) GROUP BY invoice_id, project_id
+),
+invoice_totals AS (
+  SELECT invoice_id, SUM(value) AS value, COUNT(*) AS projects FROM shares GROUP BY invoice_id
+),
+project_revenue AS (
+  SELECT sh.project_id,
+         SUM(COALESCE(i.subtotal, 0) * CASE WHEN t.value > 0 THEN sh.value / t.value ELSE 1.0 / t.projects END) AS revenue
+  FROM shares sh
+  JOIN invoice_totals t ON t.invoice_id = sh.invoice_id
+  JOIN invoices i ON i.id = sh.invoice_id AND i.user_id = ?` + invRange + `
+  GROUP BY sh.project_id
+)
+SELECT c.id, c.name, p.id, p.name,
+       (SELECT COALESCE(SUM(te.duration_seconds), 0) / 3600.0 FROM time_entries te
+        WHERE te.project_id = p.id AND te.user_id = p.user_id` + teRange + `) AS hours,
+       COALESCE((SELECT pr.revenue FROM project_revenue pr WHERE pr.project_id = p.id), 0) AS revenue,
+       (SELECT COALESCE(SUM(-ft.amount), 0) FROM finance_transactions ft
+        WHERE ft.project_id = p.id AND ft.user_id = p.user_id AND ft.expense_type = 'non_reimbursable'` + ftRange + `) AS direct,
+       (SELECT COALESCE(SUM(-ft.amount), 0) FROM finance_transactions ft
+        WHERE ft.project_id = p.id AND ft.user_id = p.user_id AND ft.expense_type = 'reimbursable'` + ftRange + `) AS reimbursable
+FROM projects p
+JOIN clients c ON p.client_id = c.id
+WHERE p.user_id = ?`
+	args := []any{userID, userID, userID}
+	for i := 0; i < 4; i++ {
+		args = append(args, rangeArgs...)
+	}
+	args = append(args, userID)
+	if filter.ClientID > 0 {
+		query += " AND c.id = ?"
+		args = append(args, filter.ClientID)
+	}
+	if filter.ProjectID > 0 {
+		query += " AND p.id = ?"
+		args = append(args, filter.ProjectID)
+	}
+	query += " ORDER BY c.name ASC, p.name ASC"
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return dto.ProjectProfitabilityOutput{}, fmt.Errorf("failed to query project profitability: %w", err)
+	}
+	defer closeWithLog(rows, "closing profitability rows")
+
+	out := dto.ProjectProfitabilityOutput{Rows: []dto.ProjectProfitabilityRow{}}
+	for rows.Next() {
+		var r dto.ProjectProfitabilityRow
+		if err := rows.Scan(
+			&r.ClientID, &r.ClientName,
+			&r.ProjectID, &r.ProjectName,
+			&r.Hours, &r.Revenue, &r.DirectExpenses, &r.ReimbursableExpenses,
+		); err != nil {
+			log.Println("Error scanning profitability row:", err)
+			continue
+		}
+		if r.Hours == 0 && r.Revenue == 0 && r.DirectExpenses == 0 && r.ReimbursableExpenses == 0 {
+			continue
+		}
+		r.Profit = r.Revenue - r.DirectExpenses
+		if r.Hours > 0 {
+			r.EffectiveHourlyRate = r.Profit / r.Hours
+		}
+		out.Rows = append(out.Rows, r)
+		out.TotalHours += r.Hours
+		out.TotalRevenue += r.Revenue
+		out.TotalDirectExpenses += r.DirectExpenses
+		out.TotalProfit += r.Profit
+	}
+	if out.TotalHours > 0 {
+		out.EffectiveHourlyRate = out.TotalProfit / out.TotalHours
+	}
+	return out, nil
+}
+
+// GetCategoryReport totals finance income and expenses per category within the
+// filter's date range. Split transactions count toward each split's category;
+// transfers between accounts are left out.
+func (s *ReportService) GetCategoryReport(userID int, filter dto.ReportFilter) (dto.CategoryReportOutput, error) {
+	where := "l.user_id = ? AND l.transfer_id IS NULL"
+	args := []any{userID}
+	if filter.StartDate != "" {
+		where += " AND l.date >= ?"
+		args = append(args, filter.StartDate)
+	}
+	if filter.EndDate != "" {
+		where += " AND l.date <= ?"
+		args = append(args, filter.EndDate)
+	}
+	if filter.ClientID > 0 {
+		where += " AND l.client_id = ?"
+		args = append(args, filter.ClientID)
+	}
+	if filter.ProjectID > 0 {
+		where += " AND l.project_id = ?"
+		args = append(args, filter.ProjectID)
+	}
+
+	// #nosec G202 -- the where clause is built from fixed predicates with parameter binding.
+	rows, err := s.db.Query(`
+SELECT COALESCE(c.id, 0), COALESCE(c.name, 'Uncategorized'), COALESCE(c.type, ''), COALESCE(c.color, ''),
+       COALESCE(SUM(CASE WHEN l.amount > 0 THEN l.amount END), 0),
+       COALESCE(SUM(CASE WHEN l.amount < 0 THEN -l.amount END), 0),
+       COUNT(*)
+FROM (`+transactionLinesSQL+`) l
+LEFT JOIN finance_categories c ON c.id = l.category_id AND c.user_id = l.user_id
+WHERE `+where+`
+GROUP BY COALESCE(c.id, 0)
+ORDER BY COALESCE(c.id, 0) = 0, COALESCE(c.name, '') ASC`, args...)
+	if err != nil {
+		return dto.CategoryReportOutput{}, fmt.Errorf("failed to query category report: %w", err)
+	}
+	defer closeWithLog(rows, "closing category report rows")
+
+	out := dto.CategoryReportOutput{Rows: []dto.CategoryReportRow{}}
+	for rows.Next() {
+		var r dto.CategoryReportRow
+		if err := rows.Scan(&r.CategoryID, &r.CategoryName, &r.CategoryType, &r.CategoryColor, &r.Income, &r.Expense, &r.Count); err != nil {
+			log.Println("Error scanning category report row:", err)
+			continue
+		}
+		r.Income = math.Round(r.Income*100) / 100
+		r.Expense = math.Round(r.Expense*100) / 100
+		r.Net = math.Round((r.Income-r.Expense)*100) / 100
+		out.Rows = append(out.Rows, r)
+		out.TotalIncome += r.Income
+		out.TotalExpense += r.Expense
+	}
+	out.TotalIncome = math.Round(out.TotalIncome*100) / 100
+	out.TotalExpense = math.Round(out.TotalExpense*100) / 100
+	out.Net = math.Round((out.TotalIncome-out.TotalExpense)*100) / 100
+	return out, nil
+}
@@ -0,0 +1,352 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"tally/internal/dto"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SearchService provides global search across clients, projects, time
+// entries, invoices and finance transactions.
+type SearchService struct {
+	db *sql.DB
+}
+
+// NewSearchService creates a new SearchService instance.
+func NewSearchService(db *sql.DB) *SearchService {
+	return &SearchService{db: db}
+}
+
+// searchSource describes how one entity type is searched and displayed.
+// The FTS5 table shares rowids with the base table (see db.EnsureSearchIndex).
+type searchSource struct {
+	kind     string
+	table    string // FTS5 table
+	base     string // source table with alias, joined on alias.id = table.rowid
+	alias    string
+	joins    string
+	title    string
+	subtitle string
+	date     string
+	text     string // indexed text, used for plain matching
+	// plainText turns the raw text into searchable prose for plain matching.
+	plainText func(string) string
+}
+
+var searchSources = []searchSource{
+	{
+		kind:     "client",
+		table:    "search_clients",
+		base:     "clients c",
+		alias:    "c",
+		title:    "c.name",
+		subtitle: "COALESCE(c.email, '')",
+		date:     "''",
+		text:     "c.name || ' ' || COALESCE(c.notes, '')",
+	},
+	{
+		kind:     "project",
+		table:    "search_projects",
+		base:     "projects p",
+		alias:    "p",
+		joins:    "LEFT JOIN clients c ON c.id = p.client_id",
+		title:    "p.name",
+		subtitle: "COALESCE(c.name, '')",
+		date:     "COALESCE(p.deadline, '')",
+		text:     "p.name || ' ' || COALESCE(p.description, '')",
+	},
+	{
+		kind:     "time_entry",
+		table:    "search_time_entries",
+		base:     "time_entries te",
+		alias:    "te",
+		joins:    "LEFT JOIN projects p ON p.id = te.project_id LEFT JOIN clients c ON c.id = p.client_id",
+		title:    "COALESCE(p.name, '')",
+		subtitle: "COALESCE(c.name, '')",
+		date:     "COALESCE(te.date, '')",
+		text:     "COALESCE(te.description, '')",
+	},
+	{
+		kind:      "invoice",
+		table:     "search_invoices",
+		base:      "invoices i",
+		alias:     "i",
+		joins:     "LEFT JOIN clients c ON c.id = i.client_id",
+		title:     "'Invoice ' || COALESCE(i.number, '')",
+		subtitle:  "COALESCE(c.name, '')",
+		date:      "COALESCE(i.issue_date, '')",
+		text:      "COALESCE(i.number, '') || char(30) || COALESCE(i.items_json, '')",
+		plainText: invoiceItemsText,
+	},
+	{
+		kind:     "transaction",
+		table:    "search_transactions",
+		base:     "finance_transactions ft",
+		alias:    "ft",
+		joins:    "LEFT JOIN finance_accounts a ON a.id = ft.account_id",
+		title:    "COALESCE(ft.description, '')",
+		subtitle: "COALESCE(a.name, '')",
+		date:     "COALESCE(ft.date, '')",
+		text:     "COALESCE(ft.description, '')",
+	},
+}
+
+// Search returns records matching every word of the query, ranked and grouped
+// by entity type. Words match as prefixes, so "postg migr" finds "Postgres
+// migration". Without FTS5 a plain substring match is used instead.
+func (s *SearchService) Search(userID int, input dto.SearchInput) (dto.SearchOutput, error) {
+	out := dto.SearchOutput{Query: input.Query, Groups: []dto.SearchGroup{}}
+	terms := searchTerms(input.Query)
+	if len(terms) == 0 {
+		return out, nil
+	}
+	limit := input.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	wanted := map[string]bool{}
+	for _, t := range input.Types {
+		wanted[t] = true
+	}
+	out.FullText = s.fullTextReady()
+
+	for _, src := range searchSources {
+		if len(wanted) > 0 && !wanted[src.kind] {
+			continue
+		}
+		var results []dto.SearchResult
+		var err error
+		if out.FullText {
+			results, err = s.searchFullText(userID, src, terms, limit)
+		} else {
+			results, err = s.searchPlain(userID, src, terms, limit)
+		}
+		if err != nil {
+			return dto.SearchOutput{}, err
+		}
+		if len(results) == 0 {
+			continue
+		}
+		out.Total += len(results)
+		out.Groups = append(out.Groups, dto.SearchGroup{Type: src.kind, Results: results})
+	}
+
+	sort.SliceStable(out.Groups, func(i, j int) bool {
+		return out.Groups[i].Results[0].Score > out.Groups[j].Results[0].Score
+	})
+	return out, nil
+}
+
+// fullTextReady reports whether SQLite has FTS5 and all search tables exist.
+func (s *SearchService) fullTextReady() bool {
+	names := make([]interface{}, len(searchSources))
+	for i, src := range searchSources {
+		names[i] = src.table
+	}
+	var fts5 bool
+	var count int
+	query := "SELECT sqlite_compileoption_used('ENABLE_FTS5'), (SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name IN (?" + strings.Repeat(", ?", len(names)-1) + "))"
+	if err := s.db.QueryRow(query, names...).Scan(&fts5, &count); err != nil {
+		log.Println("Error checking search index:", err)
+		return false
+	}
+	return fts5 && count == len(names)
+}
+
+func (s *SearchService) searchFullText(userID int, src searchSource, terms []string, limit int) ([]dto.SearchResult, error) {
+	quoted := make([]string, len(terms))
+	for i, t := range terms {
+		quoted[i] = `"` + t + `"*`
+	}
+	// #nosec G202 -- table, column and join fragments come from searchSources.
+	query := fmt.Sprintf(`
+SELECT %[2]s.id, %[4]s, %[5]s, %[6]s, snippet(%[1]s, -1, '[', ']', '…', 12), -bm25(%[1]s)
+FROM %[1]s
+JOIN %[3]s ON %[2]s.id = %[1]s.rowid
+%[7]s
+WHERE %[1]s MATCH ? AND %[2]s.user_id = ?
+ORDER BY bm25(%[1]s)
+LIMIT ?`, src.table, src.alias, src.base, src.title, src.subtitle, src.date, src.joins)
+
+	rows, err := s.db.Query(query, strings.Join(quoted, " "), userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search %s: %w", src.kind, err)
+	}
+	defer closeWithLog(rows, "closing search rows")
+
+	results := []dto.SearchResult{}
+	for rows.Next() {
+		r := dto.SearchResult{Type: src.kind}
+		if err := rows.Scan(&r.ID, &r.Title, &r.Subtitle, &r.Date, &r.Snippet, &r.Score); err != nil {
+			log.Println("Error scanning search result:", err)
+			continue
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+func (s *SearchService) searchPlain(userID int, src searchSource, terms []string, limit int) ([]dto.SearchResult, error) {
+	where := []string{src.alias + ".user_id = ?"}
+	args := []interface{}{userID}
+	for _, t := range terms {
+		where = append(where, "("+src.text+") LIKE ? ESCAPE '\\'")
+		args = append(args, "%"+likeEscaper.Replace(t)+"%")
+	}
+	// #nosec G202 -- table, column and join fragments come from searchSources.
+	query := fmt.Sprintf(`
+SELECT %[1]s.id, %[3]s, %[4]s, %[5]s, %[6]s
+FROM %[2]s
+%[7]s
+WHERE %[8]s
+ORDER BY %[5]s DESC, %[1]s.id DESC`, src.alias, src.base, src.title, src.subtitle, src.date, src.text, src.joins, strings.Join(where, " AND "))
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search %s: %w", src.kind, err)
+	}
+	defer closeWithLog(rows, "closing search rows")
+
+	results := []dto.SearchResult{}
+	for rows.Next() {
+		r := dto.SearchResult{Type: src.kind}
+		var text string
+		if err := rows.Scan(&r.ID, &r.Title, &r.Subtitle, &r.Date, &text); err != nil {
+			log.Println("Error scanning search result:", err)
+			continue
+		}
+		if src.plainText != nil {
+			text = src.plainText(text)
+		}
+		// LIKE also matched JSON keys and markup; require the words in the prose.
+		lower := strings.ToLower(text)
+		matched := true
+		for _, t := range terms {
+			if !strings.Contains(lower, t) {
+				matched = false
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+		r.Snippet, r.Score = plainSnippet(text, terms)
+		results = append(results, r)
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// searchTerms lowercases the query and splits it into words.
+func searchTerms(query string) []string {
+	return strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// plainSnippet returns an excerpt around the first hit with hits wrapped in
+// [ ], and the number of hits as a crude relevance score.
+func plainSnippet(text string, terms []string) (string, float64) {
+	lower, starts, ends := lowerWithOffsets(text)
+	type span struct{ start, end int }
+	var hits []span
+	for _, t := range terms {
+		for from := 0; ; {
+			i := strings.Index(lower[from:], t)
+			if i < 0 {
+				break
+			}
+			hits = append(hits, span{starts[from+i], ends[from+i+len(t)-1]})
+			from += i + len(t)
+		}
+	}
+	if len(hits) == 0 {
+		return text, 0
+	}
+	sort.Slice(hits, func(i, j int) bool { return hits[i].start < hits[j].start })
+
+	const radius = 40
+	start, end := hits[0].start-radius, hits[0].end+radius*2
+	if start < 0 {
+		start = 0
+	}
+	if end > len(text) {
+		end = len(text)
+	}
+	for start > 0 && !isRuneStart(text, start) {
+		start--
+	}
+	for end < len(text) && !isRuneStart(text, end) {
+		end++
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	pos := start
+	for _, h := range hits {
+		if h.start < pos || h.end > end {
+			continue
+		}
+		b.WriteString(text[pos:h.start])
+		b.WriteString("[" + text[h.start:h.end] + "]")
+		pos = h.end
+	}
+	b.WriteString(text[pos:end])
+	if end < len(text) {
+		b.WriteString("…")
+	}
+	return b.String(), float64(len(hits))
+}
+
+// lowerWithOffsets lowercases text one rune at a time, since lowercasing
+// can change a rune's length in bytes. For each byte of the result it
+// returns where the rune it came from starts and ends in text.
+func lowerWithOffsets(text string) (string, []int, []int) {
+	var b strings.Builder
+	var starts, ends []int
+	for i, r := range text {
+		size := utf8.RuneLen(r)
+		if r == utf8.RuneError {
+			_, size = utf8.DecodeRuneInString(text[i:])
+		}
+		n, _ := b.WriteString(string(unicode.ToLower(r)))
+		for range n {
+			starts = append(starts, i)
+			ends = append(ends, i+size)
+		}
+	}
+	return b.String(), starts, ends
+}
+
+func isRuneStart(s string, i int) bool {
+	return s[i]&0xC0 != 0x80
+}
+
+// invoiceItemsText turns "number<RS>items_json" into the invoice number
+// followed by its line item descriptions.
+func invoiceItemsText(raw string) string {
+	number, itemsJSON, _ := strings.Cut(raw, "\x1e")
+	parts := []string{number}
+	var items []struct {
+		Description string `json:"description"`
+	}
+	if err := json.Unmarshal([]byte(itemsJSON), &items); err == nil {
+		for _, it := range items {
+			parts = append(parts, it.Description)
+		}
+	}
+	return strings.Join(nonEmpty(parts...), " · ")
+}
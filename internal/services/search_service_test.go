@@ -0,0 +1,103 @@
+package services
+
+import (
+	"errors"
+	appdb "tally/internal/db"
+	"tally/internal/dto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchService_Search(t *testing.T) {
+	db := setupFullTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	auth := NewAuthService(db)
+	user := createTestUser(t, auth, "search_user")
+	other := createTestUser(t, auth, "search_other")
+	clientSvc := NewClientService(db)
+	projectSvc := NewProjectService(db)
+	timeSvc := NewTimesheetService(db)
+	invoiceSvc := NewInvoiceService(db)
+
+	// Existing rows are indexed when the index is first built.
+	client := clientSvc.Create(user.ID, dto.CreateClientInput{Name: "Northwind", Notes: "Prefers Postgres over MySQL"})
+	err := appdb.EnsureSearchIndex(db)
+	if err != nil && !errors.Is(err, appdb.ErrSearchUnavailable) {
+		require.NoError(t, err)
+	}
+	fullText := err == nil
+
+	project := projectSvc.Create(user.ID, dto.CreateProjectInput{ClientID: client.ID, Name: "Data platform"})
+	entry := timeSvc.Create(user.ID, dto.CreateTimeEntryInput{ProjectID: project.ID, Date: "2025-02-03", DurationSeconds: 3600, Description: "Postgres migration dry run"})
+	timeSvc.Create(user.ID, dto.CreateTimeEntryInput{ProjectID: project.ID, Date: "2025-02-04", DurationSeconds: 3600, Description: "Weekly sync"})
+	inv := invoiceSvc.Create(user.ID, dto.CreateInvoiceInput{ClientID: client.ID, Number: "INV-7", IssueDate: "2025-02-28", Status: "sent", Items: []dto.InvoiceItemInput{
+		{Description: "Postgres migration", Quantity: 10, UnitPrice: 100, Amount: 1000},
+		{Description: "Support", Quantity: 1, UnitPrice: 50, Amount: 50},
+	}})
+	_, err = db.Exec("INSERT INTO finance_transactions(user_id, account_id, date, description, amount) VALUES(?, 1, '2025-02-10', 'Postgres hosting', -25)", user.ID)
+	require.NoError(t, err)
+	otherClient := clientSvc.Create(other.ID, dto.CreateClientInput{Name: "Elsewhere", Notes: "Postgres migration"})
+	assert.NotZero(t, otherClient.ID)
+
+	searchSvc := NewSearchService(db)
+	out, err := searchSvc.Search(user.ID, dto.SearchInput{Query: "postgres migration"})
+	require.NoError(t, err)
+	assert.Equal(t, fullText, out.FullText)
+	assert.Equal(t, 2, out.Total)
+	byType := map[string][]dto.SearchResult{}
+	for _, g := range out.Groups {
+		byType[g.Type] = g.Results
+	}
+	if assert.Len(t, byType["invoice"], 1) {
+		assert.Equal(t, inv.ID, byType["invoice"][0].ID)
+		assert.Equal(t, "Invoice INV-7", byType["invoice"][0].Title)
+		assert.Equal(t, "Northwind", byType["invoice"][0].Subtitle)
+		assert.Contains(t, byType["invoice"][0].Snippet, "[Postgres] [migration]")
+	}
+	if assert.Len(t, byType["time_entry"], 1) {
+		assert.Equal(t, entry.ID, byType["time_entry"][0].ID)
+		assert.Equal(t, "Data platform", byType["time_entry"][0].Title)
+		assert.Equal(t, "2025-02-03", byType["time_entry"][0].Date)
+	}
+
+	// Prefixes match, and the type filter narrows the groups.
+	out, err = searchSvc.Search(user.ID, dto.SearchInput{Query: "postg", Types: []string{"client", "transaction"}})
+	require.NoError(t, err)
+	assert.Equal(t, 2, out.Total)
+	for _, g := range out.Groups {
+		assert.Contains(t, []string{"client", "transaction"}, g.Type)
+	}
+
+	// The index follows updates and deletes.
+	timeSvc.Update(user.ID, dto.UpdateTimeEntryInput{ID: entry.ID, ProjectID: project.ID, Date: "2025-02-03", DurationSeconds: 3600, Description: "Schema review"})
+	out, err = searchSvc.Search(user.ID, dto.SearchInput{Query: "postgres migration", Types: []string{"time_entry"}})
+	require.NoError(t, err)
+	assert.Empty(t, out.Groups)
+	out, err = searchSvc.Search(user.ID, dto.SearchInput{Query: "schema"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, out.Total)
+
+	timeSvc.Delete(user.ID, entry.ID)
+	out, err = searchSvc.Search(user.ID, dto.SearchInput{Query: "schema"})
+	require.NoError(t, err)
+	assert.Zero(t, out.Total)
+
+	out, err = searchSvc.Search(user.ID, dto.SearchInput{Query: "  !!  "})
+	require.NoError(t, err)
+	assert.Zero(t, out.Total)
+}
+
+func TestPlainSnippet_LengthChangingCase(t *testing.T) {
+	// "Ⱥ" lowercases from two bytes to three, so offsets in the lowercased
+	// text do not match the original.
+	snippet, score := plainSnippet("ȺȺȺ Café Ⱥ café", []string{"café"})
+	assert.Equal(t, 2.0, score)
+	assert.Equal(t, "ȺȺȺ [Café] Ⱥ [café]", snippet)
+
+	snippet, score = plainSnippet("İstanbul ⱥ office", []string{"ⱥ"})
+	assert.Equal(t, 1.0, score)
+	assert.Equal(t, "İstanbul [ⱥ] office", snippet)
+}
@@ -93,7 +93,12 @@ func (s *SettingsService) Update(userID int, input dto.UserSettings) (dto.UserSe
 		return dto.UserSettings{}, err
 	}
 
-	// 3. Update Invoice Settings
+	// 3. Update Invoice Settings, keeping the payment terms and late fees,
+	// which are not part of the general settings.
+	stored, err := invSvc.Get(userID)
+	if err != nil {
+		return dto.UserSettings{}, err
+	}
 	_, err = invSvc.Update(userID, dto.UserInvoiceSettings{
 		SenderName:             normalized.SenderName,
 		SenderCompany:          normalized.SenderCompany,
@@ -103,6 +108,9 @@ func (s *SettingsService) Update(userID int, input dto.UserSettings) (dto.UserSe
 		SenderPostalCode:       normalized.SenderPostalCode,
 		DefaultTerms:           normalized.InvoiceTerms,
 		DefaultMessageTemplate: normalized.DefaultMessageTemplate,
+		PaymentTerms:           stored.PaymentTerms,
+		LateFeeType:            stored.LateFeeType,
+		LateFeeValue:           stored.LateFeeValue,
 	})
 	if err != nil {
 		return dto.UserSettings{}, err
@@ -124,3 +124,42 @@ func TestSettingsService_Get_NonExistentUser(t *testing.T) {
 		t.Errorf("expected default timezone UTC for non-existent user, got %q", settings.Timezone)
 	}
 }
+
+func TestSettingsService_UpdateKeepsPaymentTermsAndLateFees(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() {
+		if err := db.Close(); err != nil {
+			t.Errorf("failed to close db: %v", err)
+		}
+	}()
+
+	authService := NewAuthService(db)
+	user, err := authService.Register(dto.RegisterInput{Username: "u3", Password: "pwd"})
+	if err != nil {
+		t.Fatalf("register failed: %v", err)
+	}
+
+	invSvc := NewUserInvoiceSettingsService(db)
+	if _, err := invSvc.Update(user.ID, dto.UserInvoiceSettings{PaymentTerms: "net_30", LateFeeType: "percent", LateFeeValue: 1.5}); err != nil {
+		t.Fatalf("invoice settings update failed: %v", err)
+	}
+
+	svc := NewSettingsService(db)
+	if _, err := svc.Update(user.ID, dto.UserSettings{SenderName: "Alice", InvoiceTerms: "Thanks"}); err != nil {
+		t.Fatalf("update failed: %v", err)
+	}
+
+	got, err := invSvc.Get(user.ID)
+	if err != nil {
+		t.Fatalf("get failed: %v", err)
+	}
+	if got.SenderName != "Alice" {
+		t.Errorf("expected sender name Alice, got %q", got.SenderName)
+	}
+	if got.PaymentTerms != "net_30" {
+		t.Errorf("expected payment terms net_30 to survive, got %q", got.PaymentTerms)
+	}
+	if got.LateFeeType != "percent" || got.LateFeeValue != 1.5 {
+		t.Errorf("expected 1.5%% late fee to survive, got %q %v", got.LateFeeType, got.LateFeeValue)
+	}
+}
@@ -0,0 +1,13 @@
+package services
+
+import "context"
+
+// Startup hands the Wails context to services that emit events. Wails only
+// calls OnStartup for the application, so main passes it on from there.
+func Startup(ctx context.Context, svcs ...interface{}) {
+	for _, svc := range svcs {
+		if s, ok := svc.(interface{ startup(context.Context) }); ok {
+			s.startup(ctx)
+		}
+	}
+}
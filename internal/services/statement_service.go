@@ -0,0 +1,216 @@
+package services
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"strings"
+	"tally/internal/dto"
+	"tally/internal/pdf"
+	"time"
+)
+
+// StatementService builds client statements of account from invoices, payments and credits.
+type StatementService struct {
+	db *sql.DB
+}
+
+// NewStatementService creates a new StatementService instance.
+func NewStatementService(db *sql.DB) *StatementService {
+	return &StatementService{db: db}
+}
+
+// Get returns the statement of account for a client over the filter's date range.
+// Draft invoices are not owed yet and are left out.
+func (s *StatementService) Get(userID int, filter dto.StatementFilter) (dto.StatementOutput, error) {
+	client, err := NewInvoiceService(s.db).getClient(userID, filter.ClientID)
+	if err != nil {
+		return dto.StatementOutput{}, fmt.Errorf("client not found: %w", err)
+	}
+	if filter.EndDate == "" {
+		filter.EndDate = time.Now().Format("2006-01-02")
+	}
+	for _, d := range []string{filter.StartDate, filter.EndDate} {
+		if d == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", d); err != nil {
+			return dto.StatementOutput{}, fmt.Errorf("invalid date %q: expected YYYY-MM-DD", d)
+		}
+	}
+
+	out := dto.StatementOutput{
+		ClientID:   client.ID,
+		ClientName: client.Name,
+		StartDate:  filter.StartDate,
+		EndDate:    filter.EndDate,
+		Currency:   client.Currency,
+		Lines:      []dto.StatementLine{},
+	}
+
+	if filter.StartDate != "" {
+		err := s.db.QueryRow(`
+SELECT
+  COALESCE((SELECT SUM(total) FROM invoices WHERE user_id = ? AND client_id = ? AND status != 'draft' AND issue_date < ?), 0)
+- COALESCE((SELECT SUM(amount) FROM payments WHERE user_id = ? AND client_id = ? AND date < ?), 0)`,
+			userID, client.ID, filter.StartDate, userID, client.ID, filter.StartDate,
+		).Scan(&out.OpeningBalance)
+		if err != nil {
+			return dto.StatementOutput{}, fmt.Errorf("failed to compute opening balance: %w", err)
+		}
+	}
+
+	start := filter.StartDate
+	if start == "" {
+		start = "0000-01-01"
+	}
+	rows, err := s.db.Query(`
+SELECT issue_date, 'invoice', number, COALESCE(due_date, ''), '', total, 0 AS ord, id
+FROM invoices
+WHERE user_id = ? AND client_id = ? AND status != 'draft' AND issue_date >= ? AND issue_date <= ?
+UNION ALL
+SELECT pm.date, pm.kind, COALESCE(i.number, ''), COALESCE(pm.method, ''), COALESCE(pm.reference, ''), pm.amount, 1 AS ord, pm.id
+FROM payments pm
+LEFT JOIN invoices i ON i.id = pm.invoice_id
+WHERE pm.user_id = ? AND pm.client_id = ? AND pm.date >= ? AND pm.date <= ?
+ORDER BY 1 ASC, 7 ASC, 8 ASC`,
+		userID, client.ID, start, filter.EndDate,
+		userID, client.ID, start, filter.EndDate,
+	)
+	if err != nil {
+		return dto.StatementOutput{}, fmt.Errorf("failed to query statement activity: %w", err)
+	}
+	defer closeWithLog(rows, "closing statement rows")
+
+	balance := out.OpeningBalance
+	for rows.Next() {
+		var date, kind, number, extra1, extra2 string
+		var amount float64
+		var ord, id int
+		if err := rows.Scan(&date, &kind, &number, &extra1, &extra2, &amount, &ord, &id); err != nil {
+			log.Println("Error scanning statement row:", err)
+			continue
+		}
+
+		line := dto.StatementLine{Date: date, Type: kind}
+		switch kind {
+		case "invoice":
+			line.Reference = "Invoice " + number
+			if extra1 != "" {
+				line.Details = "Due " + extra1
+			}
+			line.Charges = amount
+			balance += amount
+			out.TotalInvoiced += amount
+		default:
+			line.Reference = "Payment"
+			if kind == "credit" {
+				line.Reference = "Credit"
+			}
+			if number != "" {
+				line.Reference += " - Invoice " + number
+			}
+			line.Details = strings.TrimSpace(strings.Join(nonEmpty(extra1, extra2), " "))
+			line.Credits = amount
+			balance -= amount
+			if kind == "credit" {
+				out.TotalCredits += amount
+			} else {
+				out.TotalPaid += amount
+			}
+		}
+		line.Balance = balance
+		out.Lines = append(out.Lines, line)
+	}
+	out.ClosingBalance = balance
+	return out, nil
+}
+
+// RenderHTML returns the statement as a self-contained HTML document.
+func (s *StatementService) RenderHTML(userID int, filter dto.StatementFilter) (string, error) {
+	statement, err := s.Get(userID, filter)
+	if err != nil {
+		return "", err
+	}
+	invoiceSvc := NewInvoiceService(s.db)
+	client, err := invoiceSvc.getClient(userID, filter.ClientID)
+	if err != nil {
+		return "", fmt.Errorf("client not found: %w", err)
+	}
+	settings, err := invoiceSvc.getUserSettings(userID)
+	if err != nil {
+		log.Println("Falling back to default settings due to error:", err)
+	}
+	return pdf.NewGenerator(pdf.GetTemplatesDir()).GenerateStatementHTML(statement, client, settings)
+}
+
+// GeneratePDF renders the statement as a PDF and returns it base64 encoded.
+func (s *StatementService) GeneratePDF(userID int, filter dto.StatementFilter) (string, error) {
+	statement, err := s.Get(userID, filter)
+	if err != nil {
+		return "", err
+	}
+	invoiceSvc := NewInvoiceService(s.db)
+	client, err := invoiceSvc.getClient(userID, filter.ClientID)
+	if err != nil {
+		return "", fmt.Errorf("client not found: %w", err)
+	}
+	settings, err := invoiceSvc.getUserSettings(userID)
+	if err != nil {
+		log.Println("Falling back to default settings due to error:", err)
+	}
+	return pdf.NewGenerator(pdf.GetTemplatesDir()).GenerateStatementPDF(statement, client, settings)
+}
+
+// SendEmail emails the statement PDF to the client via the configured provider.
+func (s *StatementService) SendEmail(userID int, filter dto.StatementFilter) error {
+	client, err := NewInvoiceService(s.db).getClient(userID, filter.ClientID)
+	if err != nil {
+		return fmt.Errorf("client not found: %w", err)
+	}
+	emailSettings := NewInvoiceEmailSettingsService(s.db).Get(userID)
+
+	pdfBase64, err := s.GeneratePDF(userID, filter)
+	if err != nil {
+		return fmt.Errorf("failed to generate PDF: %w", err)
+	}
+	pdfBytes, err := base64.StdEncoding.DecodeString(pdfBase64)
+	if err != nil {
+		return fmt.Errorf("failed to decode PDF: %w", err)
+	}
+
+	endDate := filter.EndDate
+	if endDate == "" {
+		endDate = time.Now().Format("2006-01-02")
+	}
+	recipients, err := NewClientContactService(s.db).GetInvoiceRecipients(userID, client.ID)
+	if err != nil {
+		return err
+	}
+	_, err = deliverEmail(emailSettings, outgoingEmail{
+		To:             recipients.To,
+		CC:             recipients.CC,
+		BCC:            recipients.BCC,
+		Subject:        fmt.Sprintf("Statement of account - %s", client.Name),
+		Body:           fmt.Sprintf("Please find attached your statement of account as of %s.", endDate),
+		AttachmentName: fmt.Sprintf("Statement-%s.pdf", endDate),
+		Attachment:     pdfBytes,
+	})
+	if err != nil {
+		log.Println("SendStatement: delivery failed:", err)
+		return err
+	}
+	return nil
+}
+
+// nonEmpty returns the non-blank values in order.
+func nonEmpty(values ...string) []string {
+	var out []string
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"tally/internal/dto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatementService_RunningBalance(t *testing.T) {
+	db := setupFullTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	auth := NewAuthService(db)
+	user := createTestUser(t, auth, "statement_user")
+
+	clientSvc := NewClientService(db)
+	invoiceSvc := NewInvoiceService(db)
+	paymentSvc := NewPaymentService(db)
+	statementSvc := NewStatementService(db)
+
+	client := clientSvc.Create(user.ID, dto.CreateClientInput{Name: "Acme", Currency: "CAD"})
+	old := invoiceSvc.Create(user.ID, dto.CreateInvoiceInput{ClientID: client.ID, Number: "S-1", IssueDate: "2025-01-10", DueDate: "2025-02-09", Total: 500, Status: "sent"})
+	feb := invoiceSvc.Create(user.ID, dto.CreateInvoiceInput{ClientID: client.ID, Number: "S-2", IssueDate: "2025-02-10", DueDate: "2025-03-12", Total: 300, Status: "sent"})
+	invoiceSvc.Create(user.ID, dto.CreateInvoiceInput{ClientID: client.ID, Number: "S-3", IssueDate: "2025-02-11", Total: 999, Status: "draft"})
+
+	_, err := paymentSvc.Create(user.ID, dto.CreatePaymentInput{InvoiceID: old.ID, Date: "2025-01-20", Amount: 200})
+	assert.NoError(t, err)
+	_, err = paymentSvc.Create(user.ID, dto.CreatePaymentInput{InvoiceID: feb.ID, Date: "2025-02-20", Amount: 300, Method: "e-transfer"})
+	assert.NoError(t, err)
+	_, err = paymentSvc.Create(user.ID, dto.CreatePaymentInput{ClientID: client.ID, Kind: "credit", Date: "2025-02-25", Amount: 50, Notes: "goodwill"})
+	assert.NoError(t, err)
+
+	// Validation
+	_, err = paymentSvc.Create(user.ID, dto.CreatePaymentInput{ClientID: client.ID, Date: "2025-02-25", Amount: -1})
+	assert.Error(t, err)
+	_, err = paymentSvc.Create(user.ID, dto.CreatePaymentInput{ClientID: client.ID, Kind: "refund", Date: "2025-02-25", Amount: 1})
+	assert.Error(t, err)
+
+	// Fully paid invoices are marked paid; partially paid ones are not.
+	paid, err := invoiceSvc.Get(user.ID, feb.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "paid", paid.Status)
+	partial, err := invoiceSvc.Get(user.ID, old.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "sent", partial.Status)
+
+	st, err := statementSvc.Get(user.ID, dto.StatementFilter{ClientID: client.ID, StartDate: "2025-02-01", EndDate: "2025-02-28"})
+	assert.NoError(t, err)
+	assert.InDelta(t, 300.0, st.OpeningBalance, 0.001)
+	assert.InDelta(t, 300.0, st.TotalInvoiced, 0.001)
+	assert.InDelta(t, 300.0, st.TotalPaid, 0.001)
+	assert.InDelta(t, 50.0, st.TotalCredits, 0.001)
+	assert.InDelta(t, 250.0, st.ClosingBalance, 0.001)
+	if assert.Len(t, st.Lines, 3) {
+		assert.Equal(t, "invoice", st.Lines[0].Type)
+		assert.InDelta(t, 600.0, st.Lines[0].Balance, 0.001)
+		assert.Equal(t, "Payment - Invoice S-2", st.Lines[1].Reference)
+		assert.InDelta(t, 300.0, st.Lines[1].Balance, 0.001)
+		assert.Equal(t, "credit", st.Lines[2].Type)
+		assert.InDelta(t, 250.0, st.Lines[2].Balance, 0.001)
+	}
+
+	html, err := statementSvc.RenderHTML(user.ID, dto.StatementFilter{ClientID: client.ID, StartDate: "2025-02-01", EndDate: "2025-02-28"})
+	assert.NoError(t, err)
+	assert.Contains(t, html, "STATEMENT")
+	assert.Contains(t, html, "Invoice S-2")
+	assert.Contains(t, html, "250.00")
+	assert.NotContains(t, html, "S-3")
+
+	// Removing the payment reopens the invoice.
+	payments := paymentSvc.List(user.ID, dto.PaymentFilter{InvoiceID: feb.ID})
+	if assert.Len(t, payments, 1) {
+		assert.Equal(t, "S-2", payments[0].InvoiceNumber)
+		paymentSvc.Delete(user.ID, payments[0].ID)
+	}
+	reopened, err := invoiceSvc.Get(user.ID, feb.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "sent", reopened.Status)
+}
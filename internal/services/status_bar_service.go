@@ -55,7 +55,7 @@ func (s *StatusBarService) Get(userID int) (dto.StatusBarOutput, error) {
 
 	var uninvoicedTotal float64
 	if err := s.db.QueryRow(
-		`SELECT COALESCE(SUM((te.duration_seconds / 3600.0) * COALESCE(p.hourly_rate, 0)), 0)
+		`SELECT COALESCE(SUM((te.duration_seconds / 3600.0) * `+effectiveRateSQL+`), 0)
 		 FROM time_entries te
 		 JOIN projects p ON p.id = te.project_id AND p.user_id = te.user_id
 		 WHERE te.user_id = ?
@@ -0,0 +1,249 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"tally/internal/dto"
+	"tally/internal/mapper"
+	"tally/internal/models"
+)
+
+// TagService manages tags and their assignment to projects, time entries,
+// invoices and finance transactions.
+type TagService struct {
+	db *sql.DB
+}
+
+// NewTagService creates a new TagService instance.
+func NewTagService(db *sql.DB) *TagService {
+	return &TagService{db: db}
+}
+
+// taggableTables maps an entity type to the table holding its records.
+var taggableTables = map[string]string{
+	"project":     "projects",
+	"time_entry":  "time_entries",
+	"invoice":     "invoices",
+	"transaction": "finance_transactions",
+}
+
+// sqlExecutor is satisfied by both *sql.DB and *sql.Tx.
+type sqlExecutor interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// List returns the user's tags with how many records carry each.
+func (s *TagService) List(userID int) []dto.TagOutput {
+	rows, err := s.db.Query(`
+SELECT t.id, t.name, COALESCE(t.color, ''), (SELECT COUNT(*) FROM entity_tags et WHERE et.tag_id = t.id)
+FROM tags t
+WHERE t.user_id = ?
+ORDER BY t.name ASC`, userID)
+	if err != nil {
+		log.Println("Error querying tags:", err)
+		return []dto.TagOutput{}
+	}
+	defer closeWithLog(rows, "closing tag rows")
+
+	var tags []models.Tag
+	for rows.Next() {
+		var t models.Tag
+		if err := rows.Scan(&t.ID, &t.Name, &t.Color, &t.UsageCount); err != nil {
+			log.Println("Error scanning tag:", err)
+			continue
+		}
+		tags = append(tags, t)
+	}
+	return mapper.ToTagOutputList(tags)
+}
+
+// Create adds a tag. Names are unique per user, ignoring case.
+func (s *TagService) Create(userID int, input dto.CreateTagInput) (dto.TagOutput, error) {
+	name, err := normalizeTagName(input.Name)
+	if err != nil {
+		return dto.TagOutput{}, err
+	}
+	res, err := s.db.Exec("INSERT INTO tags(user_id, name, color) VALUES(?, ?, ?)", userID, name, input.Color)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			return dto.TagOutput{}, fmt.Errorf("tag %q already exists", name)
+		}
+		return dto.TagOutput{}, fmt.Errorf("failed to insert tag: %w", err)
+	}
+	id, _ := res.LastInsertId()
+	return mapper.ToTagOutput(models.Tag{ID: int(id), Name: name, Color: input.Color}), nil
+}
+
+// Update renames or recolors a tag; every tagged record follows.
+func (s *TagService) Update(userID int, input dto.UpdateTagInput) (dto.TagOutput, error) {
+	name, err := normalizeTagName(input.Name)
+	if err != nil {
+		return dto.TagOutput{}, err
+	}
+	res, err := s.db.Exec("UPDATE tags SET name = ?, color = ? WHERE id = ? AND user_id = ?", name, input.Color, input.ID, userID)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			return dto.TagOutput{}, fmt.Errorf("tag %q already exists", name)
+		}
+		return dto.TagOutput{}, fmt.Errorf("failed to update tag: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return dto.TagOutput{}, fmt.Errorf("tag not found or not owned by user")
+	}
+	out := models.Tag{ID: input.ID, Name: name, Color: input.Color}
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM entity_tags WHERE tag_id = ?", input.ID).Scan(&out.UsageCount); err != nil {
+		return dto.TagOutput{}, err
+	}
+	return mapper.ToTagOutput(out), nil
+}
+
+// Delete removes a tag from every record and deletes it.
+func (s *TagService) Delete(userID int, id int) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.Exec("DELETE FROM entity_tags WHERE tag_id IN (SELECT id FROM tags WHERE id = ? AND user_id = ?)", id, userID); err != nil {
+		return fmt.Errorf("failed to untag records: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM tags WHERE id = ? AND user_id = ?", id, userID); err != nil {
+		return fmt.Errorf("failed to delete tag: %w", err)
+	}
+	return tx.Commit()
+}
+
+// GetEntityTags returns the tag names on one record, in the order they were added.
+func (s *TagService) GetEntityTags(userID int, entityType string, entityID int) ([]string, error) {
+	if err := s.checkEntity(userID, entityType, entityID); err != nil {
+		return nil, err
+	}
+	var joined sql.NullString
+	// #nosec G202 -- the subquery is built from a fixed entity type.
+	if err := s.db.QueryRow("SELECT "+entityTagsSQL(entityType, "?"), entityID).Scan(&joined); err != nil {
+		return nil, fmt.Errorf("failed to load tags: %w", err)
+	}
+	return splitTags(joined.String), nil
+}
+
+// SetEntityTags replaces the tags on one record, creating unknown tags.
+func (s *TagService) SetEntityTags(userID int, input dto.SetEntityTagsInput) ([]string, error) {
+	if err := s.checkEntity(userID, input.EntityType, input.EntityID); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	names, err := setEntityTags(tx, userID, input.EntityType, input.EntityID, input.Tags)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+// checkEntity verifies that a taggable record exists and belongs to the user.
+func (s *TagService) checkEntity(userID int, entityType string, entityID int) error {
+	table, ok := taggableTables[entityType]
+	if !ok {
+		return fmt.Errorf("unknown entity type: %s", entityType)
+	}
+	var count int
+	// #nosec G202 -- table name comes from taggableTables.
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM "+table+" WHERE id = ? AND user_id = ?", entityID, userID).Scan(&count); err != nil {
+		return fmt.Errorf("failed to check %s: %w", entityType, err)
+	}
+	if count == 0 {
+		return fmt.Errorf("%s not found", strings.ReplaceAll(entityType, "_", " "))
+	}
+	return nil
+}
+
+// setEntityTags replaces the tags on a record and returns the stored names.
+// The caller has checked that the record belongs to the user.
+func setEntityTags(exec sqlExecutor, userID int, entityType string, entityID int, names []string) ([]string, error) {
+	if _, err := exec.Exec("DELETE FROM entity_tags WHERE entity_type = ? AND entity_id = ?", entityType, entityID); err != nil {
+		return nil, fmt.Errorf("failed to clear tags: %w", err)
+	}
+	stored := []string{}
+	seen := map[string]bool{}
+	for _, raw := range names {
+		name, err := normalizeTagName(raw)
+		if err != nil {
+			return nil, err
+		}
+		if seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+
+		if _, err := exec.Exec("INSERT OR IGNORE INTO tags(user_id, name) VALUES(?, ?)", userID, name); err != nil {
+			return nil, fmt.Errorf("failed to create tag: %w", err)
+		}
+		var tagID int
+		// An existing tag keeps its spelling.
+		if err := exec.QueryRow("SELECT id, name FROM tags WHERE user_id = ? AND name = ?", userID, name).Scan(&tagID, &name); err != nil {
+			return nil, fmt.Errorf("failed to load tag: %w", err)
+		}
+		if _, err := exec.Exec("INSERT OR IGNORE INTO entity_tags(tag_id, entity_type, entity_id) VALUES(?, ?, ?)", tagID, entityType, entityID); err != nil {
+			return nil, fmt.Errorf("failed to tag %s: %w", entityType, err)
+		}
+		stored = append(stored, name)
+	}
+	return stored, nil
+}
+
+// normalizeTagName trims a tag name and collapses inner whitespace.
+func normalizeTagName(name string) (string, error) {
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" {
+		return "", fmt.Errorf("tag name is required")
+	}
+	if strings.Contains(name, ",") {
+		return "", fmt.Errorf("tag names cannot contain commas")
+	}
+	return name, nil
+}
+
+// entityTagsSQL returns a scalar subquery yielding the comma-joined tag names
+// of the record whose id is idExpr, in the order they were added. Its aliases
+// (et, tg) must not clash with the alias idExpr refers to.
+func entityTagsSQL(entityType string, idExpr string) string {
+	return `(SELECT group_concat(name, ',') FROM (
+  SELECT tg.name FROM entity_tags et JOIN tags tg ON tg.id = et.tag_id
+  WHERE et.entity_type = '` + entityType + `' AND et.entity_id = ` + idExpr + `
+  ORDER BY et.rowid))`
+}
+
+// tagFilterSQL returns a predicate matching records of entityType (id in
+// idExpr) that carry any of the given tags, with its arguments.
+func tagFilterSQL(entityType string, idExpr string, userID int, tags []string) (string, []interface{}) {
+	args := []interface{}{entityType, userID}
+	placeholders := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		placeholders = append(placeholders, "?")
+		args = append(args, strings.TrimSpace(tag))
+	}
+	return idExpr + ` IN (
+  SELECT et.entity_id FROM entity_tags et JOIN tags t ON t.id = et.tag_id
+  WHERE et.entity_type = ? AND t.user_id = ? AND t.name IN (` + strings.Join(placeholders, ", ") + `))`, args
+}
+
+// splitTags turns a comma-joined tag list into a slice, never nil.
+func splitTags(joined string) []string {
+	if joined == "" {
+		return []string{}
+	}
+	return strings.Split(joined, ",")
+}
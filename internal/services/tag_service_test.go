@@ -0,0 +1,130 @@
+package services
+
+import (
+	"tally/internal/dto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTagService_CRUDAndEntityTags(t *testing.T) {
+	db := setupFullTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	auth := NewAuthService(db)
+	user := createTestUser(t, auth, "tag_user")
+	other := createTestUser(t, auth, "tag_other")
+	tagSvc := NewTagService(db)
+	clientSvc := NewClientService(db)
+	projectSvc := NewProjectService(db)
+
+	urgent, err := tagSvc.Create(user.ID, dto.CreateTagInput{Name: "  Urgent ", Color: "#f00"})
+	require.NoError(t, err)
+	assert.Equal(t, "Urgent", urgent.Name)
+	_, err = tagSvc.Create(user.ID, dto.CreateTagInput{Name: "urgent"})
+	assert.Error(t, err)
+	_, err = tagSvc.Create(user.ID, dto.CreateTagInput{Name: "a,b"})
+	assert.Error(t, err)
+	_, err = tagSvc.Create(other.ID, dto.CreateTagInput{Name: "Urgent"})
+	assert.NoError(t, err, "tag names are unique per user only")
+
+	// Project tags are stored in entity_tags; existing tags keep their spelling.
+	client := clientSvc.Create(user.ID, dto.CreateClientInput{Name: "Acme"})
+	project := projectSvc.Create(user.ID, dto.CreateProjectInput{ClientID: client.ID, Name: "Site", Tags: []string{"urgent", "Web", "web"}})
+	assert.Equal(t, []string{"Urgent", "Web"}, project.Tags)
+	got, err := projectSvc.Get(user.ID, project.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Urgent", "Web"}, got.Tags)
+
+	updated := projectSvc.Update(user.ID, dto.UpdateProjectInput{ID: project.ID, ClientID: client.ID, Name: "Site", Tags: []string{"Web"}})
+	assert.Equal(t, []string{"Web"}, updated.Tags)
+
+	// Other records are tagged through SetEntityTags, which checks ownership.
+	inv := NewInvoiceService(db).Create(user.ID, dto.CreateInvoiceInput{ClientID: client.ID, Number: "T-1", IssueDate: "2025-01-10", Status: "draft"})
+	names, err := tagSvc.SetEntityTags(user.ID, dto.SetEntityTagsInput{EntityType: "invoice", EntityID: inv.ID, Tags: []string{"Urgent", "Q1"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Urgent", "Q1"}, names)
+	_, err = tagSvc.SetEntityTags(other.ID, dto.SetEntityTagsInput{EntityType: "invoice", EntityID: inv.ID, Tags: []string{"x"}})
+	assert.Error(t, err)
+	_, err = tagSvc.SetEntityTags(user.ID, dto.SetEntityTagsInput{EntityType: "client", EntityID: client.ID, Tags: []string{"x"}})
+	assert.Error(t, err)
+	names, err = tagSvc.GetEntityTags(user.ID, "invoice", inv.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Urgent", "Q1"}, names)
+
+	list := tagSvc.List(user.ID)
+	require.Len(t, list, 3)
+	assert.Equal(t, "Q1", list[0].Name)
+	assert.Equal(t, 1, list[0].UsageCount)
+	assert.Equal(t, "Urgent", list[1].Name)
+	assert.Equal(t, 1, list[1].UsageCount)
+	assert.Equal(t, "Web", list[2].Name)
+
+	// Renaming a tag renames it everywhere.
+	_, err = tagSvc.Update(user.ID, dto.UpdateTagInput{ID: list[2].ID, Name: "Urgent"})
+	assert.Error(t, err)
+	_, err = tagSvc.Update(user.ID, dto.UpdateTagInput{ID: list[2].ID, Name: "Website"})
+	require.NoError(t, err)
+	got, _ = projectSvc.Get(user.ID, project.ID)
+	assert.Equal(t, []string{"Website"}, got.Tags)
+
+	// Deleting a tag or a tagged record drops the links.
+	require.NoError(t, tagSvc.Delete(user.ID, urgent.ID))
+	names, _ = tagSvc.GetEntityTags(user.ID, "invoice", inv.ID)
+	assert.Equal(t, []string{"Q1"}, names)
+	require.NoError(t, projectSvc.Delete(user.ID, project.ID))
+	var links int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM entity_tags WHERE entity_type = 'project'").Scan(&links))
+	assert.Equal(t, 0, links)
+}
+
+func TestTagFilters(t *testing.T) {
+	db := setupFullTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	auth := NewAuthService(db)
+	user := createTestUser(t, auth, "tag_filter_user")
+	tagSvc := NewTagService(db)
+	clientSvc := NewClientService(db)
+	projectSvc := NewProjectService(db)
+	timeSvc := NewTimesheetService(db)
+
+	client := clientSvc.Create(user.ID, dto.CreateClientInput{Name: "Acme"})
+	tagged := projectSvc.Create(user.ID, dto.CreateProjectInput{ClientID: client.ID, Name: "Tagged", HourlyRate: 100, Tags: []string{"Retainer"}})
+	plain := projectSvc.Create(user.ID, dto.CreateProjectInput{ClientID: client.ID, Name: "Plain", HourlyRate: 100})
+
+	fromProject := timeSvc.Create(user.ID, dto.CreateTimeEntryInput{ProjectID: tagged.ID, Date: "2025-01-05", DurationSeconds: 3600, Billable: true})
+	ownTag := timeSvc.Create(user.ID, dto.CreateTimeEntryInput{ProjectID: plain.ID, Date: "2025-01-06", DurationSeconds: 7200, Billable: true})
+	timeSvc.Create(user.ID, dto.CreateTimeEntryInput{ProjectID: plain.ID, Date: "2025-01-07", DurationSeconds: 1800, Billable: true})
+	_, err := tagSvc.SetEntityTags(user.ID, dto.SetEntityTagsInput{EntityType: "time_entry", EntityID: ownTag.ID, Tags: []string{"retainer", "Support"}})
+	require.NoError(t, err)
+
+	// An entry matches through its own tags or its project's.
+	entries := timeSvc.ListFiltered(user.ID, dto.TimeEntryFilter{Tags: []string{"RETAINER"}})
+	require.Len(t, entries, 2)
+	assert.Equal(t, fromProject.ID, entries[0].ID)
+	assert.Equal(t, []string{}, entries[0].Tags)
+	assert.Equal(t, ownTag.ID, entries[1].ID)
+	assert.Equal(t, []string{"Retainer", "Support"}, entries[1].Tags)
+	assert.Len(t, timeSvc.ListFiltered(user.ID, dto.TimeEntryFilter{ProjectID: plain.ID, Tags: []string{"Retainer"}}), 1)
+	assert.Len(t, timeSvc.List(user.ID, 0), 3)
+
+	report, err := NewReportService(db).Get(user.ID, dto.ReportFilter{Tags: []string{"Retainer"}})
+	require.NoError(t, err)
+	assert.InDelta(t, 3.0, report.TotalHours, 0.001)
+	assert.InDelta(t, 300.0, report.TotalIncome, 0.001)
+
+	financeSvc := NewFinanceService(db, nil)
+	_, err = db.Exec("INSERT INTO finance_transactions(user_id, account_id, date, description, amount) VALUES(?, 1, '2025-01-03', 'Hosting', -20), (?, 1, '2025-01-04', 'Coffee', -5)", user.ID, user.ID)
+	require.NoError(t, err)
+	all := financeSvc.GetTransactions(user.ID, dto.TransactionFilter{})
+	require.Len(t, all, 2)
+	hosting := all[1]
+	_, err = tagSvc.SetEntityTags(user.ID, dto.SetEntityTagsInput{EntityType: "transaction", EntityID: hosting.ID, Tags: []string{"Retainer"}})
+	require.NoError(t, err)
+	filtered := financeSvc.GetTransactions(user.ID, dto.TransactionFilter{Tags: []string{"retainer", "unused"}})
+	require.Len(t, filtered, 1)
+	assert.Equal(t, "Hosting", filtered[0].Description)
+	assert.Equal(t, []string{"Retainer"}, filtered[0].Tags)
+}
@@ -46,6 +46,9 @@ func setupFullTestDB(t *testing.T) *sql.DB {
 			billing_city TEXT,
 			billing_province TEXT,
 			billing_postal_code TEXT,
+			payment_terms TEXT,
+			requires_po BOOLEAN DEFAULT 0,
+			archived_at DATETIME,
 			FOREIGN KEY(user_id) REFERENCES users(id)
 		);`,
 		`CREATE TABLE projects (
@@ -60,6 +63,7 @@ func setupFullTestDB(t *testing.T) *sql.DB {
 			deadline TEXT,
 			tags TEXT,
 			service_type TEXT,
+			archived_at DATETIME,
 			FOREIGN KEY(user_id) REFERENCES users(id),
 			FOREIGN KEY(client_id) REFERENCES clients(id)
 		);`,
@@ -92,9 +96,259 @@ func setupFullTestDB(t *testing.T) *sql.DB {
 			total REAL,
 			status TEXT,
 			items_json TEXT,
+			purchase_order_id INTEGER,
 			FOREIGN KEY(user_id) REFERENCES users(id),
 			FOREIGN KEY(client_id) REFERENCES clients(id)
 		);`,
+		`CREATE TABLE purchase_orders (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER,
+			client_id INTEGER NOT NULL,
+			number TEXT NOT NULL,
+			authorized_amount REAL NOT NULL DEFAULT 0,
+			currency TEXT,
+			valid_from TEXT,
+			valid_to TEXT,
+			notes TEXT,
+			created_at TEXT DEFAULT (datetime('now')),
+			UNIQUE(user_id, client_id, number)
+		);`,
+		`CREATE TABLE rate_cards (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			client_id INTEGER,
+			project_id INTEGER,
+			rate REAL NOT NULL,
+			currency TEXT,
+			effective_from TEXT NOT NULL,
+			notes TEXT,
+			created_at TEXT DEFAULT (datetime('now')),
+			updated_at TEXT DEFAULT (datetime('now')),
+			FOREIGN KEY(user_id) REFERENCES users(id),
+			FOREIGN KEY(client_id) REFERENCES clients(id),
+			FOREIGN KEY(project_id) REFERENCES projects(id)
+		);`,
+		`CREATE TABLE finance_accounts (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			name TEXT NOT NULL,
+			type TEXT NOT NULL,
+			currency TEXT DEFAULT 'CAD',
+			balance REAL DEFAULT 0,
+			opening_balance REAL DEFAULT 0,
+			opening_date TEXT,
+			bank_name TEXT,
+			created_at TEXT DEFAULT (datetime('now')),
+			updated_at TEXT DEFAULT (datetime('now')),
+			FOREIGN KEY(user_id) REFERENCES users(id)
+		);`,
+		`CREATE TABLE finance_categories (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			name TEXT NOT NULL,
+			type TEXT NOT NULL,
+			color TEXT,
+			icon TEXT,
+			created_at TEXT DEFAULT (datetime('now')),
+			updated_at TEXT DEFAULT (datetime('now')),
+			FOREIGN KEY(user_id) REFERENCES users(id)
+		);`,
+		`CREATE TABLE finance_transactions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			account_id INTEGER NOT NULL,
+			category_id INTEGER,
+			date TEXT NOT NULL,
+			description TEXT NOT NULL,
+			amount REAL NOT NULL,
+			status TEXT DEFAULT 'pending',
+			reference_id TEXT,
+			client_id INTEGER,
+			project_id INTEGER,
+			expense_type TEXT,
+			invoice_id INTEGER,
+			import_batch_id INTEGER,
+			fingerprint TEXT,
+			possible_duplicate_of_id INTEGER,
+			category_rule_id INTEGER,
+			reconciliation_id INTEGER,
+			transfer_id INTEGER,
+			not_transfer BOOLEAN DEFAULT 0,
+			created_at TEXT DEFAULT (datetime('now')),
+			updated_at TEXT DEFAULT (datetime('now')),
+			FOREIGN KEY(user_id) REFERENCES users(id),
+			FOREIGN KEY(account_id) REFERENCES finance_accounts(id),
+			FOREIGN KEY(category_id) REFERENCES finance_categories(id),
+			FOREIGN KEY(client_id) REFERENCES clients(id),
+			FOREIGN KEY(project_id) REFERENCES projects(id),
+			FOREIGN KEY(invoice_id) REFERENCES invoices(id)
+		);`,
+		`CREATE TRIGGER finance_transactions_transfer_ad AFTER DELETE ON finance_transactions BEGIN
+			UPDATE finance_transactions SET transfer_id = NULL WHERE transfer_id = old.id;
+		END;`,
+		`CREATE TABLE transaction_splits (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER,
+			transaction_id INTEGER NOT NULL,
+			category_id INTEGER,
+			amount REAL NOT NULL,
+			memo TEXT,
+			sort_order INTEGER DEFAULT 0
+		);`,
+		`CREATE TRIGGER transaction_splits_transaction_ad AFTER DELETE ON finance_transactions BEGIN
+			DELETE FROM transaction_splits WHERE transaction_id = old.id;
+		END;`,
+		`CREATE TABLE reconciliations (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER,
+			account_id INTEGER NOT NULL,
+			statement_date TEXT NOT NULL,
+			statement_balance REAL NOT NULL,
+			status TEXT NOT NULL DEFAULT 'in_progress',
+			created_at TEXT DEFAULT (datetime('now')),
+			completed_at TEXT
+		);`,
+		`CREATE TABLE budgets (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER,
+			category_id INTEGER NOT NULL,
+			period TEXT NOT NULL DEFAULT 'monthly',
+			amount REAL NOT NULL,
+			rollover TEXT NOT NULL DEFAULT 'none',
+			start_date TEXT NOT NULL,
+			alert_threshold REAL NOT NULL DEFAULT 80,
+			created_at TEXT DEFAULT (datetime('now')),
+			updated_at TEXT DEFAULT (datetime('now')),
+			UNIQUE(user_id, category_id)
+		);`,
+		`CREATE TABLE budget_alerts (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			budget_id INTEGER NOT NULL,
+			period_start TEXT NOT NULL,
+			level TEXT NOT NULL,
+			created_at TEXT DEFAULT (datetime('now')),
+			UNIQUE(budget_id, period_start, level)
+		);`,
+		`CREATE TRIGGER budgets_category_ad AFTER DELETE ON finance_categories BEGIN
+			DELETE FROM budgets WHERE category_id = old.id;
+		END;`,
+		`CREATE TRIGGER budget_alerts_budget_ad AFTER DELETE ON budgets BEGIN
+			DELETE FROM budget_alerts WHERE budget_id = old.id;
+		END;`,
+		`CREATE TABLE attachments (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			entity_type TEXT NOT NULL,
+			entity_id INTEGER NOT NULL,
+			sha256 TEXT NOT NULL,
+			file_name TEXT NOT NULL,
+			mime_type TEXT NOT NULL,
+			size INTEGER NOT NULL,
+			created_at TEXT DEFAULT (datetime('now')),
+			UNIQUE(entity_type, entity_id, sha256)
+		);`,
+		`CREATE TRIGGER attachments_transaction_ad AFTER DELETE ON finance_transactions BEGIN
+			DELETE FROM attachments WHERE entity_type = 'transaction' AND entity_id = old.id;
+		END;`,
+		`CREATE TRIGGER attachments_invoice_ad AFTER DELETE ON invoices BEGIN
+			DELETE FROM attachments WHERE entity_type = 'invoice' AND entity_id = old.id;
+		END;`,
+		`CREATE TRIGGER attachments_client_ad AFTER DELETE ON clients BEGIN
+			DELETE FROM attachments WHERE entity_type = 'client' AND entity_id = old.id;
+		END;`,
+		`CREATE TABLE categorization_rules (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER,
+			name TEXT NOT NULL,
+			priority INTEGER NOT NULL DEFAULT 0,
+			match_type TEXT NOT NULL DEFAULT 'substring',
+			pattern TEXT,
+			min_amount REAL,
+			max_amount REAL,
+			account_id INTEGER,
+			category_id INTEGER NOT NULL,
+			tags TEXT,
+			disabled BOOLEAN DEFAULT 0,
+			created_at TEXT DEFAULT (datetime('now'))
+		);`,
+		`CREATE TRIGGER categorization_rules_category_ad AFTER DELETE ON finance_categories BEGIN
+			DELETE FROM categorization_rules WHERE category_id = old.id;
+		END;`,
+		`CREATE TABLE import_batches (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER,
+			account_id INTEGER NOT NULL,
+			file_name TEXT,
+			format TEXT NOT NULL,
+			profile_id INTEGER,
+			bank_type TEXT,
+			row_count INTEGER NOT NULL DEFAULT 0,
+			imported_count INTEGER NOT NULL DEFAULT 0,
+			created_at TEXT DEFAULT (datetime('now')),
+			rolled_back_at TEXT
+		);`,
+		`CREATE TABLE import_profiles (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER,
+			name TEXT NOT NULL,
+			delimiter TEXT NOT NULL DEFAULT ',',
+			skip_rows INTEGER NOT NULL DEFAULT 0,
+			date_column TEXT NOT NULL,
+			description_columns_json TEXT NOT NULL DEFAULT '[]',
+			amount_column TEXT,
+			debit_column TEXT,
+			credit_column TEXT,
+			reference_column TEXT,
+			date_format TEXT,
+			sign_convention TEXT NOT NULL DEFAULT 'signed',
+			decimal_separator TEXT NOT NULL DEFAULT '.',
+			created_at TEXT DEFAULT (datetime('now')),
+			UNIQUE(user_id, name)
+		);`,
+		`CREATE TABLE payments (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			client_id INTEGER NOT NULL,
+			invoice_id INTEGER,
+			kind TEXT NOT NULL DEFAULT 'payment',
+			date TEXT NOT NULL,
+			amount REAL NOT NULL,
+			method TEXT,
+			reference TEXT,
+			notes TEXT,
+			transaction_id INTEGER,
+			created_at TEXT DEFAULT (datetime('now')),
+			updated_at TEXT DEFAULT (datetime('now')),
+			FOREIGN KEY(user_id) REFERENCES users(id),
+			FOREIGN KEY(client_id) REFERENCES clients(id),
+			FOREIGN KEY(invoice_id) REFERENCES invoices(id)
+		);`,
+		`CREATE TABLE client_contacts (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			client_id INTEGER NOT NULL,
+			name TEXT NOT NULL DEFAULT '',
+			email TEXT NOT NULL,
+			phone TEXT,
+			title TEXT,
+			role TEXT NOT NULL DEFAULT 'billing',
+			created_at TEXT DEFAULT (datetime('now')),
+			updated_at TEXT DEFAULT (datetime('now')),
+			FOREIGN KEY(user_id) REFERENCES users(id),
+			FOREIGN KEY(client_id) REFERENCES clients(id)
+		);`,
+		`CREATE TABLE invoice_late_fees (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			invoice_id INTEGER NOT NULL,
+			period INTEGER NOT NULL,
+			amount REAL NOT NULL,
+			description TEXT NOT NULL,
+			created_at TEXT DEFAULT (datetime('now')),
+			FOREIGN KEY(user_id) REFERENCES users(id),
+			FOREIGN KEY(invoice_id) REFERENCES invoices(id),
+			UNIQUE(invoice_id, period)
+		);`,
 		`CREATE TABLE user_preferences (
 			user_id INTEGER PRIMARY KEY,
 			currency TEXT DEFAULT 'USD',
@@ -126,9 +380,58 @@ func setupFullTestDB(t *testing.T) *sql.DB {
 			sender_postal_code TEXT,
 			default_terms TEXT DEFAULT 'Due upon receipt',
 			default_message_template TEXT DEFAULT 'Thank you for your business.',
+			payment_terms TEXT,
+			late_fee_type TEXT DEFAULT '',
+			late_fee_value REAL DEFAULT 0,
 			updated_at TEXT DEFAULT (datetime('now')),
 			FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
 		);`,
+		`CREATE TABLE tags (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER,
+			name TEXT NOT NULL COLLATE NOCASE,
+			color TEXT,
+			created_at TEXT DEFAULT (datetime('now')),
+			UNIQUE(user_id, name)
+		);`,
+		`CREATE TABLE entity_tags (
+			tag_id INTEGER NOT NULL,
+			entity_type TEXT NOT NULL,
+			entity_id INTEGER NOT NULL,
+			PRIMARY KEY(tag_id, entity_type, entity_id)
+		);`,
+		`CREATE TRIGGER entity_tags_project_ad AFTER DELETE ON projects BEGIN
+			DELETE FROM entity_tags WHERE entity_type = 'project' AND entity_id = old.id;
+		END;`,
+		`CREATE TRIGGER entity_tags_time_entry_ad AFTER DELETE ON time_entries BEGIN
+			DELETE FROM entity_tags WHERE entity_type = 'time_entry' AND entity_id = old.id;
+		END;`,
+		`CREATE TRIGGER entity_tags_transaction_ad AFTER DELETE ON finance_transactions BEGIN
+			DELETE FROM entity_tags WHERE entity_type = 'transaction' AND entity_id = old.id;
+		END;`,
+		`CREATE TABLE custom_field_definitions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER,
+			entity_type TEXT NOT NULL,
+			field_key TEXT NOT NULL,
+			label TEXT NOT NULL,
+			field_type TEXT NOT NULL,
+			options_json TEXT DEFAULT '[]',
+			show_on_invoice BOOLEAN DEFAULT 1,
+			sort_order INTEGER DEFAULT 0,
+			created_at TEXT DEFAULT (datetime('now')),
+			UNIQUE(user_id, entity_type, field_key)
+		);`,
+		`CREATE TABLE custom_field_values (
+			field_id INTEGER NOT NULL,
+			entity_id INTEGER NOT NULL,
+			value TEXT NOT NULL,
+			PRIMARY KEY(field_id, entity_id)
+		);`,
+		`CREATE TRIGGER custom_field_values_client_ad AFTER DELETE ON clients BEGIN
+			DELETE FROM custom_field_values WHERE entity_id = old.id
+			  AND field_id IN (SELECT id FROM custom_field_definitions WHERE entity_type = 'client');
+		END;`,
 	}
 
 	for _, query := range queries {
@@ -18,13 +18,27 @@ func NewTimesheetService(db *sql.DB) *TimesheetService {
 	return &TimesheetService{db: db}
 }
 
+// timeEntryColumns is the column list shared by the time entry queries.
+var timeEntryColumns = "id, project_id, invoice_id, date, start_time, end_time, duration_seconds, description, billable, invoiced, " + entityTagsSQL("time_entry", "time_entries.id")
+
 // List returns all time entries for a specific user, optionally filtered by project ID.
 func (s *TimesheetService) List(userID int, projectID int) []dto.TimeEntryOutput {
-	query := "SELECT id, project_id, invoice_id, date, start_time, end_time, duration_seconds, description, billable, invoiced FROM time_entries WHERE user_id = ?"
+	return s.ListFiltered(userID, dto.TimeEntryFilter{ProjectID: projectID})
+}
+
+// ListFiltered returns the user's time entries matching the filter.
+func (s *TimesheetService) ListFiltered(userID int, filter dto.TimeEntryFilter) []dto.TimeEntryOutput {
+	query := "SELECT " + timeEntryColumns + " FROM time_entries WHERE user_id = ?"
 	args := []interface{}{userID}
-	if projectID > 0 {
+	if filter.ProjectID > 0 {
 		query += " AND project_id = ?"
-		args = append(args, projectID)
+		args = append(args, filter.ProjectID)
+	}
+	if len(filter.Tags) > 0 {
+		entryTags, entryArgs := tagFilterSQL("time_entry", "time_entries.id", userID, filter.Tags)
+		projectTags, projectArgs := tagFilterSQL("project", "time_entries.project_id", userID, filter.Tags)
+		query += " AND (" + entryTags + " OR " + projectTags + ")"
+		args = append(append(args, entryArgs...), projectArgs...)
 	}
 
 	rows, err := s.db.Query(query, args...)
@@ -38,7 +52,8 @@ func (s *TimesheetService) List(userID int, projectID int) []dto.TimeEntryOutput
 	for rows.Next() {
 		var t models.TimeEntry
 		var invoiceID sql.NullInt64
-		err := rows.Scan(&t.ID, &t.ProjectID, &invoiceID, &t.Date, &t.StartTime, &t.EndTime, &t.DurationSeconds, &t.Description, &t.Billable, &t.Invoiced)
+		var tags sql.NullString
+		err := rows.Scan(&t.ID, &t.ProjectID, &invoiceID, &t.Date, &t.StartTime, &t.EndTime, &t.DurationSeconds, &t.Description, &t.Billable, &t.Invoiced, &tags)
 		if err != nil {
 			log.Println("Error scanning time entry:", err)
 			continue
@@ -48,6 +63,7 @@ func (s *TimesheetService) List(userID int, projectID int) []dto.TimeEntryOutput
 		} else {
 			t.InvoiceID = 0
 		}
+		t.Tags = splitTags(tags.String)
 		entries = append(entries, t)
 	}
 	return mapper.ToTimeEntryOutputList(entries)
@@ -55,10 +71,11 @@ func (s *TimesheetService) List(userID int, projectID int) []dto.TimeEntryOutput
 
 // Get returns a single time entry by ID for a specific user.
 func (s *TimesheetService) Get(userID int, id int) (dto.TimeEntryOutput, error) {
-	row := s.db.QueryRow("SELECT id, project_id, invoice_id, date, start_time, end_time, duration_seconds, description, billable, invoiced FROM time_entries WHERE id = ? AND user_id = ?", id, userID)
+	row := s.db.QueryRow("SELECT "+timeEntryColumns+" FROM time_entries WHERE id = ? AND user_id = ?", id, userID)
 	var t models.TimeEntry
 	var invoiceID sql.NullInt64
-	err := row.Scan(&t.ID, &t.ProjectID, &invoiceID, &t.Date, &t.StartTime, &t.EndTime, &t.DurationSeconds, &t.Description, &t.Billable, &t.Invoiced)
+	var tags sql.NullString
+	err := row.Scan(&t.ID, &t.ProjectID, &invoiceID, &t.Date, &t.StartTime, &t.EndTime, &t.DurationSeconds, &t.Description, &t.Billable, &t.Invoiced, &tags)
 	if err != nil {
 		return dto.TimeEntryOutput{}, err
 	}
@@ -67,6 +84,7 @@ func (s *TimesheetService) Get(userID int, id int) (dto.TimeEntryOutput, error)
 	} else {
 		t.InvoiceID = 0
 	}
+	t.Tags = splitTags(tags.String)
 	return mapper.ToTimeEntryOutput(t), nil
 }
 
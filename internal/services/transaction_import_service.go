@@ -0,0 +1,490 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"tally/internal/dto"
+	"tally/internal/mapper"
+	"tally/internal/models"
+	"time"
+	"unicode"
+)
+
+// TransactionImportService imports bank files in two phases: Parse shows every
+// row with what importing it would do, Commit imports the rows the user picked
+// as one batch, and RollbackBatch undoes a batch.
+type TransactionImportService struct {
+	db      *sql.DB
+	budgets BudgetAlerter
+}
+
+// NewTransactionImportService creates a new TransactionImportService instance.
+// budgets is told about each imported batch; nil skips budget alerts.
+func NewTransactionImportService(db *sql.DB, budgets BudgetAlerter) *TransactionImportService {
+	return &TransactionImportService{db: db, budgets: budgets}
+}
+
+// Import row statuses.
+const (
+	importStatusNew       = "new"
+	importStatusDuplicate = "duplicate"
+	importStatusReview    = "review" // near-duplicate: imported unless deselected, then flagged
+	importStatusError     = "error"
+)
+
+// nearDuplicateDays is how far apart a same-amount transaction may be dated
+// and still be flagged as a possible duplicate.
+const nearDuplicateDays = 3
+
+// classifiedRow is a parsed row with its import status.
+type classifiedRow struct {
+	importRow
+	Status        string
+	Reason        string
+	DuplicateOfID int
+	Fingerprint   string
+}
+
+// Parse reads an import file and classifies each row without saving anything.
+func (s *TransactionImportService) Parse(userID int, input dto.ImportTransactionsInput) (dto.ImportParseOutput, error) {
+	rows, format, balances, err := s.classify(userID, input)
+	if err != nil {
+		return dto.ImportParseOutput{}, err
+	}
+
+	out := dto.ImportParseOutput{Format: format, Rows: []dto.ImportRowOutput{}, Warnings: []string{}}
+	for _, b := range balances {
+		if err := b.check(); err != nil {
+			out.Warnings = append(out.Warnings, err.Error())
+		}
+	}
+	for _, r := range rows {
+		row := dto.ImportRowOutput{
+			Line:          r.Line,
+			Description:   r.Transaction.Description,
+			Amount:        r.Transaction.Amount,
+			ReferenceID:   r.Transaction.ReferenceID,
+			Status:        r.Status,
+			Reason:        r.Reason,
+			DuplicateOfID: r.DuplicateOfID,
+		}
+		if !r.Transaction.Date.IsZero() {
+			row.Date = r.Transaction.Date.Format("2006-01-02")
+		}
+		switch r.Status {
+		case importStatusNew:
+			out.NewCount++
+		case importStatusDuplicate:
+			out.DuplicateCount++
+		case importStatusReview:
+			out.ReviewCount++
+		default:
+			out.ErrorCount++
+		}
+		out.Rows = append(out.Rows, row)
+	}
+	return out, nil
+}
+
+// Commit imports the selected rows of a file as one batch. The file is parsed
+// again, so the selection refers to rows by line. Selected duplicates and
+// near-duplicates count as reviewed and are imported unflagged; rows that fail
+// to parse are ignored.
+func (s *TransactionImportService) Commit(userID int, input dto.CommitImportInput) (dto.ImportBatchOutput, error) {
+	if len(input.Lines) == 0 {
+		return dto.ImportBatchOutput{}, fmt.Errorf("no rows selected")
+	}
+	rows, format, _, err := s.classify(userID, input.Import)
+	if err != nil {
+		return dto.ImportBatchOutput{}, err
+	}
+
+	selected := map[int]bool{}
+	for _, line := range input.Lines {
+		selected[line] = true
+	}
+	var picked []classifiedRow
+	for _, r := range rows {
+		if selected[r.Line] && r.Status != importStatusError {
+			picked = append(picked, r)
+		}
+	}
+	if len(picked) == 0 {
+		return dto.ImportBatchOutput{}, fmt.Errorf("none of the selected rows can be imported")
+	}
+	return s.insertBatch(userID, input.Import, format, len(rows), picked, false)
+}
+
+const importBatchColumns = `b.id, b.account_id, COALESCE(a.name, ''), COALESCE(b.file_name, ''), b.format, b.row_count, b.imported_count,
+  (SELECT COUNT(*) FROM finance_transactions t WHERE t.import_batch_id = b.id), b.created_at, COALESCE(b.rolled_back_at, '')`
+
+// ListBatches returns the user's imports, newest first, optionally for one account.
+func (s *TransactionImportService) ListBatches(userID int, accountID int) []dto.ImportBatchOutput {
+	query := "SELECT " + importBatchColumns + " FROM import_batches b LEFT JOIN finance_accounts a ON a.id = b.account_id WHERE b.user_id = ?"
+	args := []interface{}{userID}
+	if accountID > 0 {
+		query += " AND b.account_id = ?"
+		args = append(args, accountID)
+	}
+	query += " ORDER BY b.id DESC"
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		log.Println("Error querying import batches:", err)
+		return []dto.ImportBatchOutput{}
+	}
+	defer closeWithLog(rows, "closing import batch rows")
+
+	var batches []models.ImportBatch
+	for rows.Next() {
+		b, err := scanImportBatch(rows)
+		if err != nil {
+			log.Println("Error scanning import batch:", err)
+			continue
+		}
+		batches = append(batches, b)
+	}
+	return mapper.ToImportBatchOutputList(batches)
+}
+
+// GetBatch returns one import batch.
+func (s *TransactionImportService) GetBatch(userID int, id int) (dto.ImportBatchOutput, error) {
+	row := s.db.QueryRow("SELECT "+importBatchColumns+" FROM import_batches b LEFT JOIN finance_accounts a ON a.id = b.account_id WHERE b.id = ? AND b.user_id = ?", id, userID)
+	b, err := scanImportBatch(row)
+	if err != nil {
+		return dto.ImportBatchOutput{}, fmt.Errorf("import batch not found: %w", err)
+	}
+	return mapper.ToImportBatchOutput(b), nil
+}
+
+// RollbackBatch deletes the transactions an import created. It refuses when
+// any of them has since been billed on an invoice or reconciled.
+func (s *TransactionImportService) RollbackBatch(userID int, id int) (dto.ImportBatchOutput, error) {
+	batch, err := s.GetBatch(userID, id)
+	if err != nil {
+		return dto.ImportBatchOutput{}, err
+	}
+	if batch.RolledBack {
+		return dto.ImportBatchOutput{}, fmt.Errorf("import batch was already rolled back")
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return dto.ImportBatchOutput{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var billed int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM finance_transactions WHERE import_batch_id = ? AND user_id = ? AND invoice_id IS NOT NULL", id, userID).Scan(&billed); err != nil {
+		return dto.ImportBatchOutput{}, fmt.Errorf("failed to check billed transactions: %w", err)
+	}
+	if billed > 0 {
+		return dto.ImportBatchOutput{}, fmt.Errorf("%d transaction(s) from this import were billed on invoices; remove them from the invoices first", billed)
+	}
+	var reconciled int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM finance_transactions WHERE import_batch_id = ? AND user_id = ? AND status = 'reconciled'", id, userID).Scan(&reconciled); err != nil {
+		return dto.ImportBatchOutput{}, fmt.Errorf("failed to check reconciled transactions: %w", err)
+	}
+	if reconciled > 0 {
+		return dto.ImportBatchOutput{}, fmt.Errorf("%d transaction(s) from this import are reconciled and locked", reconciled)
+	}
+	if _, err := tx.Exec("DELETE FROM finance_transactions WHERE import_batch_id = ? AND user_id = ?", id, userID); err != nil {
+		return dto.ImportBatchOutput{}, fmt.Errorf("failed to delete imported transactions: %w", err)
+	}
+	if _, err := tx.Exec("UPDATE import_batches SET rolled_back_at = datetime('now') WHERE id = ? AND user_id = ?", id, userID); err != nil {
+		return dto.ImportBatchOutput{}, fmt.Errorf("failed to mark import batch: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return dto.ImportBatchOutput{}, err
+	}
+	return s.GetBatch(userID, id)
+}
+
+func scanImportBatch(row rowScanner) (models.ImportBatch, error) {
+	var b models.ImportBatch
+	err := row.Scan(&b.ID, &b.AccountID, &b.AccountName, &b.FileName, &b.Format, &b.RowCount, &b.ImportedCount,
+		&b.TransactionCount, &b.CreatedAt, &b.RolledBackAt)
+	return b, err
+}
+
+// classify parses an import file and marks every row as new, duplicate of an
+// existing transaction, possible duplicate for review, or error.
+func (s *TransactionImportService) classify(userID int, input dto.ImportTransactionsInput) ([]classifiedRow, string, []statementBalance, error) {
+	var accounts int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM finance_accounts WHERE id = ? AND user_id = ?", input.AccountID, userID).Scan(&accounts); err != nil {
+		return nil, "", nil, fmt.Errorf("failed to check account: %w", err)
+	}
+	if accounts == 0 {
+		return nil, "", nil, fmt.Errorf("account not found")
+	}
+	format, err := detectImportFormat(input.Format, input.FileContent)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	input.Format = format
+	parsed, balances, err := parseImportFile(s.db, userID, input)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	if err := ensureFingerprints(s.db, userID, input.AccountID); err != nil {
+		return nil, "", nil, err
+	}
+
+	rows := make([]classifiedRow, 0, len(parsed))
+	occurrences := map[string]int{}
+	references := map[string]int{} // bank reference to the line it first appeared on
+	for _, p := range parsed {
+		r := classifiedRow{importRow: p, Status: importStatusNew}
+		if p.Err != nil {
+			r.Status, r.Reason = importStatusError, p.Err.Error()
+			rows = append(rows, r)
+			continue
+		}
+		t := p.Transaction
+		r.Fingerprint = transactionFingerprint(t.Date, t.Description, t.Amount)
+		if err := s.matchExisting(userID, input.AccountID, &r, occurrences, references); err != nil {
+			return nil, "", nil, err
+		}
+		rows = append(rows, r)
+	}
+	return rows, format, balances, nil
+}
+
+// matchExisting marks a row as a duplicate when the account already has it:
+// by the bank's reference when the row has one, else as the nth unreferenced
+// transaction with the same fingerprint, so that two identical coffees on one
+// day are two transactions. A same-amount transaction with another
+// fingerprint a few days off, or on the same day, marks the row for review
+// instead. A reference repeated within the file marks the repeat as a
+// duplicate too.
+func (s *TransactionImportService) matchExisting(userID int, accountID int, r *classifiedRow, occurrences map[string]int, references map[string]int) error {
+	t := r.Transaction
+	var existing int
+	if t.ReferenceID != "" {
+		err := s.db.QueryRow("SELECT id FROM finance_transactions WHERE user_id = ? AND account_id = ? AND reference_id = ? LIMIT 1",
+			userID, accountID, t.ReferenceID).Scan(&existing)
+		if err == nil {
+			r.Status, r.DuplicateOfID = importStatusDuplicate, existing
+			r.Reason = fmt.Sprintf("reference %s already imported", t.ReferenceID)
+			return nil
+		}
+		if err != sql.ErrNoRows {
+			return fmt.Errorf("failed to check for duplicates: %w", err)
+		}
+		if line, ok := references[t.ReferenceID]; ok {
+			r.Status = importStatusDuplicate
+			r.Reason = fmt.Sprintf("reference %s repeats line %d", t.ReferenceID, line)
+			return nil
+		}
+		references[t.ReferenceID] = r.Line
+	}
+
+	// Rows carrying a different bank reference are different transactions.
+	occurrences[r.Fingerprint]++
+	occurrence := occurrences[r.Fingerprint]
+	err := s.db.QueryRow(`SELECT id FROM finance_transactions
+WHERE user_id = ? AND account_id = ? AND fingerprint = ? AND COALESCE(reference_id, '') = ''
+ORDER BY id LIMIT 1 OFFSET ?`, userID, accountID, r.Fingerprint, occurrence-1).Scan(&existing)
+	if err == nil {
+		r.Status, r.DuplicateOfID = importStatusDuplicate, existing
+		r.Reason = "already imported"
+		if occurrence > 1 {
+			r.Reason = fmt.Sprintf("already imported (occurrence %d)", occurrence)
+		}
+		return nil
+	}
+	if err != sql.ErrNoRows {
+		return fmt.Errorf("failed to check for duplicates: %w", err)
+	}
+
+	rows, err := s.db.Query(`SELECT id, date, description FROM finance_transactions
+WHERE user_id = ? AND account_id = ? AND ROUND(amount * 100) = ROUND(? * 100) AND date BETWEEN ? AND ?
+  AND COALESCE(fingerprint, '') != ?
+ORDER BY id`, userID, accountID, t.Amount,
+		t.Date.AddDate(0, 0, -nearDuplicateDays).Format("2006-01-02"), t.Date.AddDate(0, 0, nearDuplicateDays).Format("2006-01-02"), r.Fingerprint)
+	if err != nil {
+		return fmt.Errorf("failed to check for near-duplicates: %w", err)
+	}
+	defer closeWithLog(rows, "closing near-duplicate rows")
+	for rows.Next() {
+		var id int
+		var date, description string
+		if err := rows.Scan(&id, &date, &description); err != nil {
+			return fmt.Errorf("failed to scan near-duplicate: %w", err)
+		}
+		if len(date) > 10 {
+			date = date[:10]
+		}
+		sameDay := date == t.Date.Format("2006-01-02")
+		if sameDay || descriptionSimilarity(description, t.Description) >= 0.5 {
+			r.Status, r.DuplicateOfID = importStatusReview, id
+			r.Reason = fmt.Sprintf("looks like %q on %s", description, date)
+			return nil
+		}
+	}
+	return rows.Err()
+}
+
+// insertBatch records an import batch and inserts its rows, categorizing them
+// by the user's rules. With flagReview, near-duplicates are marked for the
+// user to keep or delete later.
+func (s *TransactionImportService) insertBatch(userID int, input dto.ImportTransactionsInput, format string, rowCount int, rows []classifiedRow, flagReview bool) (dto.ImportBatchOutput, error) {
+	rules, err := loadCategorizationRules(s.db, userID)
+	if err != nil {
+		return dto.ImportBatchOutput{}, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return dto.ImportBatchOutput{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.Exec("INSERT INTO import_batches(user_id, account_id, file_name, format, profile_id, bank_type, row_count) VALUES(?, ?, ?, ?, ?, ?, ?)",
+		userID, input.AccountID, input.FileName, format, nullableID(input.ProfileID), input.BankType, rowCount)
+	if err != nil {
+		return dto.ImportBatchOutput{}, fmt.Errorf("failed to record import batch: %w", err)
+	}
+	batchID, _ := res.LastInsertId()
+
+	stmt, err := tx.Prepare(`INSERT INTO finance_transactions(user_id, account_id, date, description, amount, status, reference_id, import_batch_id,
+  fingerprint, possible_duplicate_of_id)
+VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return dto.ImportBatchOutput{}, err
+	}
+	defer closeWithLog(stmt, "closing import statement")
+
+	count := 0
+	for _, r := range rows {
+		t := r.Transaction
+		var duplicateOf interface{}
+		if flagReview && r.Status == importStatusReview {
+			duplicateOf = r.DuplicateOfID
+		}
+		res, err := stmt.Exec(userID, input.AccountID, t.Date.Format("2006-01-02"), t.Description, t.Amount, "pending", t.ReferenceID, batchID,
+			r.Fingerprint, duplicateOf)
+		if err != nil {
+			return dto.ImportBatchOutput{}, fmt.Errorf("failed to insert transaction from line %d: %w", r.Line, err)
+		}
+		id, _ := res.LastInsertId()
+		t.ID, t.AccountID = int(id), input.AccountID
+		if _, err := categorizeTransaction(tx, userID, t, rules); err != nil {
+			return dto.ImportBatchOutput{}, err
+		}
+		count++
+	}
+	if _, err := tx.Exec("UPDATE import_batches SET imported_count = ? WHERE id = ?", count, batchID); err != nil {
+		return dto.ImportBatchOutput{}, fmt.Errorf("failed to update import batch: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return dto.ImportBatchOutput{}, err
+	}
+	checkBudgets(s.budgets, userID)
+	return s.GetBatch(userID, int(batchID))
+}
+
+// ResolvePossibleDuplicate settles a transaction imported as a possible
+// duplicate: keep clears the flag, otherwise the transaction is deleted.
+func (s *TransactionImportService) ResolvePossibleDuplicate(userID int, transactionID int, keep bool) error {
+	query := "DELETE FROM finance_transactions WHERE id = ? AND user_id = ? AND possible_duplicate_of_id IS NOT NULL AND status != 'reconciled'"
+	if keep {
+		query = "UPDATE finance_transactions SET possible_duplicate_of_id = NULL WHERE id = ? AND user_id = ? AND possible_duplicate_of_id IS NOT NULL"
+	}
+	res, err := s.db.Exec(query, transactionID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to resolve possible duplicate: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("transaction not found or not flagged as a possible duplicate")
+	}
+	return nil
+}
+
+// transactionFingerprint identifies a transaction by date, amount in cents and
+// description, ignoring case, punctuation and spacing.
+func transactionFingerprint(date time.Time, description string, amount float64) string {
+	return fmt.Sprintf("%s|%d|%s", date.Format("2006-01-02"), int64(math.Round(amount*100)), normalizeDescription(description))
+}
+
+func normalizeDescription(description string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(description), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// descriptionSimilarity is the share of words two descriptions have in common.
+func descriptionSimilarity(a string, b string) float64 {
+	wordsA := strings.Fields(normalizeDescription(a))
+	wordsB := strings.Fields(normalizeDescription(b))
+	if len(wordsA) == 0 || len(wordsB) == 0 {
+		return 0
+	}
+	set := map[string]bool{}
+	for _, w := range wordsA {
+		set[w] = true
+	}
+	shared := 0
+	union := len(set)
+	counted := map[string]bool{}
+	for _, w := range wordsB {
+		if counted[w] {
+			continue
+		}
+		counted[w] = true
+		if set[w] {
+			shared++
+		} else {
+			union++
+		}
+	}
+	return float64(shared) / float64(union)
+}
+
+// ensureFingerprints fingerprints an account's transactions that predate
+// fingerprinting or were entered by hand.
+func ensureFingerprints(db *sql.DB, userID int, accountID int) error {
+	rows, err := db.Query("SELECT id, date, description, amount FROM finance_transactions WHERE user_id = ? AND account_id = ? AND fingerprint IS NULL ORDER BY id", userID, accountID)
+	if err != nil {
+		return fmt.Errorf("failed to load transactions to fingerprint: %w", err)
+	}
+	type pending struct {
+		id          int
+		fingerprint string
+	}
+	var todo []pending
+	for rows.Next() {
+		var id int
+		var date, description string
+		var amount float64
+		if err := rows.Scan(&id, &date, &description, &amount); err != nil {
+			closeWithLog(rows, "closing fingerprint rows")
+			return fmt.Errorf("failed to scan transaction: %w", err)
+		}
+		d, err := parseDate(strings.TrimSpace(date[:min(len(date), 10)]))
+		if err != nil {
+			continue
+		}
+		todo = append(todo, pending{id: id, fingerprint: transactionFingerprint(d, description, amount)})
+	}
+	closeWithLog(rows, "closing fingerprint rows")
+	if len(todo) == 0 {
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+	for _, p := range todo {
+		if _, err := tx.Exec("UPDATE finance_transactions SET fingerprint = ? WHERE id = ?", p.fingerprint, p.id); err != nil {
+			return fmt.Errorf("failed to fingerprint transaction: %w", err)
+		}
+	}
+	return tx.Commit()
+}
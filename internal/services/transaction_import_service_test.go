@@ -0,0 +1,178 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"tally/internal/dto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactionImportService_ParseCommitRollback(t *testing.T) {
+	db := setupFinanceTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	auth := NewAuthService(db)
+	user := createTestUser(t, auth, "two_phase_user")
+	other := createTestUser(t, auth, "two_phase_other")
+	finance := NewFinanceService(db, nil)
+	imports := NewTransactionImportService(db, nil)
+	acc := finance.CreateAccount(user.ID, dto.CreateAccountInput{Name: "Chequing", Type: "checking"})
+
+	// An earlier import already brought in the rent.
+	_, err := finance.ImportTransactions(user.ID, dto.ImportTransactionsInput{AccountID: acc.ID, FileContent: "2024-01-01,Rent,-1200.00\n"})
+	require.NoError(t, err)
+
+	file := dto.ImportTransactionsInput{
+		AccountID: acc.ID,
+		FileName:  "january.csv",
+		FileContent: "Date,Description,Amount\n" +
+			"2024-01-01,Rent,-1200.00\n" +
+			"2024-01-03,Coffee,-4.50\n" +
+			"2024-01-03,Coffee,-4.50\n" +
+			"not a date,Broken,-1\n" +
+			"2024-01-05,Client payment,2500.00\n",
+	}
+	parsed, err := imports.Parse(user.ID, file)
+	require.NoError(t, err)
+	assert.Equal(t, "csv", parsed.Format)
+	require.Len(t, parsed.Rows, 5)
+	assert.Equal(t, 3, parsed.NewCount)
+	assert.Equal(t, 1, parsed.DuplicateCount)
+	assert.Equal(t, 1, parsed.ErrorCount)
+
+	byLine := map[int]dto.ImportRowOutput{}
+	for _, r := range parsed.Rows {
+		byLine[r.Line] = r
+	}
+	assert.Equal(t, "duplicate", byLine[2].Status)
+	assert.NotZero(t, byLine[2].DuplicateOfID)
+	assert.Equal(t, "new", byLine[3].Status)
+	assert.Equal(t, "new", byLine[4].Status, "two identical coffees are two purchases")
+	assert.Equal(t, "error", byLine[5].Status)
+	assert.Contains(t, byLine[5].Reason, "invalid date")
+
+	// Parsing saved nothing; the user keeps both coffees and skips the rent.
+	assert.Len(t, finance.GetTransactions(user.ID, dto.TransactionFilter{AccountID: acc.ID}), 1)
+	_, err = imports.Commit(user.ID, dto.CommitImportInput{Import: file})
+	assert.Error(t, err)
+	_, err = imports.Commit(user.ID, dto.CommitImportInput{Import: file, Lines: []int{5}})
+	assert.Error(t, err, "only an error row selected")
+	_, err = imports.Commit(other.ID, dto.CommitImportInput{Import: file, Lines: []int{3}})
+	assert.Error(t, err, "another user's account")
+
+	batch, err := imports.Commit(user.ID, dto.CommitImportInput{Import: file, Lines: []int{3, 4, 5, 6}})
+	require.NoError(t, err)
+	assert.Equal(t, 3, batch.ImportedCount)
+	assert.Equal(t, 5, batch.RowCount)
+	assert.Equal(t, "january.csv", batch.FileName)
+	assert.Equal(t, "Chequing", batch.AccountName)
+	assert.Len(t, finance.GetTransactions(user.ID, dto.TransactionFilter{AccountID: acc.ID}), 4)
+
+	batches := imports.ListBatches(user.ID, acc.ID)
+	require.Len(t, batches, 2)
+	assert.Equal(t, batch.ID, batches[0].ID)
+	assert.Empty(t, imports.ListBatches(other.ID, 0))
+
+	// Rolling back removes exactly the batch, once.
+	_, err = imports.RollbackBatch(other.ID, batch.ID)
+	assert.Error(t, err)
+	rolled, err := imports.RollbackBatch(user.ID, batch.ID)
+	require.NoError(t, err)
+	assert.True(t, rolled.RolledBack)
+	assert.Equal(t, 0, rolled.TransactionCount)
+	txs := finance.GetTransactions(user.ID, dto.TransactionFilter{AccountID: acc.ID})
+	require.Len(t, txs, 1)
+	assert.Equal(t, "Rent", txs[0].Description)
+	_, err = imports.RollbackBatch(user.ID, batch.ID)
+	assert.Error(t, err)
+}
+
+func TestTransactionImportService_RollbackRefusesBilledTransactions(t *testing.T) {
+	db := setupFinanceTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	user := createTestUser(t, NewAuthService(db), "rollback_billed_user")
+	finance := NewFinanceService(db, nil)
+	imports := NewTransactionImportService(db, nil)
+	acc := finance.CreateAccount(user.ID, dto.CreateAccountInput{Name: "Card", Type: "credit"})
+
+	camt := strings.Replace(testCAMT053, "%CLOSING%", "1200.00", 1)
+	parsed, err := imports.Parse(user.ID, dto.ImportTransactionsInput{AccountID: acc.ID, FileContent: camt})
+	require.NoError(t, err)
+	assert.Equal(t, "camt053", parsed.Format)
+	require.Len(t, parsed.Warnings, 1, "an unbalanced statement warns instead of failing")
+
+	batch, err := imports.Commit(user.ID, dto.CommitImportInput{Import: dto.ImportTransactionsInput{AccountID: acc.ID, FileContent: camt}, Lines: []int{1, 2}})
+	require.NoError(t, err)
+	require.Equal(t, 2, batch.ImportedCount)
+
+	_, err = db.Exec("UPDATE finance_transactions SET invoice_id = 99 WHERE reference_id = 'BANKREF-1'")
+	require.NoError(t, err)
+	_, err = imports.RollbackBatch(user.ID, batch.ID)
+	assert.ErrorContains(t, err, "billed on invoices")
+	assert.Len(t, finance.GetTransactions(user.ID, dto.TransactionFilter{AccountID: acc.ID}), 2)
+}
+
+func TestTransactionImportService_Fingerprints(t *testing.T) {
+	db := setupFinanceTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	user := createTestUser(t, NewAuthService(db), "fingerprint_user")
+	finance := NewFinanceService(db, nil)
+	imports := NewTransactionImportService(db, nil)
+	acc := finance.CreateAccount(user.ID, dto.CreateAccountInput{Name: "Chequing", Type: "checking"})
+
+	count, err := finance.ImportTransactions(user.ID, dto.ImportTransactionsInput{AccountID: acc.ID, FileContent: "2024-03-01,Coffee Shop,-4.50\n2024-03-01,Coffee Shop,-4.50\n"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, count, "identical rows in one file are both kept")
+
+	// A later export with a third coffee and different spacing and case keeps
+	// only the third; a same-amount charge two days off needs review.
+	parsed, err := imports.Parse(user.ID, dto.ImportTransactionsInput{AccountID: acc.ID, FileContent: "2024-03-01,COFFEE  SHOP,-4.50\n" +
+		"2024-03-01,coffee shop.,-4.50\n" +
+		"2024-03-01,Coffee Shop,-4.50\n" +
+		"2024-03-03,Coffee Shop #12,-4.50\n" +
+		"2024-03-03,Bakery,-4.50\n"})
+	require.NoError(t, err)
+	statuses := []string{}
+	for _, r := range parsed.Rows {
+		statuses = append(statuses, r.Status)
+	}
+	assert.Equal(t, []string{"duplicate", "duplicate", "new", "review", "new"}, statuses)
+	assert.Contains(t, parsed.Rows[3].Reason, "Coffee Shop")
+	assert.Equal(t, 1, parsed.ReviewCount)
+
+	// A bank reference is matched first; the rest fall back to fingerprints
+	// against transactions imported without one. A reference the file
+	// repeats is a duplicate of its first row.
+	_, err = db.Exec("UPDATE finance_transactions SET reference_id = 'FIT-1' WHERE id = (SELECT MIN(id) FROM finance_transactions WHERE account_id = ?)", acc.ID)
+	require.NoError(t, err)
+	coffee := "<STMTTRN><DTPOSTED>20240301</DTPOSTED><TRNAMT>-4.50</TRNAMT><FITID>%s</FITID><NAME>Coffee Shop</NAME></STMTTRN>"
+	ofx := "<OFX><BANKTRANLIST>" + fmt.Sprintf(coffee, "FIT-1") + fmt.Sprintf(coffee, "FIT-2") + fmt.Sprintf(coffee, "FIT-3") + fmt.Sprintf(coffee, "FIT-3") + "</BANKTRANLIST></OFX>"
+	parsed, err = imports.Parse(user.ID, dto.ImportTransactionsInput{AccountID: acc.ID, FileContent: ofx})
+	require.NoError(t, err)
+	require.Len(t, parsed.Rows, 4)
+	assert.Equal(t, "duplicate", parsed.Rows[0].Status)
+	assert.Contains(t, parsed.Rows[0].Reason, "FIT-1")
+	assert.Equal(t, "duplicate", parsed.Rows[1].Status)
+	assert.Equal(t, "new", parsed.Rows[2].Status)
+	assert.Equal(t, "duplicate", parsed.Rows[3].Status)
+	assert.Equal(t, fmt.Sprintf("reference FIT-3 repeats line %d", parsed.Rows[2].Line), parsed.Rows[3].Reason)
+
+	// The one-step import brings near-duplicates in flagged for review.
+	count, err = finance.ImportTransactions(user.ID, dto.ImportTransactionsInput{AccountID: acc.ID, FileContent: "2024-03-02,Coffee Shop Downtown,-4.50\n2024-03-20,Lunch,-12.00\n"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	flagged := finance.GetTransactions(user.ID, dto.TransactionFilter{AccountID: acc.ID, NeedsReview: true})
+	require.Len(t, flagged, 1)
+	assert.Equal(t, "Coffee Shop Downtown", flagged[0].Description)
+	require.NotNil(t, flagged[0].PossibleDuplicateOfID)
+
+	require.NoError(t, imports.ResolvePossibleDuplicate(user.ID, flagged[0].ID, false))
+	assert.Error(t, imports.ResolvePossibleDuplicate(user.ID, flagged[0].ID, true), "already resolved")
+	assert.Empty(t, finance.GetTransactions(user.ID, dto.TransactionFilter{AccountID: acc.ID, NeedsReview: true}))
+	assert.Len(t, finance.GetTransactions(user.ID, dto.TransactionFilter{AccountID: acc.ID}), 3)
+}
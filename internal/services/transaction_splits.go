@@ -0,0 +1,121 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+	"tally/internal/dto"
+)
+
+// transactionLinesSQL yields one row per unsplit transaction and one per
+// split, with the category and amount that row counts toward. Aggregations
+// by category or sign read from it so that splits are respected.
+const transactionLinesSQL = `SELECT t.id AS transaction_id, t.user_id, t.account_id, t.date, t.client_id, t.project_id, t.transfer_id,
+  CASE WHEN sp.id IS NULL THEN t.category_id ELSE sp.category_id END AS category_id,
+  COALESCE(sp.amount, t.amount) AS amount
+FROM finance_transactions t LEFT JOIN transaction_splits sp ON sp.transaction_id = t.id`
+
+// uncategorizedSQL is a predicate for transactions (alias t) that have
+// neither a category nor splits and are not transfers.
+const uncategorizedSQL = "t.category_id IS NULL AND t.transfer_id IS NULL AND NOT EXISTS (SELECT 1 FROM transaction_splits sp WHERE sp.transaction_id = t.id)"
+
+// GetTransactionSplits returns the splits of a transaction, empty when it isn't split.
+func (s *FinanceService) GetTransactionSplits(userID int, transactionID int) ([]dto.TransactionSplitOutput, error) {
+	splits, err := s.loadSplits(userID, []int{transactionID})
+	if err != nil {
+		return nil, err
+	}
+	if out := splits[transactionID]; out != nil {
+		return out, nil
+	}
+	return []dto.TransactionSplitOutput{}, nil
+}
+
+// SetTransactionSplits divides a transaction across categories, replacing
+// any previous splits and its own category. Without splits it goes back to
+// being uncategorized.
+func (s *FinanceService) SetTransactionSplits(userID int, input dto.SetTransactionSplitsInput) ([]dto.TransactionSplitOutput, error) {
+	var amount float64
+	if err := s.db.QueryRow("SELECT amount FROM finance_transactions WHERE id = ? AND user_id = ?", input.TransactionID, userID).Scan(&amount); err != nil {
+		return nil, fmt.Errorf("transaction not found: %w", err)
+	}
+	if len(input.Splits) == 1 {
+		return nil, fmt.Errorf("a split needs at least two parts; set the category instead")
+	}
+	total := 0.0
+	for i, sp := range input.Splits {
+		if sp.Amount == 0 {
+			return nil, fmt.Errorf("split %d has no amount", i+1)
+		}
+		var categories int
+		if err := s.db.QueryRow("SELECT COUNT(*) FROM finance_categories WHERE id = ? AND user_id = ?", sp.CategoryID, userID).Scan(&categories); err != nil {
+			return nil, fmt.Errorf("failed to check category: %w", err)
+		}
+		if categories == 0 {
+			return nil, fmt.Errorf("split %d: category not found", i+1)
+		}
+		total += sp.Amount
+	}
+	if len(input.Splits) > 0 && math.Abs(total-amount) >= 0.005 {
+		return nil, fmt.Errorf("splits add up to %.2f but the transaction is %.2f", total, amount)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	if _, err := tx.Exec("DELETE FROM transaction_splits WHERE transaction_id = ? AND user_id = ?", input.TransactionID, userID); err != nil {
+		return nil, fmt.Errorf("failed to clear splits: %w", err)
+	}
+	for i, sp := range input.Splits {
+		if _, err := tx.Exec("INSERT INTO transaction_splits(user_id, transaction_id, category_id, amount, memo, sort_order) VALUES(?, ?, ?, ?, ?, ?)",
+			userID, input.TransactionID, sp.CategoryID, sp.Amount, strings.TrimSpace(sp.Memo), i); err != nil {
+			return nil, fmt.Errorf("failed to insert split: %w", err)
+		}
+	}
+	if _, err := tx.Exec("UPDATE finance_transactions SET category_id = NULL, category_rule_id = NULL, updated_at = datetime('now') WHERE id = ? AND user_id = ?",
+		input.TransactionID, userID); err != nil {
+		return nil, fmt.Errorf("failed to update transaction: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	checkBudgets(s.budgets, userID)
+	return s.GetTransactionSplits(userID, input.TransactionID)
+}
+
+// loadSplits returns the splits of the given transactions by transaction ID.
+func (s *FinanceService) loadSplits(userID int, transactionIDs []int) (map[int][]dto.TransactionSplitOutput, error) {
+	out := map[int][]dto.TransactionSplitOutput{}
+	if len(transactionIDs) == 0 {
+		return out, nil
+	}
+	placeholders := make([]string, len(transactionIDs))
+	args := []interface{}{userID}
+	for i, id := range transactionIDs {
+		placeholders[i] = "?"
+		args = append(args, id)
+	}
+	// #nosec G202 -- only placeholders are concatenated.
+	rows, err := s.db.Query(`SELECT sp.id, sp.transaction_id, sp.category_id, COALESCE(c.name, ''), COALESCE(c.color, ''), sp.amount, COALESCE(sp.memo, '')
+FROM transaction_splits sp LEFT JOIN finance_categories c ON c.id = sp.category_id
+WHERE sp.user_id = ? AND sp.transaction_id IN (`+strings.Join(placeholders, ", ")+`)
+ORDER BY sp.transaction_id, sp.sort_order, sp.id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load splits: %w", err)
+	}
+	defer closeWithLog(rows, "closing split rows")
+	for rows.Next() {
+		var sp dto.TransactionSplitOutput
+		var transactionID int
+		var categoryID sql.NullInt64
+		if err := rows.Scan(&sp.ID, &transactionID, &categoryID, &sp.CategoryName, &sp.CategoryColor, &sp.Amount, &sp.Memo); err != nil {
+			return nil, fmt.Errorf("failed to scan split: %w", err)
+		}
+		sp.CategoryID = nullIntPtr(categoryID)
+		out[transactionID] = append(out[transactionID], sp)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"tally/internal/dto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFinanceService_TransactionSplits(t *testing.T) {
+	db := setupFinanceTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	auth := NewAuthService(db)
+	user := createTestUser(t, auth, "splits_user")
+	other := createTestUser(t, auth, "splits_other")
+	finance := NewFinanceService(db, nil)
+	reports := NewReportService(db)
+	acc := finance.CreateAccount(user.ID, dto.CreateAccountInput{Name: "Card", Type: "credit"})
+	office := finance.CreateCategory(user.ID, dto.CreateCategoryInput{Name: "Office", Type: "expense"})
+	groceries := finance.CreateCategory(user.ID, dto.CreateCategoryInput{Name: "Groceries", Type: "expense"})
+	refunds := finance.CreateCategory(user.ID, dto.CreateCategoryInput{Name: "Refunds", Type: "income"})
+	theirs := finance.CreateCategory(other.ID, dto.CreateCategoryInput{Name: "Theirs", Type: "expense"})
+
+	_, err := finance.ImportTransactions(user.ID, dto.ImportTransactionsInput{AccountID: acc.ID, FileContent: "2024-06-01,COSTCO WHOLESALE,-150.00\n" +
+		"2024-06-02,Paycheque,1000.00\n"})
+	require.NoError(t, err)
+	var costco dto.TransactionOutput
+	for _, tx := range finance.GetTransactions(user.ID, dto.TransactionFilter{AccountID: acc.ID}) {
+		if tx.Description == "COSTCO WHOLESALE" {
+			costco = tx
+		}
+	}
+	require.NotZero(t, costco.ID)
+
+	_, err = finance.SetTransactionSplits(user.ID, dto.SetTransactionSplitsInput{TransactionID: costco.ID, Splits: []dto.TransactionSplitInput{
+		{CategoryID: office.ID, Amount: -100}, {CategoryID: groceries.ID, Amount: -40},
+	}})
+	assert.ErrorContains(t, err, "add up to")
+	_, err = finance.SetTransactionSplits(user.ID, dto.SetTransactionSplitsInput{TransactionID: costco.ID, Splits: []dto.TransactionSplitInput{
+		{CategoryID: office.ID, Amount: -150},
+	}})
+	assert.Error(t, err, "a single split is just a category")
+	_, err = finance.SetTransactionSplits(user.ID, dto.SetTransactionSplitsInput{TransactionID: costco.ID, Splits: []dto.TransactionSplitInput{
+		{CategoryID: office.ID, Amount: -100}, {CategoryID: theirs.ID, Amount: -50},
+	}})
+	assert.ErrorContains(t, err, "category not found")
+	_, err = finance.SetTransactionSplits(other.ID, dto.SetTransactionSplitsInput{TransactionID: costco.ID})
+	assert.Error(t, err)
+
+	// A refund line makes one part of the charge income.
+	finance.UpdateTransaction(user.ID, costco.ID, &groceries.ID)
+	splits, err := finance.SetTransactionSplits(user.ID, dto.SetTransactionSplitsInput{TransactionID: costco.ID, Splits: []dto.TransactionSplitInput{
+		{CategoryID: office.ID, Amount: -120, Memo: " Printer "},
+		{CategoryID: groceries.ID, Amount: -40},
+		{CategoryID: refunds.ID, Amount: 10, Memo: "Returned item"},
+	}})
+	require.NoError(t, err)
+	require.Len(t, splits, 3)
+	assert.Equal(t, "Office", splits[0].CategoryName)
+	assert.Equal(t, "Printer", splits[0].Memo)
+
+	for _, tx := range finance.GetTransactions(user.ID, dto.TransactionFilter{AccountID: acc.ID}) {
+		if tx.ID == costco.ID {
+			assert.Nil(t, tx.CategoryID, "a split transaction has no category of its own")
+			assert.Len(t, tx.Splits, 3)
+		}
+	}
+	for _, s := range NewCategorySuggestionService(db, nil).Suggest(user.ID, acc.ID) {
+		assert.NotEqual(t, costco.ID, s.TransactionID, "split transactions are categorized")
+	}
+
+	summary := finance.GetSummary(user.ID)
+	assert.InDelta(t, 1010, summary.TotalIncome, 0.001)
+	assert.InDelta(t, 160, summary.TotalExpense, 0.001)
+
+	report, err := reports.GetCategoryReport(user.ID, dto.ReportFilter{StartDate: "2024-06-01", EndDate: "2024-06-30"})
+	require.NoError(t, err)
+	byName := map[string]dto.CategoryReportRow{}
+	for _, r := range report.Rows {
+		byName[r.CategoryName] = r
+	}
+	assert.InDelta(t, 120, byName["Office"].Expense, 0.001)
+	assert.InDelta(t, 40, byName["Groceries"].Expense, 0.001)
+	assert.InDelta(t, 10, byName["Refunds"].Income, 0.001)
+	assert.InDelta(t, 1000, byName["Uncategorized"].Income, 0.001)
+	assert.InDelta(t, 850, report.Net, 0.001)
+
+	// Setting a category by hand replaces the splits.
+	finance.UpdateTransaction(user.ID, costco.ID, &office.ID)
+	splits, err = finance.GetTransactionSplits(user.ID, costco.ID)
+	require.NoError(t, err)
+	assert.Empty(t, splits)
+	report, err = reports.GetCategoryReport(user.ID, dto.ReportFilter{})
+	require.NoError(t, err)
+	for _, r := range report.Rows {
+		if r.CategoryName == "Office" {
+			assert.InDelta(t, 150, r.Expense, 0.001)
+		}
+	}
+
+	// Deleting the transaction removes its splits.
+	_, err = finance.SetTransactionSplits(user.ID, dto.SetTransactionSplitsInput{TransactionID: costco.ID, Splits: []dto.TransactionSplitInput{
+		{CategoryID: office.ID, Amount: -75}, {CategoryID: groceries.ID, Amount: -75},
+	}})
+	require.NoError(t, err)
+	require.NoError(t, finance.DeleteTransaction(user.ID, costco.ID))
+	var remaining int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM transaction_splits").Scan(&remaining))
+	assert.Zero(t, remaining)
+}
@@ -0,0 +1,251 @@
+package services
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"tally/internal/dto"
+	"time"
+)
+
+// TransferService links money moved between the user's own accounts, such as
+// paying a credit card from chequing. The outgoing and incoming transactions
+// of a transfer point at each other and count as neither income nor expense.
+type TransferService struct {
+	db *sql.DB
+}
+
+// NewTransferService creates a new TransferService instance.
+func NewTransferService(db *sql.DB) *TransferService {
+	return &TransferService{db: db}
+}
+
+// transferWindowDays is how far apart the two sides of a transfer may be
+// dated; banks often post the incoming side a day or two later.
+const transferWindowDays = 4
+
+const transferColumns = `o.id, i.id, o.account_id, COALESCE(oa.name, ''), i.account_id, COALESCE(ia.name, ''),
+  i.amount, o.date, i.date, o.description, i.description`
+
+const transferFrom = ` FROM finance_transactions o
+JOIN finance_transactions i ON i.id = o.transfer_id AND i.user_id = o.user_id
+LEFT JOIN finance_accounts oa ON oa.id = o.account_id
+LEFT JOIN finance_accounts ia ON ia.id = i.account_id`
+
+// transferProposalFrom joins two transactions that are not linked yet.
+const transferProposalFrom = ` FROM finance_transactions o
+JOIN finance_transactions i ON i.user_id = o.user_id
+LEFT JOIN finance_accounts oa ON oa.id = o.account_id
+LEFT JOIN finance_accounts ia ON ia.id = i.account_id`
+
+// List returns the user's transfers, newest first.
+func (s *TransferService) List(userID int) []dto.TransferOutput {
+	rows, err := s.db.Query("SELECT "+transferColumns+transferFrom+" WHERE o.user_id = ? AND o.amount < 0 ORDER BY o.date DESC, o.id DESC", userID)
+	if err != nil {
+		log.Println("Error querying transfers:", err)
+		return []dto.TransferOutput{}
+	}
+	defer closeWithLog(rows, "closing transfer rows")
+
+	out := []dto.TransferOutput{}
+	for rows.Next() {
+		t, err := scanTransfer(rows)
+		if err != nil {
+			log.Println("Error scanning transfer:", err)
+			continue
+		}
+		out = append(out, t)
+	}
+	return out
+}
+
+// Detect proposes transfers: unlinked transactions in different accounts
+// whose amounts are opposite and whose dates are at most transferWindowDays
+// apart, closest dates first. Nothing is linked; the user confirms a proposal
+// with Link, since a client deposit and a card purchase of the same amount
+// can look alike. Transactions that are categorized, split, billed on an
+// invoice, awaiting duplicate review or were unlinked by the user are left
+// alone.
+func (s *TransferService) Detect(userID int) ([]dto.TransferOutput, error) {
+	rows, err := s.db.Query(`SELECT t.id, t.account_id, t.date, t.amount FROM finance_transactions t
+WHERE t.user_id = ? AND t.transfer_id IS NULL AND COALESCE(t.not_transfer, 0) = 0
+  AND t.invoice_id IS NULL AND t.possible_duplicate_of_id IS NULL AND t.category_id IS NULL AND t.amount != 0
+  AND NOT EXISTS (SELECT 1 FROM transaction_splits sp WHERE sp.transaction_id = t.id)
+ORDER BY t.date, t.id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transfer candidates: %w", err)
+	}
+	type side struct {
+		id, accountID int
+		date          time.Time
+	}
+	// Outgoing and incoming sides keyed by amount in cents.
+	outgoing := map[int64][]side{}
+	incoming := map[int64][]side{}
+	for rows.Next() {
+		var sd side
+		var date string
+		var amount float64
+		if err := rows.Scan(&sd.id, &sd.accountID, &date, &amount); err != nil {
+			closeWithLog(rows, "closing transfer candidate rows")
+			return nil, fmt.Errorf("failed to scan transfer candidate: %w", err)
+		}
+		d, err := parseDate(date[:min(len(date), 10)])
+		if err != nil {
+			continue
+		}
+		sd.date = d
+		cents := int64(math.Round(amount * 100))
+		if cents < 0 {
+			outgoing[-cents] = append(outgoing[-cents], sd)
+		} else {
+			incoming[cents] = append(incoming[cents], sd)
+		}
+	}
+	closeWithLog(rows, "closing transfer candidate rows")
+
+	type pair struct {
+		out, in side
+		gap     int
+	}
+	var pairs []pair
+	for cents, outs := range outgoing {
+		for _, o := range outs {
+			for _, i := range incoming[cents] {
+				gap := int(math.Abs(i.date.Sub(o.date).Hours() / 24))
+				if i.accountID != o.accountID && gap <= transferWindowDays {
+					pairs = append(pairs, pair{out: o, in: i, gap: gap})
+				}
+			}
+		}
+	}
+	sort.Slice(pairs, func(a, b int) bool {
+		if pairs[a].gap != pairs[b].gap {
+			return pairs[a].gap < pairs[b].gap
+		}
+		if pairs[a].out.id != pairs[b].out.id {
+			return pairs[a].out.id < pairs[b].out.id
+		}
+		return pairs[a].in.id < pairs[b].in.id
+	})
+
+	used := map[int]bool{}
+	out := []dto.TransferOutput{}
+	for _, p := range pairs {
+		if used[p.out.id] || used[p.in.id] {
+			continue
+		}
+		used[p.out.id], used[p.in.id] = true, true
+		t, err := scanTransfer(s.db.QueryRow("SELECT "+transferColumns+transferProposalFrom+" WHERE o.id = ? AND i.id = ? AND o.user_id = ?",
+			p.out.id, p.in.id, userID))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load proposed transfer: %w", err)
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+// Link pairs two transactions as a transfer by hand. They must be in
+// different accounts with opposite amounts and not already linked.
+func (s *TransferService) Link(userID int, input dto.LinkTransferInput) (dto.TransferOutput, error) {
+	if input.TransactionID == input.CounterpartID {
+		return dto.TransferOutput{}, fmt.Errorf("a transfer needs two transactions")
+	}
+	type side struct {
+		id, accountID, splits int
+		amount                float64
+		transferID            sql.NullInt64
+	}
+	sides := make([]side, 2)
+	for i, id := range []int{input.TransactionID, input.CounterpartID} {
+		err := s.db.QueryRow(`SELECT t.id, t.account_id, t.amount, t.transfer_id,
+  (SELECT COUNT(*) FROM transaction_splits sp WHERE sp.transaction_id = t.id)
+FROM finance_transactions t WHERE t.id = ? AND t.user_id = ?`, id, userID).
+			Scan(&sides[i].id, &sides[i].accountID, &sides[i].amount, &sides[i].transferID, &sides[i].splits)
+		if err != nil {
+			return dto.TransferOutput{}, fmt.Errorf("transaction %d not found: %w", id, err)
+		}
+		if sides[i].transferID.Valid {
+			return dto.TransferOutput{}, fmt.Errorf("transaction %d is already part of a transfer", id)
+		}
+		if sides[i].splits > 0 {
+			return dto.TransferOutput{}, fmt.Errorf("transaction %d is split across categories", id)
+		}
+	}
+	if sides[0].accountID == sides[1].accountID {
+		return dto.TransferOutput{}, fmt.Errorf("a transfer moves money between two different accounts")
+	}
+	if sides[0].amount == 0 || math.Abs(sides[0].amount+sides[1].amount) >= 0.005 {
+		return dto.TransferOutput{}, fmt.Errorf("the amounts %.2f and %.2f do not offset each other", sides[0].amount, sides[1].amount)
+	}
+	out, in := sides[0], sides[1]
+	if out.amount > 0 {
+		out, in = in, out
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return dto.TransferOutput{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	if err := linkTransfer(tx, userID, out.id, in.id); err != nil {
+		return dto.TransferOutput{}, err
+	}
+	if err := tx.Commit(); err != nil {
+		return dto.TransferOutput{}, err
+	}
+	return s.get(userID, out.id)
+}
+
+// Unlink splits a transfer back into ordinary income and expense, given
+// either of its transactions. Detection won't pair the two again.
+func (s *TransferService) Unlink(userID int, transactionID int) error {
+	var counterpart sql.NullInt64
+	if err := s.db.QueryRow("SELECT transfer_id FROM finance_transactions WHERE id = ? AND user_id = ?", transactionID, userID).Scan(&counterpart); err != nil {
+		return fmt.Errorf("transaction not found: %w", err)
+	}
+	if !counterpart.Valid {
+		return fmt.Errorf("transaction is not part of a transfer")
+	}
+	if _, err := s.db.Exec("UPDATE finance_transactions SET transfer_id = NULL, not_transfer = 1, updated_at = datetime('now') WHERE id IN (?, ?) AND user_id = ?",
+		transactionID, counterpart.Int64, userID); err != nil {
+		return fmt.Errorf("failed to unlink transfer: %w", err)
+	}
+	return nil
+}
+
+// get loads the transfer whose outgoing side is the given transaction.
+func (s *TransferService) get(userID int, outgoingID int) (dto.TransferOutput, error) {
+	t, err := scanTransfer(s.db.QueryRow("SELECT "+transferColumns+transferFrom+" WHERE o.id = ? AND o.user_id = ?", outgoingID, userID))
+	if err != nil {
+		return dto.TransferOutput{}, fmt.Errorf("transfer not found: %w", err)
+	}
+	return t, nil
+}
+
+// linkTransfer points the two sides of a transfer at each other.
+func linkTransfer(exec sqlExecutor, userID int, outgoingID int, incomingID int) error {
+	if _, err := exec.Exec("UPDATE finance_transactions SET transfer_id = ?, not_transfer = 0, updated_at = datetime('now') WHERE id = ? AND user_id = ?",
+		incomingID, outgoingID, userID); err != nil {
+		return fmt.Errorf("failed to link transfer: %w", err)
+	}
+	if _, err := exec.Exec("UPDATE finance_transactions SET transfer_id = ?, not_transfer = 0, updated_at = datetime('now') WHERE id = ? AND user_id = ?",
+		outgoingID, incomingID, userID); err != nil {
+		return fmt.Errorf("failed to link transfer: %w", err)
+	}
+	return nil
+}
+
+func scanTransfer(row rowScanner) (dto.TransferOutput, error) {
+	var t dto.TransferOutput
+	if err := row.Scan(&t.OutgoingID, &t.IncomingID, &t.FromAccountID, &t.FromAccountName, &t.ToAccountID, &t.ToAccountName,
+		&t.Amount, &t.FromDate, &t.ToDate, &t.FromDescription, &t.ToDescription); err != nil {
+		return t, err
+	}
+	t.FromDate = t.FromDate[:min(len(t.FromDate), 10)]
+	t.ToDate = t.ToDate[:min(len(t.ToDate), 10)]
+	return t, nil
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"tally/internal/dto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransferService_Detect(t *testing.T) {
+	db := setupFinanceTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	user := createTestUser(t, NewAuthService(db), "transfers_user")
+	finance := NewFinanceService(db, nil)
+	transfers := NewTransferService(db)
+	chequing := finance.CreateAccount(user.ID, dto.CreateAccountInput{Name: "Chequing", Type: "checking"})
+	card := finance.CreateAccount(user.ID, dto.CreateAccountInput{Name: "Visa", Type: "credit"})
+
+	_, err := finance.ImportTransactions(user.ID, dto.ImportTransactionsInput{AccountID: chequing.ID, FileContent: "2024-07-01,Paycheque,3000.00\n" +
+		"2024-07-03,VISA PAYMENT,-500.00\n" +
+		"2024-07-20,Rent,-1200.00\n"})
+	require.NoError(t, err)
+	detected, err := transfers.Detect(user.ID)
+	require.NoError(t, err)
+	assert.Empty(t, detected, "one side alone is not a transfer")
+
+	// The card statement brings the other side two days later, plus a
+	// same-amount refund too far away to pair.
+	_, err = finance.ImportTransactions(user.ID, dto.ImportTransactionsInput{AccountID: card.ID, FileContent: "2024-07-02,Groceries,-80.00\n" +
+		"2024-07-05,PAYMENT - THANK YOU,500.00\n" +
+		"2024-07-15,Refund,500.00\n"})
+	require.NoError(t, err)
+	assert.Empty(t, transfers.List(user.ID), "import links nothing by itself")
+	_, err = finance.ImportTransactions(user.ID, dto.ImportTransactionsInput{AccountID: card.ID, FileContent: "2024-07-21,Client deposit,1200.00\n"})
+	require.NoError(t, err)
+	category := finance.CreateCategory(user.ID, dto.CreateCategoryInput{Name: "Sales", Type: "income"})
+	for _, tx := range finance.GetTransactions(user.ID, dto.TransactionFilter{AccountID: card.ID}) {
+		if tx.Description == "Client deposit" {
+			finance.UpdateTransaction(user.ID, tx.ID, &category.ID)
+		}
+	}
+
+	detected, err = transfers.Detect(user.ID)
+	require.NoError(t, err)
+	require.Len(t, detected, 1, "the categorized deposit is not proposed against the rent")
+	assert.Equal(t, "2024-07-03", detected[0].FromDate)
+	assert.Equal(t, "2024-07-05", detected[0].ToDate)
+	assert.Empty(t, transfers.List(user.ID), "proposals are not linked")
+	_, err = transfers.Link(user.ID, dto.LinkTransferInput{TransactionID: detected[0].OutgoingID, CounterpartID: detected[0].IncomingID})
+	require.NoError(t, err)
+
+	list := transfers.List(user.ID)
+	require.Len(t, list, 1)
+	assert.Equal(t, chequing.ID, list[0].FromAccountID)
+	assert.Equal(t, "Visa", list[0].ToAccountName)
+	assert.InDelta(t, 500, list[0].Amount, 0.001)
+	assert.Equal(t, "2024-07-03", list[0].FromDate)
+	assert.Equal(t, "2024-07-05", list[0].ToDate)
+
+	summary := finance.GetSummary(user.ID)
+	assert.InDelta(t, 4700, summary.TotalIncome, 0.001, "the transfer is not income")
+	assert.InDelta(t, 1280, summary.TotalExpense, 0.001, "the transfer is not an expense")
+	for _, tx := range finance.GetTransactions(user.ID, dto.TransactionFilter{}) {
+		if tx.ID == list[0].OutgoingID {
+			require.NotNil(t, tx.TransferID)
+			assert.Equal(t, list[0].IncomingID, *tx.TransferID)
+		}
+	}
+	assert.Len(t, finance.GetTransactions(user.ID, dto.TransactionFilter{ExcludeTransfers: true}), 5)
+	for _, s := range NewCategorySuggestionService(db, nil).Suggest(user.ID, 0) {
+		assert.NotEqual(t, list[0].OutgoingID, s.TransactionID, "transfers need no category")
+	}
+
+	// Unlinking sticks: detection does not pair them again.
+	require.NoError(t, transfers.Unlink(user.ID, list[0].IncomingID))
+	assert.Error(t, transfers.Unlink(user.ID, list[0].IncomingID))
+	detected, err = transfers.Detect(user.ID)
+	require.NoError(t, err)
+	assert.Empty(t, detected)
+	assert.InDelta(t, 5200, finance.GetSummary(user.ID).TotalIncome, 0.001)
+
+	// Deleting one side of a transfer unlinks the other.
+	linked, err := transfers.Link(user.ID, dto.LinkTransferInput{TransactionID: list[0].IncomingID, CounterpartID: list[0].OutgoingID})
+	require.NoError(t, err)
+	assert.Equal(t, list[0].OutgoingID, linked.OutgoingID, "the negative side is the outgoing one")
+	require.NoError(t, finance.DeleteTransaction(user.ID, linked.IncomingID))
+	assert.Empty(t, transfers.List(user.ID))
+	var transferID *int
+	for _, tx := range finance.GetTransactions(user.ID, dto.TransactionFilter{AccountID: chequing.ID}) {
+		if tx.ID == linked.OutgoingID {
+			transferID = tx.TransferID
+		}
+	}
+	assert.Nil(t, transferID)
+}
+
+func TestTransferService_Link(t *testing.T) {
+	db := setupFinanceTestDB(t)
+	defer func() { _ = db.Close() }()
+
+	auth := NewAuthService(db)
+	user := createTestUser(t, auth, "transfers_link_user")
+	other := createTestUser(t, auth, "transfers_link_other")
+	finance := NewFinanceService(db, nil)
+	transfers := NewTransferService(db)
+	chequing := finance.CreateAccount(user.ID, dto.CreateAccountInput{Name: "Chequing", Type: "checking"})
+	savings := finance.CreateAccount(user.ID, dto.CreateAccountInput{Name: "Savings", Type: "savings"})
+
+	// Weekly transfers of the same amount pair by closest date.
+	_, err := finance.ImportTransactions(user.ID, dto.ImportTransactionsInput{AccountID: chequing.ID, FileContent: "2024-08-01,To savings,-100.00\n" +
+		"2024-08-08,To savings,-100.00\n" +
+		"2024-08-09,Coffee,-4.00\n" +
+		"2024-08-12,Cheque to savings,-50.00\n"})
+	require.NoError(t, err)
+	_, err = finance.ImportTransactions(user.ID, dto.ImportTransactionsInput{AccountID: savings.ID, FileContent: "2024-08-02,From chequing,100.00\n" +
+		"2024-08-08,From chequing,100.00\n" +
+		"2024-08-25,Cheque deposit,50.00\n"})
+	require.NoError(t, err)
+	proposed, err := transfers.Detect(user.ID)
+	require.NoError(t, err)
+	for _, p := range proposed {
+		_, err := transfers.Link(user.ID, dto.LinkTransferInput{TransactionID: p.OutgoingID, CounterpartID: p.IncomingID})
+		require.NoError(t, err)
+	}
+	list := transfers.List(user.ID)
+	require.Len(t, list, 2)
+	for _, tr := range list {
+		assert.Contains(t, []string{"2024-08-01|2024-08-02", "2024-08-08|2024-08-08"}, tr.FromDate+"|"+tr.ToDate)
+	}
+
+	ids := map[string]int{}
+	for _, tx := range finance.GetTransactions(user.ID, dto.TransactionFilter{}) {
+		ids[tx.Description] = tx.ID
+	}
+	_, err = transfers.Link(user.ID, dto.LinkTransferInput{TransactionID: ids["Coffee"], CounterpartID: ids["Coffee"]})
+	assert.Error(t, err)
+	_, err = transfers.Link(user.ID, dto.LinkTransferInput{TransactionID: ids["Coffee"], CounterpartID: list[0].IncomingID})
+	assert.ErrorContains(t, err, "already part of a transfer")
+	_, err = transfers.Link(user.ID, dto.LinkTransferInput{TransactionID: ids["Coffee"], CounterpartID: ids["Cheque deposit"]})
+	assert.ErrorContains(t, err, "do not offset")
+	_, err = transfers.Link(user.ID, dto.LinkTransferInput{TransactionID: ids["Coffee"], CounterpartID: ids["Cheque to savings"]})
+	assert.ErrorContains(t, err, "different accounts")
+
+	// A cheque that took too long to clear is linked by hand.
+	_, err = transfers.Link(other.ID, dto.LinkTransferInput{TransactionID: ids["Cheque to savings"], CounterpartID: ids["Cheque deposit"]})
+	assert.ErrorContains(t, err, "not found")
+	linked, err := transfers.Link(user.ID, dto.LinkTransferInput{TransactionID: ids["Cheque to savings"], CounterpartID: ids["Cheque deposit"]})
+	require.NoError(t, err)
+	assert.Equal(t, "Savings", linked.ToAccountName)
+	assert.Len(t, transfers.List(user.ID), 3)
+}
@@ -2,10 +2,12 @@ package services
 
 import (
 	"database/sql"
+	"fmt"
 	"log"
 	"tally/internal/dto"
 	"tally/internal/mapper"
 	"tally/internal/models"
+	"tally/internal/utils"
 )
 
 // UserInvoiceSettingsService manages invoice templates and sender info.
@@ -21,11 +23,13 @@ func NewUserInvoiceSettingsService(db *sql.DB) *UserInvoiceSettingsService {
 // Get retrieves user invoice settings from DB.
 func (s *UserInvoiceSettingsService) Get(userID int) (dto.UserInvoiceSettings, error) {
 	query := `SELECT 
-		user_id, sender_name, sender_company, sender_address, sender_phone, sender_email, sender_postal_code, default_terms, default_message_template
+		user_id, sender_name, sender_company, sender_address, sender_phone, sender_email, sender_postal_code, default_terms, default_message_template,
+		payment_terms, late_fee_type, late_fee_value
 		FROM user_invoice_settings WHERE user_id = ?`
 
 	var settings models.UserInvoiceSettings
-	var sName, sCompany, sAddress, sPhone, sEmail, sPostal, dTerms, dTemplate sql.NullString
+	var sName, sCompany, sAddress, sPhone, sEmail, sPostal, dTerms, dTemplate, pTerms, feeType sql.NullString
+	var feeValue sql.NullFloat64
 
 	err := s.db.QueryRow(query, userID).Scan(
 		&settings.UserID,
@@ -37,6 +41,9 @@ func (s *UserInvoiceSettingsService) Get(userID int) (dto.UserInvoiceSettings, e
 		&sPostal,
 		&dTerms,
 		&dTemplate,
+		&pTerms,
+		&feeType,
+		&feeValue,
 	)
 
 	if err != nil {
@@ -71,6 +78,9 @@ func (s *UserInvoiceSettingsService) Get(userID int) (dto.UserInvoiceSettings, e
 	if dTemplate.Valid {
 		settings.DefaultMessageTemplate = dTemplate.String
 	}
+	settings.PaymentTerms = pTerms.String
+	settings.LateFeeType = feeType.String
+	settings.LateFeeValue = feeValue.Float64
 
 	return mapper.ToUserInvoiceSettingsDTO(settings), nil
 }
@@ -84,9 +94,20 @@ func (s *UserInvoiceSettingsService) Update(userID int, input dto.UserInvoiceSet
 	if input.DefaultMessageTemplate == "" {
 		input.DefaultMessageTemplate = "Thank you for your business."
 	}
+	if err := utils.ValidatePaymentTerms(input.PaymentTerms); err != nil {
+		return dto.UserInvoiceSettings{}, err
+	}
+	switch input.LateFeeType {
+	case "", "flat", "percent":
+	default:
+		return dto.UserInvoiceSettings{}, fmt.Errorf("unknown late fee type: %s", input.LateFeeType)
+	}
+	if input.LateFeeValue < 0 {
+		return dto.UserInvoiceSettings{}, fmt.Errorf("late fee must not be negative")
+	}
 
-	query := `INSERT INTO user_invoice_settings (user_id, sender_name, sender_company, sender_address, sender_phone, sender_email, sender_postal_code, default_terms, default_message_template, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, datetime('now'))
+	query := `INSERT INTO user_invoice_settings (user_id, sender_name, sender_company, sender_address, sender_phone, sender_email, sender_postal_code, default_terms, default_message_template, payment_terms, late_fee_type, late_fee_value, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, datetime('now'))
 		ON CONFLICT(user_id) DO UPDATE SET
 		sender_name=excluded.sender_name,
 		sender_company=excluded.sender_company,
@@ -96,9 +117,12 @@ func (s *UserInvoiceSettingsService) Update(userID int, input dto.UserInvoiceSet
 		sender_postal_code=excluded.sender_postal_code,
 		default_terms=excluded.default_terms,
 		default_message_template=excluded.default_message_template,
+		payment_terms=excluded.payment_terms,
+		late_fee_type=excluded.late_fee_type,
+		late_fee_value=excluded.late_fee_value,
 		updated_at=datetime('now')`
 
-	_, err := s.db.Exec(query, userID, input.SenderName, input.SenderCompany, input.SenderAddress, input.SenderPhone, input.SenderEmail, input.SenderPostalCode, input.DefaultTerms, input.DefaultMessageTemplate)
+	_, err := s.db.Exec(query, userID, input.SenderName, input.SenderCompany, input.SenderAddress, input.SenderPhone, input.SenderEmail, input.SenderPostalCode, input.DefaultTerms, input.DefaultMessageTemplate, input.PaymentTerms, input.LateFeeType, input.LateFeeValue)
 	if err != nil {
 		log.Printf("Error updating user invoice settings for user %d: %v", userID, err)
 		return dto.UserInvoiceSettings{}, err
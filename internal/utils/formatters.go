@@ -78,7 +78,8 @@ func FormatServiceType(serviceType string) string {
 
 // ApplyTemplate replaces placeholders in a template string with values from the data struct.
 // It supports placeholders like {{key}} matching struct fields case-insensitively or by json tag.
-func ApplyTemplate(tmplStr string, data interface{}) string {
+// Extra maps (e.g. custom field values by key) supply further placeholders; struct fields win.
+func ApplyTemplate(tmplStr string, data interface{}, extra ...map[string]string) string {
 	if tmplStr == "" {
 		return ""
 	}
@@ -95,6 +96,11 @@ func ApplyTemplate(tmplStr string, data interface{}) string {
 	}
 
 	dataMap := make(map[string]string)
+	for _, m := range extra {
+		for k, v := range m {
+			dataMap[strings.ToLower(k)] = v
+		}
+	}
 	typ := val.Type()
 	for i := 0; i < val.NumField(); i++ {
 		field := typ.Field(i)
@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Payment term codes. Net and end-of-month terms carry a day count,
+// e.g. "net_30" or "eom_10".
+const (
+	TermsDueOnReceipt = "due_on_receipt"
+	TermsNetPrefix    = "net_"
+	TermsEOMPrefix    = "eom_"
+)
+
+// parsePaymentTerms splits a terms code into its kind and day count.
+func parsePaymentTerms(terms string) (kind string, days int, err error) {
+	terms = strings.ToLower(strings.TrimSpace(terms))
+	if terms == TermsDueOnReceipt {
+		return TermsDueOnReceipt, 0, nil
+	}
+	for _, prefix := range []string{TermsNetPrefix, TermsEOMPrefix} {
+		if rest, ok := strings.CutPrefix(terms, prefix); ok {
+			n, convErr := strconv.Atoi(rest)
+			if convErr != nil || n < 0 || n > 365 {
+				return "", 0, fmt.Errorf("invalid payment terms %q", terms)
+			}
+			return prefix, n, nil
+		}
+	}
+	return "", 0, fmt.Errorf("invalid payment terms %q", terms)
+}
+
+// ValidatePaymentTerms reports whether terms is a supported code. Empty is allowed.
+func ValidatePaymentTerms(terms string) error {
+	if strings.TrimSpace(terms) == "" {
+		return nil
+	}
+	_, _, err := parsePaymentTerms(terms)
+	return err
+}
+
+// DueDateForTerms returns the due date (YYYY-MM-DD) for an invoice issued on issueDate.
+// End-of-month terms count days from the last day of the issue month.
+func DueDateForTerms(issueDate string, terms string) (string, error) {
+	issued, err := time.Parse("2006-01-02", issueDate)
+	if err != nil {
+		return "", fmt.Errorf("invalid issue date %q: expected YYYY-MM-DD", issueDate)
+	}
+	kind, days, err := parsePaymentTerms(terms)
+	if err != nil {
+		return "", err
+	}
+
+	switch kind {
+	case TermsNetPrefix:
+		issued = issued.AddDate(0, 0, days)
+	case TermsEOMPrefix:
+		endOfMonth := time.Date(issued.Year(), issued.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+		issued = endOfMonth.AddDate(0, 0, days)
+	}
+	return issued.Format("2006-01-02"), nil
+}
+
+// PaymentTermsLabel returns the human-readable form of a terms code, e.g. "Net 30".
+// Unknown codes are returned unchanged so free-text terms still display.
+func PaymentTermsLabel(terms string) string {
+	kind, days, err := parsePaymentTerms(terms)
+	if err != nil {
+		return terms
+	}
+	switch kind {
+	case TermsNetPrefix:
+		return fmt.Sprintf("Net %d", days)
+	case TermsEOMPrefix:
+		if days == 0 {
+			return "End of month"
+		}
+		return fmt.Sprintf("End of month + %d", days)
+	}
+	return "Due on receipt"
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"embed"
 	"tally/internal/db"
 	"tally/internal/services"
@@ -38,7 +39,25 @@ func main() {
 	userInvoiceSettingsService := services.NewUserInvoiceSettingsService(dbConn)
 	reportService := services.NewReportService(dbConn)
 	statusBarService := services.NewStatusBarService(dbConn)
-	financeService := services.NewFinanceService(dbConn)
+	budgetService := services.NewBudgetService(dbConn)
+	financeService := services.NewFinanceService(dbConn, budgetService)
+	rateCardService := services.NewRateCardService(dbConn)
+	paymentService := services.NewPaymentService(dbConn)
+	statementService := services.NewStatementService(dbConn)
+	clientContactService := services.NewClientContactService(dbConn)
+	searchService := services.NewSearchService(dbConn)
+	tagService := services.NewTagService(dbConn)
+	customFieldService := services.NewCustomFieldService(dbConn)
+	purchaseOrderService := services.NewPurchaseOrderService(dbConn)
+	importProfileService := services.NewImportProfileService(dbConn)
+	transactionImportService := services.NewTransactionImportService(dbConn, budgetService)
+	categorizationRuleService := services.NewCategorizationRuleService(dbConn, budgetService)
+	categorySuggestionService := services.NewCategorySuggestionService(dbConn, budgetService)
+	reconciliationService := services.NewReconciliationService(dbConn)
+	transferService := services.NewTransferService(dbConn)
+	invoiceMatchService := services.NewInvoiceMatchService(dbConn)
+	attachmentService := services.NewAttachmentService(dbConn)
+	recurringExpenseService := services.NewRecurringExpenseService(dbConn)
 	servicesDuration := time.Since(servicesStart)
 
 	app.SetBootTimings(BootTimings{
@@ -60,7 +79,10 @@ func main() {
 			Assets: assets,
 		},
 		BackgroundColour: &options.RGBA{R: 27, G: 38, B: 54, A: 1},
-		OnStartup:        app.startup,
+		OnStartup: func(ctx context.Context) {
+			app.startup(ctx)
+			services.Startup(ctx, budgetService)
+		},
 		Bind: []interface{}{
 			app,
 			authService,
@@ -77,6 +99,24 @@ func main() {
 			reportService,
 			statusBarService,
 			financeService,
+			rateCardService,
+			paymentService,
+			statementService,
+			clientContactService,
+			searchService,
+			tagService,
+			customFieldService,
+			purchaseOrderService,
+			importProfileService,
+			transactionImportService,
+			categorizationRuleService,
+			categorySuggestionService,
+			reconciliationService,
+			transferService,
+			invoiceMatchService,
+			budgetService,
+			attachmentService,
+			recurringExpenseService,
 		},
 	})
 